@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/gateways/importfile"
+	"homework/internal/usecase"
+	"io"
+	"os"
+	"strings"
+)
+
+// runImport - подкоманда import: импортирует датчики и события из файлов CSV или NDJSON и печатает отчёт.
+// Формат файла определяется по расширению. Без -user датчики регистрируются без владельца,
+// с -user - от имени пользователя, как через POST /import. Несуществующий пользователь - ошибка,
+// иначе датчики привязались бы к несуществующему пользователю. Отчёт печатается в w
+func runImport(ctx context.Context, w io.Writer, imp *usecase.Import, users *usecase.User, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	sensorsPath := flags.String("sensors", "", "file with sensors to register, .csv or .ndjson")
+	eventsPath := flags.String("events", "", "file with events to import, .csv or .ndjson")
+	userID := flags.Int64("user", 0, "id of the user on whose behalf the import runs")
+	dryRun := flags.Bool("dry-run", false, "validate the files and print the report without saving anything")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *sensorsPath == "" && *eventsPath == "" {
+		return errors.New("nothing to import, set -sensors or -events")
+	}
+	if *userID != 0 {
+		if _, err := users.GetUserByID(ctx, *userID); err != nil {
+			return fmt.Errorf("user %d: %w", *userID, err)
+		}
+		ctx = usecase.WithCaller(ctx, *userID)
+	}
+
+	sensors, closeSensors, err := openImportFile(*sensorsPath, importfile.NewSensorReader)
+	if err != nil {
+		return err
+	}
+	defer closeSensors()
+	events, closeEvents, err := openImportFile(*eventsPath, importfile.NewEventReader)
+	if err != nil {
+		return err
+	}
+	defer closeEvents()
+
+	report, err := imp.Import(ctx, sensors, events, *dryRun)
+	printImportReport(w, report)
+	return err
+}
+
+// openImportFile - открывает файл импорта, для пустого пути возвращает nil
+func openImportFile[T any](path string, newReader func(io.Reader, importfile.Format) (usecase.ImportReader[T], error)) (usecase.ImportReader[T], func(), error) {
+	if path == "" {
+		return nil, func() {}, nil
+	}
+	format, ok := importfile.FormatOf(path)
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown format of %s, expected .csv or .ndjson", path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, err := newReader(file, format)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return reader, func() { _ = file.Close() }, nil
+}
+
+func printImportReport(w io.Writer, report *domain.ImportReport) {
+	if report.DryRun {
+		fmt.Fprintln(w, "dry run, nothing is saved")
+	}
+	fmt.Fprintf(w, "sensors: %d created, %d existing, %d rejected\n",
+		report.SensorsCreated, report.SensorsExisting, report.SensorsRejected)
+	fmt.Fprintf(w, "events: %d imported, %d duplicate, %d rejected\n",
+		report.EventsImported, report.EventsDuplicate, report.EventsRejected)
+	// ключи новых датчиков больше нигде не показываются, без них датчики не смогут отправлять события
+	for _, key := range report.SensorKeys {
+		fmt.Fprintf(w, "key %s: %s\n", key.SerialNumber, key.APIKey)
+	}
+	for _, importErr := range report.Errors {
+		// причины, собранные errors.Join, печатаются в одну строку
+		fmt.Fprintf(w, "%s:%d: %s\n", importErr.Source, importErr.Line, strings.ReplaceAll(importErr.Err.Error(), "\n", ": "))
+	}
+	if rejected := report.SensorsRejected + report.EventsRejected; rejected > int64(len(report.Errors)) {
+		fmt.Fprintf(w, "... and %d more rejected records\n", rejected-int64(len(report.Errors)))
+	}
+}
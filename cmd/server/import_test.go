@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	eventRepository "homework/internal/repository/event/inmemory"
+	homeRepository "homework/internal/repository/home/inmemory"
+	sensorRepository "homework/internal/repository/sensor/inmemory"
+	transaction "homework/internal/repository/transaction/inmemory"
+	userRepository "homework/internal/repository/user/inmemory"
+)
+
+// importFixture - сценарии импорта на репозиториях в памяти
+type importFixture struct {
+	imp   *usecase.Import
+	users *usecase.User
+	ur    *userRepository.UserRepository
+	sr    *sensorRepository.SensorRepository
+	sor   *userRepository.SensorOwnerRepository
+}
+
+func newImportFixture() *importFixture {
+	er := eventRepository.NewEventRepository()
+	sr := sensorRepository.NewSensorRepository()
+	ur := userRepository.NewUserRepository()
+	sor := userRepository.NewSensorOwnerRepository()
+	skr := sensorRepository.NewSensorKeyRepository()
+	str := sensorRepository.NewSensorTypeRepository()
+	hr := homeRepository.NewHomeRepository()
+	tr := transaction.NewTransactor()
+	return &importFixture{
+		imp: usecase.NewImport(
+			usecase.NewSensor(sr, sor, skr, hr, str, tr),
+			usecase.NewEvent(er, sr, sor, hr, str, tr),
+			tr,
+		),
+		users: usecase.NewUser(ur, sor, sr, hr, tr),
+		ur:    ur,
+		sr:    sr,
+		sor:   sor,
+	}
+}
+
+// writeImportFile - пишет файл импорта во временный каталог теста и возвращает путь к нему
+func writeImportFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestRunImport(t *testing.T) {
+	timestamp := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339Nano)
+	sensors := "serial_number,type,description\n0000000001,cc,Дверь\n0000000002,unknown,\n"
+	events := `{"sensor_serial_number": "0000000001", "timestamp": "` + timestamp + `", "payload": 1}` + "\n" +
+		`{"sensor_serial_number": "0000000009", "timestamp": "` + timestamp + `", "payload": 1}` + "\n"
+
+	t.Run("ok, sensors and events imported for user", func(t *testing.T) {
+		ctx := context.Background()
+		f := newImportFixture()
+		user := &domain.User{Name: "Пользователь"}
+		require.NoError(t, f.ur.SaveUser(ctx, user))
+
+		var out bytes.Buffer
+		err := runImport(ctx, &out, f.imp, f.users, []string{
+			"-user", strconv.FormatInt(user.ID, 10),
+			"-sensors", writeImportFile(t, "sensors.csv", sensors),
+			"-events", writeImportFile(t, "events.ndjson", events),
+		})
+		require.NoError(t, err)
+
+		report := out.String()
+		assert.Contains(t, report, "sensors: 1 created, 0 existing, 1 rejected\n")
+		assert.Contains(t, report, "events: 1 imported, 0 duplicate, 1 rejected\n")
+		assert.Contains(t, report, "key 0000000001: ", "Ключ нового датчика не напечатан")
+		assert.Contains(t, report, "sensors:3: ")
+		assert.Contains(t, report, "events:2: ")
+		assert.NotContains(t, report, "dry run")
+
+		sensor, err := f.sr.GetSensorBySerialNumber(ctx, "0000000001")
+		require.NoError(t, err)
+		owners, err := f.sor.GetSensorOwners(ctx, sensor.ID)
+		require.NoError(t, err)
+		if assert.Len(t, owners, 1, "Датчик не привязан к пользователю из -user") {
+			assert.Equal(t, user.ID, owners[0].UserID)
+		}
+	})
+
+	t.Run("ok, dry run saves nothing", func(t *testing.T) {
+		ctx := context.Background()
+		f := newImportFixture()
+
+		var out bytes.Buffer
+		err := runImport(ctx, &out, f.imp, f.users, []string{
+			"-dry-run",
+			"-sensors", writeImportFile(t, "sensors.csv", sensors),
+		})
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "dry run, nothing is saved\n")
+		assert.Contains(t, out.String(), "sensors: 1 created, 0 existing, 1 rejected\n")
+
+		_, err = f.sr.GetSensorBySerialNumber(ctx, "0000000001")
+		assert.ErrorIs(t, err, usecase.ErrSensorNotFound, "Проверочный импорт сохранил датчик")
+	})
+
+	t.Run("fail, invalid arguments", func(t *testing.T) {
+		ctx := context.Background()
+		f := newImportFixture()
+
+		tests := map[string][]string{
+			"nothing to import": nil,
+			"unknown flag":      {"-sensor", "sensors.csv"},
+			"unknown format":    {"-sensors", writeImportFile(t, "sensors.txt", sensors)},
+			"missing file":      {"-events", filepath.Join(t.TempDir(), "events.csv")},
+			"missing column":    {"-sensors", writeImportFile(t, "sensors.csv", "type\ncc\n")},
+			"unknown user":      {"-user", "42", "-sensors", writeImportFile(t, "sensors.csv", sensors)},
+		}
+		for name, args := range tests {
+			var out bytes.Buffer
+			err := runImport(ctx, &out, f.imp, f.users, args)
+			assert.Error(t, err, name)
+			assert.Empty(t, out.String(), "Отчёт напечатан, хотя импорт не начался: %s", name)
+		}
+
+		_, err := f.sr.GetSensorBySerialNumber(ctx, "0000000001")
+		assert.ErrorIs(t, err, usecase.ErrSensorNotFound, "Датчик сохранён, хотя импорт не начался")
+	})
+}
+
+func TestPrintImportReport(t *testing.T) {
+	t.Run("ok, full report", func(t *testing.T) {
+		var out bytes.Buffer
+		printImportReport(&out, &domain.ImportReport{
+			DryRun:          true,
+			SensorsCreated:  1,
+			SensorsExisting: 2,
+			SensorsRejected: 1,
+			EventsImported:  3,
+			EventsDuplicate: 4,
+			EventsRejected:  3,
+			SensorKeys:      []domain.ImportSensorKey{{SerialNumber: "0000000001", APIKey: "key"}},
+			Errors: []domain.ImportError{
+				{Source: domain.ImportSourceSensors, Line: 3, Err: usecase.ErrWrongSensorType},
+				{Source: domain.ImportSourceEvents, Line: 5, Err: errors.Join(usecase.ErrInvalidImportRecord, errors.New("bad payload"))},
+			},
+		})
+		assert.Equal(t, "dry run, nothing is saved\n"+
+			"sensors: 1 created, 2 existing, 1 rejected\n"+
+			"events: 3 imported, 4 duplicate, 3 rejected\n"+
+			"key 0000000001: key\n"+
+			"sensors:3: "+usecase.ErrWrongSensorType.Error()+"\n"+
+			"events:5: "+usecase.ErrInvalidImportRecord.Error()+": bad payload\n"+
+			"... and 2 more rejected records\n", out.String())
+	})
+
+	t.Run("ok, empty report", func(t *testing.T) {
+		var out bytes.Buffer
+		printImportReport(&out, &domain.ImportReport{})
+		assert.Equal(t, "sensors: 0 created, 0 existing, 0 rejected\n"+
+			"events: 0 imported, 0 duplicate, 0 rejected\n", out.String())
+	})
+}
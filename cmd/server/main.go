@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"homework/internal/usecase"
 	"log"
@@ -9,15 +10,22 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	httpGateway "homework/internal/gateways/http"
+	"homework/internal/repository/event/archive"
 	eventRepository "homework/internal/repository/event/postgres"
+	homeRepository "homework/internal/repository/home/postgres"
 	sensorRepository "homework/internal/repository/sensor/postgres"
+	transaction "homework/internal/repository/transaction/postgres"
 	userRepository "homework/internal/repository/user/postgres"
 )
 
+// defaultRetentionInterval - как часто удаляются события с истёкшим сроком хранения
+const defaultRetentionInterval = time.Hour
+
 func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
@@ -33,17 +41,91 @@ func main() {
 	}
 	defer pool.Close()
 
-	er := eventRepository.NewEventRepository(pool)
+	dedupWindow := usecase.DefaultEventDedupWindow
+	if value := os.Getenv("EVENT_DEDUP_WINDOW"); value != "" {
+		if dedupWindow, err = time.ParseDuration(value); err != nil || dedupWindow < 0 {
+			log.Fatalf("can't parse EVENT_DEDUP_WINDOW")
+		}
+	}
+
+	er := eventRepository.NewEventRepository(pool, eventRepository.WithDedupWindow(dedupWindow))
 	sr := sensorRepository.NewSensorRepository(pool)
 	ur := userRepository.NewUserRepository(pool)
 	sor := userRepository.NewSensorOwnerRepository(pool)
+	skr := sensorRepository.NewSensorKeyRepository(pool)
+	str := sensorRepository.NewSensorTypeRepository(pool)
+	hr := homeRepository.NewHomeRepository(pool)
+	rr := homeRepository.NewRoomRepository(pool)
+	tr := transaction.NewTransactor(pool)
+
+	secret := []byte(os.Getenv("AUTH_SECRET"))
+	if len(secret) == 0 {
+		log.Printf("AUTH_SECRET is not set, issued tokens will not survive a restart")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalf("can't generate auth secret")
+		}
+	}
+
+	var sensorOptions []func(*usecase.Sensor)
+	if sensorKeySecret := os.Getenv("SENSOR_KEY_SECRET"); sensorKeySecret != "" {
+		sensorOptions = append(sensorOptions, usecase.WithSensorKeySecret([]byte(sensorKeySecret)))
+	} else {
+		log.Printf("SENSOR_KEY_SECRET is not set, signed sensor requests will be rejected")
+	}
+
+	maxClockSkew := usecase.DefaultMaxClockSkew
+	if value := os.Getenv("EVENT_MAX_CLOCK_SKEW"); value != "" {
+		if maxClockSkew, err = time.ParseDuration(value); err != nil || maxClockSkew < 0 {
+			log.Fatalf("can't parse EVENT_MAX_CLOCK_SKEW")
+		}
+	}
+
+	var retentionOptions []func(*usecase.Retention)
+	if value := os.Getenv("EVENT_RETENTION_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			log.Fatalf("can't parse EVENT_RETENTION_DAYS")
+		}
+		retentionOptions = append(retentionOptions, usecase.WithDefaultRetention(time.Duration(days)*24*time.Hour))
+	}
+	if dir := os.Getenv("EVENT_ARCHIVE_DIR"); dir != "" {
+		retentionOptions = append(retentionOptions, usecase.WithEventArchive(archive.NewEventArchive(dir)))
+	}
+	if os.Getenv("EVENT_RETENTION_PRUNE_ROLLUPS") == "true" {
+		retentionOptions = append(retentionOptions, usecase.WithRollupsPruning())
+	}
+	retentionInterval := defaultRetentionInterval
+	if value := os.Getenv("EVENT_RETENTION_INTERVAL"); value != "" {
+		if retentionInterval, err = time.ParseDuration(value); err != nil || retentionInterval <= 0 {
+			log.Fatalf("can't parse EVENT_RETENTION_INTERVAL")
+		}
+	}
+	retention := usecase.NewRetention(eventRepository.NewRetentionRepository(pool), er, sr, str, tr, retentionOptions...)
 
+	event := usecase.NewEvent(er, sr, sor, hr, str, tr, usecase.WithMaxClockSkew(maxClockSkew))
+	sensor := usecase.NewSensor(sr, sor, skr, hr, str, tr, sensorOptions...)
 	useCases := httpGateway.UseCases{
-		Event:  usecase.NewEvent(er, sr),
-		Sensor: usecase.NewSensor(sr),
-		User:   usecase.NewUser(ur, sor, sr),
+		Auth:       usecase.NewAuth(ur, secret),
+		Event:      event,
+		Sensor:     sensor,
+		SensorType: usecase.NewSensorType(str),
+		User:       usecase.NewUser(ur, sor, sr, hr, tr),
+		Home:       usecase.NewHome(hr, ur, sr, tr),
+		Room:       usecase.NewRoom(rr, hr, sr, sor, tr),
+		Retention:  retention,
+		Import:     usecase.NewImport(sensor, event, tr),
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(ctx, os.Stdout, useCases.Import, useCases.User, os.Args[2:]); err != nil {
+			log.Fatalf("import failed: %v", err)
+		}
+		return
+	}
+
+	go pruneExpiredEvents(ctx, retention, retentionInterval)
+
 	host := os.Getenv("HTTP_HOST")
 	if host == "" {
 		host = "localhost"
@@ -53,8 +135,38 @@ func main() {
 		port = 8080
 	}
 
-	r := httpGateway.NewServer(useCases, httpGateway.WithHost(host), httpGateway.WithPort(uint16(port)))
+	serverOptions := []func(*httpGateway.Server){httpGateway.WithHost(host), httpGateway.WithPort(uint16(port))}
+	if value := os.Getenv("STREAM_SLOW_CONSUMER_POLICY"); value != "" {
+		policy, ok := httpGateway.SlowConsumerPolicyOf(value)
+		if !ok {
+			log.Fatalf("can't parse STREAM_SLOW_CONSUMER_POLICY")
+		}
+		serverOptions = append(serverOptions, httpGateway.WithSlowConsumerPolicy(policy))
+	}
+
+	r := httpGateway.NewServer(useCases, serverOptions...)
 	if err := r.Run(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Printf("error during server shutdown: %v", err)
 	}
 }
+
+// pruneExpiredEvents - удаляет события с истёкшим сроком хранения сразу после запуска и затем раз в interval,
+// пока не отменён ctx
+func pruneExpiredEvents(ctx context.Context, retention *usecase.Retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		pruned, err := retention.PruneExpiredEvents(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("error during events pruning: %v", err)
+		}
+		if pruned > 0 {
+			log.Printf("pruned %d expired events", pruned)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
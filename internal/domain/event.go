@@ -4,12 +4,135 @@ import "time"
 
 // Event - структура события по датчику
 type Event struct {
-	// Timestamp - время события
+	// ID - id события в хранилище, упорядочивает события с одинаковым временем
+	ID int64
+	// Sequence - номер события в порядке сохранения. Последовательности возрастают в том порядке,
+	// в котором события становятся видны в хранилище, поэтому поток событий можно продолжить с любой из них
+	Sequence int64
+	// Timestamp - время события по часам устройства, если устройство его не передало - время получения
 	Timestamp time.Time
+	// ReceivedAt - время получения события сервером
+	ReceivedAt time.Time
+	// EventID - идентификатор события, заданный устройством. По нему отсеиваются повторно отправленные события
+	EventID string `json:",omitempty"`
 	// 	SensorSerialNumber - серийный номер датчика
 	SensorSerialNumber string
 	// SensorID - id датчика
 	SensorID int64
-	// Payload - данные события
+	// Payload - целочисленное значение события, для логического значения - 0 или 1
 	Payload int64
+	// Value - значение события, если оно не целое: float64, bool, string или map[string]any
+	// со значениями каналов. nil - значение целое и хранится только в Payload
+	Value any `json:",omitempty"`
+	// CalibratedValue - значение после калибровки датчика, действовавшей в момент события
+	CalibratedValue *float64 `json:",omitempty"`
+	// Unit - единица измерения откалиброванного значения
+	Unit string `json:",omitempty"`
+	// HomeID - id дома, которому принадлежал датчик в момент события
+	HomeID int64
+}
+
+// HistoryCursor - позиция события в истории датчика. События упорядочены по времени, а с одинаковым временем - по ID
+type HistoryCursor struct {
+	Timestamp time.Time
+	ID        int64
+}
+
+// HistoryQuery - параметры выборки истории датчика
+type HistoryQuery struct {
+	// Start, End - границы периода по времени события, включительно
+	Start time.Time
+	End   time.Time
+	// Limit - наибольшее число событий в выборке, 0 - без ограничения
+	Limit int
+	// Descending - выбирать события от новых к старым
+	Descending bool
+	// After - позиция, после которой в выбранном порядке продолжается выборка, nil - с начала периода
+	After *HistoryCursor
+	// HomeID - выбирать только события, полученные датчиком в этом доме, nil - события всех домов
+	HomeID *int64
+}
+
+// ReplayQuery - параметры дозагрузки событий датчика в поток. События выбираются по возрастанию Sequence
+type ReplayQuery struct {
+	// AfterSequence - выбирать события с последовательностью больше AfterSequence
+	AfterSequence int64
+	// UntilSequence - выбирать события с последовательностью не больше UntilSequence, 0 - без ограничения
+	UntilSequence int64
+	// Since - выбирать события со временем не раньше Since, нулевое время - без ограничения
+	Since time.Time
+	// Limit - наибольшее число событий в выборке
+	Limit int
+	// HomeID - выбирать только события, полученные датчиком в этом доме, nil - события всех домов
+	HomeID *int64
+}
+
+// HistoryPage - страница истории датчика
+type HistoryPage struct {
+	Events []Event
+	// Next - позиция для запроса следующей страницы, nil - страница последняя
+	Next *HistoryCursor
+}
+
+// NumericValue - числовое значение события для агрегации: откалиброванное значение, если оно есть,
+// иначе дробное значение или Payload для целого и логического значения. Строки и каналы числового значения не имеют
+func (e Event) NumericValue() (float64, bool) {
+	if e.CalibratedValue != nil {
+		return *e.CalibratedValue, true
+	}
+	switch v := e.Value.(type) {
+	case nil, bool:
+		return float64(e.Payload), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// HistoryFill - способ заполнения интервалов агрегации, в которых нет событий
+type HistoryFill string
+
+const (
+	// HistoryFillNone - интервалы без событий не возвращаются
+	HistoryFillNone HistoryFill = "none"
+	// HistoryFillNull - интервалы без событий возвращаются без значений
+	HistoryFillNull HistoryFill = "null"
+	// HistoryFillPrevious - интервалы без событий получают последнее значение предыдущего интервала
+	HistoryFillPrevious HistoryFill = "previous"
+)
+
+// HistoryBuckets - допустимые длительности интервалов агрегации истории
+var HistoryBuckets = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+// HistoryAggregateQuery - параметры агрегации истории датчика
+type HistoryAggregateQuery struct {
+	// Start, End - границы периода по времени события, включительно
+	Start time.Time
+	End   time.Time
+	// Bucket - длительность интервала. Интервалы выровнены по началу суток UTC
+	Bucket time.Duration
+	// Fill - способ заполнения интервалов без событий
+	Fill HistoryFill
+	// HomeID - учитывать только события, полученные датчиком в этом доме, nil - события всех домов
+	HomeID *int64
+}
+
+// HistoryBucket - агрегаты числовых значений событий датчика за интервал
+type HistoryBucket struct {
+	// Start - начало интервала
+	Start time.Time
+	// Count - число событий с числовым значением
+	Count int64
+	// Min, Max, Avg, First, Last - наименьшее, наибольшее, среднее, первое и последнее значение,
+	// nil - в интервале нет событий
+	Min   *float64
+	Max   *float64
+	Avg   *float64
+	First *float64
+	Last  *float64
 }
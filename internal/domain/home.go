@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// Home - структура для хранения дома (квартиры), объединяющего пользователей и датчики
+type Home struct {
+	// ID - id дома
+	ID int64
+	// Name - название дома
+	Name string
+	// CreatedAt - дата создания дома
+	CreatedAt time.Time
+}
+
+// HomeMember - структура для связи пользователя и дома
+// Роль в доме распространяется на все датчики дома.
+type HomeMember struct {
+	// HomeID - id дома
+	HomeID int64
+	// UserID - id пользователя
+	UserID int64
+	// Role - роль пользователя в доме
+	Role SensorRole
+}
@@ -0,0 +1,46 @@
+package domain
+
+// ImportSource - файл импорта, к которому относится запись
+type ImportSource string
+
+const (
+	ImportSourceSensors ImportSource = "sensors"
+	ImportSourceEvents  ImportSource = "events"
+)
+
+// ImportReport - итог импорта датчиков и событий
+type ImportReport struct {
+	// DryRun - импорт выполнен для проверки, изменения не сохранены
+	DryRun bool
+	// SensorsCreated - число зарегистрированных датчиков
+	SensorsCreated int64
+	// SensorsExisting - число датчиков, которые уже были зарегистрированы
+	SensorsExisting int64
+	// SensorsRejected - число отклонённых записей датчиков
+	SensorsRejected int64
+	// EventsImported - число сохранённых событий
+	EventsImported int64
+	// EventsDuplicate - число событий, которые уже были сохранены
+	EventsDuplicate int64
+	// EventsRejected - число отклонённых записей событий
+	EventsRejected int64
+	// Errors - причины отклонения записей в порядке файлов, в отчёт попадают только первые из них
+	Errors []ImportError
+	// SensorKeys - ключи зарегистрированных датчиков в порядке файла, при проверочном импорте ключи не выдаются
+	SensorKeys []ImportSensorKey
+}
+
+// ImportSensorKey - ключ датчика, зарегистрированного импортом
+type ImportSensorKey struct {
+	SerialNumber string
+	// APIKey - ключ датчика в открытом виде, кроме отчёта импорта он нигде не показывается
+	APIKey string
+}
+
+// ImportError - причина отклонения записи файла импорта
+type ImportError struct {
+	Source ImportSource
+	// Line - номер строки записи в файле, начиная с 1
+	Line int
+	Err  error
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// RetentionPolicy - срок хранения событий. Политика задаётся для типа датчика или для отдельного датчика,
+// политика без типа и датчика - общая, она задаётся настройками сервера
+type RetentionPolicy struct {
+	// SensorType - тип датчиков, на которые действует политика
+	SensorType SensorType
+	// SensorID - id датчика, на который действует политика
+	SensorID int64
+	// Retention - сколько хранятся события по времени события, 0 - события хранятся бессрочно
+	Retention time.Duration
+}
+
+// RetentionPreview - события датчика, которые удалит очередная очистка
+type RetentionPreview struct {
+	SensorID     int64
+	SerialNumber string
+	SensorType   SensorType
+	// Retention - срок хранения, действующий для датчика
+	Retention time.Duration
+	// Before - удаляются события, время которых раньше Before
+	Before time.Time
+	// Events - число таких событий
+	Events int64
+}
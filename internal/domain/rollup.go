@@ -0,0 +1,117 @@
+package domain
+
+import "time"
+
+// RollupResolutions - длительности интервалов, по которым агрегаты событий накапливаются по мере получения событий
+var RollupResolutions = []time.Duration{time.Hour, 24 * time.Hour}
+
+// HistoryRollup - накопленные агрегаты числовых значений событий датчика за интервал,
+// полученных, пока датчик принадлежал дому HomeID
+type HistoryRollup struct {
+	SensorID   int64
+	HomeID     int64
+	Resolution time.Duration
+	// Start - начало интервала, выровненное по Resolution
+	Start time.Time
+	Count int64
+	Sum   float64
+	Min   float64
+	Max   float64
+	// FirstTimestamp, First - время и значение самого раннего события интервала
+	FirstTimestamp time.Time
+	First          float64
+	// LastTimestamp, Last - время и значение самого позднего события интервала
+	LastTimestamp time.Time
+	Last          float64
+}
+
+// Add - учитывает значение события
+func (r *HistoryRollup) Add(timestamp time.Time, value float64) {
+	r.Merge(HistoryRollup{
+		Count:          1,
+		Sum:            value,
+		Min:            value,
+		Max:            value,
+		FirstTimestamp: timestamp,
+		First:          value,
+		LastTimestamp:  timestamp,
+		Last:           value,
+	})
+}
+
+// Merge - учитывает агрегаты событий, полученных позже уже учтённых.
+// Из событий с одинаковым временем последним считается полученное позже, как и в истории
+func (r *HistoryRollup) Merge(other HistoryRollup) {
+	if other.Count == 0 {
+		return
+	}
+	if r.Count == 0 || other.Min < r.Min {
+		r.Min = other.Min
+	}
+	if r.Count == 0 || other.Max > r.Max {
+		r.Max = other.Max
+	}
+	if r.Count == 0 || other.FirstTimestamp.Before(r.FirstTimestamp) {
+		r.FirstTimestamp, r.First = other.FirstTimestamp, other.First
+	}
+	if r.Count == 0 || !other.LastTimestamp.Before(r.LastTimestamp) {
+		r.LastTimestamp, r.Last = other.LastTimestamp, other.Last
+	}
+	r.Count += other.Count
+	r.Sum += other.Sum
+}
+
+// Bucket - агрегаты интервала в виде ответа агрегации истории
+func (r HistoryRollup) Bucket() HistoryBucket {
+	if r.Count == 0 {
+		return HistoryBucket{Start: r.Start}
+	}
+	avg := r.Sum / float64(r.Count)
+	return HistoryBucket{
+		Start: r.Start,
+		Count: r.Count,
+		Min:   &r.Min,
+		Max:   &r.Max,
+		Avg:   &avg,
+		First: &r.First,
+		Last:  &r.Last,
+	}
+}
+
+// rollupKey - интервал агрегатов однозначно определяется датчиком, домом, длительностью и началом
+type rollupKey struct {
+	sensorID   int64
+	homeID     int64
+	resolution time.Duration
+	start      int64
+}
+
+// RollupEvents - собирает агрегаты событий по интервалам всех RollupResolutions.
+// События учитываются в порядке получения, события без числового значения пропускаются
+func RollupEvents(events []Event) []HistoryRollup {
+	var rollups []HistoryRollup
+	index := make(map[rollupKey]int)
+	for _, event := range events {
+		value, ok := event.NumericValue()
+		if !ok {
+			continue
+		}
+		for _, resolution := range RollupResolutions {
+			start := event.Timestamp.Truncate(resolution)
+			key := rollupKey{sensorID: event.SensorID, homeID: event.HomeID, resolution: resolution, start: start.UnixNano()}
+			i, ok := index[key]
+			if !ok {
+				i = len(rollups)
+				index[key] = i
+				rollups = append(rollups, HistoryRollup{
+					SensorID:   event.SensorID,
+					HomeID:     event.HomeID,
+					Resolution: resolution,
+					Start:      start,
+				})
+			}
+			rollups[i].Add(event.Timestamp, value)
+		}
+	}
+	return rollups
+}
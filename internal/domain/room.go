@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// Room - структура для хранения комнаты дома (кухня, прихожая, ...)
+type Room struct {
+	// ID - id комнаты
+	ID int64
+	// HomeID - id дома, которому принадлежит комната
+	HomeID int64
+	// Name - название комнаты
+	Name string
+	// CreatedAt - дата создания комнаты
+	CreatedAt time.Time
+}
+
+// RoomSummary - текущее состояние всех датчиков комнаты
+type RoomSummary struct {
+	// Room - комната
+	Room Room
+	// Sensors - датчики комнаты
+	Sensors []Sensor
+	// LastActivity - дата последнего изменения состояния среди датчиков комнаты
+	LastActivity time.Time
+}
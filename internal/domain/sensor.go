@@ -7,6 +7,13 @@ type SensorType string
 const (
 	SensorTypeContactClosure SensorType = "cc"
 	SensorTypeADC            SensorType = "adc"
+	SensorTypeTemperature    SensorType = "temperature"
+	SensorTypeHumidity       SensorType = "humidity"
+	SensorTypeMotion         SensorType = "motion"
+	SensorTypeCO2            SensorType = "co2"
+	SensorTypePowerMeter     SensorType = "power_meter"
+	SensorTypeDoorLock       SensorType = "door_lock"
+	SensorTypeClimate        SensorType = "climate"
 )
 
 // Sensor - структура для хранения данных датчика
@@ -17,14 +24,96 @@ type Sensor struct {
 	SerialNumber string
 	// Type - тип датчика
 	Type SensorType
-	// CurrentState - текущее состояние датчика
+	// CurrentState - текущее состояние датчика, Payload последнего события
 	CurrentState int64
+	// CurrentValue - нецелое значение последнего события, см. Event.Value
+	CurrentValue any `json:",omitempty"`
+	// CalibratedState - откалиброванное значение последнего события, nil - калибровка не задана
+	CalibratedState *float64 `json:",omitempty"`
+	// Unit - единица измерения откалиброванного значения
+	Unit string `json:",omitempty"`
+	// Calibration - калибровка, переводящая сырое значение датчика в физическую величину
+	Calibration *Calibration `json:",omitempty"`
 	// Description - описание датчика
 	Description string
 	// IsActive - активен ли датчик
 	IsActive bool
 	// RegisteredAt - дата регистрации датчика
 	RegisteredAt time.Time
-	// LastActivity - дата последнего изменения состояния датчика
+	// LastActivity - время самого нового события датчика, по которому выставлено текущее состояние
 	LastActivity time.Time
+	// HomeID - id дома, которому принадлежит датчик, 0 - датчик не привязан к дому
+	HomeID int64
+	// RoomID - id комнаты дома, в которой установлен датчик, 0 - комната не указана
+	RoomID int64
+	// DeletedAt - дата удаления датчика, нулевое значение - датчик не удалён
+	DeletedAt time.Time
+}
+
+// SensorUpdate - изменяемые поля датчика, nil - поле не меняется
+type SensorUpdate struct {
+	// Description - новое описание датчика
+	Description *string
+	// IsActive - новый флаг активности датчика
+	IsActive *bool
+	// Unit - новая единица измерения откалиброванного значения
+	Unit *string
+	// Calibration - новая калибровка датчика, калибровка вида none снимает её
+	Calibration *Calibration
+}
+
+type CalibrationKind string
+
+const (
+	// CalibrationNone - калибровки нет, используется для её снятия
+	CalibrationNone CalibrationKind = "none"
+	// CalibrationLinear - линейное преобразование: Scale * x + Offset
+	CalibrationLinear CalibrationKind = "linear"
+	// CalibrationPolynomial - многочлен: Coefficients[0] + Coefficients[1] * x + Coefficients[2] * x^2 + ...
+	CalibrationPolynomial CalibrationKind = "polynomial"
+	// CalibrationTable - таблица соответствия с линейной интерполяцией между точками,
+	// за пределами таблицы берётся значение крайней точки
+	CalibrationTable CalibrationKind = "table"
+)
+
+// Calibration - калибровка датчика. Сырое значение события при калибровке не меняется
+type Calibration struct {
+	// Kind - вид калибровки
+	Kind CalibrationKind
+	// Scale - множитель линейной калибровки
+	Scale float64 `json:",omitempty"`
+	// Offset - смещение линейной калибровки
+	Offset float64 `json:",omitempty"`
+	// Coefficients - коэффициенты многочлена по возрастанию степени
+	Coefficients []float64 `json:",omitempty"`
+	// Points - точки таблицы соответствия по возрастанию сырого значения
+	Points []CalibrationPoint `json:",omitempty"`
+}
+
+// CalibrationPoint - точка таблицы соответствия
+type CalibrationPoint struct {
+	// Raw - сырое значение датчика
+	Raw float64
+	// Value - откалиброванное значение
+	Value float64
+}
+
+// SensorKey - ключ, которым датчик подтверждает отправляемые события
+type SensorKey struct {
+	// SensorID - id датчика
+	SensorID int64
+	// KeyHash - SHA-256 от ключа, сам ключ не хранится
+	KeyHash []byte
+	// Salt - случайное значение, из которого вместе с секретом сервера выводится ключ для проверки подписей.
+	// Пусто у ключей, выданных без секрета, такими ключами подписывать запросы нельзя
+	Salt []byte
+	// CreatedAt - дата выдачи ключа
+	CreatedAt time.Time
+}
+
+// RegisteredSensor - датчик вместе с выданным ему ключом
+type RegisteredSensor struct {
+	Sensor
+	// APIKey - ключ датчика в открытом виде, показывается только один раз при выдаче
+	APIKey string `json:"APIKey,omitempty"`
 }
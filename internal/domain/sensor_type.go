@@ -0,0 +1,72 @@
+package domain
+
+import "math"
+
+// SensorValueKind - вид значения, которое передаёт датчик
+type SensorValueKind string
+
+const (
+	// SensorValueKindBinary - два состояния: 0 или 1, можно передать и логическим значением
+	SensorValueKindBinary SensorValueKind = "binary"
+	// SensorValueKindInteger - целое число в диапазоне типа
+	SensorValueKindInteger SensorValueKind = "integer"
+	// SensorValueKindFloat - число с дробной частью в диапазоне типа, целые числа тоже допустимы
+	SensorValueKindFloat SensorValueKind = "float"
+	// SensorValueKindBoolean - логическое значение
+	SensorValueKindBoolean SensorValueKind = "boolean"
+	// SensorValueKindString - строка
+	SensorValueKindString SensorValueKind = "string"
+	// SensorValueKindChannels - набор именованных каналов, каждый со своим видом значения
+	SensorValueKindChannels SensorValueKind = "channels"
+)
+
+// SensorTypeDefinition - описание типа датчика из реестра типов
+type SensorTypeDefinition struct {
+	// Type - код типа, указывается при регистрации датчика
+	Type SensorType
+	// Name - название типа
+	Name string
+	// ValueKind - вид значения, которое передаёт датчик
+	ValueKind SensorValueKind
+	// MinValue - минимальное допустимое значение события
+	MinValue float64
+	// MaxValue - максимальное допустимое значение события
+	MaxValue float64
+	// Unit - единица измерения, пустая строка - значение безразмерное
+	Unit string
+	// Channels - каналы датчика, заполняются только для вида значения channels
+	Channels []SensorChannelDefinition
+}
+
+// SensorChannelDefinition - описание канала многоканального датчика
+type SensorChannelDefinition struct {
+	// Name - имя канала, ключ в значении события
+	Name string
+	// ValueKind - вид значения канала, кроме channels
+	ValueKind SensorValueKind
+	// MinValue - минимальное допустимое значение канала
+	MinValue float64
+	// MaxValue - максимальное допустимое значение канала
+	MaxValue float64
+	// Unit - единица измерения канала
+	Unit string
+}
+
+// BuiltinSensorTypes - типы датчиков, известные системе изначально.
+// Для cc и adc диапазон не ограничен, как и до появления реестра типов.
+func BuiltinSensorTypes() []SensorTypeDefinition {
+	return []SensorTypeDefinition{
+		{Type: SensorTypeContactClosure, Name: "Сухой контакт", ValueKind: SensorValueKindInteger, MinValue: math.MinInt64, MaxValue: math.MaxInt64},
+		{Type: SensorTypeADC, Name: "АЦП", ValueKind: SensorValueKindFloat, MinValue: math.MinInt64, MaxValue: math.MaxInt64},
+		{Type: SensorTypeTemperature, Name: "Температура", ValueKind: SensorValueKindFloat, MinValue: -60, MaxValue: 150, Unit: "°C"},
+		{Type: SensorTypeHumidity, Name: "Влажность", ValueKind: SensorValueKindFloat, MinValue: 0, MaxValue: 100, Unit: "%"},
+		{Type: SensorTypeMotion, Name: "Движение", ValueKind: SensorValueKindBinary, MinValue: 0, MaxValue: 1},
+		{Type: SensorTypeCO2, Name: "Углекислый газ", ValueKind: SensorValueKindInteger, MinValue: 0, MaxValue: 10000, Unit: "ppm"},
+		{Type: SensorTypePowerMeter, Name: "Счётчик электроэнергии", ValueKind: SensorValueKindInteger, MinValue: 0, MaxValue: math.MaxInt64, Unit: "Вт·ч"},
+		{Type: SensorTypeDoorLock, Name: "Дверной замок", ValueKind: SensorValueKindBinary, MinValue: 0, MaxValue: 1},
+		{Type: SensorTypeClimate, Name: "Климат", ValueKind: SensorValueKindChannels, Channels: []SensorChannelDefinition{
+			{Name: "temp", ValueKind: SensorValueKindFloat, MinValue: -60, MaxValue: 150, Unit: "°C"},
+			{Name: "rh", ValueKind: SensorValueKindFloat, MinValue: 0, MaxValue: 100, Unit: "%"},
+		}},
+	}
+}
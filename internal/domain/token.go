@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+type TokenType string
+
+const (
+	TokenTypeAccess  TokenType = "access"
+	TokenTypeRefresh TokenType = "refresh"
+)
+
+// Tokens - пара токенов, выдаваемая пользователю при входе
+type Tokens struct {
+	// AccessToken - токен доступа к API
+	AccessToken string
+	// RefreshToken - токен для получения новой пары токенов
+	RefreshToken string
+	// ExpiresAt - время истечения токена доступа
+	ExpiresAt time.Time
+}
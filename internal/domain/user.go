@@ -6,8 +6,28 @@ type User struct {
 	ID int64
 	// Name - имя пользователя
 	Name string
+	// IsAdmin - администратор видит все дома и датчики
+	IsAdmin bool
 }
 
+// UserUpdate - изменяемые поля пользователя, nil - поле не меняется
+type UserUpdate struct {
+	// Name - новое имя пользователя, логин при этом не меняется
+	Name *string
+}
+
+// SensorRole - роль пользователя в привязке к датчику
+type SensorRole string
+
+const (
+	// SensorRoleOwner - владелец: может выдавать и отзывать доступ к датчику
+	SensorRoleOwner SensorRole = "owner"
+	// SensorRoleMember - участник: может читать данные датчика и настраивать его
+	SensorRoleMember SensorRole = "member"
+	// SensorRoleViewer - наблюдатель: может только читать историю и поток событий
+	SensorRoleViewer SensorRole = "viewer"
+)
+
 // SensorOwner - структура для связи пользователя и датчика
 // Связь многие-ко-многим: пользователь может иметь доступ к нескольким датчикам, датчик может быть доступен для нескольких пользователей.
 type SensorOwner struct {
@@ -15,4 +35,16 @@ type SensorOwner struct {
 	UserID int64
 	// SensorID - id датчика
 	SensorID int64
+	// Role - роль пользователя
+	Role SensorRole
+}
+
+// Credentials - учётные данные пользователя для входа в систему
+type Credentials struct {
+	// UserID - id пользователя
+	UserID int64
+	// Login - логин пользователя
+	Login string
+	// PasswordHash - хэш пароля
+	PasswordHash []byte
 }
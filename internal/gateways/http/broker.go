@@ -2,61 +2,262 @@ package http
 
 import (
 	"homework/internal/domain"
+	"maps"
+	"slices"
 	"sync"
-
-	"github.com/coder/websocket"
+	"sync/atomic"
 )
 
 const buffer int = 10
 
+// SlowConsumerPolicy - что брокер делает с событием, которое не помещается в канал медленного подписчика
+type SlowConsumerPolicy string
+
+const (
+	// SlowConsumerDropNewest - событие не доставляется, поток дозагружает пропущенные события из хранилища
+	SlowConsumerDropNewest SlowConsumerPolicy = "drop_newest"
+	// SlowConsumerDropOldest - из канала вытесняется самое старое событие
+	SlowConsumerDropOldest SlowConsumerPolicy = "drop_oldest"
+	// SlowConsumerCoalesce - в канале остаётся только последнее событие каждого датчика
+	SlowConsumerCoalesce SlowConsumerPolicy = "coalesce"
+	// SlowConsumerDisconnect - канал закрывается, а соединение отключается с причиной slowConsumerReason
+	SlowConsumerDisconnect SlowConsumerPolicy = "disconnect"
+)
+
+// slowConsumerReason - причина закрытия соединения, отключённого по политике SlowConsumerDisconnect
+const slowConsumerReason = "slow consumer"
+
+// SlowConsumerPolicyOf - политика медленного подписчика по названию
+func SlowConsumerPolicyOf(name string) (SlowConsumerPolicy, bool) {
+	switch policy := SlowConsumerPolicy(name); policy {
+	case SlowConsumerDropNewest, SlowConsumerDropOldest, SlowConsumerCoalesce, SlowConsumerDisconnect:
+		return policy, true
+	}
+	return "", false
+}
+
+// subscription - канал событий соединения, политика его переполнения и число событий, которые соединению
+// не доставлены
+type subscription struct {
+	ch      chan *domain.Event
+	policy  SlowConsumerPolicy
+	dropped atomic.Int64
+	// disconnected - канал закрыт по политике SlowConsumerDisconnect
+	disconnected atomic.Bool
+	// mu - упорядочивает отправку событий в канал при одновременной рассылке
+	mu sync.Mutex
+}
+
+// send - кладёт событие в канал, а если канал переполнен, поступает с ним по политике подписки
+func (s *subscription) send(event *domain.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.disconnected.Load() {
+		return
+	}
+	select {
+	case s.ch <- event:
+		return
+	default:
+	}
+	switch s.policy {
+	case SlowConsumerDropOldest:
+		// канал только читается, пока отправка заблокирована mu, поэтому место под событие уже есть
+		select {
+		case <-s.ch:
+			s.dropped.Add(1)
+		default:
+		}
+		s.ch <- event
+	case SlowConsumerCoalesce:
+		s.coalesce(event)
+	case SlowConsumerDisconnect:
+		s.dropped.Add(1)
+		s.disconnect()
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// disconnect - закрывает канал, соединение которого не успевает читать события
+func (s *subscription) disconnect() {
+	s.disconnected.Store(true)
+	close(s.ch)
+}
+
+// coalesce - оставляет в канале только последнее событие каждого датчика вместе с новым событием.
+// Если датчиков больше, чем помещается в канал, вытесняются события, пришедшие раньше
+func (s *subscription) coalesce(event *domain.Event) {
+	queued := make([]*domain.Event, 0, cap(s.ch)+1)
+drain:
+	for len(queued) < cap(s.ch) {
+		select {
+		case e := <-s.ch:
+			queued = append(queued, e)
+		default:
+			break drain
+		}
+	}
+	queued = append(queued, event)
+
+	last := make(map[int64]int, len(queued))
+	for i, e := range queued {
+		last[e.SensorID] = i
+	}
+	kept := queued[:0]
+	for i, e := range queued {
+		if last[e.SensorID] == i {
+			kept = append(kept, e)
+		}
+	}
+	kept = kept[max(0, len(kept)-cap(s.ch)):]
+	s.dropped.Add(int64(len(queued) - len(kept)))
+	for _, e := range kept {
+		s.ch <- e
+	}
+}
+
+// EventBroker - рассылает события датчиков подписанным соединениям. Соединение - любое сравнимое значение,
+// уникальное для подписчика: websocket-соединение, запрос потока SSE
 type EventBroker struct {
-	subscriptions map[*websocket.Conn]chan *domain.Event
-	ids           map[int64][]*websocket.Conn
-	mu            sync.RWMutex
+	subscriptions map[any]*subscription
+	// sensors - датчики, на которые подписано соединение
+	sensors map[any]map[int64]struct{}
+	ids     map[int64][]any
+	mu      sync.RWMutex
 }
 
 func NewEventBroker() *EventBroker {
 	return &EventBroker{
-		ids:           make(map[int64][]*websocket.Conn),
-		subscriptions: make(map[*websocket.Conn]chan *domain.Event),
+		ids:           make(map[int64][]any),
+		subscriptions: make(map[any]*subscription),
+		sensors:       make(map[any]map[int64]struct{}),
 	}
 }
 
-func (b *EventBroker) Subscribe(conn *websocket.Conn, sensorID int64) chan *domain.Event {
+// Subscribe - подписывает соединение на события датчиков. Новое соединение получает политику
+// SlowConsumerDropNewest
+func (b *EventBroker) Subscribe(conn any, sensorIDs ...int64) chan *domain.Event {
+	return b.SubscribeWithPolicy(conn, SlowConsumerDropNewest, sensorIDs...)
+}
+
+// SubscribeWithPolicy - подписывает соединение на события датчиков с политикой переполнения канала.
+// Повторная подписка того же соединения добавляет датчики к уже подписанным и возвращает тот же канал,
+// политика при этом не меняется
+func (b *EventBroker) SubscribeWithPolicy(conn any, policy SlowConsumerPolicy, sensorIDs ...int64) chan *domain.Event {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if ch, ok := b.subscriptions[conn]; ok {
-		return ch
+	sub, ok := b.subscriptions[conn]
+	if !ok {
+		sub = &subscription{ch: make(chan *domain.Event, buffer), policy: policy}
+		b.subscriptions[conn] = sub
+		b.sensors[conn] = make(map[int64]struct{})
+	}
+	for _, sensorID := range sensorIDs {
+		if _, ok := b.sensors[conn][sensorID]; ok {
+			continue
+		}
+		b.sensors[conn][sensorID] = struct{}{}
+		b.ids[sensorID] = append(b.ids[sensorID], conn)
 	}
 
-	ch := make(chan *domain.Event, buffer)
-	b.ids[sensorID] = append(b.ids[sensorID], conn)
-	b.subscriptions[conn] = ch
+	return sub.ch
+}
 
-	return ch
+// UnsubscribeSensors - отписывает соединение от событий датчиков, канал соединения остаётся открытым
+func (b *EventBroker) UnsubscribeSensors(conn any, sensorIDs ...int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.unsubscribeSensors(conn, sensorIDs)
 }
 
-func (b *EventBroker) Unsubscribe(conn *websocket.Conn) {
+// Sensors - датчики, на которые подписано соединение, по возрастанию id
+func (b *EventBroker) Sensors(conn any) []int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	sensorIDs := make([]int64, 0, len(b.sensors[conn]))
+	for sensorID := range b.sensors[conn] {
+		sensorIDs = append(sensorIDs, sensorID)
+	}
+	slices.Sort(sensorIDs)
+	return sensorIDs
+}
+
+// Unsubscribe - отписывает соединение от всех датчиков и закрывает его канал
+func (b *EventBroker) Unsubscribe(conn any) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if ch, ok := b.subscriptions[conn]; ok {
-		close(ch)
+	if sub, ok := b.subscriptions[conn]; ok {
+		b.unsubscribeSensors(conn, slices.Collect(maps.Keys(b.sensors[conn])))
+		if !sub.disconnected.Load() {
+			close(sub.ch)
+		}
 		delete(b.subscriptions, conn)
+		delete(b.sensors, conn)
 	}
 }
 
+func (b *EventBroker) unsubscribeSensors(conn any, sensorIDs []int64) {
+	for _, sensorID := range sensorIDs {
+		if _, ok := b.sensors[conn][sensorID]; !ok {
+			continue
+		}
+		delete(b.sensors[conn], sensorID)
+		b.ids[sensorID] = slices.DeleteFunc(b.ids[sensorID], func(c any) bool {
+			return c == conn
+		})
+		if len(b.ids[sensorID]) == 0 {
+			delete(b.ids, sensorID)
+		}
+	}
+}
+
+// Dropped - сколько событий не доставлено соединению из-за переполненного канала с момента подписки
+func (b *EventBroker) Dropped(conn any) int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if sub, ok := b.subscriptions[conn]; ok {
+		return sub.dropped.Load()
+	}
+	return 0
+}
+
+// Missed - сколько недоставленных соединению событий поток должен дозагрузить из хранилища. При остальных
+// политиках клиент сам согласился терять события, и дозагружать нечего
+func (b *EventBroker) Missed(conn any) int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if sub, ok := b.subscriptions[conn]; ok && sub.policy == SlowConsumerDropNewest {
+		return sub.dropped.Load()
+	}
+	return 0
+}
+
+// Disconnected - закрыт ли канал соединения по политике SlowConsumerDisconnect
+func (b *EventBroker) Disconnected(conn any) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	sub, ok := b.subscriptions[conn]
+	return ok && sub.disconnected.Load()
+}
+
+// Publish - рассылает событие подписчикам датчика. Если канал подписчика переполнен, событие обрабатывается
+// по политике подписки, а недоставленные события учитываются в Dropped
 func (b *EventBroker) Publish(sensorID int64, event *domain.Event) {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
 	for _, conn := range b.ids[sensorID] {
-		if ch, ok := b.subscriptions[conn]; ok {
-			select {
-			case ch <- event:
-			default:
-			}
+		if sub, ok := b.subscriptions[conn]; ok {
+			sub.send(event)
 		}
 	}
 }
@@ -65,11 +266,14 @@ func (b *EventBroker) close() {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	for sensorID, ch := range b.subscriptions {
-		close(ch)
-		delete(b.subscriptions, sensorID)
+	for conn, sub := range b.subscriptions {
+		if !sub.disconnected.Load() {
+			close(sub.ch)
+		}
+		delete(b.subscriptions, conn)
+		delete(b.sensors, conn)
 	}
-	for conn := range b.ids {
-		delete(b.ids, conn)
+	for sensorID := range b.ids {
+		delete(b.ids, sensorID)
 	}
 }
@@ -0,0 +1,322 @@
+package http
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	csvContentType     = "text/csv"
+	parquetContentType = "application/vnd.apache.parquet"
+	// exportRowGroupSize - сколько строк накапливается в памяти до записи группы строк Parquet
+	exportRowGroupSize = 10000
+)
+
+// exportFormat - формат выгрузки истории
+type exportFormat struct {
+	name        string
+	contentType string
+	newExporter func(w io.Writer) historyExporter
+}
+
+// exportFormats - поддерживаемые форматы, первый используется, если клиент согласен на любой
+var exportFormats = []exportFormat{
+	{name: "csv", contentType: csvContentType, newExporter: newCSVExporter},
+	{name: "ndjson", contentType: ndjsonContentType, newExporter: newNDJSONExporter},
+	{name: "parquet", contentType: parquetContentType, newExporter: newParquetExporter},
+}
+
+// historyExporter - пишет события выгрузки в выбранном формате
+type historyExporter interface {
+	WriteEvent(event domain.Event) error
+	// Close - дописывает буферизованные данные и окончание файла
+	Close() error
+}
+
+// exportRow - событие в виде плоской строки для CSV и Parquet. Нецелое значение события записывается в Value как JSON
+type exportRow struct {
+	SensorID           int64     `parquet:"sensor_id"`
+	SensorSerialNumber string    `parquet:"sensor_serial_number"`
+	ID                 int64     `parquet:"id"`
+	Timestamp          time.Time `parquet:"timestamp,timestamp(microsecond)"`
+	ReceivedAt         time.Time `parquet:"received_at,timestamp(microsecond)"`
+	EventID            string    `parquet:"event_id"`
+	Payload            int64     `parquet:"payload"`
+	Value              *string   `parquet:"value,optional"`
+	CalibratedValue    *float64  `parquet:"calibrated_value,optional"`
+	Unit               string    `parquet:"unit"`
+	HomeID             int64     `parquet:"home_id"`
+}
+
+// exportColumns - заголовок CSV в порядке полей exportRow
+var exportColumns = []string{"sensor_id", "sensor_serial_number", "id", "timestamp", "received_at", "event_id",
+	"payload", "value", "calibrated_value", "unit", "home_id"}
+
+func toExportRow(event domain.Event) (exportRow, error) {
+	row := exportRow{
+		SensorID:           event.SensorID,
+		SensorSerialNumber: event.SensorSerialNumber,
+		ID:                 event.ID,
+		Timestamp:          event.Timestamp,
+		ReceivedAt:         event.ReceivedAt,
+		EventID:            event.EventID,
+		Payload:            event.Payload,
+		CalibratedValue:    event.CalibratedValue,
+		Unit:               event.Unit,
+		HomeID:             event.HomeID,
+	}
+	if event.Value != nil {
+		value, err := json.Marshal(event.Value)
+		if err != nil {
+			return row, err
+		}
+		encoded := string(value)
+		row.Value = &encoded
+	}
+	return row, nil
+}
+
+type csvExporter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func newCSVExporter(w io.Writer) historyExporter {
+	return &csvExporter{w: csv.NewWriter(w)}
+}
+
+func (e *csvExporter) WriteEvent(event domain.Event) error {
+	row, err := toExportRow(event)
+	if err != nil {
+		return err
+	}
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	record := []string{
+		strconv.FormatInt(row.SensorID, 10),
+		row.SensorSerialNumber,
+		strconv.FormatInt(row.ID, 10),
+		row.Timestamp.UTC().Format(time.RFC3339Nano),
+		row.ReceivedAt.UTC().Format(time.RFC3339Nano),
+		row.EventID,
+		strconv.FormatInt(row.Payload, 10),
+		"",
+		"",
+		row.Unit,
+		strconv.FormatInt(row.HomeID, 10),
+	}
+	if row.Value != nil {
+		record[7] = *row.Value
+	}
+	if row.CalibratedValue != nil {
+		record[8] = strconv.FormatFloat(*row.CalibratedValue, 'g', -1, 64)
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExporter) writeHeader() error {
+	if e.wroteHeader {
+		return nil
+	}
+	e.wroteHeader = true
+	return e.w.Write(exportColumns)
+}
+
+func (e *csvExporter) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ndjsonExporter - события в том же виде, что и в истории датчика, по одному в строке
+type ndjsonExporter struct {
+	encoder *json.Encoder
+}
+
+func newNDJSONExporter(w io.Writer) historyExporter {
+	return &ndjsonExporter{encoder: json.NewEncoder(w)}
+}
+
+func (e *ndjsonExporter) WriteEvent(event domain.Event) error {
+	return e.encoder.Encode(event)
+}
+
+func (e *ndjsonExporter) Close() error {
+	return nil
+}
+
+type parquetExporter struct {
+	w *parquet.GenericWriter[exportRow]
+}
+
+func newParquetExporter(w io.Writer) historyExporter {
+	return &parquetExporter{w: parquet.NewGenericWriter[exportRow](w,
+		parquet.MaxRowsPerRowGroup(exportRowGroupSize),
+		parquet.Compression(&parquet.Snappy),
+	)}
+}
+
+func (e *parquetExporter) WriteEvent(event domain.Event) error {
+	row, err := toExportRow(event)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write([]exportRow{row})
+	return err
+}
+
+func (e *parquetExporter) Close() error {
+	return e.w.Close()
+}
+
+// exportWriter - выставляет заголовки выгрузки при первой записи в ответ. До неё на ошибку ещё можно ответить
+// обычным кодом ошибки
+type exportWriter struct {
+	c      *gin.Context
+	format exportFormat
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	w.start()
+	return w.c.Writer.Write(p)
+}
+
+func (w *exportWriter) start() {
+	if w.c.Writer.Written() {
+		return
+	}
+	w.c.Header("Content-Type", w.format.contentType)
+	w.c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": "history." + w.format.name,
+	}))
+	w.c.Writer.WriteHeaderNow()
+}
+
+// acceptRange - диапазон типов из заголовка Accept с его весом q
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// negotiateExportFormat - формат из параметра format, иначе поддерживаемый формат из заголовка Accept
+// с наибольшим весом q, при равных весах - указанный раньше. Типы с q=0 клиент не принимает, в том числе через */*
+func negotiateExportFormat(c *gin.Context) (exportFormat, bool) {
+	if name := c.Query("format"); name != "" {
+		for _, format := range exportFormats {
+			if format.name == name {
+				return format, true
+			}
+		}
+		return exportFormat{}, false
+	}
+	accept := c.GetHeader("Accept")
+	if accept == "" {
+		return exportFormats[0], true
+	}
+	var ranges []acceptRange
+	rejected := make(map[string]bool)
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(value, 64)
+			if err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		if q == 0 {
+			rejected[mediaType] = true
+			continue
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+	slices.SortStableFunc(ranges, func(a, b acceptRange) int {
+		return cmp.Compare(b.q, a.q)
+	})
+	for _, r := range ranges {
+		for _, format := range exportFormats {
+			if !rejected[format.contentType] && (r.mediaType == "*/*" || r.mediaType == format.contentType) {
+				return format, true
+			}
+		}
+	}
+	return exportFormat{}, false
+}
+
+func (h *Handlers) getHistoryExport(c *gin.Context) {
+	var sensorIDs []int64
+	for _, value := range c.QueryArray("sensor_id") {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			h.handleError(c, err, http.StatusUnprocessableEntity, ErrInvalidIDFormat)
+			return
+		}
+		sensorIDs = append(sensorIDs, id)
+	}
+	query := domain.HistoryQuery{
+		Start: h.parseDate(c, "start_date"),
+		End:   h.parseDate(c, "end_date"),
+	}
+	if c.IsAborted() {
+		return
+	}
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		h.handleError(c, usecase.ErrInvalidHistoryQuery, http.StatusBadRequest, ErrInvalidHistoryQuery)
+		return
+	}
+	format, ok := negotiateExportFormat(c)
+	if !ok {
+		if c.Query("format") != "" {
+			h.handleError(c, usecase.ErrInvalidHistoryQuery, http.StatusBadRequest, ErrInvalidHistoryQuery)
+			return
+		}
+		c.AbortWithStatus(http.StatusNotAcceptable)
+		return
+	}
+
+	w := &exportWriter{c: c, format: format}
+	exporter := format.newExporter(w)
+	err := h.us.Event.ExportSensorHistory(c.Request.Context(), sensorIDs, query, exporter.WriteEvent)
+	if err == nil {
+		err = exporter.Close()
+	}
+	if err != nil && c.Writer.Written() {
+		// ответ уже начат, клиент получит оборванный файл
+		log.Printf("history export interrupted: %v", err)
+		c.Abort()
+		return
+	}
+	switch {
+	case errors.Is(err, usecase.ErrInvalidHistoryQuery):
+		h.handleError(c, err, http.StatusBadRequest, ErrInvalidHistoryQuery)
+	case err != nil:
+		h.handleSensorError(c, err, ErrHistoryExportFailed)
+	default:
+		w.start()
+	}
+}
@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"maps"
+	"slices"
+)
+
+const (
+	// feedReplayPage - сколько событий читается из хранилища за один запрос дозагрузки
+	feedReplayPage = 1000
+	// feedCatchUpThreshold - сколько отданных событий рассылки запоминается до дозагрузки, которая их забывает
+	feedCatchUpThreshold = 1000
+)
+
+// eventFeed - склеивает дозагрузку событий из хранилища с рассылкой брокера так, что клиент потока получает
+// каждое событие ровно один раз, даже если брокер не доставил часть событий. GetLastSequence возвращает
+// последовательность, до которой все события уже видны в хранилище, и дозагрузка читает события только до неё,
+// поэтому все события датчика с последовательностью не больше watermarks[датчик] уже отданы клиенту или
+// не нужны ему. События выше watermark приходят из рассылки не по порядку, отданные запоминаются в delivered
+type eventFeed struct {
+	event *usecase.Event
+	write func(*domain.Event) error
+	// watermarks - датчики, события которых отдаются клиенту
+	watermarks map[int64]int64
+	// delivered - датчики отданных событий выше watermark по их последовательностям
+	delivered map[int64]int64
+	// dropped - сколько недоставленных брокером событий уже восполнено дозагрузкой
+	dropped int64
+}
+
+func newEventFeed(event *usecase.Event, write func(*domain.Event) error) *eventFeed {
+	return &eventFeed{
+		event:      event,
+		write:      write,
+		watermarks: make(map[int64]int64),
+		delivered:  make(map[int64]int64),
+	}
+}
+
+// subscribe - начинает отдавать клиенту события датчиков. С from сначала отдаются сохранённые события
+// по параметрам дозагрузки, без него - только события, сохранённые после подписки. Соединение подписывается
+// на датчики в брокере до вызова, иначе события, сохранённые во время дозагрузки, не дойдут до клиента
+func (f *eventFeed) subscribe(ctx context.Context, sensorIDs []int64, from *domain.ReplayQuery) error {
+	head, err := f.event.GetLastSequence(ctx)
+	if err != nil {
+		return err
+	}
+	if from == nil {
+		// события уже подписанных датчиков, сохранённые до head, могут ещё ждать в канале соединения
+		for _, sensorID := range sensorIDs {
+			if _, ok := f.watermarks[sensorID]; !ok {
+				f.watermarks[sensorID] = head
+			}
+		}
+		return nil
+	}
+	for _, sensorID := range sensorIDs {
+		if _, ok := f.watermarks[sensorID]; !ok {
+			f.watermarks[sensorID] = from.AfterSequence
+		}
+	}
+	// дозагрузка читает хранилище после head, поэтому отдаёт все события датчиков до head включительно
+	if err := f.replay(ctx, sensorIDs, *from, head); err != nil {
+		return err
+	}
+	for _, sensorID := range sensorIDs {
+		f.watermarks[sensorID] = max(f.watermarks[sensorID], head)
+	}
+	f.forget()
+	return nil
+}
+
+// sent - запоминает событие, отправленное клиенту в обход ленты, чтобы не отдать его повторно из рассылки
+func (f *eventFeed) sent(event *domain.Event) {
+	if watermark, ok := f.watermarks[event.SensorID]; ok && event.Sequence > watermark {
+		f.delivered[event.Sequence] = event.SensorID
+	}
+}
+
+// unsubscribe - перестаёт отдавать клиенту события датчиков
+func (f *eventFeed) unsubscribe(sensorIDs []int64) {
+	for _, sensorID := range sensorIDs {
+		delete(f.watermarks, sensorID)
+	}
+	f.forget()
+}
+
+// live - отдаёт клиенту событие рассылки. dropped - сколько событий брокер не доставил соединению:
+// если их стало больше, пропущенные события дозагружаются из хранилища
+func (f *eventFeed) live(ctx context.Context, event *domain.Event, dropped int64) error {
+	if err := f.deliver(event); err != nil {
+		return err
+	}
+	if dropped > f.dropped || len(f.delivered) > feedCatchUpThreshold {
+		return f.catchUp(ctx, dropped)
+	}
+	return nil
+}
+
+// catchUp - отдаёт клиенту сохранённые события, которые он ещё не получил, и сдвигает watermark всех датчиков
+func (f *eventFeed) catchUp(ctx context.Context, dropped int64) error {
+	if len(f.watermarks) == 0 {
+		f.dropped = dropped
+		return nil
+	}
+	head, err := f.event.GetLastSequence(ctx)
+	if err != nil {
+		return err
+	}
+	sensorIDs := slices.Sorted(maps.Keys(f.watermarks))
+	from := slices.Min(slices.Collect(maps.Values(f.watermarks)))
+	if err := f.replay(ctx, sensorIDs, domain.ReplayQuery{AfterSequence: from}, head); err != nil {
+		return err
+	}
+	for _, sensorID := range sensorIDs {
+		f.watermarks[sensorID] = max(f.watermarks[sensorID], head)
+	}
+	f.forget()
+	f.dropped = dropped
+	return nil
+}
+
+// replay - отдаёт клиенту сохранённые события датчиков по параметрам дозагрузки с последовательностью
+// не больше head, пока они не кончатся. События выше head могут стать видны раньше меньших, их отдаёт рассылка
+func (f *eventFeed) replay(ctx context.Context, sensorIDs []int64, query domain.ReplayQuery, head int64) error {
+	if head <= query.AfterSequence {
+		return nil
+	}
+	query.Limit = feedReplayPage
+	query.UntilSequence = head
+	for {
+		events, err := f.event.ReplayEvents(ctx, sensorIDs, query)
+		if err != nil {
+			return err
+		}
+		for i := range events {
+			if err := f.deliver(&events[i]); err != nil {
+				return err
+			}
+			query.AfterSequence = events[i].Sequence
+		}
+		if len(events) < query.Limit {
+			return nil
+		}
+	}
+}
+
+// deliver - отдаёт клиенту событие, если оно ему нужно и ещё не отдано
+func (f *eventFeed) deliver(event *domain.Event) error {
+	watermark, ok := f.watermarks[event.SensorID]
+	if !ok || event.Sequence != 0 && event.Sequence <= watermark {
+		return nil
+	}
+	if _, ok := f.delivered[event.Sequence]; ok {
+		return nil
+	}
+	if err := f.write(event); err != nil {
+		return err
+	}
+	if event.Sequence != 0 {
+		f.delivered[event.Sequence] = event.SensorID
+	}
+	return nil
+}
+
+// forget - забывает отданные события, которые уже не выше watermark своего датчика
+func (f *eventFeed) forget() {
+	maps.DeleteFunc(f.delivered, func(sequence int64, sensorID int64) bool {
+		watermark, ok := f.watermarks[sensorID]
+		return !ok || sequence <= watermark
+	})
+}
@@ -1,29 +1,93 @@
 package http
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"homework/internal/domain"
+	"homework/internal/gateways/importfile"
 	"homework/internal/usecase"
 	"homework/models"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
 )
 
 const (
-	ErrInvalidJSONFormat     = "Неверный формат JSON"
-	ErrValidation            = "Ошибка при валидации"
-	ErrUserNotFound          = "Пользователь не найден"
-	ErrUserCreateFailed      = "Не удалось создать пользователя"
-	ErrSensorNotFound        = "Сенсор не найден"
-	ErrSensorCreateFailed    = "Не удалось создать сенсор"
-	ErrSensorAttach          = "Ошибка при привязке сенсора к пользователю"
-	ErrEventProcessingFailed = "Ошибка обработки события"
-	ErrInvalidIDFormat       = "Некорректный формат ID"
-	ErrInvalidDateFormat     = "Некорректный формат даты"
+	ErrInvalidJSONFormat      = "Неверный формат JSON"
+	ErrValidation             = "Ошибка при валидации"
+	ErrUserNotFound           = "Пользователь не найден"
+	ErrUserCreateFailed       = "Не удалось создать пользователя"
+	ErrSensorNotFound         = "Сенсор не найден"
+	ErrSensorCreateFailed     = "Не удалось создать сенсор"
+	ErrSensorAttach           = "Ошибка при привязке сенсора к пользователю"
+	ErrEventProcessingFailed  = "Ошибка обработки события"
+	ErrInvalidIDFormat        = "Некорректный формат ID"
+	ErrInvalidDateFormat      = "Некорректный формат даты"
+	ErrUnauthorized           = "Требуется авторизация"
+	ErrAuthFailed             = "Ошибка авторизации"
+	ErrInvalidCredentials     = "Неверный логин или пароль"
+	ErrUserAlreadyExists      = "Пользователь с таким именем уже существует"
+	ErrSensorAlreadyExists    = "Датчик уже зарегистрирован другим пользователем"
+	ErrSensorUnauthorized     = "Событие не подтверждено ключом датчика"
+	ErrSensorKeyFailed        = "Ошибка при работе с ключом датчика"
+	ErrSensorAccessDenied     = "Недостаточно прав для работы с датчиком"
+	ErrLastSensorOwner        = "Нельзя лишить датчик последнего владельца"
+	ErrSensorAccessFailed     = "Ошибка при изменении доступа к датчику"
+	ErrHomeNotFound           = "Дом не найден"
+	ErrHomeCreateFailed       = "Не удалось создать дом"
+	ErrLastHomeOwner          = "Нельзя лишить дом последнего владельца"
+	ErrHomeMembersFailed      = "Ошибка при изменении участников дома"
+	ErrRoomNotFound           = "Комната не найдена"
+	ErrRoomFailed             = "Ошибка при работе с комнатой"
+	ErrSensorNotInRoomHome    = "Датчик и комната принадлежат разным домам"
+	ErrSensorUpdateFailed     = "Не удалось изменить датчик"
+	ErrSensorDeleteFailed     = "Не удалось удалить датчик"
+	ErrSensorInactive         = "Датчик отключён, события не принимаются"
+	ErrUserUpdateFailed       = "Ошибка при изменении пользователя"
+	ErrUnknownSensorType      = "Неизвестный тип датчика"
+	ErrSensorTypeNotFound     = "Тип датчика не найден"
+	ErrSensorTypeExists       = "Тип датчика с таким кодом уже существует"
+	ErrSensorTypeFailed       = "Ошибка при работе с реестром типов датчиков"
+	ErrSensorValueOutOfRange  = "Значение события вне допустимого диапазона типа датчика"
+	ErrAdminRequired          = "Действие доступно только администратору"
+	ErrInvalidSensorValue     = "Значение события не соответствует типу датчика"
+	ErrInvalidCalibration     = "Калибровка задана неверно или не подходит типу датчика"
+	ErrEventFromFuture        = "Время события опережает время сервера больше допустимого"
+	ErrEventBatchTooLarge     = "В пакете слишком много событий"
+	ErrEventDuplicate         = "Событие с таким event_id уже сохранено"
+	ErrInvalidHistoryQuery    = "Неверные параметры выборки истории"
+	ErrRetentionNotFound      = "Политика хранения не найдена"
+	ErrRetentionFailed        = "Ошибка при работе с политиками хранения"
+	ErrHistoryExportFailed    = "Ошибка выгрузки истории"
+	ErrInvalidImportFile      = "Неверный формат файла импорта"
+	ErrEventTimestampRequired = "Не указано время события"
+	ErrImportFailed           = "Ошибка импорта"
+	ErrStreamCommandFailed    = "Ошибка при изменении подписки на события"
+	ErrSensorIDRequired       = "Не указан ни один датчик"
+	ErrEventStreamFailed      = "Ошибка при открытии потока событий"
+	ErrSlowConsumerPolicy     = "Неизвестная политика медленного клиента"
+)
+
+const (
+	// sensorKeyHeader - заголовок с ключом датчика в открытом виде
+	sensorKeyHeader = "X-Sensor-Key"
+	// sensorSignatureHeader - заголовок с HMAC-SHA256 подписью времени и тела запроса в hex
+	sensorSignatureHeader = "X-Sensor-Signature"
+	// sensorTimestampHeader - заголовок со временем подписи в секундах Unix
+	sensorTimestampHeader = "X-Sensor-Timestamp"
+	// ndjsonContentType - тип тела с JSON-объектами, по одному в строке
+	ndjsonContentType = "application/x-ndjson"
+	// nextCursorHeader - заголовок ответа с курсором следующей страницы истории
+	nextCursorHeader = "X-Next-Cursor"
 )
 
 type Handlers struct {
@@ -51,6 +115,20 @@ func (h *Handlers) requireJSONContentType(c *gin.Context) {
 	}
 }
 
+// requireBatchContentType - пакет событий принимается массивом JSON или в NDJSON
+func (h *Handlers) requireBatchContentType(c *gin.Context) {
+	if c.ContentType() != "application/json" && c.ContentType() != ndjsonContentType {
+		c.AbortWithStatus(http.StatusUnsupportedMediaType)
+	}
+}
+
+// requireMultipartContentType - файлы импорта принимаются формой multipart/form-data
+func (h *Handlers) requireMultipartContentType(c *gin.Context) {
+	if c.ContentType() != "multipart/form-data" {
+		c.AbortWithStatus(http.StatusUnsupportedMediaType)
+	}
+}
+
 func (h *Handlers) handleError(c *gin.Context, err error, status int, message string) {
 	if err != nil {
 		modelError := models.Error{
@@ -89,11 +167,131 @@ func (h *Handlers) postUsers(c *gin.Context) {
 	h.handleError(c, c.ShouldBindJSON(&user), http.StatusBadRequest, ErrInvalidJSONFormat)
 	h.handleError(c, user.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
 
-	result, err := h.us.User.RegisterUser(c.Request.Context(), &domain.User{Name: *user.Name})
+	if c.IsAborted() {
+		return
+	}
+
+	result, err := h.us.User.RegisterUser(c.Request.Context(), &domain.User{Name: *user.Name}, *user.Password)
+	if errors.Is(err, usecase.ErrUserAlreadyExists) {
+		h.handleError(c, err, http.StatusConflict, ErrUserAlreadyExists)
+		return
+	}
 	h.handleError(c, err, http.StatusUnprocessableEntity, ErrUserCreateFailed)
 	c.JSON(http.StatusOK, result)
 }
 
+func (h *Handlers) getUsers(c *gin.Context) {
+	users, err := h.us.User.GetUsers(c.Request.Context())
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+	result := make([]models.User, 0, len(users))
+	for i := range users {
+		result = append(result, toUserModel(&users[i]))
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handlers) getUsersUID(c *gin.Context) {
+	userID := h.parseId(c, "user_id")
+	if c.IsAborted() {
+		return
+	}
+	user, err := h.us.User.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toUserModel(user))
+}
+
+func (h *Handlers) patchUsersUID(c *gin.Context) {
+	userID := h.parseId(c, "user_id")
+	if c.IsAborted() {
+		return
+	}
+	var update models.UserToUpdate
+	h.handleError(c, c.ShouldBindJSON(&update), http.StatusBadRequest, ErrInvalidJSONFormat)
+	h.handleError(c, update.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
+	if c.IsAborted() {
+		return
+	}
+	user, err := h.us.User.UpdateUser(c.Request.Context(), userID, domain.UserUpdate{Name: update.Name})
+	if err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toUserModel(user))
+}
+
+func (h *Handlers) deleteUsersUID(c *gin.Context) {
+	userID := h.parseId(c, "user_id")
+	if c.IsAborted() {
+		return
+	}
+	if err := h.us.User.DeleteUser(c.Request.Context(), userID); err != nil {
+		h.handleUserError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handlers) handleUserError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrUserNotFound):
+		h.handleError(c, err, http.StatusNotFound, ErrUserNotFound)
+	case errors.Is(err, usecase.ErrInvalidUserName):
+		h.handleError(c, err, http.StatusUnprocessableEntity, ErrValidation)
+	case errors.Is(err, usecase.ErrLastSensorOwner):
+		h.handleError(c, err, http.StatusConflict, ErrLastSensorOwner)
+	case errors.Is(err, usecase.ErrLastHomeOwner):
+		h.handleError(c, err, http.StatusConflict, ErrLastHomeOwner)
+	default:
+		h.handleError(c, err, http.StatusInternalServerError, ErrUserUpdateFailed)
+	}
+}
+
+func (h *Handlers) postAuthLogin(c *gin.Context) {
+	var credentials models.UserCredentials
+	h.handleError(c, c.ShouldBindJSON(&credentials), http.StatusBadRequest, ErrInvalidJSONFormat)
+	h.handleError(c, credentials.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
+	if c.IsAborted() {
+		return
+	}
+
+	tokens, err := h.us.Auth.Login(c.Request.Context(), *credentials.Login, *credentials.Password)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidCredentials) {
+			h.handleError(c, err, http.StatusUnauthorized, ErrInvalidCredentials)
+		} else {
+			h.handleError(c, err, http.StatusInternalServerError, ErrAuthFailed)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toTokensModel(tokens))
+}
+
+func (h *Handlers) postAuthRefresh(c *gin.Context) {
+	var refreshToken models.RefreshToken
+	h.handleError(c, c.ShouldBindJSON(&refreshToken), http.StatusBadRequest, ErrInvalidJSONFormat)
+	h.handleError(c, refreshToken.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
+	if c.IsAborted() {
+		return
+	}
+
+	tokens, err := h.us.Auth.Refresh(c.Request.Context(), *refreshToken.RefreshToken)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidToken) {
+			h.handleError(c, err, http.StatusUnauthorized, ErrUnauthorized)
+		} else {
+			h.handleError(c, err, http.StatusInternalServerError, ErrAuthFailed)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toTokensModel(tokens))
+}
+
 func (h *Handlers) getSensors(c *gin.Context) {
 	sensors, err := h.us.Sensor.GetSensors(c.Request.Context())
 	h.handleError(c, err, http.StatusInternalServerError, ErrSensorNotFound)
@@ -105,12 +303,31 @@ func (h *Handlers) postSensors(c *gin.Context) {
 	h.handleError(c, c.ShouldBindJSON(&sensor), http.StatusBadRequest, ErrInvalidJSONFormat)
 	h.handleError(c, sensor.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
 
+	if c.IsAborted() {
+		return
+	}
+
 	result, err := h.us.Sensor.RegisterSensor(c.Request.Context(), &domain.Sensor{
 		Type:         domain.SensorType(*sensor.Type),
 		SerialNumber: *sensor.SerialNumber,
 		Description:  *sensor.Description,
 		IsActive:     *sensor.IsActive,
+		HomeID:       sensor.HomeID,
 	})
+	switch {
+	case errors.Is(err, usecase.ErrWrongSensorType):
+		h.handleError(c, err, http.StatusUnprocessableEntity, ErrUnknownSensorType)
+		return
+	case errors.Is(err, usecase.ErrSensorAlreadyExists):
+		h.handleError(c, err, http.StatusConflict, ErrSensorAlreadyExists)
+		return
+	case errors.Is(err, usecase.ErrHomeNotFound):
+		h.handleError(c, err, http.StatusNotFound, ErrHomeNotFound)
+		return
+	case errors.Is(err, usecase.ErrSensorAccessDenied):
+		h.handleError(c, err, http.StatusForbidden, ErrSensorAccessDenied)
+		return
+	}
 	h.handleError(c, err, http.StatusInternalServerError, ErrSensorCreateFailed)
 	c.JSON(http.StatusOK, result)
 }
@@ -122,6 +339,251 @@ func (h *Handlers) getSensorsSID(c *gin.Context) {
 	c.JSON(http.StatusOK, sensor)
 }
 
+func (h *Handlers) patchSensorsSID(c *gin.Context) {
+	sensorID := h.parseId(c, "sensor_id")
+	if c.IsAborted() {
+		return
+	}
+	var update models.SensorToUpdate
+	h.handleError(c, c.ShouldBindJSON(&update), http.StatusBadRequest, ErrInvalidJSONFormat)
+	h.handleError(c, update.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
+	if c.IsAborted() {
+		return
+	}
+	sensor, err := h.us.Sensor.UpdateSensor(c.Request.Context(), sensorID, domain.SensorUpdate{
+		Description: update.Description,
+		IsActive:    update.IsActive,
+		Unit:        update.Unit,
+		Calibration: toCalibration(update.Calibration),
+	})
+	if err != nil {
+		h.handleSensorError(c, err, ErrSensorUpdateFailed)
+		return
+	}
+	c.JSON(http.StatusOK, sensor)
+}
+
+// deleteSensorsSID - удаляет датчик, с параметром purge=true сначала удаляются и все его события
+func (h *Handlers) deleteSensorsSID(c *gin.Context) {
+	sensorID := h.parseId(c, "sensor_id")
+	if c.IsAborted() {
+		return
+	}
+	purge := false
+	if value := c.Query("purge"); value != "" {
+		var err error
+		purge, err = strconv.ParseBool(value)
+		h.handleError(c, err, http.StatusUnprocessableEntity, ErrValidation)
+		if c.IsAborted() {
+			return
+		}
+	}
+	if purge {
+		if err := h.us.Event.PurgeSensorEvents(c.Request.Context(), sensorID); err != nil {
+			h.handleSensorError(c, err, ErrSensorDeleteFailed)
+			return
+		}
+	}
+	if err := h.us.Sensor.DeleteSensor(c.Request.Context(), sensorID); err != nil {
+		h.handleSensorError(c, err, ErrSensorDeleteFailed)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handlers) handleSensorError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, usecase.ErrSensorNotFound):
+		h.handleError(c, err, http.StatusNotFound, ErrSensorNotFound)
+	case errors.Is(err, usecase.ErrSensorAccessDenied):
+		h.handleError(c, err, http.StatusForbidden, ErrSensorAccessDenied)
+	case errors.Is(err, usecase.ErrInvalidCalibration):
+		h.handleError(c, err, http.StatusUnprocessableEntity, ErrInvalidCalibration)
+	default:
+		h.handleError(c, err, http.StatusInternalServerError, message)
+	}
+}
+
+func (h *Handlers) getSensorTypes(c *gin.Context) {
+	definitions, err := h.us.SensorType.GetSensorTypes(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, ErrSensorTypeFailed)
+		return
+	}
+	result := make([]models.SensorTypeDefinition, len(definitions))
+	for i, definition := range definitions {
+		result[i] = toSensorTypeModel(&definition)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handlers) postSensorTypes(c *gin.Context) {
+	var definition models.SensorTypeDefinition
+	h.handleError(c, c.ShouldBindJSON(&definition), http.StatusBadRequest, ErrInvalidJSONFormat)
+	h.handleError(c, definition.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
+	if c.IsAborted() {
+		return
+	}
+	channels := make([]domain.SensorChannelDefinition, len(definition.Channels))
+	for i, channel := range definition.Channels {
+		channels[i] = domain.SensorChannelDefinition{
+			Name:      *channel.Name,
+			ValueKind: domain.SensorValueKind(*channel.ValueKind),
+			MinValue:  channel.MinValue,
+			MaxValue:  channel.MaxValue,
+			Unit:      channel.Unit,
+		}
+	}
+	created, err := h.us.SensorType.CreateSensorType(c.Request.Context(), domain.SensorTypeDefinition{
+		Type:      domain.SensorType(*definition.Type),
+		Name:      *definition.Name,
+		ValueKind: domain.SensorValueKind(*definition.ValueKind),
+		MinValue:  definition.MinValue,
+		MaxValue:  definition.MaxValue,
+		Unit:      definition.Unit,
+		Channels:  channels,
+	})
+	if err != nil {
+		h.handleSensorTypeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toSensorTypeModel(created))
+}
+
+func (h *Handlers) getSensorTypesType(c *gin.Context) {
+	definition, err := h.us.SensorType.GetSensorType(c.Request.Context(), domain.SensorType(c.Param("sensor_type")))
+	if err != nil {
+		h.handleSensorTypeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toSensorTypeModel(definition))
+}
+
+func (h *Handlers) handleSensorTypeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrSensorTypeNotFound):
+		h.handleError(c, err, http.StatusNotFound, ErrSensorTypeNotFound)
+	case errors.Is(err, usecase.ErrSensorTypeAlreadyExists):
+		h.handleError(c, err, http.StatusConflict, ErrSensorTypeExists)
+	case errors.Is(err, usecase.ErrInvalidSensorType):
+		h.handleError(c, err, http.StatusUnprocessableEntity, ErrValidation)
+	case errors.Is(err, usecase.ErrAdminRequired):
+		h.handleError(c, err, http.StatusForbidden, ErrAdminRequired)
+	default:
+		h.handleError(c, err, http.StatusInternalServerError, ErrSensorTypeFailed)
+	}
+}
+
+// maxRetentionDays - наибольший срок хранения, который помещается в time.Duration
+const maxRetentionDays = int64(math.MaxInt64 / int64(24*time.Hour))
+
+func (h *Handlers) getRetention(c *gin.Context) {
+	policies, err := h.us.Retention.GetRetentionPolicies(c.Request.Context())
+	if err != nil {
+		h.handleRetentionError(c, err)
+		return
+	}
+	result := make([]models.RetentionPolicy, len(policies))
+	for i, policy := range policies {
+		result[i] = models.RetentionPolicy{
+			SensorType:    string(policy.SensorType),
+			SensorID:      policy.SensorID,
+			RetentionDays: swag.Int64(int64(policy.Retention / (24 * time.Hour))),
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handlers) putRetentionSensorTypesType(c *gin.Context) {
+	h.putRetentionPolicy(c, domain.RetentionPolicy{SensorType: domain.SensorType(c.Param("sensor_type"))})
+}
+
+func (h *Handlers) deleteRetentionSensorTypesType(c *gin.Context) {
+	h.deleteRetentionPolicy(c, domain.RetentionPolicy{SensorType: domain.SensorType(c.Param("sensor_type"))})
+}
+
+func (h *Handlers) putRetentionSensorsSID(c *gin.Context) {
+	sensorID := h.parseId(c, "sensor_id")
+	if c.IsAborted() {
+		return
+	}
+	h.putRetentionPolicy(c, domain.RetentionPolicy{SensorID: sensorID})
+}
+
+func (h *Handlers) deleteRetentionSensorsSID(c *gin.Context) {
+	sensorID := h.parseId(c, "sensor_id")
+	if c.IsAborted() {
+		return
+	}
+	h.deleteRetentionPolicy(c, domain.RetentionPolicy{SensorID: sensorID})
+}
+
+// putRetentionPolicy - задаёт срок хранения из тела запроса для политики с ключом из пути
+func (h *Handlers) putRetentionPolicy(c *gin.Context, policy domain.RetentionPolicy) {
+	var toSet models.RetentionPolicyToSet
+	h.handleError(c, c.ShouldBindJSON(&toSet), http.StatusBadRequest, ErrInvalidJSONFormat)
+	h.handleError(c, toSet.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
+	if c.IsAborted() {
+		return
+	}
+	if *toSet.RetentionDays > maxRetentionDays {
+		h.handleError(c, usecase.ErrInvalidRetentionPolicy, http.StatusUnprocessableEntity, ErrValidation)
+		return
+	}
+	policy.Retention = time.Duration(*toSet.RetentionDays) * 24 * time.Hour
+	if err := h.us.Retention.SetRetentionPolicy(c.Request.Context(), policy); err != nil {
+		h.handleRetentionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handlers) deleteRetentionPolicy(c *gin.Context, policy domain.RetentionPolicy) {
+	if err := h.us.Retention.DeleteRetentionPolicy(c.Request.Context(), policy); err != nil {
+		h.handleRetentionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handlers) getRetentionPreview(c *gin.Context) {
+	preview, err := h.us.Retention.PreviewRetention(c.Request.Context())
+	if err != nil {
+		h.handleRetentionError(c, err)
+		return
+	}
+	result := make([]models.RetentionPreviewItem, len(preview))
+	for i, item := range preview {
+		before := strfmt.DateTime(item.Before)
+		result[i] = models.RetentionPreviewItem{
+			SensorID:      swag.Int64(item.SensorID),
+			SerialNumber:  swag.String(item.SerialNumber),
+			SensorType:    swag.String(string(item.SensorType)),
+			RetentionDays: swag.Int64(int64(item.Retention / (24 * time.Hour))),
+			Before:        &before,
+			Events:        swag.Int64(item.Events),
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handlers) handleRetentionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrRetentionPolicyNotFound):
+		h.handleError(c, err, http.StatusNotFound, ErrRetentionNotFound)
+	case errors.Is(err, usecase.ErrSensorNotFound):
+		h.handleError(c, err, http.StatusNotFound, ErrSensorNotFound)
+	case errors.Is(err, usecase.ErrSensorTypeNotFound):
+		h.handleError(c, err, http.StatusNotFound, ErrSensorTypeNotFound)
+	case errors.Is(err, usecase.ErrInvalidRetentionPolicy):
+		h.handleError(c, err, http.StatusUnprocessableEntity, ErrValidation)
+	case errors.Is(err, usecase.ErrAdminRequired):
+		h.handleError(c, err, http.StatusForbidden, ErrAdminRequired)
+	default:
+		h.handleError(c, err, http.StatusInternalServerError, ErrRetentionFailed)
+	}
+}
+
 func (h *Handlers) getUsersUIDSensors(c *gin.Context) {
 	userID := h.parseId(c, "user_id")
 	sensors, err := h.us.User.GetUserSensors(c.Request.Context(), userID)
@@ -134,9 +596,18 @@ func (h *Handlers) postUsersUIDSensors(c *gin.Context) {
 	var sensorID models.SensorToUserBinding
 	h.handleError(c, c.ShouldBindJSON(&sensorID), http.StatusBadRequest, ErrInvalidJSONFormat)
 	h.handleError(c, sensorID.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
-	if err := h.us.User.AttachSensorToUser(c.Request.Context(), userID, *sensorID.SensorID); err != nil {
+	if c.IsAborted() {
+		return
+	}
+	role := domain.SensorRoleViewer
+	if sensorID.Role != "" {
+		role = domain.SensorRole(sensorID.Role)
+	}
+	if err := h.us.User.AttachSensorToUser(c.Request.Context(), userID, *sensorID.SensorID, role); err != nil {
 		if errors.Is(err, usecase.ErrUserNotFound) {
 			h.handleError(c, err, http.StatusNotFound, ErrUserNotFound)
+		} else if errors.Is(err, usecase.ErrSensorAccessDenied) {
+			h.handleError(c, err, http.StatusForbidden, ErrSensorAccessDenied)
 		} else {
 			h.handleError(c, err, http.StatusUnprocessableEntity, ErrSensorAttach)
 		}
@@ -145,42 +616,908 @@ func (h *Handlers) postUsersUIDSensors(c *gin.Context) {
 	c.JSON(http.StatusCreated, nil)
 }
 
-func (h *Handlers) postEvent(c *gin.Context) {
-	var event models.SensorEvent
-	h.handleError(c, c.ShouldBindJSON(&event), http.StatusBadRequest, ErrInvalidJSONFormat)
-	h.handleError(c, event.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
-	tmp := &domain.Event{
-		Payload:            *event.Payload,
-		Timestamp:          time.Now(),
-		SensorSerialNumber: *event.SensorSerialNumber,
-	}
-	h.handleError(
-		c,
-		h.us.Event.ReceiveEvent(c.Request.Context(), tmp),
-		http.StatusInternalServerError,
-		ErrEventProcessingFailed,
-	)
-	sensor, err := h.us.Sensor.GetSensorBySerialNumber(c.Request.Context(), *event.SensorSerialNumber)
-	h.handleError(c, err, http.StatusNotFound, ErrSensorNotFound)
-	h.eb.Publish(sensor.ID, tmp)
-	c.Status(http.StatusCreated)
+// deleteUsersUIDSensorsSID - отвязывает датчик от пользователя, то же, что DELETE /sensors/{sensor_id}/access/{user_id}
+func (h *Handlers) deleteUsersUIDSensorsSID(c *gin.Context) {
+	userID := h.parseId(c, "user_id")
+	sensorID := h.parseId(c, "sensor_id")
+	if c.IsAborted() {
+		return
+	}
+	if err := h.us.User.RevokeSensorAccess(c.Request.Context(), sensorID, userID); err != nil {
+		h.handleSensorAccessError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
 }
 
-func (h *Handlers) getSensorsSIDEvents(c *gin.Context) {
+func (h *Handlers) getSensorsSIDAccess(c *gin.Context) {
 	sensorID := h.parseId(c, "sensor_id")
-	_, err := h.us.Sensor.GetSensorByID(c.Request.Context(), sensorID)
+	if c.IsAborted() {
+		return
+	}
+	sensorOwners, err := h.us.User.GetSensorAccess(c.Request.Context(), sensorID)
 	if err != nil {
-		h.handleError(c, err, http.StatusNotFound, ErrSensorNotFound)
+		h.handleSensorAccessError(c, err)
 		return
 	}
-	_ = h.ws.Handle(c, sensorID)
+	result := make([]models.SensorAccess, len(sensorOwners))
+	for i, sensorOwner := range sensorOwners {
+		result[i] = models.SensorAccess{
+			UserID: swag.Int64(sensorOwner.UserID),
+			Role:   swag.String(string(sensorOwner.Role)),
+		}
+	}
+	c.JSON(http.StatusOK, result)
 }
 
-func (h *Handlers) getSensorsSIDHistory(c *gin.Context) {
+func (h *Handlers) putSensorsSIDAccessUID(c *gin.Context) {
 	sensorID := h.parseId(c, "sensor_id")
-	startTime := h.parseDate(c, "start_date")
-	endTime := h.parseDate(c, "end_date")
-	events, err := h.us.Event.GetSensorHistory(c.Request.Context(), sensorID, startTime, endTime)
-	h.handleError(c, err, http.StatusNotFound, ErrSensorNotFound)
-	c.JSON(http.StatusOK, events)
+	userID := h.parseId(c, "user_id")
+	if c.IsAborted() {
+		return
+	}
+	var role models.SensorAccessRole
+	h.handleError(c, c.ShouldBindJSON(&role), http.StatusBadRequest, ErrInvalidJSONFormat)
+	h.handleError(c, role.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
+	if c.IsAborted() {
+		return
+	}
+	err := h.us.User.SetSensorAccess(c.Request.Context(), sensorID, userID, domain.SensorRole(*role.Role))
+	if err != nil {
+		h.handleSensorAccessError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handlers) deleteSensorsSIDAccessUID(c *gin.Context) {
+	sensorID := h.parseId(c, "sensor_id")
+	userID := h.parseId(c, "user_id")
+	if c.IsAborted() {
+		return
+	}
+	if err := h.us.User.RevokeSensorAccess(c.Request.Context(), sensorID, userID); err != nil {
+		h.handleSensorAccessError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handlers) handleSensorAccessError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrSensorNotFound):
+		h.handleError(c, err, http.StatusNotFound, ErrSensorNotFound)
+	case errors.Is(err, usecase.ErrUserNotFound):
+		h.handleError(c, err, http.StatusNotFound, ErrUserNotFound)
+	case errors.Is(err, usecase.ErrSensorAccessDenied):
+		h.handleError(c, err, http.StatusForbidden, ErrSensorAccessDenied)
+	case errors.Is(err, usecase.ErrLastSensorOwner):
+		h.handleError(c, err, http.StatusConflict, ErrLastSensorOwner)
+	default:
+		h.handleError(c, err, http.StatusInternalServerError, ErrSensorAccessFailed)
+	}
+}
+
+func (h *Handlers) getHomes(c *gin.Context) {
+	homes, err := h.us.Home.GetHomes(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, ErrHomeNotFound)
+		return
+	}
+	result := make([]models.Home, len(homes))
+	for i, home := range homes {
+		result[i] = toHomeModel(&home)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handlers) postHomes(c *gin.Context) {
+	var home models.HomeToCreate
+	h.handleError(c, c.ShouldBindJSON(&home), http.StatusBadRequest, ErrInvalidJSONFormat)
+	h.handleError(c, home.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
+	if c.IsAborted() {
+		return
+	}
+	created, err := h.us.Home.CreateHome(c.Request.Context(), &domain.Home{Name: *home.Name})
+	if errors.Is(err, usecase.ErrInvalidHomeName) {
+		h.handleError(c, err, http.StatusUnprocessableEntity, ErrValidation)
+		return
+	}
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, ErrHomeCreateFailed)
+		return
+	}
+	c.JSON(http.StatusCreated, toHomeModel(created))
+}
+
+func (h *Handlers) getHomesHID(c *gin.Context) {
+	homeID := h.parseId(c, "home_id")
+	if c.IsAborted() {
+		return
+	}
+	home, err := h.us.Home.GetHomeByID(c.Request.Context(), homeID)
+	if err != nil {
+		h.handleHomeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toHomeModel(home))
+}
+
+func (h *Handlers) getHomesHIDMembers(c *gin.Context) {
+	homeID := h.parseId(c, "home_id")
+	if c.IsAborted() {
+		return
+	}
+	members, err := h.us.Home.GetHomeMembers(c.Request.Context(), homeID)
+	if err != nil {
+		h.handleHomeError(c, err)
+		return
+	}
+	result := make([]models.HomeMember, len(members))
+	for i, member := range members {
+		result[i] = models.HomeMember{
+			UserID: swag.Int64(member.UserID),
+			Role:   swag.String(string(member.Role)),
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handlers) putHomesHIDMembersUID(c *gin.Context) {
+	homeID := h.parseId(c, "home_id")
+	userID := h.parseId(c, "user_id")
+	if c.IsAborted() {
+		return
+	}
+	var role models.SensorAccessRole
+	h.handleError(c, c.ShouldBindJSON(&role), http.StatusBadRequest, ErrInvalidJSONFormat)
+	h.handleError(c, role.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
+	if c.IsAborted() {
+		return
+	}
+	err := h.us.Home.SetHomeMember(c.Request.Context(), homeID, userID, domain.SensorRole(*role.Role))
+	if err != nil {
+		h.handleHomeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handlers) deleteHomesHIDMembersUID(c *gin.Context) {
+	homeID := h.parseId(c, "home_id")
+	userID := h.parseId(c, "user_id")
+	if c.IsAborted() {
+		return
+	}
+	if err := h.us.Home.RemoveHomeMember(c.Request.Context(), homeID, userID); err != nil {
+		h.handleHomeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handlers) getHomesHIDSensors(c *gin.Context) {
+	homeID := h.parseId(c, "home_id")
+	if c.IsAborted() {
+		return
+	}
+	sensors, err := h.us.Home.GetHomeSensors(c.Request.Context(), homeID)
+	if err != nil {
+		h.handleHomeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sensors)
+}
+
+func (h *Handlers) handleHomeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrHomeNotFound):
+		h.handleError(c, err, http.StatusNotFound, ErrHomeNotFound)
+	case errors.Is(err, usecase.ErrUserNotFound):
+		h.handleError(c, err, http.StatusNotFound, ErrUserNotFound)
+	case errors.Is(err, usecase.ErrSensorAccessDenied):
+		h.handleError(c, err, http.StatusForbidden, ErrSensorAccessDenied)
+	case errors.Is(err, usecase.ErrLastHomeOwner):
+		h.handleError(c, err, http.StatusConflict, ErrLastHomeOwner)
+	default:
+		h.handleError(c, err, http.StatusInternalServerError, ErrHomeMembersFailed)
+	}
+}
+
+func (h *Handlers) getHomesHIDRooms(c *gin.Context) {
+	homeID := h.parseId(c, "home_id")
+	if c.IsAborted() {
+		return
+	}
+	rooms, err := h.us.Room.GetRooms(c.Request.Context(), homeID)
+	if err != nil {
+		h.handleRoomError(c, err)
+		return
+	}
+	result := make([]models.Room, len(rooms))
+	for i, room := range rooms {
+		result[i] = toRoomModel(&room)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handlers) postHomesHIDRooms(c *gin.Context) {
+	homeID := h.parseId(c, "home_id")
+	if c.IsAborted() {
+		return
+	}
+	var room models.RoomToCreate
+	h.handleError(c, c.ShouldBindJSON(&room), http.StatusBadRequest, ErrInvalidJSONFormat)
+	h.handleError(c, room.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
+	if c.IsAborted() {
+		return
+	}
+	created, err := h.us.Room.CreateRoom(c.Request.Context(), &domain.Room{HomeID: homeID, Name: *room.Name})
+	if err != nil {
+		h.handleRoomError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, toRoomModel(created))
+}
+
+func (h *Handlers) getRoomsRID(c *gin.Context) {
+	roomID := h.parseId(c, "room_id")
+	if c.IsAborted() {
+		return
+	}
+	room, err := h.us.Room.GetRoomByID(c.Request.Context(), roomID)
+	if err != nil {
+		h.handleRoomError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toRoomModel(room))
+}
+
+func (h *Handlers) putRoomsRID(c *gin.Context) {
+	roomID := h.parseId(c, "room_id")
+	if c.IsAborted() {
+		return
+	}
+	var room models.RoomToCreate
+	h.handleError(c, c.ShouldBindJSON(&room), http.StatusBadRequest, ErrInvalidJSONFormat)
+	h.handleError(c, room.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
+	if c.IsAborted() {
+		return
+	}
+	renamed, err := h.us.Room.RenameRoom(c.Request.Context(), roomID, *room.Name)
+	if err != nil {
+		h.handleRoomError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toRoomModel(renamed))
+}
+
+func (h *Handlers) deleteRoomsRID(c *gin.Context) {
+	roomID := h.parseId(c, "room_id")
+	if c.IsAborted() {
+		return
+	}
+	if err := h.us.Room.DeleteRoom(c.Request.Context(), roomID); err != nil {
+		h.handleRoomError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handlers) getRoomsRIDSensors(c *gin.Context) {
+	roomID := h.parseId(c, "room_id")
+	if c.IsAborted() {
+		return
+	}
+	sensors, err := h.us.Room.GetRoomSensors(c.Request.Context(), roomID)
+	if err != nil {
+		h.handleRoomError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sensors)
+}
+
+func (h *Handlers) getRoomsRIDSummary(c *gin.Context) {
+	roomID := h.parseId(c, "room_id")
+	if c.IsAborted() {
+		return
+	}
+	summary, err := h.us.Room.GetRoomSummary(c.Request.Context(), roomID)
+	if err != nil {
+		h.handleRoomError(c, err)
+		return
+	}
+	room := toRoomModel(&summary.Room)
+	result := models.RoomSummary{
+		Room:         &room,
+		Sensors:      make([]*models.RoomSensorState, len(summary.Sensors)),
+		LastActivity: strfmt.DateTime(summary.LastActivity),
+	}
+	for i, sensor := range summary.Sensors {
+		lastActivity := strfmt.DateTime(sensor.LastActivity)
+		result.Sensors[i] = &models.RoomSensorState{
+			SensorID:        swag.Int64(sensor.ID),
+			SerialNumber:    swag.String(sensor.SerialNumber),
+			Type:            swag.String(string(sensor.Type)),
+			CurrentState:    swag.Int64(sensor.CurrentState),
+			CurrentValue:    sensor.CurrentValue,
+			CalibratedState: sensor.CalibratedState,
+			Unit:            sensor.Unit,
+			IsActive:        swag.Bool(sensor.IsActive),
+			LastActivity:    &lastActivity,
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handlers) putSensorsSIDRoom(c *gin.Context) {
+	sensorID := h.parseId(c, "sensor_id")
+	if c.IsAborted() {
+		return
+	}
+	var room models.SensorRoom
+	h.handleError(c, c.ShouldBindJSON(&room), http.StatusBadRequest, ErrInvalidJSONFormat)
+	h.handleError(c, room.Validate(nil), http.StatusUnprocessableEntity, ErrValidation)
+	if c.IsAborted() {
+		return
+	}
+	sensor, err := h.us.Room.AssignSensorToRoom(c.Request.Context(), sensorID, *room.RoomID)
+	if err != nil {
+		h.handleRoomError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sensor)
+}
+
+func (h *Handlers) deleteSensorsSIDRoom(c *gin.Context) {
+	sensorID := h.parseId(c, "sensor_id")
+	if c.IsAborted() {
+		return
+	}
+	if err := h.us.Room.UnassignSensorFromRoom(c.Request.Context(), sensorID); err != nil {
+		h.handleRoomError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handlers) handleRoomError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrRoomNotFound):
+		h.handleError(c, err, http.StatusNotFound, ErrRoomNotFound)
+	case errors.Is(err, usecase.ErrHomeNotFound):
+		h.handleError(c, err, http.StatusNotFound, ErrHomeNotFound)
+	case errors.Is(err, usecase.ErrSensorNotFound):
+		h.handleError(c, err, http.StatusNotFound, ErrSensorNotFound)
+	case errors.Is(err, usecase.ErrSensorAccessDenied):
+		h.handleError(c, err, http.StatusForbidden, ErrSensorAccessDenied)
+	case errors.Is(err, usecase.ErrSensorNotInRoomHome):
+		h.handleError(c, err, http.StatusConflict, ErrSensorNotInRoomHome)
+	case errors.Is(err, usecase.ErrInvalidRoomName):
+		h.handleError(c, err, http.StatusUnprocessableEntity, ErrValidation)
+	default:
+		h.handleError(c, err, http.StatusInternalServerError, ErrRoomFailed)
+	}
+}
+
+func (h *Handlers) postEvent(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		h.handleError(c, err, http.StatusBadRequest, ErrInvalidJSONFormat)
+		return
+	}
+	event, err := decodeEvent(body)
+	if err != nil {
+		status, message := eventErrorStatus(err)
+		h.handleError(c, err, status, message)
+		return
+	}
+	if err := h.authenticateSensor(c, event.SensorSerialNumber, body); err != nil {
+		if errors.Is(err, usecase.ErrSensorUnauthorized) {
+			h.handleError(c, err, http.StatusUnauthorized, ErrSensorUnauthorized)
+		} else {
+			h.handleError(c, err, http.StatusInternalServerError, ErrEventProcessingFailed)
+		}
+		return
+	}
+	err = h.us.Event.ReceiveEvent(c.Request.Context(), event)
+	if errors.Is(err, usecase.ErrEventDuplicate) {
+		// повтор уже сохранённого события получает тот же ответ, что и оригинал, но подписчикам не рассылается
+		c.Status(http.StatusCreated)
+		return
+	}
+	if err != nil {
+		status, message := eventErrorStatus(err)
+		h.handleError(c, err, status, message)
+		return
+	}
+	h.eb.Publish(event.SensorID, event)
+	c.Status(http.StatusCreated)
+}
+
+// postEventsBatch - принимает пакет событий массивом JSON или потоком NDJSON, по событию в строке.
+// Пакет отправляет пользователь от имени датчиков, которыми владеет: ключи датчиков здесь не проверяются.
+// Отклонённые события не мешают сохранению остальных, результат возвращается по каждому событию
+func (h *Handlers) postEventsBatch(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		h.handleError(c, err, http.StatusBadRequest, ErrInvalidJSONFormat)
+		return
+	}
+	var items [][]byte
+	if c.ContentType() == ndjsonContentType {
+		items = splitNDJSON(body)
+	} else {
+		var array []json.RawMessage
+		if err := json.Unmarshal(body, &array); err != nil {
+			h.handleError(c, err, http.StatusBadRequest, ErrInvalidJSONFormat)
+			return
+		}
+		for _, item := range array {
+			items = append(items, item)
+		}
+	}
+	if len(items) > usecase.MaxEventBatchSize {
+		h.handleError(c, usecase.ErrEventBatchTooLarge, http.StatusRequestEntityTooLarge, ErrEventBatchTooLarge)
+		return
+	}
+
+	results := make([]*models.EventBatchItemResult, len(items))
+	events := make([]domain.Event, 0, len(items))
+	indexes := make([]int, 0, len(items))
+	for i, item := range items {
+		event, err := decodeEvent(item)
+		if err != nil {
+			status, message := eventErrorStatus(err)
+			results[i] = eventBatchItemResult(i, status, message)
+			continue
+		}
+		events = append(events, *event)
+		indexes = append(indexes, i)
+	}
+	errs, err := h.us.Event.ReceiveEvents(c.Request.Context(), events)
+	if err != nil {
+		h.handleError(c, err, http.StatusInternalServerError, ErrEventProcessingFailed)
+		return
+	}
+
+	var accepted int64
+	for j, err := range errs {
+		if errors.Is(err, usecase.ErrEventDuplicate) {
+			accepted++
+			results[indexes[j]] = eventBatchItemResult(indexes[j], http.StatusCreated, ErrEventDuplicate)
+			continue
+		}
+		if err != nil {
+			status, message := eventErrorStatus(err)
+			results[indexes[j]] = eventBatchItemResult(indexes[j], status, message)
+			continue
+		}
+		accepted++
+		results[indexes[j]] = eventBatchItemResult(indexes[j], http.StatusCreated, "")
+		h.eb.Publish(events[j].SensorID, &events[j])
+	}
+	c.JSON(http.StatusOK, models.EventBatchResult{
+		Accepted: swag.Int64(accepted),
+		Rejected: swag.Int64(int64(len(items)) - accepted),
+		Results:  results,
+	})
+}
+
+// decodeEvent - разбирает тело события
+func decodeEvent(body []byte) (*domain.Event, error) {
+	var event models.SensorEvent
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&event); err != nil {
+		return nil, errors.Join(importfile.ErrInvalidEventJSON, err)
+	}
+	result, err := importfile.ToDomainEvent(&event)
+	if err != nil {
+		return nil, err
+	}
+	if result.Timestamp.IsZero() {
+		result.Timestamp = time.Now()
+	}
+	return result, nil
+}
+
+// eventErrorStatus - код ответа и сообщение для ошибки приёма события
+func eventErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, importfile.ErrInvalidEventJSON):
+		return http.StatusBadRequest, ErrInvalidJSONFormat
+	case errors.Is(err, importfile.ErrInvalidEvent):
+		return http.StatusUnprocessableEntity, ErrValidation
+	case errors.Is(err, usecase.ErrSensorNotFound):
+		return http.StatusNotFound, ErrSensorNotFound
+	case errors.Is(err, usecase.ErrSensorAccessDenied):
+		return http.StatusForbidden, ErrSensorAccessDenied
+	case errors.Is(err, usecase.ErrSensorInactive):
+		return http.StatusConflict, ErrSensorInactive
+	case errors.Is(err, usecase.ErrSensorValueOutOfRange):
+		return http.StatusUnprocessableEntity, ErrSensorValueOutOfRange
+	case errors.Is(err, usecase.ErrInvalidSensorValue):
+		return http.StatusUnprocessableEntity, ErrInvalidSensorValue
+	case errors.Is(err, usecase.ErrEventFromFuture):
+		return http.StatusUnprocessableEntity, ErrEventFromFuture
+	default:
+		return http.StatusInternalServerError, ErrEventProcessingFailed
+	}
+}
+
+func eventBatchItemResult(index, status int, reason string) *models.EventBatchItemResult {
+	return &models.EventBatchItemResult{
+		Index:  swag.Int64(int64(index)),
+		Status: swag.Int64(int64(status)),
+		Reason: reason,
+	}
+}
+
+// splitNDJSON - делит тело NDJSON на строки, пустые строки пропускаются
+func splitNDJSON(body []byte) [][]byte {
+	var items [][]byte
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		items = append(items, line)
+	}
+	return items
+}
+
+// authenticateSensor - проверяет подпись времени и тела запроса, а если её нет - ключ датчика
+func (h *Handlers) authenticateSensor(c *gin.Context, serialNumber string, body []byte) error {
+	if signature := c.GetHeader(sensorSignatureHeader); signature != "" {
+		decoded, err := hex.DecodeString(signature)
+		if err != nil {
+			return usecase.ErrSensorUnauthorized
+		}
+		timestamp, err := strconv.ParseInt(c.GetHeader(sensorTimestampHeader), 10, 64)
+		if err != nil {
+			return usecase.ErrSensorUnauthorized
+		}
+		_, err = h.us.Sensor.AuthenticateSensorBySignature(c.Request.Context(), serialNumber, timestamp, body, decoded)
+		return err
+	}
+	_, err := h.us.Sensor.AuthenticateSensorByKey(c.Request.Context(), serialNumber, c.GetHeader(sensorKeyHeader))
+	return err
+}
+
+func (h *Handlers) postSensorsSIDKey(c *gin.Context) {
+	sensorID := h.parseId(c, "sensor_id")
+	if c.IsAborted() {
+		return
+	}
+	apiKey, err := h.us.Sensor.RotateSensorKey(c.Request.Context(), sensorID)
+	if err != nil {
+		if errors.Is(err, usecase.ErrSensorNotFound) {
+			h.handleError(c, err, http.StatusNotFound, ErrSensorNotFound)
+		} else if errors.Is(err, usecase.ErrSensorAccessDenied) {
+			h.handleError(c, err, http.StatusForbidden, ErrSensorAccessDenied)
+		} else {
+			h.handleError(c, err, http.StatusInternalServerError, ErrSensorKeyFailed)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, models.SensorKey{
+		SensorID: swag.Int64(sensorID),
+		APIKey:   swag.String(apiKey),
+	})
+}
+
+func (h *Handlers) deleteSensorsSIDKey(c *gin.Context) {
+	sensorID := h.parseId(c, "sensor_id")
+	if c.IsAborted() {
+		return
+	}
+	if err := h.us.Sensor.RevokeSensorKey(c.Request.Context(), sensorID); err != nil {
+		if errors.Is(err, usecase.ErrSensorNotFound) {
+			h.handleError(c, err, http.StatusNotFound, ErrSensorNotFound)
+		} else if errors.Is(err, usecase.ErrSensorAccessDenied) {
+			h.handleError(c, err, http.StatusForbidden, ErrSensorAccessDenied)
+		} else {
+			h.handleError(c, err, http.StatusInternalServerError, ErrSensorKeyFailed)
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handlers) getSensorsSIDEvents(c *gin.Context) {
+	sensorID := h.parseId(c, "sensor_id")
+	_, err := h.us.Sensor.GetSensorByID(c.Request.Context(), sensorID)
+	if err != nil {
+		h.handleError(c, err, http.StatusNotFound, ErrSensorNotFound)
+		return
+	}
+	policy := h.parseSlowConsumerPolicy(c)
+	if c.IsAborted() {
+		return
+	}
+	_ = h.ws.Handle(c, sensorID, policy)
+}
+
+// getStream - открывает поток событий датчиков, на которые клиент подписывается командами
+func (h *Handlers) getStream(c *gin.Context) {
+	policy := h.parseSlowConsumerPolicy(c)
+	if c.IsAborted() {
+		return
+	}
+	_ = h.ws.HandleStream(c, policy)
+}
+
+// parseSlowConsumerPolicy - политика медленного клиента из параметра slow_consumer, без него - политика сервера
+func (h *Handlers) parseSlowConsumerPolicy(c *gin.Context) SlowConsumerPolicy {
+	name := c.Query("slow_consumer")
+	if name == "" {
+		return h.ws.policy
+	}
+	policy, ok := SlowConsumerPolicyOf(name)
+	if !ok {
+		h.handleError(c, errors.New("unknown slow consumer policy"), http.StatusBadRequest, ErrSlowConsumerPolicy)
+	}
+	return policy
+}
+
+// getEventsStream - отдаёт события датчиков sensor_id потоком Server-Sent Events для клиентов, которым
+// недоступен websocket. Последовательность последнего полученного события берётся из заголовка Last-Event-ID
+// или параметра last_event_id, поток можно начать и с сохранённых событий не раньше from_timestamp
+func (h *Handlers) getEventsStream(c *gin.Context) {
+	var sensorIDs []int64
+	for _, value := range c.QueryArray("sensor_id") {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			h.handleError(c, err, http.StatusUnprocessableEntity, ErrInvalidIDFormat)
+			return
+		}
+		sensorIDs = append(sensorIDs, id)
+	}
+	if len(sensorIDs) == 0 {
+		h.handleError(c, errors.New("sensor_id is required"), http.StatusBadRequest, ErrSensorIDRequired)
+		return
+	}
+	var from *domain.ReplayQuery
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value != "" {
+		sequence, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || sequence < 0 {
+			h.handleError(c, errors.Join(errors.New("invalid last event id"), err), http.StatusUnprocessableEntity, ErrInvalidIDFormat)
+			return
+		}
+		from = &domain.ReplayQuery{AfterSequence: sequence}
+	}
+	if c.Query("from_timestamp") != "" {
+		since := h.parseDate(c, "from_timestamp")
+		if c.IsAborted() {
+			return
+		}
+		if from == nil {
+			from = &domain.ReplayQuery{}
+		}
+		from.Since = since
+	}
+	policy := h.parseSlowConsumerPolicy(c)
+	if c.IsAborted() {
+		return
+	}
+	if err := h.ws.HandleSSE(c, sensorIDs, from, policy); err != nil {
+		h.handleSensorError(c, err, ErrEventStreamFailed)
+	}
+}
+
+// getSensorsSIDHistory - отдаёт страницу истории датчика. Если за страницей есть продолжение,
+// курсор для его запроса передаётся в заголовке X-Next-Cursor
+func (h *Handlers) getSensorsSIDHistory(c *gin.Context) {
+	sensorID := h.parseId(c, "sensor_id")
+	if c.IsAborted() {
+		return
+	}
+	query := domain.HistoryQuery{
+		Start: h.parseDate(c, "start_date"),
+		End:   h.parseDate(c, "end_date"),
+	}
+	if c.IsAborted() {
+		return
+	}
+	var err error
+	if limit := c.Query("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 1 {
+			h.handleError(c, errors.Join(usecase.ErrInvalidHistoryQuery, err), http.StatusBadRequest, ErrInvalidHistoryQuery)
+			return
+		}
+	}
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		h.handleError(c, usecase.ErrInvalidHistoryQuery, http.StatusBadRequest, ErrInvalidHistoryQuery)
+		return
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		if query.After, err = decodeHistoryCursor(cursor); err != nil {
+			h.handleError(c, errors.Join(usecase.ErrInvalidHistoryQuery, err), http.StatusBadRequest, ErrInvalidHistoryQuery)
+			return
+		}
+	}
+
+	page, err := h.us.Event.GetSensorHistory(c.Request.Context(), sensorID, query)
+	if errors.Is(err, usecase.ErrInvalidHistoryQuery) {
+		h.handleError(c, err, http.StatusBadRequest, ErrInvalidHistoryQuery)
+		return
+	}
+	if err != nil {
+		h.handleError(c, err, http.StatusNotFound, ErrSensorNotFound)
+		return
+	}
+	if page.Next != nil {
+		c.Header(nextCursorHeader, encodeHistoryCursor(*page.Next))
+	}
+	events := page.Events
+	if events == nil {
+		events = []domain.Event{}
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// getSensorsSIDHistoryAggregate - отдаёт агрегаты истории датчика по интервалам bucket.
+// Интервалы без событий заполняются по параметру fill
+func (h *Handlers) getSensorsSIDHistoryAggregate(c *gin.Context) {
+	sensorID := h.parseId(c, "sensor_id")
+	if c.IsAborted() {
+		return
+	}
+	query := domain.HistoryAggregateQuery{
+		Start: h.parseDate(c, "start_date"),
+		End:   h.parseDate(c, "end_date"),
+		Fill:  domain.HistoryFill(c.DefaultQuery("fill", string(domain.HistoryFillNone))),
+	}
+	if c.IsAborted() {
+		return
+	}
+	bucket, ok := domain.HistoryBuckets[c.Query("bucket")]
+	if !ok {
+		h.handleError(c, usecase.ErrInvalidHistoryQuery, http.StatusBadRequest, ErrInvalidHistoryQuery)
+		return
+	}
+	query.Bucket = bucket
+
+	buckets, err := h.us.Event.GetSensorHistoryAggregates(c.Request.Context(), sensorID, query)
+	if errors.Is(err, usecase.ErrInvalidHistoryQuery) {
+		h.handleError(c, err, http.StatusBadRequest, ErrInvalidHistoryQuery)
+		return
+	}
+	if err != nil {
+		h.handleError(c, err, http.StatusNotFound, ErrSensorNotFound)
+		return
+	}
+	result := make([]models.SensorHistoryBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		result = append(result, toSensorHistoryBucketModel(bucket))
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// encodeHistoryCursor - курсор истории для клиента непрозрачен: это время события в наносекундах и id события в base64
+func encodeHistoryCursor(cursor domain.HistoryCursor) string {
+	raw := strconv.FormatInt(cursor.Timestamp.UnixNano(), 10) + "." + strconv.FormatInt(cursor.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeHistoryCursor(cursor string) (*domain.HistoryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return nil, errors.New("malformed history cursor")
+	}
+	nanos, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	eventID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.HistoryCursor{Timestamp: time.Unix(0, nanos).UTC(), ID: eventID}, nil
+}
+
+func toTokensModel(tokens *domain.Tokens) models.Tokens {
+	expiresAt := strfmt.DateTime(tokens.ExpiresAt)
+	return models.Tokens{
+		AccessToken:  swag.String(tokens.AccessToken),
+		RefreshToken: swag.String(tokens.RefreshToken),
+		TokenType:    swag.String("Bearer"),
+		ExpiresAt:    &expiresAt,
+	}
+}
+
+func toUserModel(user *domain.User) models.User {
+	return models.User{
+		ID:   swag.Int64(user.ID),
+		Name: swag.String(user.Name),
+	}
+}
+
+func toHomeModel(home *domain.Home) models.Home {
+	createdAt := strfmt.DateTime(home.CreatedAt)
+	return models.Home{
+		ID:        swag.Int64(home.ID),
+		Name:      swag.String(home.Name),
+		CreatedAt: &createdAt,
+	}
+}
+
+func toSensorHistoryBucketModel(bucket domain.HistoryBucket) models.SensorHistoryBucket {
+	start := strfmt.DateTime(bucket.Start)
+	return models.SensorHistoryBucket{
+		Start: &start,
+		Count: swag.Int64(bucket.Count),
+		Min:   bucket.Min,
+		Max:   bucket.Max,
+		Avg:   bucket.Avg,
+		First: bucket.First,
+		Last:  bucket.Last,
+	}
+}
+
+func toRoomModel(room *domain.Room) models.Room {
+	createdAt := strfmt.DateTime(room.CreatedAt)
+	return models.Room{
+		ID:        swag.Int64(room.ID),
+		HomeID:    swag.Int64(room.HomeID),
+		Name:      swag.String(room.Name),
+		CreatedAt: &createdAt,
+	}
+}
+
+func toSensorTypeModel(definition *domain.SensorTypeDefinition) models.SensorTypeDefinition {
+	result := models.SensorTypeDefinition{
+		Type:      swag.String(string(definition.Type)),
+		Name:      swag.String(definition.Name),
+		ValueKind: swag.String(string(definition.ValueKind)),
+		MinValue:  definition.MinValue,
+		MaxValue:  definition.MaxValue,
+		Unit:      definition.Unit,
+	}
+	for _, channel := range definition.Channels {
+		result.Channels = append(result.Channels, &models.SensorChannelDefinition{
+			Name:      swag.String(channel.Name),
+			ValueKind: swag.String(string(channel.ValueKind)),
+			MinValue:  channel.MinValue,
+			MaxValue:  channel.MaxValue,
+			Unit:      channel.Unit,
+		})
+	}
+	return result
+}
+
+// toCalibration - калибровка из запроса, nil - калибровка не меняется
+func toCalibration(calibration *models.SensorCalibration) *domain.Calibration {
+	if calibration == nil {
+		return nil
+	}
+	result := &domain.Calibration{
+		Kind:         domain.CalibrationKind(swag.StringValue(calibration.Kind)),
+		Scale:        calibration.Scale,
+		Offset:       calibration.Offset,
+		Coefficients: calibration.Coefficients,
+	}
+	for _, point := range calibration.Points {
+		if point == nil {
+			continue
+		}
+		result.Points = append(result.Points, domain.CalibrationPoint{
+			Raw:   swag.Float64Value(point.Raw),
+			Value: swag.Float64Value(point.Value),
+		})
+	}
+	return result
 }
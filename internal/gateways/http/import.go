@@ -0,0 +1,145 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/gateways/importfile"
+	"homework/internal/usecase"
+	"homework/models"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/swag"
+)
+
+// importFile - открывает файл импорта из поля формы, формат определяется по расширению имени файла,
+// иначе по его Content-Type. Без файла в поле возвращается nil
+func importFile[T any](form *multipart.Form, field string, newReader func(io.Reader, importfile.Format) (usecase.ImportReader[T], error)) (usecase.ImportReader[T], io.Closer, error) {
+	files := form.File[field]
+	if len(files) == 0 {
+		return nil, nil, nil
+	}
+	if len(files) > 1 {
+		return nil, nil, fmt.Errorf("%w: several files in %s", importfile.ErrInvalidFile, field)
+	}
+	format, ok := importfile.FormatOf(files[0].Filename)
+	if !ok {
+		mediaType, _, _ := mime.ParseMediaType(files[0].Header.Get("Content-Type"))
+		switch mediaType {
+		case csvContentType:
+			format = importfile.FormatCSV
+		case ndjsonContentType:
+			format = importfile.FormatNDJSON
+		default:
+			return nil, nil, fmt.Errorf("%w: unknown format of %s", importfile.ErrInvalidFile, field)
+		}
+	}
+	file, err := files[0].Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, err := newReader(file, format)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+	return reader, file, nil
+}
+
+// importErrorReason - причина отклонения записи в том же виде, что и ответ на запрос с этой записью
+func importErrorReason(err error) string {
+	switch {
+	case errors.Is(err, importfile.ErrInvalidFile):
+		return ErrInvalidImportFile
+	case errors.Is(err, importfile.ErrInvalidSensorJSON):
+		return ErrInvalidJSONFormat
+	case errors.Is(err, importfile.ErrInvalidSensor):
+		return ErrValidation
+	case errors.Is(err, usecase.ErrWrongSensorType):
+		return ErrUnknownSensorType
+	case errors.Is(err, usecase.ErrSensorAlreadyExists):
+		return ErrSensorAlreadyExists
+	case errors.Is(err, usecase.ErrHomeNotFound):
+		return ErrHomeNotFound
+	case errors.Is(err, usecase.ErrInvalidEventTimestamp):
+		return ErrEventTimestampRequired
+	}
+	_, message := eventErrorStatus(err)
+	return message
+}
+
+func toImportReportModel(report *domain.ImportReport) models.ImportReport {
+	errs := make([]*models.ImportError, 0, len(report.Errors))
+	for _, importErr := range report.Errors {
+		errs = append(errs, &models.ImportError{
+			File:   swag.String(string(importErr.Source)),
+			Line:   swag.Int64(int64(importErr.Line)),
+			Reason: swag.String(importErrorReason(importErr.Err)),
+		})
+	}
+	keys := make([]*models.ImportSensorKey, 0, len(report.SensorKeys))
+	for _, key := range report.SensorKeys {
+		keys = append(keys, &models.ImportSensorKey{
+			SerialNumber: swag.String(key.SerialNumber),
+			APIKey:       swag.String(key.APIKey),
+		})
+	}
+	return models.ImportReport{
+		DryRun:          swag.Bool(report.DryRun),
+		SensorsCreated:  swag.Int64(report.SensorsCreated),
+		SensorsExisting: swag.Int64(report.SensorsExisting),
+		SensorsRejected: swag.Int64(report.SensorsRejected),
+		EventsImported:  swag.Int64(report.EventsImported),
+		EventsDuplicate: swag.Int64(report.EventsDuplicate),
+		EventsRejected:  swag.Int64(report.EventsRejected),
+		Errors:          errs,
+		SensorKeys:      keys,
+	}
+}
+
+// postImport - импортирует датчики и события из файлов sensors и events формы multipart/form-data.
+// С dry_run=true импорт только проверяется и возвращает отчёт, ничего не сохраняя
+func (h *Handlers) postImport(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		h.handleError(c, err, http.StatusBadRequest, ErrInvalidImportFile)
+		return
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		h.handleError(c, err, http.StatusBadRequest, ErrInvalidImportFile)
+		return
+	}
+	sensors, sensorsFile, err := importFile(form, "sensors", importfile.NewSensorReader)
+	if err != nil {
+		h.handleError(c, err, http.StatusUnprocessableEntity, ErrInvalidImportFile)
+		return
+	}
+	if sensorsFile != nil {
+		defer sensorsFile.Close()
+	}
+	events, eventsFile, err := importFile(form, "events", importfile.NewEventReader)
+	if err != nil {
+		h.handleError(c, err, http.StatusUnprocessableEntity, ErrInvalidImportFile)
+		return
+	}
+	if eventsFile != nil {
+		defer eventsFile.Close()
+	}
+	if sensors == nil && events == nil {
+		h.handleError(c, importfile.ErrInvalidFile, http.StatusUnprocessableEntity, ErrInvalidImportFile)
+		return
+	}
+
+	report, err := h.us.Import.Import(c.Request.Context(), sensors, events, dryRun)
+	h.handleError(c, err, http.StatusInternalServerError, ErrImportFailed)
+	if c.IsAborted() {
+		return
+	}
+	c.JSON(http.StatusOK, toImportReportModel(report))
+}
@@ -2,22 +2,38 @@ package http
 
 import (
 	"bytes"
+	"errors"
+	"homework/internal/usecase"
+	"homework/models"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/swag"
 )
 
 type bufferedResponseWriter struct {
 	gin.ResponseWriter
+	// buffer - копия тела ответа, nil - ответ отдаётся потоком и не копируется
 	buffer *bytes.Buffer
 }
 
 func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
-	w.buffer.Write(data)
+	if w.buffer != nil {
+		w.buffer.Write(data)
+	}
 	return w.ResponseWriter.Write(data)
 }
 
+// Flush - отправляет клиенту записанное. Обработчик, который сбрасывает ответ по частям, отдаёт его потоком,
+// поэтому дальше тело не копируется, а Content-Length не выставляется
+func (w *bufferedResponseWriter) Flush() {
+	w.buffer = nil
+	w.ResponseWriter.Flush()
+}
+
 func ContentLengthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		brw := &bufferedResponseWriter{
@@ -26,7 +42,7 @@ func ContentLengthMiddleware() gin.HandlerFunc {
 		}
 		c.Writer = brw
 		c.Next()
-		if brw.Header().Get("Content-Length") != "" || brw.buffer.Len() == 0 {
+		if brw.buffer == nil || brw.Header().Get("Content-Length") != "" || brw.buffer.Len() == 0 {
 			return
 		}
 		if c.Request.Method == http.MethodHead {
@@ -35,3 +51,45 @@ func ContentLengthMiddleware() gin.HandlerFunc {
 		}
 	}
 }
+
+// AuthMiddleware - определяет пользователя по токену доступа и передаёт его в контекст запроса.
+// Браузеры не умеют выставлять заголовки при открытии websocket и потока EventSource, поэтому для них токен
+// также принимается в параметре access_token.
+func AuthMiddleware(auth *usecase.Auth) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok && (strings.EqualFold(c.GetHeader("Upgrade"), "websocket") || acceptsEventStream(c)) {
+			token, ok = c.GetQuery("access_token")
+		}
+		if !ok || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.Error{Reason: swag.String(ErrUnauthorized)})
+			return
+		}
+		user, err := auth.Authenticate(c.Request.Context(), token)
+		if errors.Is(err, usecase.ErrInvalidToken) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.Error{Reason: swag.String(ErrUnauthorized)})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.Error{Reason: swag.String(ErrAuthFailed)})
+			return
+		}
+		if user.IsAdmin {
+			c.Request = c.Request.WithContext(usecase.WithAdmin(c.Request.Context(), user.ID))
+		} else {
+			c.Request = c.Request.WithContext(usecase.WithCaller(c.Request.Context(), user.ID))
+		}
+		c.Next()
+	}
+}
+
+// acceptsEventStream - клиент ждёт ответ text/event-stream, так EventSource открывает поток событий
+func acceptsEventStream(c *gin.Context) bool {
+	for _, mediaRange := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err == nil && mediaType == sseContentType {
+			return true
+		}
+	}
+	return false
+}
@@ -6,32 +6,134 @@ import (
 
 func setupRouter(r *gin.Engine, us UseCases, ws *WebSocketHandler) {
 	handlers := &Handlers{us: us, ws: ws, eb: ws.eb}
-	r.Use(ContentLengthMiddleware())
+	auth := AuthMiddleware(us.Auth)
+	// тело ответа копируется в память ради Content-Length, поэтому только для HEAD: потоковые ответы не буферизуются
+	contentLength := ContentLengthMiddleware()
 
 	r.HandleMethodNotAllowed = true
 	r.NoMethod(handlers.noMethod)
 
+	r.POST("/auth/login", handlers.requireJSONContentType, handlers.postAuthLogin)
+	r.OPTIONS("/auth/login", handlers.optionsHandler("POST,OPTIONS"))
+
+	r.POST("/auth/refresh", handlers.requireJSONContentType, handlers.postAuthRefresh)
+	r.OPTIONS("/auth/refresh", handlers.optionsHandler("POST,OPTIONS"))
+
+	r.GET("/users", auth, handlers.requireJSONAccept, handlers.getUsers)
 	r.POST("/users", handlers.requireJSONContentType, handlers.postUsers)
-	r.OPTIONS("/users", handlers.optionsHandler("POST,OPTIONS"))
+	r.OPTIONS("/users", handlers.optionsHandler("GET,POST,OPTIONS"))
 
-	r.GET("/sensors", handlers.requireJSONAccept, handlers.getSensors)
-	r.HEAD("/sensors", handlers.requireJSONAccept, handlers.getSensors)
-	r.POST("/sensors", handlers.requireJSONContentType, handlers.postSensors)
+	r.GET("/users/:user_id", auth, handlers.requireJSONAccept, handlers.getUsersUID)
+	r.PATCH("/users/:user_id", auth, handlers.requireJSONContentType, handlers.patchUsersUID)
+	r.DELETE("/users/:user_id", auth, handlers.deleteUsersUID)
+	r.OPTIONS("/users/:user_id", handlers.optionsHandler("GET,PATCH,DELETE,OPTIONS"))
+
+	r.GET("/sensors", auth, handlers.requireJSONAccept, handlers.getSensors)
+	r.HEAD("/sensors", contentLength, auth, handlers.requireJSONAccept, handlers.getSensors)
+	r.POST("/sensors", auth, handlers.requireJSONContentType, handlers.postSensors)
 	r.OPTIONS("/sensors", handlers.optionsHandler("GET,POST,HEAD,OPTIONS"))
 
-	r.GET("/sensors/:sensor_id", handlers.requireJSONAccept, handlers.getSensorsSID)
-	r.HEAD("/sensors/:sensor_id", handlers.requireJSONAccept, handlers.getSensorsSID)
-	r.OPTIONS("/sensors/:sensor_id", handlers.optionsHandler("GET,HEAD,OPTIONS"))
+	r.GET("/sensor-types", auth, handlers.requireJSONAccept, handlers.getSensorTypes)
+	r.POST("/sensor-types", auth, handlers.requireJSONContentType, handlers.postSensorTypes)
+	r.OPTIONS("/sensor-types", handlers.optionsHandler("GET,POST,OPTIONS"))
+
+	r.GET("/sensor-types/:sensor_type", auth, handlers.requireJSONAccept, handlers.getSensorTypesType)
+	r.OPTIONS("/sensor-types/:sensor_type", handlers.optionsHandler("GET,OPTIONS"))
+
+	r.GET("/retention", auth, handlers.requireJSONAccept, handlers.getRetention)
+	r.OPTIONS("/retention", handlers.optionsHandler("GET,OPTIONS"))
+
+	r.GET("/retention/preview", auth, handlers.requireJSONAccept, handlers.getRetentionPreview)
+	r.OPTIONS("/retention/preview", handlers.optionsHandler("GET,OPTIONS"))
+
+	r.PUT("/retention/sensor-types/:sensor_type", auth, handlers.requireJSONContentType, handlers.putRetentionSensorTypesType)
+	r.DELETE("/retention/sensor-types/:sensor_type", auth, handlers.deleteRetentionSensorTypesType)
+	r.OPTIONS("/retention/sensor-types/:sensor_type", handlers.optionsHandler("PUT,DELETE,OPTIONS"))
 
-	r.GET("/users/:user_id/sensors", handlers.requireJSONAccept, handlers.getUsersUIDSensors)
-	r.HEAD("/users/:user_id/sensors", handlers.requireJSONAccept, handlers.getUsersUIDSensors)
-	r.POST("/users/:user_id/sensors", handlers.requireJSONContentType, handlers.postUsersUIDSensors)
+	r.PUT("/retention/sensors/:sensor_id", auth, handlers.requireJSONContentType, handlers.putRetentionSensorsSID)
+	r.DELETE("/retention/sensors/:sensor_id", auth, handlers.deleteRetentionSensorsSID)
+	r.OPTIONS("/retention/sensors/:sensor_id", handlers.optionsHandler("PUT,DELETE,OPTIONS"))
+
+	r.GET("/sensors/:sensor_id", auth, handlers.requireJSONAccept, handlers.getSensorsSID)
+	r.HEAD("/sensors/:sensor_id", contentLength, auth, handlers.requireJSONAccept, handlers.getSensorsSID)
+	r.PATCH("/sensors/:sensor_id", auth, handlers.requireJSONContentType, handlers.patchSensorsSID)
+	r.DELETE("/sensors/:sensor_id", auth, handlers.deleteSensorsSID)
+	r.OPTIONS("/sensors/:sensor_id", handlers.optionsHandler("GET,PATCH,DELETE,HEAD,OPTIONS"))
+
+	r.POST("/sensors/:sensor_id/key", auth, handlers.postSensorsSIDKey)
+	r.DELETE("/sensors/:sensor_id/key", auth, handlers.deleteSensorsSIDKey)
+	r.OPTIONS("/sensors/:sensor_id/key", handlers.optionsHandler("POST,DELETE,OPTIONS"))
+
+	r.GET("/sensors/:sensor_id/access", auth, handlers.requireJSONAccept, handlers.getSensorsSIDAccess)
+	r.OPTIONS("/sensors/:sensor_id/access", handlers.optionsHandler("GET,OPTIONS"))
+
+	r.PUT("/sensors/:sensor_id/access/:user_id", auth, handlers.requireJSONContentType, handlers.putSensorsSIDAccessUID)
+	r.DELETE("/sensors/:sensor_id/access/:user_id", auth, handlers.deleteSensorsSIDAccessUID)
+	r.OPTIONS("/sensors/:sensor_id/access/:user_id", handlers.optionsHandler("PUT,DELETE,OPTIONS"))
+
+	r.GET("/users/:user_id/sensors", auth, handlers.requireJSONAccept, handlers.getUsersUIDSensors)
+	r.HEAD("/users/:user_id/sensors", contentLength, auth, handlers.requireJSONAccept, handlers.getUsersUIDSensors)
+	r.POST("/users/:user_id/sensors", auth, handlers.requireJSONContentType, handlers.postUsersUIDSensors)
 	r.OPTIONS("/users/:user_id/sensors", handlers.optionsHandler("GET,POST,HEAD,OPTIONS"))
 
+	r.DELETE("/users/:user_id/sensors/:sensor_id", auth, handlers.deleteUsersUIDSensorsSID)
+	r.OPTIONS("/users/:user_id/sensors/:sensor_id", handlers.optionsHandler("DELETE,OPTIONS"))
+
+	r.GET("/homes", auth, handlers.requireJSONAccept, handlers.getHomes)
+	r.POST("/homes", auth, handlers.requireJSONContentType, handlers.postHomes)
+	r.OPTIONS("/homes", handlers.optionsHandler("GET,POST,OPTIONS"))
+
+	r.GET("/homes/:home_id", auth, handlers.requireJSONAccept, handlers.getHomesHID)
+	r.OPTIONS("/homes/:home_id", handlers.optionsHandler("GET,OPTIONS"))
+
+	r.GET("/homes/:home_id/members", auth, handlers.requireJSONAccept, handlers.getHomesHIDMembers)
+	r.OPTIONS("/homes/:home_id/members", handlers.optionsHandler("GET,OPTIONS"))
+
+	r.PUT("/homes/:home_id/members/:user_id", auth, handlers.requireJSONContentType, handlers.putHomesHIDMembersUID)
+	r.DELETE("/homes/:home_id/members/:user_id", auth, handlers.deleteHomesHIDMembersUID)
+	r.OPTIONS("/homes/:home_id/members/:user_id", handlers.optionsHandler("PUT,DELETE,OPTIONS"))
+
+	r.GET("/homes/:home_id/sensors", auth, handlers.requireJSONAccept, handlers.getHomesHIDSensors)
+	r.OPTIONS("/homes/:home_id/sensors", handlers.optionsHandler("GET,OPTIONS"))
+
+	r.GET("/homes/:home_id/rooms", auth, handlers.requireJSONAccept, handlers.getHomesHIDRooms)
+	r.POST("/homes/:home_id/rooms", auth, handlers.requireJSONContentType, handlers.postHomesHIDRooms)
+	r.OPTIONS("/homes/:home_id/rooms", handlers.optionsHandler("GET,POST,OPTIONS"))
+
+	r.GET("/rooms/:room_id", auth, handlers.requireJSONAccept, handlers.getRoomsRID)
+	r.PUT("/rooms/:room_id", auth, handlers.requireJSONContentType, handlers.putRoomsRID)
+	r.DELETE("/rooms/:room_id", auth, handlers.deleteRoomsRID)
+	r.OPTIONS("/rooms/:room_id", handlers.optionsHandler("GET,PUT,DELETE,OPTIONS"))
+
+	r.GET("/rooms/:room_id/sensors", auth, handlers.requireJSONAccept, handlers.getRoomsRIDSensors)
+	r.OPTIONS("/rooms/:room_id/sensors", handlers.optionsHandler("GET,OPTIONS"))
+
+	r.GET("/rooms/:room_id/summary", auth, handlers.requireJSONAccept, handlers.getRoomsRIDSummary)
+	r.OPTIONS("/rooms/:room_id/summary", handlers.optionsHandler("GET,OPTIONS"))
+
+	r.PUT("/sensors/:sensor_id/room", auth, handlers.requireJSONContentType, handlers.putSensorsSIDRoom)
+	r.DELETE("/sensors/:sensor_id/room", auth, handlers.deleteSensorsSIDRoom)
+	r.OPTIONS("/sensors/:sensor_id/room", handlers.optionsHandler("PUT,DELETE,OPTIONS"))
+
 	r.POST("/events", handlers.requireJSONContentType, handlers.postEvent)
 	r.OPTIONS("/events", handlers.optionsHandler("POST,OPTIONS"))
 
-	r.GET("/sensors/:sensor_id/events", handlers.getSensorsSIDEvents)
+	r.POST("/events/batch", auth, handlers.requireBatchContentType, handlers.postEventsBatch)
+	r.OPTIONS("/events/batch", handlers.optionsHandler("POST,OPTIONS"))
+
+	r.GET("/sensors/:sensor_id/events", auth, handlers.getSensorsSIDEvents)
+
+	r.GET("/stream", auth, handlers.getStream)
+
+	r.GET("/events/stream", auth, handlers.getEventsStream)
+	r.OPTIONS("/events/stream", handlers.optionsHandler("GET,OPTIONS"))
+
+	r.GET("sensors/:sensor_id/history", auth, handlers.getSensorsSIDHistory)
+	r.GET("/sensors/:sensor_id/history/aggregate", auth, handlers.getSensorsSIDHistoryAggregate)
+
+	r.GET("/history/export", auth, handlers.getHistoryExport)
+	r.OPTIONS("/history/export", handlers.optionsHandler("GET,OPTIONS"))
 
-	r.GET("sensors/:sensor_id/history", handlers.getSensorsSIDHistory)
+	r.POST("/import", auth, handlers.requireMultipartContentType, handlers.postImport)
+	r.OPTIONS("/import", handlers.optionsHandler("POST,OPTIONS"))
 }
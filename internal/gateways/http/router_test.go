@@ -2,19 +2,34 @@ package http
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"homework/internal/domain"
 	"homework/internal/usecase"
+	"homework/models"
 	"homework/pkg/pg_test"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/swag"
+	"github.com/parquet-go/parquet-go"
 	"github.com/stretchr/testify/assert"
 
 	eventRepository "homework/internal/repository/event/postgres"
+	homeRepository "homework/internal/repository/home/postgres"
 	sensorRepository "homework/internal/repository/sensor/postgres"
+	transaction "homework/internal/repository/transaction/postgres"
 	userRepository "homework/internal/repository/user/postgres"
 )
 
@@ -23,15 +38,55 @@ var (
 	sr  = &sensorRepository.SensorRepository{}
 	ur  = &userRepository.UserRepository{}
 	sor = &userRepository.SensorOwnerRepository{}
+	skr = &sensorRepository.SensorKeyRepository{}
+	str = &sensorRepository.SensorTypeRepository{}
+	hr  = &homeRepository.HomeRepository{}
+	rr  = &homeRepository.RoomRepository{}
+	tr  = &transaction.Transactor{}
+	ret = &eventRepository.RetentionRepository{}
 )
 
 var useCases = UseCases{
-	Event:  usecase.NewEvent(er, sr),
-	Sensor: usecase.NewSensor(sr),
-	User:   usecase.NewUser(ur, sor, sr),
+	Auth:       usecase.NewAuth(ur, []byte("test secret")),
+	Event:      usecase.NewEvent(er, sr, sor, hr, str, tr),
+	Sensor:     usecase.NewSensor(sr, sor, skr, hr, str, tr, usecase.WithSensorKeySecret([]byte("test sensor secret"))),
+	SensorType: usecase.NewSensorType(str),
+	User:       usecase.NewUser(ur, sor, sr, hr, tr),
+	Home:       usecase.NewHome(hr, ur, sr, tr),
+	Room:       usecase.NewRoom(rr, hr, sr, sor, tr),
+	Retention:  usecase.NewRetention(ret, er, sr, str, tr),
+	Import:     usecase.NewImport(usecase.NewSensor(sr, sor, skr, hr, str, tr), usecase.NewEvent(er, sr, sor, hr, str, tr), tr),
 }
 
-var router = gin.Default()
+const (
+	testUserName     = "Тестовый пользователь"
+	testUserPassword = "test password"
+	testSensorSN     = "1234567890"
+)
+
+var (
+	// testUserID - id тестового пользователя, от имени которого выполняются запросы
+	testUserID int64
+	// testSensorKey - ключ датчика testSensorSN, выданный при регистрации
+	testSensorKey string
+)
+
+var engine = gin.Default()
+
+// router - выполняет запросы от имени тестового пользователя, если токен не указан явно
+var router = &authorizedRouter{handler: engine}
+
+type authorizedRouter struct {
+	handler http.Handler
+	token   string
+}
+
+func (r *authorizedRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Header.Get("Authorization") == "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+	r.handler.ServeHTTP(w, req)
+}
 
 func init() {
 	testDB := pg_test.SetupTestDatabase()
@@ -41,8 +96,42 @@ func init() {
 	*sr = *sensorRepository.NewSensorRepository(testDbInstance)
 	*ur = *userRepository.NewUserRepository(testDbInstance)
 	*sor = *userRepository.NewSensorOwnerRepository(testDbInstance)
+	*skr = *sensorRepository.NewSensorKeyRepository(testDbInstance)
+	*str = *sensorRepository.NewSensorTypeRepository(testDbInstance)
+	*hr = *homeRepository.NewHomeRepository(testDbInstance)
+	*rr = *homeRepository.NewRoomRepository(testDbInstance)
+	*tr = *transaction.NewTransactor(testDbInstance)
+	*ret = *eventRepository.NewRetentionRepository(testDbInstance)
+
+	setupRouter(engine, useCases, NewWebSocketHandler(useCases))
+
+	user, err := useCases.User.RegisterUser(context.Background(), &domain.User{Name: testUserName}, testUserPassword)
+	if err != nil {
+		panic(err)
+	}
+	tokens, err := useCases.Auth.IssueTokens(user.ID)
+	if err != nil {
+		panic(err)
+	}
+	router.token = tokens.AccessToken
+	testUserID = user.ID
+
+	sensor, err := useCases.Sensor.RegisterSensor(usecase.WithCaller(context.Background(), user.ID), &domain.Sensor{
+		SerialNumber: testSensorSN,
+		Type:         domain.SensorTypeContactClosure,
+		IsActive:     true,
+	})
+	if err != nil {
+		panic(err)
+	}
+	testSensorKey = sensor.APIKey
+}
 
-	setupRouter(router, useCases, NewWebSocketHandler(useCases))
+// signBody - подписывает тело запроса так же, как это делает датчик
+func signBody(apiKey string, timestamp int64, body string) string {
+	mac := hmac.New(sha256.New, []byte(apiKey))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "." + body))
+	return hex.EncodeToString(mac.Sum(nil))
 }
 
 // Все неизвестные пути должны возвращать http.StatusNotFound.
@@ -73,6 +162,115 @@ func TestUnknownRoute(t *testing.T) {
 	}
 }
 
+// Тесты /auth
+func TestAuthRoutes(t *testing.T) {
+	t.Run("no_token_401", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		req, _ := http.NewRequest(http.MethodGet, "/sensors", nil)
+		req.Header.Add("Accept", "application/json")
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("invalid_token_401", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		req, _ := http.NewRequest(http.MethodGet, "/sensors", nil)
+		req.Header.Add("Accept", "application/json")
+		req.Header.Add("Authorization", "Bearer invalid")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("POST_auth_login", func(t *testing.T) {
+		t.Run("valid_credentials_200", func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			body := `{
+				"login": "Тестовый пользователь",
+				"password": "test password"
+			}`
+			req, _ := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader([]byte(body)))
+			req.Header.Add("Content-Type", "application/json")
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+			var tokens models.Tokens
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens), "В ответе не json")
+			assert.NoError(t, tokens.Validate(nil))
+		})
+
+		t.Run("wrong_password_401", func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			body := `{
+				"login": "Тестовый пользователь",
+				"password": "wrong password"
+			}`
+			req, _ := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader([]byte(body)))
+			req.Header.Add("Content-Type", "application/json")
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, "Получили в ответ не тот код")
+		})
+
+		t.Run("request_body_has_unsupported_format_415", func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			req, _ := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader([]byte("<Login/>")))
+			req.Header.Add("Content-Type", "application/xml")
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnsupportedMediaType, w.Code, "Получили в ответ не тот код")
+		})
+
+		t.Run("request_body_is_valid_but_it_has_invalid_data_422", func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			body := `{
+				"login": "Тестовый пользователь"
+			}`
+			req, _ := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader([]byte(body)))
+			req.Header.Add("Content-Type", "application/json")
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Получили в ответ не тот код")
+		})
+	})
+
+	t.Run("POST_auth_refresh", func(t *testing.T) {
+		t.Run("valid_token_200", func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			user, err := ur.GetCredentialsByLogin(context.Background(), testUserName)
+			assert.NoError(t, err)
+			tokens, err := useCases.Auth.IssueTokens(user.UserID)
+			assert.NoError(t, err)
+
+			body, _ := json.Marshal(models.RefreshToken{RefreshToken: &tokens.RefreshToken})
+			req, _ := http.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(body))
+			req.Header.Add("Content-Type", "application/json")
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		})
+
+		t.Run("access_token_instead_of_refresh_401", func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			body, _ := json.Marshal(models.RefreshToken{RefreshToken: &router.token})
+			req, _ := http.NewRequest(http.MethodPost, "/auth/refresh", bytes.NewReader(body))
+			req.Header.Add("Content-Type", "application/json")
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, "Получили в ответ не тот код")
+		})
+	})
+}
+
 // Тесты /users
 func TestUsersRoutes(t *testing.T) {
 	t.Run("POST_users", func(t *testing.T) {
@@ -80,7 +278,8 @@ func TestUsersRoutes(t *testing.T) {
 			w := httptest.NewRecorder()
 
 			body := `{
-				"name": "Пользователь 1"
+				"name": "Пользователь 1",
+				"password": "qwerty123"
 			}`
 			req, _ := http.NewRequest(http.MethodPost, "/users", bytes.NewReader([]byte(body)))
 			req.Header.Add("Content-Type", "application/json")
@@ -118,7 +317,8 @@ func TestUsersRoutes(t *testing.T) {
 			w := httptest.NewRecorder()
 
 			body := `{
-				"name": ""
+				"name": "",
+				"password": "qwerty123"
 			}`
 			req, _ := http.NewRequest(http.MethodPost, "/users", bytes.NewReader([]byte(body)))
 			req.Header.Add("Content-Type", "application/json")
@@ -137,6 +337,7 @@ func TestUsersRoutes(t *testing.T) {
 		allowed := strings.Split(w.Header().Get("Allow"), ",")
 		assert.Contains(t, allowed, http.MethodOptions, "В разрешённых методах нет OPTIONS")
 		assert.Contains(t, allowed, http.MethodPost, "В разрешённых методах нет POST")
+		assert.Contains(t, allowed, http.MethodGet, "В разрешённых методах нет GET")
 	})
 
 	// Другие методы не поддерживаем.
@@ -146,7 +347,6 @@ func TestUsersRoutes(t *testing.T) {
 			input string
 			want  int
 		}{
-			{http.MethodGet, http.MethodGet, http.StatusMethodNotAllowed},
 			{http.MethodPut, http.MethodPut, http.StatusMethodNotAllowed},
 			{http.MethodPut, http.MethodPut, http.StatusMethodNotAllowed},
 			{http.MethodHead, http.MethodHead, http.StatusMethodNotAllowed},
@@ -418,6 +618,8 @@ func TestSensorsRoutes(t *testing.T) {
 		assert.Contains(t, allowed, http.MethodOptions, "В разрешённых методах нет OPTIONS")
 		assert.Contains(t, allowed, http.MethodGet, "В разрешённых методах нет GET")
 		assert.Contains(t, allowed, http.MethodHead, "В разрешённых методах нет HEAD")
+		assert.Contains(t, allowed, http.MethodPatch, "В разрешённых методах нет PATCH")
+		assert.Contains(t, allowed, http.MethodDelete, "В разрешённых методах нет DELETE")
 	})
 
 	// Другие методы не поддерживаем.
@@ -429,8 +631,6 @@ func TestSensorsRoutes(t *testing.T) {
 		}{
 			{http.MethodPost, http.MethodPost, http.StatusMethodNotAllowed},
 			{http.MethodPut, http.MethodPut, http.StatusMethodNotAllowed},
-			{http.MethodDelete, http.MethodDelete, http.StatusMethodNotAllowed},
-			{http.MethodPatch, http.MethodPatch, http.StatusMethodNotAllowed},
 			{http.MethodConnect, http.MethodConnect, http.StatusMethodNotAllowed},
 			{http.MethodTrace, http.MethodTrace, http.StatusMethodNotAllowed},
 		}
@@ -581,7 +781,7 @@ func TestUsersSensorsRoutes(t *testing.T) {
 			body := `{
 				"sensor_id": 1
 			}`
-			req, _ := http.NewRequest(http.MethodPost, "/users/2/sensors", bytes.NewReader([]byte(body)))
+			req, _ := http.NewRequest(http.MethodPost, "/users/100/sensors", bytes.NewReader([]byte(body)))
 			req.Header.Add("Content-Type", "application/json")
 			router.ServeHTTP(w, req)
 
@@ -657,11 +857,91 @@ func TestEventsRoutes(t *testing.T) {
 			}`
 			req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
 			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("X-Sensor-Key", testSensorKey)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusCreated, w.Code, "Получили в ответ не тот код")
+		})
+
+		t.Run("valid_signature_201", func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			body := `{"sensor_serial_number": "1234567890", "payload": 11}`
+			req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+			req.Header.Add("Content-Type", "application/json")
+			timestamp := time.Now().Unix()
+			req.Header.Add("X-Sensor-Timestamp", strconv.FormatInt(timestamp, 10))
+			req.Header.Add("X-Sensor-Signature", signBody(testSensorKey, timestamp, body))
 			router.ServeHTTP(w, req)
 
 			assert.Equal(t, http.StatusCreated, w.Code, "Получили в ответ не тот код")
 		})
 
+		t.Run("expired_signature_401", func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			body := `{"sensor_serial_number": "1234567890", "payload": 12}`
+			req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+			req.Header.Add("Content-Type", "application/json")
+			timestamp := time.Now().Add(-time.Hour).Unix()
+			req.Header.Add("X-Sensor-Timestamp", strconv.FormatInt(timestamp, 10))
+			req.Header.Add("X-Sensor-Signature", signBody(testSensorKey, timestamp, body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, "Получили в ответ не тот код")
+		})
+
+		t.Run("no_sensor_key_401", func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			body := `{"sensor_serial_number": "1234567890", "payload": 10}`
+			req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+			req.Header.Add("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, "Получили в ответ не тот код")
+			assert.Contains(t, w.Body.String(), ErrSensorUnauthorized)
+		})
+
+		t.Run("wrong_sensor_key_401", func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			body := `{"sensor_serial_number": "1234567890", "payload": 10}`
+			req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("X-Sensor-Key", "wrong key")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, "Получили в ответ не тот код")
+		})
+
+		t.Run("tampered_body_401", func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			timestamp := time.Now().Unix()
+			signature := signBody(testSensorKey, timestamp, `{"sensor_serial_number": "1234567890", "payload": 10}`)
+			body := `{"sensor_serial_number": "1234567890", "payload": 99}`
+			req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("X-Sensor-Timestamp", strconv.FormatInt(timestamp, 10))
+			req.Header.Add("X-Sensor-Signature", signature)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, "Получили в ответ не тот код")
+		})
+
+		t.Run("unknown_sensor_401", func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			body := `{"sensor_serial_number": "0000000001", "payload": 10}`
+			req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("X-Sensor-Key", testSensorKey)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, "Получили в ответ не тот код")
+		})
+
 		t.Run("request_body_has_unsupported_format_415", func(t *testing.T) {
 			w := httptest.NewRecorder()
 
@@ -743,6 +1023,1817 @@ func TestEventsRoutes(t *testing.T) {
 	})
 }
 
+// Тесты /sensors/{sensor_id}/key
+func TestSensorsKeyRoutes(t *testing.T) {
+	sensor, err := useCases.Sensor.RegisterSensor(context.Background(), &domain.Sensor{
+		SerialNumber: "2222222222",
+		Type:         domain.SensorTypeADC,
+		IsActive:     true,
+	})
+	assert.NoError(t, err)
+	sensorURL := "/sensors/" + strconv.FormatInt(sensor.ID, 10)
+
+	postEvent := func(apiKey string) int {
+		w := httptest.NewRecorder()
+		body := `{"sensor_serial_number": "2222222222", "payload": 1}`
+		req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("X-Sensor-Key", apiKey)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("POST_sensors_sensor_id_key", func(t *testing.T) {
+		t.Run("foreign_sensor_404", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, sensorURL+"/key", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusNotFound, w.Code, "Получили в ответ не тот код")
+		})
+
+		t.Run("rotate_200", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/sensors/1/key", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+			var key models.SensorKey
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &key))
+			assert.NoError(t, key.Validate(nil))
+			assert.NotEqual(t, testSensorKey, *key.APIKey, "Ключ не изменился")
+
+			assert.Equal(t, http.StatusUnauthorized, postEvent(testSensorKey), "Старый ключ всё ещё действует")
+			testSensorKey = *key.APIKey
+		})
+
+		t.Run("invalid_id_422", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/sensors/abc/key", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Получили в ответ не тот код")
+		})
+
+		t.Run("no_token_401", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/sensors/1/key", nil)
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, "Получили в ответ не тот код")
+		})
+	})
+
+	t.Run("DELETE_sensors_sensor_id_key_204", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, postEvent(sensor.APIKey), "Ключ не принят до отзыва")
+
+		apiKey, err := useCases.Sensor.RotateSensorKey(context.Background(), sensor.ID)
+		assert.NoError(t, err)
+		assert.NoError(t, useCases.Sensor.RevokeSensorKey(context.Background(), sensor.ID))
+		assert.Equal(t, http.StatusUnauthorized, postEvent(apiKey), "Отозванный ключ всё ещё действует")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/sensors/1/key", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code, "Получили в ответ не тот код")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodPost, "/sensors/1/key", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var key models.SensorKey
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &key))
+		testSensorKey = *key.APIKey
+	})
+
+	t.Run("OPTIONS_sensors_sensor_id_key_204", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodOptions, "/sensors/1/key", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code, "Получили в ответ не тот код")
+		allowed := strings.Split(w.Header().Get("Allow"), ",")
+		assert.Contains(t, allowed, http.MethodPost, "В разрешённых методах нет POST")
+		assert.Contains(t, allowed, http.MethodDelete, "В разрешённых методах нет DELETE")
+	})
+}
+
+// Тесты /sensors/{sensor_id}/access
+func TestSensorsAccessRoutes(t *testing.T) {
+	user, err := useCases.User.RegisterUser(context.Background(), &domain.User{Name: "Гость"}, "guest password")
+	assert.NoError(t, err)
+	tokens, err := useCases.Auth.IssueTokens(user.ID)
+	assert.NoError(t, err)
+	guest := &authorizedRouter{handler: engine, token: tokens.AccessToken}
+	guestURL := "/sensors/1/access/" + strconv.FormatInt(user.ID, 10)
+
+	putRole := func(url, role string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, url, bytes.NewReader([]byte(`{"role": "`+role+`"}`)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("PUT_sensors_sensor_id_access_user_id", func(t *testing.T) {
+		t.Run("grant_viewer_204", func(t *testing.T) {
+			assert.Equal(t, http.StatusNoContent, putRole(guestURL, "viewer"), "Получили в ответ не тот код")
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/sensors/1", nil)
+			req.Header.Add("Accept", "application/json")
+			guest.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code, "Наблюдатель не видит датчик")
+		})
+
+		t.Run("viewer_cannot_configure_403", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/sensors/1/key", nil)
+			guest.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusForbidden, w.Code, "Получили в ответ не тот код")
+
+			w = httptest.NewRecorder()
+			req, _ = http.NewRequest(http.MethodGet, "/sensors/1/access", nil)
+			req.Header.Add("Accept", "application/json")
+			guest.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusForbidden, w.Code, "Получили в ответ не тот код")
+		})
+
+		t.Run("invalid_role_422", func(t *testing.T) {
+			assert.Equal(t, http.StatusUnprocessableEntity, putRole(guestURL, "admin"), "Получили в ответ не тот код")
+		})
+
+		t.Run("unknown_user_404", func(t *testing.T) {
+			assert.Equal(t, http.StatusNotFound, putRole("/sensors/1/access/100", "viewer"), "Получили в ответ не тот код")
+		})
+
+		t.Run("last_owner_409", func(t *testing.T) {
+			url := "/sensors/1/access/" + strconv.FormatInt(testUserID, 10)
+			assert.Equal(t, http.StatusConflict, putRole(url, "member"), "Получили в ответ не тот код")
+		})
+	})
+
+	t.Run("GET_sensors_sensor_id_access_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/sensors/1/access", nil)
+		req.Header.Add("Accept", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var access []models.SensorAccess
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &access))
+		roles := make(map[int64]string)
+		for _, a := range access {
+			roles[*a.UserID] = *a.Role
+		}
+		assert.Equal(t, map[int64]string{testUserID: "owner", user.ID: "viewer"}, roles)
+	})
+
+	t.Run("DELETE_sensors_sensor_id_access_user_id_204", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, guestURL, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code, "Получили в ответ не тот код")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, "/sensors/1", nil)
+		req.Header.Add("Accept", "application/json")
+		guest.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, "Доступ не отозван")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodDelete, guestURL, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("OPTIONS_sensors_sensor_id_access_user_id_204", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodOptions, guestURL, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code, "Получили в ответ не тот код")
+		allowed := strings.Split(w.Header().Get("Allow"), ",")
+		assert.Contains(t, allowed, http.MethodPut, "В разрешённых методах нет PUT")
+		assert.Contains(t, allowed, http.MethodDelete, "В разрешённых методах нет DELETE")
+	})
+}
+
+// Тесты /users/{user_id}
+func TestUsersManagementRoutes(t *testing.T) {
+	user, err := useCases.User.RegisterUser(context.Background(), &domain.User{Name: "Временный"}, "temporary password")
+	assert.NoError(t, err)
+	tokens, err := useCases.Auth.IssueTokens(user.ID)
+	assert.NoError(t, err)
+	temporary := &authorizedRouter{handler: engine, token: tokens.AccessToken}
+	userURL := "/users/" + strconv.FormatInt(user.ID, 10)
+
+	t.Run("GET_users_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Add("Accept", "application/json")
+		temporary.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var users []models.User
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &users))
+		assert.Len(t, users, 1, "Пользователь видит чужие учётные записи")
+		assert.Equal(t, user.ID, *users[0].ID)
+	})
+
+	t.Run("GET_users_user_id_foreign_404", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, userURL, nil)
+		req.Header.Add("Accept", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("PATCH_users_user_id_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPatch, userURL, bytes.NewReader([]byte(`{"name": "Постоянный"}`)))
+		req.Header.Add("Content-Type", "application/json")
+		temporary.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var got models.User
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.NoError(t, got.Validate(nil))
+		assert.Equal(t, "Постоянный", *got.Name)
+	})
+
+	t.Run("PATCH_users_user_id_empty_name_422", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPatch, userURL, bytes.NewReader([]byte(`{"name": ""}`)))
+		req.Header.Add("Content-Type", "application/json")
+		temporary.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("DELETE_users_user_id_sensors_sensor_id_204", func(t *testing.T) {
+		assert.NoError(t, useCases.User.SetSensorAccess(context.Background(), 1, user.ID, domain.SensorRoleViewer))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, userURL+"/sensors/1", nil)
+		temporary.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code, "Получили в ответ не тот код")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodDelete, userURL+"/sensors/1", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("DELETE_users_user_id_204", func(t *testing.T) {
+		assert.NoError(t, useCases.User.SetSensorAccess(context.Background(), 1, user.ID, domain.SensorRoleViewer))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, userURL, nil)
+		temporary.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code, "Получили в ответ не тот код")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Add("Accept", "application/json")
+		temporary.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "Токен удалённого пользователя всё ещё действует")
+
+		owners, err := useCases.User.GetSensorAccess(context.Background(), 1)
+		assert.NoError(t, err)
+		for _, owner := range owners {
+			assert.NotEqual(t, user.ID, owner.UserID, "Привязка удалённого пользователя осталась")
+		}
+	})
+
+	t.Run("OPTIONS_users_user_id_204", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodOptions, userURL, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code, "Получили в ответ не тот код")
+		allowed := strings.Split(w.Header().Get("Allow"), ",")
+		assert.Contains(t, allowed, http.MethodPatch, "В разрешённых методах нет PATCH")
+		assert.Contains(t, allowed, http.MethodDelete, "В разрешённых методах нет DELETE")
+	})
+}
+
+// Тесты PATCH и DELETE /sensors/{sensor_id}
+func TestSensorsUpdateDeleteRoutes(t *testing.T) {
+	sensor, err := useCases.Sensor.RegisterSensor(usecase.WithCaller(context.Background(), testUserID), &domain.Sensor{
+		SerialNumber: "5560000001",
+		Type:         domain.SensorTypeADC,
+		Description:  "Датчик на балконе",
+		IsActive:     true,
+	})
+	assert.NoError(t, err)
+	sensorURL := "/sensors/" + strconv.FormatInt(sensor.ID, 10)
+
+	postEvent := func() int {
+		w := httptest.NewRecorder()
+		body := `{"sensor_serial_number": "5560000001", "payload": 1}`
+		req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("X-Sensor-Key", sensor.APIKey)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("PATCH_sensors_sensor_id_200", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, postEvent(), "Событие активного датчика не принято")
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPatch, sensorURL, bytes.NewReader([]byte(`{"is_active": false}`)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var got domain.Sensor
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.False(t, got.IsActive)
+		assert.Equal(t, "Датчик на балконе", got.Description, "Изменилось поле, которого не было в запросе")
+
+		assert.Equal(t, http.StatusConflict, postEvent(), "Событие отключённого датчика принято")
+	})
+
+	t.Run("PATCH_sensors_sensor_id_invalid_json_400", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPatch, sensorURL, bytes.NewReader([]byte(`{"is_active": "no"}`)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("DELETE_sensors_sensor_id_invalid_purge_422", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, sensorURL+"?purge=maybe", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("DELETE_sensors_sensor_id_204", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, sensorURL+"?purge=true", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code, "Получили в ответ не тот код")
+
+		_, err := useCases.Event.GetLastEventBySensorID(context.Background(), sensor.ID)
+		assert.Error(t, err, "События датчика не удалены")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, sensorURL, nil)
+		req.Header.Add("Accept", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, "Удалённый датчик всё ещё доступен")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodDelete, sensorURL, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, "Получили в ответ не тот код")
+
+		assert.Equal(t, http.StatusUnauthorized, postEvent(), "Ключ удалённого датчика всё ещё действует")
+	})
+}
+
+func TestSensorTypesRoutes(t *testing.T) {
+	admin := &domain.User{Name: "Администратор", IsAdmin: true}
+	assert.NoError(t, ur.SaveUser(context.Background(), admin))
+	tokens, err := useCases.Auth.IssueTokens(admin.ID)
+	assert.NoError(t, err)
+	adminRouter := &authorizedRouter{handler: engine, token: tokens.AccessToken}
+
+	postSensorType := func(r http.Handler, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/sensor-types", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+	pressure := `{"type": "pressure", "name": "Давление", "value_kind": "integer", "min_value": 300, "max_value": 1100, "unit": "гПа"}`
+
+	t.Run("GET_sensor_types_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/sensor-types", nil)
+		req.Header.Add("Accept", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var types []models.SensorTypeDefinition
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &types))
+		codes := make([]string, len(types))
+		for i, definition := range types {
+			assert.NoError(t, definition.Validate(nil))
+			codes[i] = *definition.Type
+		}
+		assert.Subset(t, codes, []string{"cc", "adc", "temperature", "humidity", "motion", "co2", "power_meter", "door_lock"})
+	})
+
+	t.Run("GET_sensor_types_type_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/sensor-types/humidity", nil)
+		req.Header.Add("Accept", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var got models.SensorTypeDefinition
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, float64(100), got.MaxValue)
+		assert.Equal(t, "%", got.Unit)
+	})
+
+	t.Run("GET_sensor_types_type_404", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/sensor-types/pressure", nil)
+		req.Header.Add("Accept", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("POST_sensor_types_not_admin_403", func(t *testing.T) {
+		w := postSensorType(router, pressure)
+		assert.Equal(t, http.StatusForbidden, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("POST_sensor_types_201", func(t *testing.T) {
+		w := postSensorType(adminRouter, pressure)
+		assert.Equal(t, http.StatusCreated, w.Code, "Получили в ответ не тот код")
+		var got models.SensorTypeDefinition
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.NoError(t, got.Validate(nil))
+		assert.Equal(t, "pressure", *got.Type)
+
+		w = postSensorType(adminRouter, pressure)
+		assert.Equal(t, http.StatusConflict, w.Code, "Тип датчика перезаписан")
+	})
+
+	t.Run("POST_sensor_types_channels_201", func(t *testing.T) {
+		w := postSensorType(adminRouter, `{"type": "weather", "name": "Погода", "value_kind": "channels", "channels": [
+			{"name": "wind", "value_kind": "float", "min_value": 0, "max_value": 60, "unit": "м/с"},
+			{"name": "rain", "value_kind": "boolean"}]}`)
+		assert.Equal(t, http.StatusCreated, w.Code, "Получили в ответ не тот код")
+		var got models.SensorTypeDefinition
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.NoError(t, got.Validate(nil))
+		assert.Len(t, got.Channels, 2)
+	})
+
+	t.Run("POST_sensor_types_invalid_422", func(t *testing.T) {
+		w := postSensorType(adminRouter, `{"type": "window", "name": "Окно", "value_kind": "binary", "min_value": 0, "max_value": 2}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Получили в ответ не тот код")
+
+		w = postSensorType(adminRouter, `{"type": "Window", "name": "Окно", "value_kind": "binary", "min_value": 0, "max_value": 1}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("POST_sensors_unknown_type_422", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := `{"serial_number": "5580000001", "type": "radiation", "description": "Дозиметр", "is_active": true}`
+		req, _ := http.NewRequest(http.MethodPost, "/sensors", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("POST_events_out_of_range_422", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := `{"serial_number": "5580000002", "type": "pressure", "description": "Барометр", "is_active": true}`
+		req, _ := http.NewRequest(http.MethodPost, "/sensors", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "Датчик нового типа не зарегистрирован")
+		var sensor domain.RegisteredSensor
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensor))
+
+		postEvent := func(payload int) int {
+			w := httptest.NewRecorder()
+			body := `{"sensor_serial_number": "5580000002", "payload": ` + strconv.Itoa(payload) + `}`
+			req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("X-Sensor-Key", sensor.APIKey)
+			router.ServeHTTP(w, req)
+			return w.Code
+		}
+		assert.Equal(t, http.StatusCreated, postEvent(1013), "Событие в диапазоне типа не принято")
+		assert.Equal(t, http.StatusUnprocessableEntity, postEvent(5000), "Событие вне диапазона типа принято")
+	})
+}
+
+func TestTypedEventsRoutes(t *testing.T) {
+	register := func(serialNumber string, sensorType domain.SensorType) *domain.RegisteredSensor {
+		sensor, err := useCases.Sensor.RegisterSensor(usecase.WithCaller(context.Background(), testUserID), &domain.Sensor{
+			SerialNumber: serialNumber,
+			Type:         sensorType,
+			IsActive:     true,
+		})
+		assert.NoError(t, err)
+		return sensor
+	}
+	postEvent := func(sensor *domain.RegisteredSensor, payload string) int {
+		w := httptest.NewRecorder()
+		body := `{"sensor_serial_number": "` + sensor.SerialNumber + `", "payload": ` + payload + `}`
+		req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("X-Sensor-Key", sensor.APIKey)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	history := func(sensor *domain.RegisteredSensor) []domain.Event {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/sensors/"+strconv.FormatInt(sensor.ID, 10)+"/history", nil)
+		q := req.URL.Query()
+		q.Add("start_date", time.Now().Add(-time.Hour).Format(time.RFC3339Nano))
+		q.Add("end_date", time.Now().Add(time.Hour).Format(time.RFC3339Nano))
+		req.URL.RawQuery = q.Encode()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var events []domain.Event
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &events))
+		return events
+	}
+
+	t.Run("POST_events_channels_201", func(t *testing.T) {
+		climate := register("5590000001", domain.SensorTypeClimate)
+		assert.Equal(t, http.StatusCreated, postEvent(climate, `{"temp": 21.4, "rh": 40}`))
+		assert.Equal(t, http.StatusUnprocessableEntity, postEvent(climate, `{"temp": "тепло"}`), "Канал неверного вида принят")
+		assert.Equal(t, http.StatusUnprocessableEntity, postEvent(climate, `{"wind": 3}`), "Неизвестный канал принят")
+		assert.Equal(t, http.StatusUnprocessableEntity, postEvent(climate, `21.4`), "Одно число вместо каналов принято")
+
+		events := history(climate)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, map[string]any{"temp": 21.4, "rh": float64(40)}, events[0].Value)
+		}
+	})
+
+	t.Run("POST_events_float_201", func(t *testing.T) {
+		adc := register("5590000002", domain.SensorTypeADC)
+		assert.Equal(t, http.StatusCreated, postEvent(adc, `3.3`))
+		assert.Equal(t, http.StatusCreated, postEvent(adc, `4095`), "Целое значение АЦП не принято")
+		assert.Equal(t, http.StatusUnprocessableEntity, postEvent(adc, `true`), "Логическое значение АЦП принято")
+		assert.Equal(t, http.StatusUnprocessableEntity, postEvent(adc, `[1, 2]`), "Массив принят")
+
+		sensor, err := useCases.Sensor.GetSensorByID(context.Background(), adc.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4095), sensor.CurrentState)
+		assert.Nil(t, sensor.CurrentValue)
+	})
+
+	t.Run("POST_events_integer_keeps_precision_201", func(t *testing.T) {
+		cc := register("5590000003", domain.SensorTypeContactClosure)
+		assert.Equal(t, http.StatusCreated, postEvent(cc, `9007199254740993`))
+		assert.Equal(t, http.StatusUnprocessableEntity, postEvent(cc, `1.5`), "Дробное значение сухого контакта принято")
+
+		events := history(cc)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, int64(9007199254740993), events[0].Payload)
+			assert.Nil(t, events[0].Value)
+		}
+	})
+
+	t.Run("POST_events_binary_bool_201", func(t *testing.T) {
+		motion := register("5590000004", domain.SensorTypeMotion)
+		assert.Equal(t, http.StatusCreated, postEvent(motion, `true`))
+
+		events := history(motion)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, int64(1), events[0].Payload)
+			assert.Equal(t, true, events[0].Value)
+		}
+	})
+}
+
+// Тесты калибровки датчиков
+func TestCalibrationRoutes(t *testing.T) {
+	sensor, err := useCases.Sensor.RegisterSensor(usecase.WithCaller(context.Background(), testUserID), &domain.Sensor{
+		SerialNumber: "5600000001",
+		Type:         domain.SensorTypeADC,
+		IsActive:     true,
+	})
+	assert.NoError(t, err)
+	sensorURL := "/sensors/" + strconv.FormatInt(sensor.ID, 10)
+
+	patchSensor := func(body string) (int, domain.Sensor) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPatch, sensorURL, bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		var got domain.Sensor
+		if w.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		}
+		return w.Code, got
+	}
+	postEvent := func(payload string) int {
+		w := httptest.NewRecorder()
+		body := `{"sensor_serial_number": "5600000001", "payload": ` + payload + `}`
+		req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("X-Sensor-Key", sensor.APIKey)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("PATCH_sensors_sensor_id_calibration_200", func(t *testing.T) {
+		code, got := patchSensor(`{"unit": "V", "calibration": {"kind": "linear", "scale": 0.001, "offset": 0.5}}`)
+		assert.Equal(t, http.StatusOK, code, "Получили в ответ не тот код")
+		assert.Equal(t, "V", got.Unit)
+		assert.Nil(t, got.CalibratedState, "Откалиброванное значение без событий")
+
+		assert.Equal(t, http.StatusCreated, postEvent("2000"))
+
+		event, err := useCases.Event.GetLastEventBySensorID(context.Background(), sensor.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2000), event.Payload, "Сырое значение изменилось")
+		if assert.NotNil(t, event.CalibratedValue) {
+			assert.InDelta(t, 2.5, *event.CalibratedValue, 1e-9)
+		}
+		assert.Equal(t, "V", event.Unit)
+	})
+
+	t.Run("PATCH_sensors_sensor_id_recalibration_200", func(t *testing.T) {
+		code, got := patchSensor(`{"calibration": {"kind": "table", "points": [{"raw": 0, "value": 0}, {"raw": 4000, "value": 100}]}}`)
+		assert.Equal(t, http.StatusOK, code, "Получили в ответ не тот код")
+		assert.Equal(t, int64(2000), got.CurrentState, "Сырое состояние изменилось")
+		if assert.NotNil(t, got.CalibratedState) {
+			assert.InDelta(t, 50, *got.CalibratedState, 1e-9)
+		}
+
+		event, err := useCases.Event.GetLastEventBySensorID(context.Background(), sensor.ID)
+		assert.NoError(t, err)
+		if assert.NotNil(t, event.CalibratedValue) {
+			assert.InDelta(t, 2.5, *event.CalibratedValue, 1e-9, "Сохранённое событие пересчитано")
+		}
+	})
+
+	t.Run("PATCH_sensors_sensor_id_invalid_calibration_422", func(t *testing.T) {
+		code, _ := patchSensor(`{"calibration": {"kind": "linear"}}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, code, "Принята калибровка с нулевым множителем")
+
+		code, _ = patchSensor(`{"calibration": {"kind": "spline"}}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, code, "Принят неизвестный вид калибровки")
+	})
+
+	t.Run("PATCH_sensors_sensor_id_remove_calibration_200", func(t *testing.T) {
+		code, got := patchSensor(`{"calibration": {"kind": "none"}}`)
+		assert.Equal(t, http.StatusOK, code, "Получили в ответ не тот код")
+		assert.Nil(t, got.Calibration)
+		assert.Nil(t, got.CalibratedState)
+	})
+}
+
+// Тесты событий со временем устройства
+func TestDeviceTimestampsRoutes(t *testing.T) {
+	sensor, err := useCases.Sensor.RegisterSensor(usecase.WithCaller(context.Background(), testUserID), &domain.Sensor{
+		SerialNumber: "5610000001",
+		Type:         domain.SensorTypeContactClosure,
+		IsActive:     true,
+	})
+	assert.NoError(t, err)
+
+	postEvent := func(payload int, timestamp string) int {
+		w := httptest.NewRecorder()
+		body := `{"sensor_serial_number": "5610000001", "payload": ` + strconv.Itoa(payload)
+		if timestamp != "" {
+			body += `, "timestamp": "` + timestamp + `"`
+		}
+		req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body+"}")))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("X-Sensor-Key", sensor.APIKey)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	currentState := func() int64 {
+		got, err := useCases.Sensor.GetSensorByID(context.Background(), sensor.ID)
+		assert.NoError(t, err)
+		return got.CurrentState
+	}
+	hourAgo := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	t.Run("POST_events_device_timestamp_201", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, postEvent(1, hourAgo.Format(time.RFC3339)))
+		assert.Equal(t, int64(1), currentState())
+
+		event, err := useCases.Event.GetLastEventBySensorID(context.Background(), sensor.ID)
+		assert.NoError(t, err)
+		assert.True(t, hourAgo.Equal(event.Timestamp), "Время устройства не сохранено")
+		assert.True(t, event.ReceivedAt.After(hourAgo), "Время получения не сохранено")
+	})
+
+	t.Run("POST_events_out_of_order_201", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, postEvent(2, hourAgo.Add(-time.Hour).Format(time.RFC3339)))
+		assert.Equal(t, int64(1), currentState(), "Старое событие изменило состояние датчика")
+
+		history, err := useCases.Event.GetSensorHistory(context.Background(), sensor.ID, domain.HistoryQuery{
+			Start: hourAgo.Add(-3 * time.Hour),
+			End:   time.Now(),
+		})
+		assert.NoError(t, err)
+		assert.Len(t, history.Events, 2, "Старое событие не попало в историю")
+	})
+
+	t.Run("POST_events_future_timestamp_422", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnprocessableEntity, postEvent(3, time.Now().Add(time.Hour).Format(time.RFC3339)))
+		assert.Equal(t, int64(1), currentState())
+	})
+
+	t.Run("POST_events_invalid_timestamp_400", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, postEvent(3, "вчера"))
+	})
+
+	t.Run("POST_events_without_timestamp_201", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, postEvent(4, ""))
+		assert.Equal(t, int64(4), currentState())
+	})
+}
+
+// Тесты POST /events/batch
+func TestEventsBatchRoutes(t *testing.T) {
+	register := func(serialNumber string, isActive bool) *domain.RegisteredSensor {
+		sensor, err := useCases.Sensor.RegisterSensor(usecase.WithCaller(context.Background(), testUserID), &domain.Sensor{
+			SerialNumber: serialNumber,
+			Type:         domain.SensorTypeContactClosure,
+			IsActive:     isActive,
+		})
+		assert.NoError(t, err)
+		return sensor
+	}
+	first := register("5620000001", true)
+	second := register("5620000002", true)
+	register("5620000003", false)
+
+	postBatch := func(router http.Handler, contentType, body string) (int, models.EventBatchResult) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/events/batch", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", contentType)
+		router.ServeHTTP(w, req)
+		var result models.EventBatchResult
+		if w.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		}
+		return w.Code, result
+	}
+	statuses := func(result models.EventBatchResult) []int64 {
+		var got []int64
+		for i, item := range result.Results {
+			assert.Equal(t, int64(i), *item.Index)
+			got = append(got, *item.Status)
+		}
+		return got
+	}
+
+	t.Run("POST_events_batch_json_200", func(t *testing.T) {
+		code, result := postBatch(router, "application/json", `[
+			{"sensor_serial_number": "5620000001", "payload": 1},
+			{"sensor_serial_number": "5620000002", "payload": 2},
+			{"sensor_serial_number": "5629999999", "payload": 3},
+			{"sensor_serial_number": "5620000003", "payload": 4},
+			{"sensor_serial_number": "5620000001", "payload": [5]},
+			{"sensor_serial_number": "562"}
+		]`)
+		assert.Equal(t, http.StatusOK, code, "Получили в ответ не тот код")
+		assert.Equal(t, int64(2), *result.Accepted)
+		assert.Equal(t, int64(4), *result.Rejected)
+		assert.Equal(t, []int64{201, 201, 404, 409, 422, 422}, statuses(result))
+
+		sensor, err := useCases.Sensor.GetSensorByID(context.Background(), second.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), sensor.CurrentState)
+	})
+
+	t.Run("POST_events_batch_ndjson_200", func(t *testing.T) {
+		hourAgo := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+		body := `{"sensor_serial_number": "5620000001", "payload": 10}` + "\n" +
+			`{"sensor_serial_number": "5620000001", "payload": 11, "timestamp": "` + hourAgo + `"}` + "\n\n" +
+			`{"sensor_serial_number": ` + "\n"
+		code, result := postBatch(router, "application/x-ndjson", body)
+		assert.Equal(t, http.StatusOK, code, "Получили в ответ не тот код")
+		assert.Equal(t, []int64{201, 201, 400}, statuses(result))
+
+		sensor, err := useCases.Sensor.GetSensorByID(context.Background(), first.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), sensor.CurrentState, "Старое событие пакета изменило состояние")
+	})
+
+	t.Run("POST_events_batch_foreign_sensors_200", func(t *testing.T) {
+		user, err := useCases.User.RegisterUser(context.Background(), &domain.User{Name: "Шлюз"}, "gateway password")
+		assert.NoError(t, err)
+		tokens, err := useCases.Auth.IssueTokens(user.ID)
+		assert.NoError(t, err)
+		gateway := &authorizedRouter{handler: engine, token: tokens.AccessToken}
+		assert.NoError(t, useCases.User.SetSensorAccess(context.Background(), second.ID, user.ID, domain.SensorRoleViewer))
+
+		code, result := postBatch(gateway, "application/json", `[
+			{"sensor_serial_number": "5620000001", "payload": 1},
+			{"sensor_serial_number": "5620000002", "payload": 1}
+		]`)
+		assert.Equal(t, http.StatusOK, code, "Получили в ответ не тот код")
+		assert.Equal(t, []int64{404, 403}, statuses(result), "Чужой датчик принял событие")
+	})
+
+	t.Run("POST_events_batch_invalid_json_400", func(t *testing.T) {
+		code, _ := postBatch(router, "application/json", `{"sensor_serial_number": "5620000001", "payload": 1}`)
+		assert.Equal(t, http.StatusBadRequest, code, "Получили в ответ не тот код")
+	})
+
+	t.Run("POST_events_batch_too_large_413", func(t *testing.T) {
+		item := `{"sensor_serial_number": "5620000001", "payload": 1}`
+		body := "[" + strings.Repeat(item+",", usecase.MaxEventBatchSize) + item + "]"
+		code, _ := postBatch(router, "application/json", body)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, code, "Получили в ответ не тот код")
+	})
+
+	t.Run("POST_events_batch_unsupported_media_type_415", func(t *testing.T) {
+		code, _ := postBatch(router, "text/plain", `[]`)
+		assert.Equal(t, http.StatusUnsupportedMediaType, code, "Получили в ответ не тот код")
+	})
+
+	t.Run("POST_events_batch_unauthorized_401", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/events/batch", bytes.NewReader([]byte(`[]`)))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Authorization", "Bearer invalid")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "Получили в ответ не тот код")
+	})
+}
+
+// Тесты повторной отправки событий с event_id
+func TestIdempotentEventsRoutes(t *testing.T) {
+	sensor, err := useCases.Sensor.RegisterSensor(usecase.WithCaller(context.Background(), testUserID), &domain.Sensor{
+		SerialNumber: "5630000001",
+		Type:         domain.SensorTypeContactClosure,
+		IsActive:     true,
+	})
+	assert.NoError(t, err)
+
+	postEvent := func(payload int, eventID string) int {
+		w := httptest.NewRecorder()
+		body := `{"sensor_serial_number": "5630000001", "payload": ` + strconv.Itoa(payload) + `, "event_id": "` + eventID + `"}`
+		req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("X-Sensor-Key", sensor.APIKey)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	history := func() []domain.Event {
+		page, err := useCases.Event.GetSensorHistory(context.Background(), sensor.ID, domain.HistoryQuery{
+			Start: time.Now().Add(-time.Hour),
+			End:   time.Now().Add(time.Hour),
+		})
+		assert.NoError(t, err)
+		return page.Events
+	}
+
+	t.Run("POST_events_retry_201", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, postEvent(1, "boot-1"))
+		assert.Equal(t, http.StatusCreated, postEvent(0, "boot-1"), "Повтор события должен получить тот же ответ")
+
+		events := history()
+		if assert.Len(t, events, 1, "Повтор события сохранён второй раз") {
+			assert.Equal(t, "boot-1", events[0].EventID)
+		}
+		got, err := useCases.Sensor.GetSensorByID(context.Background(), sensor.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), got.CurrentState, "Повтор события изменил состояние датчика")
+	})
+
+	t.Run("POST_events_batch_retry_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/events/batch", bytes.NewReader([]byte(`[
+			{"sensor_serial_number": "5630000001", "payload": 0, "event_id": "boot-1"},
+			{"sensor_serial_number": "5630000001", "payload": 1, "event_id": "boot-2"},
+			{"sensor_serial_number": "5630000001", "payload": 1, "event_id": "boot-2"}
+		]`)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+
+		var result models.EventBatchResult
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		assert.Equal(t, int64(3), *result.Accepted)
+		if assert.Len(t, result.Results, 3) {
+			assert.Equal(t, ErrEventDuplicate, result.Results[0].Reason)
+			assert.Empty(t, result.Results[1].Reason)
+			assert.Equal(t, ErrEventDuplicate, result.Results[2].Reason)
+		}
+		assert.Len(t, history(), 2)
+	})
+
+	t.Run("POST_events_long_event_id_422", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnprocessableEntity, postEvent(1, strings.Repeat("x", 129)))
+	})
+}
+
+func TestSensorHistoryPaginationRoutes(t *testing.T) {
+	sensor, err := useCases.Sensor.RegisterSensor(usecase.WithCaller(context.Background(), testUserID), &domain.Sensor{
+		SerialNumber: "5640000001",
+		Type:         domain.SensorTypeADC,
+		IsActive:     true,
+	})
+	assert.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i := 0; i < 5; i++ {
+		w := httptest.NewRecorder()
+		body := `{"sensor_serial_number": "5640000001", "payload": ` + strconv.Itoa(i) +
+			`, "timestamp": "` + now.Add(time.Duration(i-10)*time.Minute).Format(time.RFC3339) + `"}`
+		req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("X-Sensor-Key", sensor.APIKey)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code, "Получили в ответ не тот код")
+	}
+
+	getHistory := func(params map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/sensors/"+strconv.FormatInt(sensor.ID, 10)+"/history", nil)
+		q := req.URL.Query()
+		q.Add("start_date", now.Add(-time.Hour).Format(time.RFC3339))
+		q.Add("end_date", now.Add(time.Hour).Format(time.RFC3339))
+		for k, v := range params {
+			q.Add(k, v)
+		}
+		req.URL.RawQuery = q.Encode()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	payloads := func(w *httptest.ResponseRecorder) []int64 {
+		var events []domain.Event
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &events))
+		result := make([]int64, 0, len(events))
+		for _, event := range events {
+			result = append(result, event.Payload)
+		}
+		return result
+	}
+
+	t.Run("GET_history_pages_200", func(t *testing.T) {
+		var got []int64
+		cursor := ""
+		for pages := 0; pages < 5; pages++ {
+			params := map[string]string{"limit": "2"}
+			if cursor != "" {
+				params["cursor"] = cursor
+			}
+			w := getHistory(params)
+			assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+			got = append(got, payloads(w)...)
+			cursor = w.Header().Get("X-Next-Cursor")
+			if cursor == "" {
+				break
+			}
+		}
+		assert.Equal(t, []int64{0, 1, 2, 3, 4}, got, "Страницы истории пропускают или повторяют события")
+	})
+
+	t.Run("GET_history_desc_200", func(t *testing.T) {
+		w := getHistory(map[string]string{"limit": "3", "order": "desc"})
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		assert.Equal(t, []int64{4, 3, 2}, payloads(w))
+		assert.NotEmpty(t, w.Header().Get("X-Next-Cursor"))
+
+		w = getHistory(map[string]string{"limit": "3", "order": "desc", "cursor": w.Header().Get("X-Next-Cursor")})
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		assert.Equal(t, []int64{1, 0}, payloads(w))
+		assert.Empty(t, w.Header().Get("X-Next-Cursor"), "Курсор выдан для последней страницы")
+	})
+
+	t.Run("GET_history_invalid_params_400", func(t *testing.T) {
+		for _, params := range []map[string]string{
+			{"limit": "0"},
+			{"limit": "много"},
+			{"limit": "10001"},
+			{"order": "sideways"},
+			{"cursor": "не курсор"},
+		} {
+			w := getHistory(params)
+			assert.Equal(t, http.StatusBadRequest, w.Code, "Получили в ответ не тот код для %v", params)
+		}
+	})
+}
+
+func TestSensorHistoryAggregateRoutes(t *testing.T) {
+	sensor, err := useCases.Sensor.RegisterSensor(usecase.WithCaller(context.Background(), testUserID), &domain.Sensor{
+		SerialNumber: "5650000001",
+		Type:         domain.SensorTypeADC,
+		IsActive:     true,
+	})
+	assert.NoError(t, err)
+
+	base := time.Now().UTC().Truncate(time.Minute).Add(-10 * time.Minute)
+	for _, event := range []struct {
+		offset  time.Duration
+		payload int
+	}{
+		{10 * time.Second, 1},
+		{20 * time.Second, 5},
+		{3*time.Minute + 10*time.Second, 9},
+	} {
+		w := httptest.NewRecorder()
+		body := `{"sensor_serial_number": "5650000001", "payload": ` + strconv.Itoa(event.payload) +
+			`, "timestamp": "` + base.Add(event.offset).Format(time.RFC3339) + `"}`
+		req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("X-Sensor-Key", sensor.APIKey)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code, "Получили в ответ не тот код")
+	}
+
+	getAggregate := func(bucket, fill string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/sensors/"+strconv.FormatInt(sensor.ID, 10)+"/history/aggregate", nil)
+		q := req.URL.Query()
+		q.Add("start_date", base.Format(time.RFC3339))
+		q.Add("end_date", base.Add(4*time.Minute).Format(time.RFC3339))
+		q.Add("bucket", bucket)
+		if fill != "" {
+			q.Add("fill", fill)
+		}
+		req.URL.RawQuery = q.Encode()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("GET_history_aggregate_200", func(t *testing.T) {
+		w := getAggregate("1m", "")
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var buckets []models.SensorHistoryBucket
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &buckets))
+		if assert.Len(t, buckets, 2) {
+			assert.Equal(t, base, time.Time(*buckets[0].Start).UTC())
+			assert.Equal(t, int64(2), *buckets[0].Count)
+			assert.Equal(t, 1.0, *buckets[0].Min)
+			assert.Equal(t, 5.0, *buckets[0].Max)
+			assert.Equal(t, 3.0, *buckets[0].Avg)
+			assert.Equal(t, 1.0, *buckets[0].First)
+			assert.Equal(t, 5.0, *buckets[0].Last)
+			assert.Equal(t, base.Add(3*time.Minute), time.Time(*buckets[1].Start).UTC())
+		}
+	})
+
+	t.Run("GET_history_aggregate_fill_200", func(t *testing.T) {
+		w := getAggregate("1m", "previous")
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var buckets []models.SensorHistoryBucket
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &buckets))
+		if assert.Len(t, buckets, 5) {
+			assert.Equal(t, int64(0), *buckets[1].Count)
+			assert.Equal(t, 5.0, *buckets[1].Avg, "Пустой интервал не заполнен предыдущим значением")
+			assert.Equal(t, 9.0, *buckets[3].Last)
+		}
+	})
+
+	t.Run("GET_history_aggregate_rollups_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/sensors/"+strconv.FormatInt(sensor.ID, 10)+"/history/aggregate", nil)
+		q := req.URL.Query()
+		q.Add("start_date", base.Truncate(24*time.Hour).Add(-24*time.Hour).Format(time.RFC3339))
+		q.Add("end_date", time.Now().UTC().Add(time.Hour).Format(time.RFC3339))
+		q.Add("bucket", "1h")
+		req.URL.RawQuery = q.Encode()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+
+		var buckets []models.SensorHistoryBucket
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &buckets))
+		var count int64
+		var sum float64
+		for _, bucket := range buckets {
+			count += *bucket.Count
+			sum += *bucket.Avg * float64(*bucket.Count)
+		}
+		assert.Equal(t, int64(3), count, "Накопленные агрегаты расходятся с событиями")
+		assert.Equal(t, 15.0, sum)
+	})
+
+	t.Run("GET_history_aggregate_invalid_params_400", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, getAggregate("7m", "").Code)
+		assert.Equal(t, http.StatusBadRequest, getAggregate("", "").Code)
+		assert.Equal(t, http.StatusBadRequest, getAggregate("1m", "linear").Code)
+	})
+}
+
+func TestHistoryExportRoutes(t *testing.T) {
+	base := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+	var sensorIDs []string
+	for i, serialNumber := range []string{"5670000001", "5670000002"} {
+		sensor, err := useCases.Sensor.RegisterSensor(usecase.WithCaller(context.Background(), testUserID), &domain.Sensor{
+			SerialNumber: serialNumber,
+			Type:         domain.SensorTypeADC,
+			IsActive:     true,
+		})
+		assert.NoError(t, err)
+		sensorIDs = append(sensorIDs, strconv.FormatInt(sensor.ID, 10))
+		for j := 0; j <= i; j++ {
+			w := httptest.NewRecorder()
+			body := `{"sensor_serial_number": "` + serialNumber + `", "payload": ` + strconv.Itoa(10*i+j) +
+				`, "timestamp": "` + base.Add(time.Duration(j)*time.Minute).Format(time.RFC3339) + `"}`
+			req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("X-Sensor-Key", sensor.APIKey)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusCreated, w.Code, "Получили в ответ не тот код")
+		}
+	}
+
+	export := func(r http.Handler, accept string, params map[string]string, ids ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/history/export", nil)
+		q := req.URL.Query()
+		for _, id := range ids {
+			q.Add("sensor_id", id)
+		}
+		q.Add("start_date", base.Add(-time.Minute).Format(time.RFC3339))
+		q.Add("end_date", base.Add(time.Hour).Format(time.RFC3339))
+		for key, value := range params {
+			q.Add(key, value)
+		}
+		req.URL.RawQuery = q.Encode()
+		if accept != "" {
+			req.Header.Add("Accept", accept)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("GET_history_export_csv_200", func(t *testing.T) {
+		w := export(router, "", nil, sensorIDs...)
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "history.csv")
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		assert.NoError(t, err)
+		if assert.Len(t, records, 4) {
+			assert.Equal(t, "sensor_id", records[0][0])
+			assert.Equal(t, []string{sensorIDs[0], "5670000001"}, records[1][:2])
+			assert.Equal(t, []string{sensorIDs[1], "5670000002"}, records[2][:2])
+			assert.Equal(t, "11", records[3][6])
+		}
+	})
+
+	t.Run("GET_history_export_ndjson_200", func(t *testing.T) {
+		w := export(router, "application/x-ndjson", map[string]string{"order": "desc"}, sensorIDs[1])
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+		decoder := json.NewDecoder(w.Body)
+		var payloads []int64
+		for decoder.More() {
+			var event domain.Event
+			assert.NoError(t, decoder.Decode(&event))
+			payloads = append(payloads, event.Payload)
+		}
+		assert.Equal(t, []int64{11, 10}, payloads)
+	})
+
+	t.Run("GET_history_export_parquet_200", func(t *testing.T) {
+		w := export(router, "text/csv", map[string]string{"format": "parquet"}, sensorIDs...)
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		assert.Equal(t, "application/vnd.apache.parquet", w.Header().Get("Content-Type"))
+
+		rows, err := parquet.Read[exportRow](bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		assert.NoError(t, err)
+		if assert.Len(t, rows, 3) {
+			assert.Equal(t, "5670000001", rows[0].SensorSerialNumber)
+			assert.Equal(t, base, rows[0].Timestamp.UTC())
+			assert.Equal(t, int64(11), rows[2].Payload)
+		}
+	})
+
+	t.Run("GET_history_export_empty_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/history/export", nil)
+		q := req.URL.Query()
+		q.Add("sensor_id", sensorIDs[0])
+		q.Add("start_date", base.Add(-2*time.Hour).Format(time.RFC3339))
+		q.Add("end_date", base.Add(-time.Hour).Format(time.RFC3339))
+		req.URL.RawQuery = q.Encode()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		assert.Equal(t, strings.Join([]string{"sensor_id", "sensor_serial_number", "id", "timestamp", "received_at",
+			"event_id", "payload", "value", "calibrated_value", "unit", "home_id"}, ",")+"\n", w.Body.String())
+	})
+
+	t.Run("GET_history_export_accept_q_200", func(t *testing.T) {
+		tests := map[string]string{
+			"text/csv;q=0.1, application/x-ndjson":                   "application/x-ndjson",
+			"application/vnd.apache.parquet;q=0.5, text/csv;q=0.8":   "text/csv",
+			"text/csv;q=0, */*":                                      "application/x-ndjson",
+			"application/xml, application/vnd.apache.parquet;q=0.01": "application/vnd.apache.parquet",
+		}
+		for accept, contentType := range tests {
+			w := export(router, accept, nil, sensorIDs...)
+			assert.Equal(t, http.StatusOK, w.Code, accept)
+			assert.Equal(t, contentType, w.Header().Get("Content-Type"), accept)
+		}
+	})
+
+	t.Run("GET_history_export_406", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotAcceptable, export(router, "application/xml", nil, sensorIDs...).Code)
+		assert.Equal(t, http.StatusNotAcceptable, export(router, "text/csv;q=0", nil, sensorIDs...).Code,
+			"Выгрузка в формате, от которого клиент отказался")
+	})
+
+	t.Run("GET_history_export_invalid_params_400", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, export(router, "", map[string]string{"format": "xlsx"}, sensorIDs...).Code)
+		assert.Equal(t, http.StatusBadRequest, export(router, "", nil).Code)
+		assert.Equal(t, http.StatusUnprocessableEntity, export(router, "", nil, "abc").Code)
+	})
+
+	t.Run("GET_history_export_foreign_404", func(t *testing.T) {
+		user, err := useCases.User.RegisterUser(context.Background(), &domain.User{Name: "Чужой выгрузки"}, "stranger password")
+		assert.NoError(t, err)
+		tokens, err := useCases.Auth.IssueTokens(user.ID)
+		assert.NoError(t, err)
+		stranger := &authorizedRouter{handler: engine, token: tokens.AccessToken}
+
+		w := export(stranger, "", nil, sensorIDs...)
+		assert.Equal(t, http.StatusNotFound, w.Code, "Получили в ответ не тот код")
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	})
+}
+
+func TestRetentionRoutes(t *testing.T) {
+	admin := &domain.User{Name: "Администратор хранения", IsAdmin: true}
+	assert.NoError(t, ur.SaveUser(context.Background(), admin))
+	tokens, err := useCases.Auth.IssueTokens(admin.ID)
+	assert.NoError(t, err)
+	adminRouter := &authorizedRouter{handler: engine, token: tokens.AccessToken}
+
+	sensor, err := useCases.Sensor.RegisterSensor(usecase.WithCaller(context.Background(), testUserID), &domain.Sensor{
+		SerialNumber: "5660000001",
+		Type:         domain.SensorTypeADC,
+		IsActive:     true,
+	})
+	assert.NoError(t, err)
+	sensorURL := "/retention/sensors/" + strconv.FormatInt(sensor.ID, 10)
+
+	now := time.Now().UTC()
+	for _, age := range []time.Duration{41 * 24 * time.Hour, 40 * 24 * time.Hour, 24 * time.Hour} {
+		w := httptest.NewRecorder()
+		body := `{"sensor_serial_number": "5660000001", "payload": 1, "timestamp": "` + now.Add(-age).Format(time.RFC3339) + `"}`
+		req, _ := http.NewRequest(http.MethodPost, "/events", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("X-Sensor-Key", sensor.APIKey)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code, "Получили в ответ не тот код")
+	}
+
+	send := func(r http.Handler, method, url, body string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, url, bytes.NewReader([]byte(body)))
+		if body != "" {
+			req.Header.Add("Content-Type", "application/json")
+		}
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	getPreview := func() []models.RetentionPreviewItem {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/retention/preview", nil)
+		req.Header.Add("Accept", "application/json")
+		adminRouter.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var preview []models.RetentionPreviewItem
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &preview))
+		return preview
+	}
+	findItem := func(preview []models.RetentionPreviewItem) *models.RetentionPreviewItem {
+		for _, item := range preview {
+			if *item.SensorID == sensor.ID {
+				return &item
+			}
+		}
+		return nil
+	}
+
+	t.Run("not_admin_403", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, send(router, http.MethodPut, sensorURL, `{"retention_days": 30}`))
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/retention/preview", nil)
+		req.Header.Add("Accept", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("PUT_retention_422", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnprocessableEntity, send(adminRouter, http.MethodPut, sensorURL, `{"retention_days": -1}`))
+		assert.Equal(t, http.StatusUnprocessableEntity, send(adminRouter, http.MethodPut, sensorURL, `{}`))
+		assert.Equal(t, http.StatusUnprocessableEntity, send(adminRouter, http.MethodPut, "/retention/sensors/abc", `{"retention_days": 1}`))
+	})
+
+	t.Run("PUT_retention_404", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, send(adminRouter, http.MethodPut, "/retention/sensors/999999999", `{"retention_days": 1}`))
+		assert.Equal(t, http.StatusNotFound, send(adminRouter, http.MethodPut, "/retention/sensor-types/unknown", `{"retention_days": 1}`))
+	})
+
+	t.Run("GET_retention_200", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, send(adminRouter, http.MethodPut, "/retention/sensor-types/humidity", `{"retention_days": 365}`))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/retention", nil)
+		req.Header.Add("Accept", "application/json")
+		adminRouter.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var policies []models.RetentionPolicy
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &policies))
+		if assert.NotEmpty(t, policies) {
+			assert.Equal(t, models.RetentionPolicy{RetentionDays: swag.Int64(0)}, policies[0], "Первой идёт общая политика")
+		}
+		assert.Contains(t, policies, models.RetentionPolicy{SensorType: "humidity", RetentionDays: swag.Int64(365)})
+
+		assert.Equal(t, http.StatusNoContent, send(adminRouter, http.MethodDelete, "/retention/sensor-types/humidity", ""))
+		assert.Equal(t, http.StatusNotFound, send(adminRouter, http.MethodDelete, "/retention/sensor-types/humidity", ""))
+	})
+
+	t.Run("GET_retention_preview_200", func(t *testing.T) {
+		assert.Nil(t, findItem(getPreview()), "Без политики события датчика хранятся бессрочно")
+
+		assert.Equal(t, http.StatusNoContent, send(adminRouter, http.MethodPut, sensorURL, `{"retention_days": 30}`))
+		item := findItem(getPreview())
+		if assert.NotNil(t, item) {
+			assert.NoError(t, item.Validate(nil))
+			assert.Equal(t, int64(2), *item.Events)
+			assert.Equal(t, int64(30), *item.RetentionDays)
+			assert.Equal(t, "5660000001", *item.SerialNumber)
+		}
+	})
+
+	t.Run("prune_expired_events", func(t *testing.T) {
+		pruned, err := useCases.Retention.PruneExpiredEvents(context.Background())
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, pruned, int64(2))
+		assert.Nil(t, findItem(getPreview()))
+
+		events, err := er.GetSensorHistory(context.Background(), sensor.ID, domain.HistoryQuery{Start: now.Add(-100 * 24 * time.Hour), End: now})
+		assert.NoError(t, err)
+		assert.Len(t, events, 1)
+
+		assert.Equal(t, http.StatusNoContent, send(adminRouter, http.MethodDelete, sensorURL, ""))
+	})
+}
+
+func TestImportRoutes(t *testing.T) {
+	base := time.Now().UTC().Truncate(time.Second).Add(-365 * 24 * time.Hour)
+	sensorsCSV := "serial_number,type,description\n" +
+		"5680000001,adc,Импортированный датчик\n" +
+		"56800,adc,\n"
+	eventsNDJSON := ""
+	for i := 0; i < 3; i++ {
+		eventsNDJSON += `{"sensor_serial_number": "5680000001", "payload": ` + strconv.Itoa(i) +
+			`, "timestamp": "` + base.Add(time.Duration(i)*time.Hour).Format(time.RFC3339) + `", "event_id": "import-` + strconv.Itoa(i) + `"}` + "\n"
+	}
+	eventsNDJSON += `{"sensor_serial_number": "5680000001", "payload": 1}` + "\n"
+
+	postImport := func(query string, files map[string]string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		form := multipart.NewWriter(body)
+		for _, name := range []string{"sensors.csv", "events.ndjson"} {
+			content, ok := files[name]
+			if !ok {
+				continue
+			}
+			part, err := form.CreateFormFile(strings.TrimSuffix(name, filepath.Ext(name)), name)
+			assert.NoError(t, err)
+			_, _ = part.Write([]byte(content))
+		}
+		assert.NoError(t, form.Close())
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/import"+query, body)
+		req.Header.Add("Content-Type", form.FormDataContentType())
+		router.ServeHTTP(w, req)
+		return w
+	}
+	decodeReport := func(w *httptest.ResponseRecorder) models.ImportReport {
+		var report models.ImportReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.NoError(t, report.Validate(nil))
+		return report
+	}
+	files := map[string]string{"sensors.csv": sensorsCSV, "events.ndjson": eventsNDJSON}
+
+	t.Run("POST_import_dry_run_200", func(t *testing.T) {
+		w := postImport("?dry_run=true", files)
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		report := decodeReport(w)
+		assert.True(t, *report.DryRun)
+		assert.Equal(t, int64(1), *report.SensorsCreated)
+		assert.Equal(t, int64(1), *report.SensorsRejected)
+		assert.Equal(t, int64(3), *report.EventsImported)
+		assert.Equal(t, int64(1), *report.EventsRejected)
+		if assert.Len(t, report.Errors, 2) {
+			assert.Equal(t, "sensors", *report.Errors[0].File)
+			assert.Equal(t, int64(3), *report.Errors[0].Line)
+			assert.Equal(t, ErrValidation, *report.Errors[0].Reason)
+			assert.Equal(t, "events", *report.Errors[1].File)
+			assert.Equal(t, int64(4), *report.Errors[1].Line)
+			assert.Equal(t, ErrEventTimestampRequired, *report.Errors[1].Reason)
+		}
+
+		_, err := useCases.Sensor.GetSensorBySerialNumber(context.Background(), "5680000001")
+		assert.ErrorIs(t, err, usecase.ErrSensorNotFound, "Проверочный импорт сохранил датчик")
+	})
+
+	t.Run("POST_import_200", func(t *testing.T) {
+		w := postImport("", files)
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		report := decodeReport(w)
+		assert.False(t, *report.DryRun)
+		assert.Equal(t, int64(1), *report.SensorsCreated)
+		assert.Equal(t, int64(3), *report.EventsImported)
+
+		sensor, err := useCases.Sensor.GetSensorBySerialNumber(context.Background(), "5680000001")
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, int64(2), sensor.CurrentState)
+		history, err := useCases.Event.GetSensorHistory(context.Background(), sensor.ID, domain.HistoryQuery{
+			Start: base.Add(-time.Hour),
+			End:   time.Now(),
+		})
+		assert.NoError(t, err)
+		if assert.Len(t, history.Events, 3) {
+			assert.True(t, base.Equal(history.Events[0].Timestamp), "Исходное время события не сохранено")
+		}
+	})
+
+	t.Run("POST_import_repeated_200", func(t *testing.T) {
+		w := postImport("", files)
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		report := decodeReport(w)
+		assert.Equal(t, int64(0), *report.SensorsCreated)
+		assert.Equal(t, int64(1), *report.SensorsExisting)
+		assert.Equal(t, int64(0), *report.EventsImported)
+		assert.Equal(t, int64(3), *report.EventsDuplicate)
+	})
+
+	t.Run("POST_import_csv_events_200", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		form := multipart.NewWriter(body)
+		part, _ := form.CreateFormFile("events", "history.csv")
+		_, _ = part.Write([]byte("sensor_serial_number,timestamp,payload,value\n" +
+			"5680000001," + base.Add(time.Minute).Format(time.RFC3339) + ",5,\n"))
+		assert.NoError(t, form.Close())
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/import", body)
+		req.Header.Add("Content-Type", form.FormDataContentType())
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		assert.Equal(t, int64(1), *decodeReport(w).EventsImported)
+	})
+
+	t.Run("POST_import_missing_column_422", func(t *testing.T) {
+		w := postImport("", map[string]string{"sensors.csv": "type\nadc\n"})
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("POST_import_no_files_422", func(t *testing.T) {
+		w := postImport("", nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("POST_import_not_multipart_415", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/import", strings.NewReader(sensorsCSV))
+		req.Header.Add("Content-Type", "text/csv")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("POST_import_unauthorized_401", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/import", nil)
+		req.Header.Add("Authorization", "Bearer invalid")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "Получили в ответ не тот код")
+	})
+}
+
+func TestHomesRoutes(t *testing.T) {
+	user, err := useCases.User.RegisterUser(context.Background(), &domain.User{Name: "Сосед"}, "neighbour password")
+	assert.NoError(t, err)
+	tokens, err := useCases.Auth.IssueTokens(user.ID)
+	assert.NoError(t, err)
+	neighbour := &authorizedRouter{handler: engine, token: tokens.AccessToken}
+
+	var home models.Home
+	t.Run("POST_homes_201", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/homes", bytes.NewReader([]byte(`{"name": "Квартира"}`)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code, "Получили в ответ не тот код")
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &home))
+		assert.NoError(t, home.Validate(nil))
+	})
+	homeURL := "/homes/" + strconv.FormatInt(*home.ID, 10)
+	neighbourURL := homeURL + "/members/" + strconv.FormatInt(user.ID, 10)
+
+	t.Run("POST_homes_empty_name_422", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/homes", bytes.NewReader([]byte(`{"name": ""}`)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("GET_homes_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/homes", nil)
+		req.Header.Add("Accept", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var homes []models.Home
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &homes))
+		assert.Contains(t, homes, home)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, "/homes", nil)
+		req.Header.Add("Accept", "application/json")
+		neighbour.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		assert.JSONEq(t, "[]", w.Body.String(), "Видны чужие дома")
+	})
+
+	t.Run("GET_homes_home_id_foreign_404", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, homeURL, nil)
+		req.Header.Add("Accept", "application/json")
+		neighbour.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, "Получили в ответ не тот код")
+	})
+
+	var sensor domain.RegisteredSensor
+	t.Run("POST_sensors_in_home_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := `{"serial_number": "5550000001", "type": "cc", "description": "Датчик двери", "is_active": true, "home_id": ` + strconv.FormatInt(*home.ID, 10) + `}`
+		req, _ := http.NewRequest(http.MethodPost, "/sensors", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensor))
+		assert.Equal(t, *home.ID, sensor.HomeID)
+	})
+	sensorURL := "/sensors/" + strconv.FormatInt(sensor.ID, 10)
+
+	t.Run("POST_sensors_in_foreign_home_404", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := `{"serial_number": "5550000002", "type": "cc", "description": "Датчик окна", "is_active": true, "home_id": ` + strconv.FormatInt(*home.ID, 10) + `}`
+		req, _ := http.NewRequest(http.MethodPost, "/sensors", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		neighbour.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("PUT_homes_home_id_members_user_id_204", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, neighbourURL, bytes.NewReader([]byte(`{"role": "viewer"}`)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code, "Получили в ответ не тот код")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, "/sensors", nil)
+		req.Header.Add("Accept", "application/json")
+		neighbour.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var sensors []domain.Sensor
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensors))
+		assert.Len(t, sensors, 1, "Участнику дома должны быть видны только датчики дома")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodPost, sensorURL+"/key", nil)
+		neighbour.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, "Наблюдатель не может настраивать датчик")
+	})
+
+	t.Run("PUT_homes_home_id_members_last_owner_409", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		url := homeURL + "/members/" + strconv.FormatInt(testUserID, 10)
+		req, _ := http.NewRequest(http.MethodPut, url, bytes.NewReader([]byte(`{"role": "member"}`)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("GET_homes_home_id_members_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, homeURL+"/members", nil)
+		req.Header.Add("Accept", "application/json")
+		neighbour.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var members []models.HomeMember
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &members))
+		roles := make(map[int64]string)
+		for _, m := range members {
+			roles[*m.UserID] = *m.Role
+		}
+		assert.Equal(t, map[int64]string{testUserID: "owner", user.ID: "viewer"}, roles)
+	})
+
+	t.Run("GET_homes_home_id_sensors_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, homeURL+"/sensors", nil)
+		req.Header.Add("Accept", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var sensors []domain.Sensor
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensors))
+		assert.Len(t, sensors, 1)
+	})
+
+	t.Run("DELETE_homes_home_id_members_user_id_204", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, neighbourURL, nil)
+		neighbour.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code, "Получили в ответ не тот код")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, sensorURL, nil)
+		req.Header.Add("Accept", "application/json")
+		neighbour.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, "Доступ к датчикам дома не отозван")
+	})
+
+	t.Run("OPTIONS_homes_204", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodOptions, "/homes", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code, "Получили в ответ не тот код")
+		allowed := strings.Split(w.Header().Get("Allow"), ",")
+		assert.Contains(t, allowed, http.MethodGet, "В разрешённых методах нет GET")
+		assert.Contains(t, allowed, http.MethodPost, "В разрешённых методах нет POST")
+	})
+}
+
+func TestRoomsRoutes(t *testing.T) {
+	home, err := useCases.Home.CreateHome(usecase.WithCaller(context.Background(), testUserID), &domain.Home{Name: "Дача"})
+	assert.NoError(t, err)
+	sensor, err := useCases.Sensor.RegisterSensor(usecase.WithCaller(context.Background(), testUserID), &domain.Sensor{
+		SerialNumber: "5550000011",
+		Type:         domain.SensorTypeContactClosure,
+		IsActive:     true,
+		HomeID:       home.ID,
+	})
+	assert.NoError(t, err)
+	sensorURL := "/sensors/" + strconv.FormatInt(sensor.ID, 10)
+
+	var room models.Room
+	t.Run("POST_homes_home_id_rooms_201", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		url := "/homes/" + strconv.FormatInt(home.ID, 10) + "/rooms"
+		req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader([]byte(`{"name": "Кухня"}`)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code, "Получили в ответ не тот код")
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &room))
+		assert.NoError(t, room.Validate(nil))
+		assert.Equal(t, home.ID, *room.HomeID)
+	})
+	roomURL := "/rooms/" + strconv.FormatInt(*room.ID, 10)
+
+	t.Run("GET_homes_home_id_rooms_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/homes/"+strconv.FormatInt(home.ID, 10)+"/rooms", nil)
+		req.Header.Add("Accept", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var rooms []models.Room
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rooms))
+		assert.Equal(t, []models.Room{room}, rooms)
+	})
+
+	t.Run("PUT_sensors_sensor_id_room_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := `{"room_id": ` + strconv.FormatInt(*room.ID, 10) + `}`
+		req, _ := http.NewRequest(http.MethodPut, sensorURL+"/room", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var got domain.Sensor
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Equal(t, *room.ID, got.RoomID)
+	})
+
+	t.Run("PUT_sensors_sensor_id_room_other_home_409", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := `{"room_id": ` + strconv.FormatInt(*room.ID, 10) + `}`
+		req, _ := http.NewRequest(http.MethodPut, "/sensors/1/room", bytes.NewReader([]byte(body)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("GET_rooms_room_id_sensors_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, roomURL+"/sensors", nil)
+		req.Header.Add("Accept", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var sensors []domain.Sensor
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &sensors))
+		assert.Len(t, sensors, 1)
+		assert.Equal(t, sensor.ID, sensors[0].ID)
+	})
+
+	t.Run("GET_rooms_room_id_summary_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, roomURL+"/summary", nil)
+		req.Header.Add("Accept", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var summary models.RoomSummary
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &summary))
+		assert.NoError(t, summary.Validate(nil))
+		assert.Len(t, summary.Sensors, 1)
+		assert.Equal(t, "5550000011", *summary.Sensors[0].SerialNumber)
+	})
+
+	t.Run("GET_rooms_room_id_foreign_404", func(t *testing.T) {
+		user, err := useCases.User.RegisterUser(context.Background(), &domain.User{Name: "Чужой"}, "stranger password")
+		assert.NoError(t, err)
+		tokens, err := useCases.Auth.IssueTokens(user.ID)
+		assert.NoError(t, err)
+		stranger := &authorizedRouter{handler: engine, token: tokens.AccessToken}
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, roomURL, nil)
+		req.Header.Add("Accept", "application/json")
+		stranger.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("PUT_rooms_room_id_200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, roomURL, bytes.NewReader([]byte(`{"name": "Кухня-столовая"}`)))
+		req.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var renamed models.Room
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &renamed))
+		assert.Equal(t, "Кухня-столовая", *renamed.Name)
+	})
+
+	t.Run("DELETE_rooms_room_id_204", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, roomURL, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNoContent, w.Code, "Получили в ответ не тот код")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, sensorURL, nil)
+		req.Header.Add("Accept", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, "Получили в ответ не тот код")
+		var got domain.Sensor
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+		assert.Zero(t, got.RoomID, "Датчик остался в удалённой комнате")
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, roomURL, nil)
+		req.Header.Add("Accept", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, "Получили в ответ не тот код")
+	})
+
+	t.Run("OPTIONS_rooms_room_id_204", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodOptions, roomURL, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code, "Получили в ответ не тот код")
+		allowed := strings.Split(w.Header().Get("Allow"), ",")
+		assert.Contains(t, allowed, http.MethodPut, "В разрешённых методах нет PUT")
+		assert.Contains(t, allowed, http.MethodDelete, "В разрешённых методах нет DELETE")
+	})
+}
+
 func TestSensorsHistory(t *testing.T) {
 	startDate := "2006-01-02T15:04:05.999Z"
 	endDate := "2010-01-02T15:07:05.999Z"
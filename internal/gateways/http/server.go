@@ -20,9 +20,15 @@ type Server struct {
 }
 
 type UseCases struct {
-	Event  *usecase.Event
-	Sensor *usecase.Sensor
-	User   *usecase.User
+	Auth       *usecase.Auth
+	Event      *usecase.Event
+	Sensor     *usecase.Sensor
+	SensorType *usecase.SensorType
+	User       *usecase.User
+	Home       *usecase.Home
+	Room       *usecase.Room
+	Retention  *usecase.Retention
+	Import     *usecase.Import
 }
 
 func NewServer(useCases UseCases, options ...func(*Server)) *Server {
@@ -50,6 +56,13 @@ func WithPort(port uint16) func(*Server) {
 	}
 }
 
+// WithSlowConsumerPolicy - политика медленного клиента для потоков событий, которые не выбрали свою
+func WithSlowConsumerPolicy(policy SlowConsumerPolicy) func(*Server) {
+	return func(s *Server) {
+		s.ws.policy = policy
+	}
+}
+
 func (s *Server) Run(ctx context.Context) error {
 	eg, appCtx := errgroup.WithContext(ctx)
 	sigQuit := make(chan os.Signal, 1)
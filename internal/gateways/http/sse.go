@@ -0,0 +1,118 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"homework/internal/domain"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	sseContentType = "text/event-stream"
+	// sseKeepAlive - период комментариев в потоке без событий, чтобы прокси не закрывали простаивающее соединение
+	sseKeepAlive = 15 * time.Second
+)
+
+// HandleSSE - отдаёт события датчиков потоком Server-Sent Events. Поле id сообщения - последовательность события,
+// поэтому клиент, переподключившись с Last-Event-ID, сначала получает пропущенные события, а затем новые.
+// С from поток начинается с сохранённых событий по параметрам дозагрузки, без него - с новых событий.
+// События, которые клиент не успевает читать, обрабатываются по политике policy, а их число приходит
+// сообщением lag. Доступ ко всем датчикам проверяется до начала ответа, ошибка возвращается, только если
+// ответ ещё не начат
+func (h *WebSocketHandler) HandleSSE(c *gin.Context, sensorIDs []int64, from *domain.ReplayQuery, policy SlowConsumerPolicy) error {
+	ctx := c.Request.Context()
+	for _, sensorID := range sensorIDs {
+		if _, err := h.useCases.Sensor.GetSensorByID(ctx, sensorID); err != nil {
+			return err
+		}
+	}
+	// подписка оформляется до дозагрузки, чтобы не потерять события, сохранённые во время неё
+	eventChan := h.eb.SubscribeWithPolicy(c.Request, policy, sensorIDs...)
+	defer h.eb.Unsubscribe(c.Request)
+	defer h.logDropped(c.Request)
+
+	c.Header("Content-Type", sseContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	feed := newEventFeed(h.useCases.Event, func(event *domain.Event) error {
+		return writeSSEEvent(c.Writer, event)
+	})
+	if err := feed.subscribe(ctx, sensorIDs, from); errorHandler("Error replaying events", err) {
+		return nil
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(h.keepAlive)
+	defer keepAlive.Stop()
+	lag := time.NewTicker(h.lagReport)
+	defer lag.Stop()
+	var reported int64
+	for {
+		var err error
+		select {
+		case event, ok := <-eventChan:
+			if !ok {
+				if h.eb.Disconnected(c.Request) {
+					_ = writeSSEMessage(c.Writer, "close", map[string]any{"reason": slowConsumerReason, "dropped": h.eb.Dropped(c.Request)})
+				}
+				return nil
+			}
+			err = feed.live(ctx, event, h.eb.Missed(c.Request))
+		case <-lag.C:
+			if dropped := h.eb.Dropped(c.Request); dropped > reported {
+				reported = dropped
+				err = writeSSEMessage(c.Writer, "lag", map[string]int64{"dropped": dropped})
+			}
+		case <-keepAlive.C:
+			_, err = io.WriteString(c.Writer, ": keep-alive\n\n")
+		case <-ctx.Done():
+			return nil
+		case <-h.close:
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Error streaming events: %v", err)
+			}
+			return nil
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeSSEEvent - пишет событие сообщением text/event-stream: последовательность события в поле id,
+// JSON события в поле data
+func writeSSEEvent(w io.Writer, event *domain.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Error marshaling event: %v", err)
+		return nil
+	}
+	if event.Sequence != 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", event.Sequence); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+	return err
+}
+
+// writeSSEMessage - пишет служебное сообщение text/event-stream с видом в поле event и JSON в поле data
+func writeSSEMessage(w io.Writer, event string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling %s message: %v", event, err)
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
@@ -0,0 +1,214 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/models"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/swag"
+)
+
+// Виды команд и сообщений потока событий /stream
+const (
+	streamSubscribe   = "subscribe"
+	streamUnsubscribe = "unsubscribe"
+	streamAck         = "ack"
+	streamError       = "error"
+	streamEvent       = "event"
+	streamLag         = "lag"
+)
+
+// HandleStream - открывает поток событий, в котором клиент сам подписывается на датчики, комнаты
+// или все доступные ему датчики и отписывается от них. Каждая команда подтверждается сообщением ack
+// со списком подписанных датчиков или отклоняется сообщением error, подписка при этом не меняется.
+// Подписка с from_sequence или from_timestamp после ack присылает сохранённые события, а затем новые,
+// без пропусков и повторов. События, которые клиент не успевает читать, обрабатываются по политике policy,
+// а их число приходит сообщением lag. Обработчик возвращается, когда соединение закрыто клиентом
+// или сервер завершает работу
+func (h *WebSocketHandler) HandleStream(c *gin.Context, policy SlowConsumerPolicy) error {
+	conn, err := websocket.Accept(c.Writer, c.Request, nil)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	eventChan := h.eb.SubscribeWithPolicy(conn, policy)
+	defer h.eb.Unsubscribe(conn)
+	defer h.logDropped(conn)
+	feed := newEventFeed(h.useCases.Event, func(event *domain.Event) error {
+		return writeStreamFrame(ctx, conn, models.StreamFrame{Type: swag.String(streamEvent), Event: event})
+	})
+
+	commands := make(chan []byte)
+	go func() {
+		defer cancel()
+		for {
+			_, msg, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			select {
+			case commands <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	lag := time.NewTicker(h.lagReport)
+	defer lag.Stop()
+	var reported int64
+	for {
+		select {
+		case msg := <-commands:
+			err = h.streamCommand(ctx, conn, feed, msg)
+		case event, ok := <-eventChan:
+			if !ok {
+				return h.closeUnsubscribed(conn)
+			}
+			err = feed.live(ctx, event, h.eb.Missed(conn))
+		case <-lag.C:
+			err = h.streamLag(ctx, conn, &reported)
+		case <-ctx.Done():
+			return conn.CloseNow()
+		case <-h.close:
+			return conn.Close(websocket.StatusNormalClosure, "server shutting down")
+		}
+		if errorHandler("Error streaming events", err) {
+			if ctx.Err() != nil {
+				return conn.CloseNow()
+			}
+			return conn.Close(websocket.StatusInternalError, "stream failed")
+		}
+	}
+}
+
+// streamCommand - выполняет команду клиента и отвечает подтверждением или ошибкой. Сохранённые события
+// подписки с from_sequence или from_timestamp отправляются после подтверждения
+func (h *WebSocketHandler) streamCommand(ctx context.Context, conn *websocket.Conn, feed *eventFeed, msg []byte) error {
+	var command models.StreamCommand
+	if err := json.Unmarshal(msg, &command); err != nil {
+		return writeStreamFrame(ctx, conn, streamErrorFrame("", ErrInvalidJSONFormat))
+	}
+	if err := command.Validate(nil); err != nil {
+		return writeStreamFrame(ctx, conn, streamErrorFrame(command.ID, ErrValidation))
+	}
+	from := streamReplayQuery(command)
+	if len(command.SensorIds) == 0 && len(command.RoomIds) == 0 && !command.All ||
+		*command.Type == streamUnsubscribe && from != nil {
+		return writeStreamFrame(ctx, conn, streamErrorFrame(command.ID, ErrValidation))
+	}
+
+	if *command.Type == streamUnsubscribe && command.All {
+		sensorIDs := h.eb.Sensors(conn)
+		h.eb.UnsubscribeSensors(conn, sensorIDs...)
+		feed.unsubscribe(sensorIDs)
+		return writeStreamFrame(ctx, conn, streamAckFrame(command.ID, h.eb.Sensors(conn)))
+	}
+	sensorIDs, err := h.streamSensors(ctx, command)
+	if err != nil {
+		return writeStreamFrame(ctx, conn, streamErrorFrame(command.ID, streamErrorReason(err)))
+	}
+	if *command.Type == streamUnsubscribe {
+		h.eb.UnsubscribeSensors(conn, sensorIDs...)
+		feed.unsubscribe(sensorIDs)
+		return writeStreamFrame(ctx, conn, streamAckFrame(command.ID, h.eb.Sensors(conn)))
+	}
+	h.eb.Subscribe(conn, sensorIDs...)
+	if err := writeStreamFrame(ctx, conn, streamAckFrame(command.ID, h.eb.Sensors(conn))); err != nil {
+		return err
+	}
+	return feed.subscribe(ctx, sensorIDs, from)
+}
+
+// streamLag - сообщает клиенту, сколько событий ему не доставлено, если с прошлого сообщения их стало больше
+func (h *WebSocketHandler) streamLag(ctx context.Context, conn *websocket.Conn, reported *int64) error {
+	dropped := h.eb.Dropped(conn)
+	if dropped <= *reported {
+		return nil
+	}
+	*reported = dropped
+	return writeStreamFrame(ctx, conn, models.StreamFrame{Type: swag.String(streamLag), Dropped: dropped})
+}
+
+// streamReplayQuery - параметры дозагрузки подписки, nil - подписка только на новые события
+func streamReplayQuery(command models.StreamCommand) *domain.ReplayQuery {
+	if command.FromSequence == nil && swag.IsZero(command.FromTimestamp) {
+		return nil
+	}
+	query := &domain.ReplayQuery{Since: time.Time(command.FromTimestamp)}
+	if command.FromSequence != nil {
+		query.AfterSequence = *command.FromSequence
+	}
+	return query
+}
+
+// writeStreamFrame - отправляет сообщение потока. Сообщение, которое не удалось сериализовать, пропускается
+func writeStreamFrame(ctx context.Context, conn *websocket.Conn, frame models.StreamFrame) error {
+	msg, err := json.Marshal(frame)
+	if errorHandler("Error marshaling stream frame", err) {
+		return nil
+	}
+	return conn.Write(ctx, websocket.MessageText, msg)
+}
+
+// streamSensors - датчики, на которые действует команда. При подписке проверяется доступ к каждому датчику,
+// отписаться от датчика можно и без доступа к нему
+func (h *WebSocketHandler) streamSensors(ctx context.Context, command models.StreamCommand) ([]int64, error) {
+	var sensors []domain.Sensor
+	if command.All {
+		all, err := h.useCases.Sensor.GetSensors(ctx)
+		if err != nil {
+			return nil, err
+		}
+		sensors = append(sensors, all...)
+	}
+	for _, roomID := range command.RoomIds {
+		room, err := h.useCases.Room.GetRoomSensors(ctx, roomID)
+		if err != nil {
+			return nil, err
+		}
+		sensors = append(sensors, room...)
+	}
+	sensorIDs := make([]int64, 0, len(sensors)+len(command.SensorIds))
+	for _, sensor := range sensors {
+		sensorIDs = append(sensorIDs, sensor.ID)
+	}
+	for _, sensorID := range command.SensorIds {
+		if *command.Type == streamSubscribe {
+			if _, err := h.useCases.Sensor.GetSensorByID(ctx, sensorID); err != nil {
+				return nil, err
+			}
+		}
+		sensorIDs = append(sensorIDs, sensorID)
+	}
+	return sensorIDs, nil
+}
+
+func streamAckFrame(id string, sensorIDs []int64) models.StreamFrame {
+	return models.StreamFrame{Type: swag.String(streamAck), ID: id, SensorIds: sensorIDs}
+}
+
+func streamErrorFrame(id string, reason string) models.StreamFrame {
+	return models.StreamFrame{Type: swag.String(streamError), ID: id, Reason: reason}
+}
+
+// streamErrorReason - причина ошибки команды в том же виде, что и ответ на HTTP-запрос к датчику или комнате
+func streamErrorReason(err error) string {
+	switch {
+	case errors.Is(err, usecase.ErrSensorNotFound):
+		return ErrSensorNotFound
+	case errors.Is(err, usecase.ErrRoomNotFound):
+		return ErrRoomNotFound
+	case errors.Is(err, usecase.ErrSensorAccessDenied):
+		return ErrSensorAccessDenied
+	default:
+		return ErrStreamCommandFailed
+	}
+}
@@ -1,7 +1,11 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"homework/internal/domain"
+	"homework/internal/usecase"
 	"log"
 	"time"
 
@@ -9,65 +13,114 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// lagReportInterval - как часто клиенту потока сообщается, что ему не доставлены новые события
+const lagReportInterval = time.Second
+
 type WebSocketHandler struct {
 	useCases UseCases
 	eb       *EventBroker
 	close    chan struct{}
+	// keepAlive - период комментариев, которые пишутся в поток SSE
+	keepAlive time.Duration
+	// lagReport - период проверки, не стало ли недоставленных клиенту событий больше
+	lagReport time.Duration
+	// policy - политика медленного клиента для соединений, которые не выбрали свою
+	policy SlowConsumerPolicy
 }
 
 func NewWebSocketHandler(useCases UseCases) *WebSocketHandler {
 	return &WebSocketHandler{
-		useCases: useCases,
-		close:    make(chan struct{}),
-		eb:       NewEventBroker(),
+		useCases:  useCases,
+		close:     make(chan struct{}),
+		eb:        NewEventBroker(),
+		keepAlive: sseKeepAlive,
+		lagReport: lagReportInterval,
+		policy:    SlowConsumerDropNewest,
 	}
 }
 
-func (h *WebSocketHandler) Handle(c *gin.Context, id int64) error {
+// Handle - отправляет клиенту последнее событие датчика, а затем новые события по мере их сохранения.
+// События, которые клиент не успевает читать, обрабатываются по политике policy. Обработчик возвращается
+// и отписывает соединение от брокера, когда соединение закрыто клиентом или сервер завершает работу
+func (h *WebSocketHandler) Handle(c *gin.Context, id int64, policy SlowConsumerPolicy) error {
 	conn, err := websocket.Accept(c.Writer, c.Request, nil)
 	if err != nil {
 		return err
 	}
-	ctx := conn.CloseRead(c)
-	eventChan := h.eb.Subscribe(conn, id)
+	ctx := conn.CloseRead(c.Request.Context())
+	eventChan := h.eb.SubscribeWithPolicy(conn, policy, id)
+	defer h.eb.Unsubscribe(conn)
+	defer h.logDropped(conn)
+	feed := newEventFeed(h.useCases.Event, func(event *domain.Event) error {
+		return writeEvent(ctx, conn, event)
+	})
 
-	go func() {
-		defer func() {
-			h.eb.Unsubscribe(conn)
-			err := conn.Close(websocket.StatusNormalClosure, "Closed")
-			if err != nil {
-				return
-			}
-		}()
-		timer := time.After(200 * time.Millisecond)
-		for {
-			select {
-			case <-timer:
-				event, err := h.useCases.Event.GetLastEventBySensorID(c, id)
-				if errorHandler("Error getting last event by sensor id", err) {
-					continue
-				}
-				h.eb.Publish(id, event)
-			case event := <-eventChan:
-				msg, err := json.Marshal(event)
-				if errorHandler("Error marshaling event", err) {
-					continue
-				}
-				err = conn.Write(ctx, websocket.MessageText, msg)
-				if errorHandler("Error writing message", err) {
-					continue
-				}
-			case <-c.Done():
-				return
-			case <-h.close:
-				return
+	err = h.sendSnapshot(ctx, conn, feed, id)
+	for err == nil {
+		select {
+		case event, ok := <-eventChan:
+			if !ok {
+				return h.closeUnsubscribed(conn)
 			}
+			err = feed.live(ctx, event, h.eb.Missed(conn))
+		case <-ctx.Done():
+			return conn.CloseNow()
+		case <-h.close:
+			return conn.Close(websocket.StatusNormalClosure, "server shutting down")
 		}
-	}()
+	}
+	errorHandler("Error streaming sensor events", err)
+	if ctx.Err() != nil {
+		return conn.CloseNow()
+	}
+	return conn.Close(websocket.StatusInternalError, "stream failed")
+}
 
+// sendSnapshot - отправляет последнее событие датчика, если оно есть. Соединение подписано в брокере
+// до вызова, поэтому события, сохранённые после чтения последнего, придут из рассылки
+func (h *WebSocketHandler) sendSnapshot(ctx context.Context, conn *websocket.Conn, feed *eventFeed, id int64) error {
+	if err := feed.subscribe(ctx, []int64{id}, nil); err != nil {
+		return err
+	}
+	event, err := h.useCases.Event.GetLastEventBySensorID(ctx, id)
+	if errors.Is(err, usecase.ErrEventNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := writeEvent(ctx, conn, event); err != nil {
+		return err
+	}
+	feed.sent(event)
 	return nil
 }
 
+// writeEvent - отправляет событие. Событие, которое не удалось сериализовать, пропускается
+func writeEvent(ctx context.Context, conn *websocket.Conn, event *domain.Event) error {
+	msg, err := json.Marshal(event)
+	if errorHandler("Error marshaling event", err) {
+		return nil
+	}
+	return conn.Write(ctx, websocket.MessageText, msg)
+}
+
+// closeUnsubscribed - закрывает соединение, канал которого закрыл брокер: по политике SlowConsumerDisconnect
+// или при завершении работы сервера
+func (h *WebSocketHandler) closeUnsubscribed(conn *websocket.Conn) error {
+	if h.eb.Disconnected(conn) {
+		return conn.Close(websocket.StatusPolicyViolation, slowConsumerReason)
+	}
+	return conn.Close(websocket.StatusNormalClosure, "server shutting down")
+}
+
+// logDropped - пишет в лог, сколько событий не доставлено закрываемому соединению, если такие есть
+func (h *WebSocketHandler) logDropped(conn any) {
+	if dropped := h.eb.Dropped(conn); dropped > 0 {
+		log.Printf("Stream closed, %d events dropped by slow consumer policy", dropped)
+	}
+}
+
 func (h *WebSocketHandler) Shutdown() error {
 	close(h.close)
 	h.eb.close()
@@ -1,13 +1,18 @@
 package http
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"homework/internal/domain"
 	"homework/internal/usecase"
+	"homework/models"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -30,20 +35,33 @@ func (t *testSuite) SetupSuite() {
 	t.ctrl = gomock.NewController(t.T())
 }
 
+// dialOptions - параметры подключения от имени пользователя с id 1
+func (t *testSuite) dialOptions(uc UseCases) *websocket.DialOptions {
+	tokens, err := uc.Auth.IssueTokens(1)
+	require.NoError(t.T(), err)
+	return &websocket.DialOptions{
+		HTTPHeader: http.Header{"Authorization": []string{"Bearer " + tokens.AccessToken}},
+	}
+}
+
 func (t *testSuite) TestWebSocketConnection() {
 	engine := gin.Default()
 
 	erMock := usecase.NewMockEventRepository(t.ctrl)
-	erMock.EXPECT().GetLastEventBySensorID(gomock.Any(), gomock.Eq(int64(1))).Return(&domain.Event{SensorID: 1, Payload: 100}, nil).Times(1)
+	erMock.EXPECT().GetLastSequence(gomock.Any()).Return(int64(7), nil).Times(1)
+	erMock.EXPECT().GetLastEventBySensorID(gomock.Any(), gomock.Eq(int64(1))).Return(&domain.Event{SensorID: 1, Payload: 100, Sequence: 7}, nil).Times(1)
 	srMock := usecase.NewMockSensorRepository(t.ctrl)
-	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Eq(int64(1))).Return(&domain.Sensor{ID: 1}, nil).Times(1)
+	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Eq(int64(1))).Return(&domain.Sensor{ID: 1}, nil).Times(2)
 	urMock := usecase.NewMockUserRepository(t.ctrl)
+	urMock.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(int64(1))).Return(&domain.User{ID: 1}, nil).Times(1)
 	sorMock := usecase.NewMockSensorOwnerRepository(t.ctrl)
+	sorMock.EXPECT().GetSensorsByUserID(gomock.Any(), gomock.Eq(int64(1))).Return([]domain.SensorOwner{{UserID: 1, SensorID: 1, Role: domain.SensorRoleViewer}}, nil).Times(2)
 
 	uc := UseCases{
-		Event:  usecase.NewEvent(erMock, srMock),
-		Sensor: usecase.NewSensor(srMock),
-		User:   usecase.NewUser(urMock, sorMock, srMock),
+		Auth:   usecase.NewAuth(urMock, []byte("test secret")),
+		Event:  usecase.NewEvent(erMock, srMock, sorMock, nil, nil, nil),
+		Sensor: usecase.NewSensor(srMock, sorMock, nil, nil, nil, nil),
+		User:   usecase.NewUser(urMock, sorMock, srMock, nil, nil),
 	}
 
 	ws := NewWebSocketHandler(uc)
@@ -57,7 +75,7 @@ func (t *testSuite) TestWebSocketConnection() {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
 
-	conn, _, err := websocket.Dial(ctx, srvURL.String()+"/sensors/1/events", nil)
+	conn, _, err := websocket.Dial(ctx, srvURL.String()+"/sensors/1/events", t.dialOptions(uc))
 	require.NoError(t.T(), err)
 	op, msg, err := conn.Read(ctx)
 	require.NoError(t.T(), err)
@@ -67,6 +85,21 @@ func (t *testSuite) TestWebSocketConnection() {
 
 	require.Equal(t.T(), int64(1), event.SensorID)
 	require.Equal(t.T(), int64(100), event.Payload)
+
+	// последнее событие отправляется один раз, дальше события приходят только из рассылки
+	ws.eb.Publish(1, &domain.Event{SensorID: 1, Payload: 100, Sequence: 7})
+	ws.eb.Publish(1, &domain.Event{SensorID: 1, Payload: 101, Sequence: 8})
+	_, msg, err = conn.Read(ctx)
+	require.NoError(t.T(), err)
+	require.NoError(t.T(), json.Unmarshal(msg, &event))
+	require.Equal(t.T(), int64(101), event.Payload)
+
+	require.NoError(t.T(), conn.Close(websocket.StatusNormalClosure, "bye-bye"))
+	assert.Eventually(t.T(), func() bool {
+		ws.eb.mu.RLock()
+		defer ws.eb.mu.RUnlock()
+		return len(ws.eb.ids) == 0 && len(ws.eb.subscriptions) == 0
+	}, time.Second*5, time.Millisecond*10, "Закрытое соединение не отписано от брокера")
 }
 
 func (t *testSuite) TestWebSocketConnectionFail() {
@@ -76,12 +109,14 @@ func (t *testSuite) TestWebSocketConnectionFail() {
 	srMock := usecase.NewMockSensorRepository(t.ctrl)
 	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Eq(int64(1))).Return(nil, usecase.ErrSensorNotFound).Times(1)
 	urMock := usecase.NewMockUserRepository(t.ctrl)
+	urMock.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(int64(1))).Return(&domain.User{ID: 1}, nil).Times(1)
 	sorMock := usecase.NewMockSensorOwnerRepository(t.ctrl)
 
 	uc := UseCases{
-		Event:  usecase.NewEvent(erMock, srMock),
-		Sensor: usecase.NewSensor(srMock),
-		User:   usecase.NewUser(urMock, sorMock, srMock),
+		Auth:   usecase.NewAuth(urMock, []byte("test secret")),
+		Event:  usecase.NewEvent(erMock, srMock, sorMock, nil, nil, nil),
+		Sensor: usecase.NewSensor(srMock, sorMock, nil, nil, nil, nil),
+		User:   usecase.NewUser(urMock, sorMock, srMock, nil, nil),
 	}
 
 	ws := NewWebSocketHandler(uc)
@@ -95,7 +130,7 @@ func (t *testSuite) TestWebSocketConnectionFail() {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
 
-	_, resp, err := websocket.Dial(ctx, srvURL.String()+"/sensors/1/events", nil)
+	_, resp, err := websocket.Dial(ctx, srvURL.String()+"/sensors/1/events", t.dialOptions(uc))
 	require.Error(t.T(), err)
 	assert.Equal(t.T(), http.StatusNotFound, resp.StatusCode)
 }
@@ -103,15 +138,20 @@ func (t *testSuite) TestWebSocketConnectionFail() {
 func (t *testSuite) TestWebSocketShutdown_Server() {
 	engine := gin.Default()
 	erMock := usecase.NewMockEventRepository(t.ctrl)
+	erMock.EXPECT().GetLastSequence(gomock.Any()).Return(int64(0), nil).Times(1)
+	erMock.EXPECT().GetLastEventBySensorID(gomock.Any(), gomock.Eq(int64(2))).Return(nil, usecase.ErrEventNotFound).Times(1)
 	srMock := usecase.NewMockSensorRepository(t.ctrl)
-	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Eq(int64(2))).Return(&domain.Sensor{ID: 2}, nil).Times(1)
+	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Eq(int64(2))).Return(&domain.Sensor{ID: 2}, nil).Times(2)
 	urMock := usecase.NewMockUserRepository(t.ctrl)
+	urMock.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(int64(1))).Return(&domain.User{ID: 1}, nil).Times(1)
 	sorMock := usecase.NewMockSensorOwnerRepository(t.ctrl)
+	sorMock.EXPECT().GetSensorsByUserID(gomock.Any(), gomock.Eq(int64(1))).Return([]domain.SensorOwner{{UserID: 1, SensorID: 2, Role: domain.SensorRoleViewer}}, nil).Times(2)
 
 	uc := UseCases{
-		Event:  usecase.NewEvent(erMock, srMock),
-		Sensor: usecase.NewSensor(srMock),
-		User:   usecase.NewUser(urMock, sorMock, srMock),
+		Auth:   usecase.NewAuth(urMock, []byte("test secret")),
+		Event:  usecase.NewEvent(erMock, srMock, sorMock, nil, nil, nil),
+		Sensor: usecase.NewSensor(srMock, sorMock, nil, nil, nil, nil),
+		User:   usecase.NewUser(urMock, sorMock, srMock, nil, nil),
 	}
 
 	ws := NewWebSocketHandler(uc)
@@ -125,7 +165,7 @@ func (t *testSuite) TestWebSocketShutdown_Server() {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	conn, _, err := websocket.Dial(ctx, srvURL.String()+"/sensors/2/events", nil)
+	conn, _, err := websocket.Dial(ctx, srvURL.String()+"/sensors/2/events", t.dialOptions(uc))
 	require.NoError(t.T(), err)
 	go func() {
 		time.Sleep(time.Millisecond * 100)
@@ -139,15 +179,20 @@ func (t *testSuite) TestWebSocketShutdown_Server() {
 func (t *testSuite) TestWebSocketShutdown_Client() {
 	engine := gin.Default()
 	erMock := usecase.NewMockEventRepository(t.ctrl)
+	erMock.EXPECT().GetLastSequence(gomock.Any()).Return(int64(0), nil).Times(1)
+	erMock.EXPECT().GetLastEventBySensorID(gomock.Any(), gomock.Eq(int64(2))).Return(nil, usecase.ErrEventNotFound).Times(1)
 	srMock := usecase.NewMockSensorRepository(t.ctrl)
-	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Eq(int64(2))).Return(&domain.Sensor{ID: 2}, nil).Times(1)
+	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Eq(int64(2))).Return(&domain.Sensor{ID: 2}, nil).Times(2)
 	urMock := usecase.NewMockUserRepository(t.ctrl)
+	urMock.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(int64(1))).Return(&domain.User{ID: 1}, nil).Times(1)
 	sorMock := usecase.NewMockSensorOwnerRepository(t.ctrl)
+	sorMock.EXPECT().GetSensorsByUserID(gomock.Any(), gomock.Eq(int64(1))).Return([]domain.SensorOwner{{UserID: 1, SensorID: 2, Role: domain.SensorRoleViewer}}, nil).Times(2)
 
 	uc := UseCases{
-		Event:  usecase.NewEvent(erMock, srMock),
-		Sensor: usecase.NewSensor(srMock),
-		User:   usecase.NewUser(urMock, sorMock, srMock),
+		Auth:   usecase.NewAuth(urMock, []byte("test secret")),
+		Event:  usecase.NewEvent(erMock, srMock, sorMock, nil, nil, nil),
+		Sensor: usecase.NewSensor(srMock, sorMock, nil, nil, nil, nil),
+		User:   usecase.NewUser(urMock, sorMock, srMock, nil, nil),
 	}
 
 	ws := NewWebSocketHandler(uc)
@@ -161,7 +206,7 @@ func (t *testSuite) TestWebSocketShutdown_Client() {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
-	conn, _, err := websocket.Dial(ctx, srvURL.String()+"/sensors/2/events", nil)
+	conn, _, err := websocket.Dial(ctx, srvURL.String()+"/sensors/2/events", t.dialOptions(uc))
 	require.NoError(t.T(), err)
 	go func() {
 		time.Sleep(time.Millisecond * 100)
@@ -173,6 +218,397 @@ func (t *testSuite) TestWebSocketShutdown_Client() {
 	assert.NoError(t.T(), ws.Shutdown())
 }
 
+func (t *testSuite) TestStream() {
+	engine := gin.Default()
+	urMock := usecase.NewMockUserRepository(t.ctrl)
+	urMock.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(int64(1))).Return(&domain.User{ID: 1}, nil).AnyTimes()
+	sorMock := usecase.NewMockSensorOwnerRepository(t.ctrl)
+	sorMock.EXPECT().GetSensorsByUserID(gomock.Any(), gomock.Eq(int64(1))).Return([]domain.SensorOwner{
+		{UserID: 1, SensorID: 1, Role: domain.SensorRoleViewer},
+		{UserID: 1, SensorID: 2, Role: domain.SensorRoleViewer},
+	}, nil).AnyTimes()
+	hrMock := usecase.NewMockHomeRepository(t.ctrl)
+	hrMock.EXPECT().GetHomesByUserID(gomock.Any(), gomock.Eq(int64(1))).Return([]domain.HomeMember{
+		{HomeID: 5, UserID: 1, Role: domain.SensorRoleViewer},
+	}, nil).AnyTimes()
+	srMock := usecase.NewMockSensorRepository(t.ctrl)
+	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Eq(int64(1))).Return(&domain.Sensor{ID: 1}, nil).AnyTimes()
+	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Eq(int64(2))).Return(&domain.Sensor{ID: 2}, nil).AnyTimes()
+	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Eq(int64(9))).Return(nil, usecase.ErrSensorNotFound).AnyTimes()
+	srMock.EXPECT().GetSensorsByHomeID(gomock.Any(), gomock.Eq(int64(5))).Return([]domain.Sensor{{ID: 4, HomeID: 5}}, nil).AnyTimes()
+	srMock.EXPECT().GetSensorsByRoomID(gomock.Any(), gomock.Eq(int64(3))).Return([]domain.Sensor{{ID: 4, HomeID: 5}}, nil).AnyTimes()
+	rrMock := usecase.NewMockRoomRepository(t.ctrl)
+	rrMock.EXPECT().GetRoomByID(gomock.Any(), gomock.Eq(int64(3))).Return(&domain.Room{ID: 3, HomeID: 5}, nil).AnyTimes()
+	erMock := usecase.NewMockEventRepository(t.ctrl)
+	erMock.EXPECT().GetLastSequence(gomock.Any()).Return(int64(100), nil).AnyTimes()
+	erMock.EXPECT().ReplaySensorEvents(gomock.Any(), gomock.Eq(int64(1)), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int64, query domain.ReplayQuery) ([]domain.Event, error) {
+			assert.Equal(t.T(), int64(90), query.AfterSequence)
+			return []domain.Event{{Sequence: 95, SensorID: 1, Payload: 95}, {Sequence: 101, SensorID: 1, Payload: 101}}, nil
+		})
+
+	uc := UseCases{
+		Auth:   usecase.NewAuth(urMock, []byte("test secret")),
+		Event:  usecase.NewEvent(erMock, srMock, sorMock, hrMock, nil, nil),
+		Sensor: usecase.NewSensor(srMock, sorMock, nil, hrMock, nil, nil),
+		Room:   usecase.NewRoom(rrMock, hrMock, srMock, sorMock, nil),
+	}
+
+	ws := NewWebSocketHandler(uc)
+	setupRouter(engine, uc, ws)
+
+	srv := httptest.NewServer(engine)
+	defer srv.Close()
+
+	srvURL, _ := url.Parse(srv.URL)
+	srvURL.Scheme = "ws"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, srvURL.String()+"/stream", t.dialOptions(uc))
+	require.NoError(t.T(), err)
+	defer conn.CloseNow()
+
+	read := func() models.StreamFrame {
+		op, msg, err := conn.Read(ctx)
+		require.NoError(t.T(), err)
+		require.Equal(t.T(), websocket.MessageText, op)
+		var frame models.StreamFrame
+		require.NoError(t.T(), json.Unmarshal(msg, &frame))
+		require.NoError(t.T(), frame.Validate(nil))
+		return frame
+	}
+	send := func(command string) models.StreamFrame {
+		require.NoError(t.T(), conn.Write(ctx, websocket.MessageText, []byte(command)))
+		return read()
+	}
+
+	frame := send(`{"type": "subscribe", "id": "a", "sensor_ids": [1]}`)
+	assert.Equal(t.T(), "ack", *frame.Type)
+	assert.Equal(t.T(), "a", frame.ID)
+	assert.Equal(t.T(), []int64{1}, frame.SensorIds)
+
+	frame = send(`{"type": "subscribe", "id": "b", "room_ids": [3]}`)
+	assert.Equal(t.T(), "ack", *frame.Type)
+	assert.Equal(t.T(), []int64{1, 4}, frame.SensorIds)
+
+	frame = send(`{"type": "subscribe", "id": "c", "sensor_ids": [2, 9]}`)
+	assert.Equal(t.T(), "error", *frame.Type)
+	assert.Equal(t.T(), "c", frame.ID)
+	assert.Equal(t.T(), ErrSensorNotFound, frame.Reason)
+
+	frame = send(`{"type": "subscribe"}`)
+	assert.Equal(t.T(), "error", *frame.Type)
+	assert.Equal(t.T(), ErrValidation, frame.Reason)
+
+	frame = send(`subscribe`)
+	assert.Equal(t.T(), "error", *frame.Type)
+	assert.Equal(t.T(), ErrInvalidJSONFormat, frame.Reason)
+
+	ws.eb.Publish(2, &domain.Event{SensorID: 2, Payload: 1})
+	ws.eb.Publish(4, &domain.Event{SensorID: 4, Payload: 7})
+	frame = read()
+	assert.Equal(t.T(), "event", *frame.Type)
+	event, _ := frame.Event.(map[string]any)
+	assert.Equal(t.T(), float64(4), event["SensorID"], "Получили событие датчика, на который нет подписки")
+	assert.Equal(t.T(), float64(7), event["Payload"])
+
+	frame = send(`{"type": "subscribe", "id": "d", "all": true}`)
+	assert.Equal(t.T(), "ack", *frame.Type)
+	assert.Equal(t.T(), []int64{1, 2, 4}, frame.SensorIds)
+
+	frame = send(`{"type": "unsubscribe", "id": "e", "sensor_ids": [1]}`)
+	assert.Equal(t.T(), "ack", *frame.Type)
+	assert.Equal(t.T(), []int64{2, 4}, frame.SensorIds)
+
+	frame = send(`{"type": "unsubscribe", "id": "f", "all": true}`)
+	assert.Equal(t.T(), "ack", *frame.Type)
+	assert.Empty(t.T(), frame.SensorIds)
+	assert.Empty(t.T(), ws.eb.ids, "Отписанное соединение осталось в рассылке")
+
+	frame = send(`{"type": "unsubscribe", "id": "g", "sensor_ids": [1], "from_sequence": 90}`)
+	assert.Equal(t.T(), "error", *frame.Type)
+	assert.Equal(t.T(), ErrValidation, frame.Reason)
+
+	frame = send(`{"type": "subscribe", "id": "h", "sensor_ids": [1], "from_sequence": 90}`)
+	assert.Equal(t.T(), "ack", *frame.Type)
+	assert.Equal(t.T(), []int64{1}, frame.SensorIds)
+	ws.eb.Publish(1, &domain.Event{Sequence: 101, SensorID: 1, Payload: 101})
+	ws.eb.Publish(1, &domain.Event{Sequence: 102, SensorID: 1, Payload: 102})
+	for _, sequence := range []float64{95, 101, 102} {
+		frame = read()
+		assert.Equal(t.T(), "event", *frame.Type)
+		event, _ := frame.Event.(map[string]any)
+		assert.Equal(t.T(), sequence, event["Sequence"], "Событие пропущено или повторено при переходе к новым событиям")
+	}
+}
+
+func (t *testSuite) TestEventFeed() {
+	ctx := context.Background()
+	erMock := usecase.NewMockEventRepository(t.ctrl)
+	srMock := usecase.NewMockSensorRepository(t.ctrl)
+	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Any()).Return(&domain.Sensor{}, nil).AnyTimes()
+	var written []int64
+	feed := newEventFeed(usecase.NewEvent(erMock, srMock, nil, nil, nil, nil), func(event *domain.Event) error {
+		written = append(written, event.Sequence)
+		return nil
+	})
+
+	erMock.EXPECT().GetLastSequence(ctx).Return(int64(10), nil)
+	require.NoError(t.T(), feed.subscribe(ctx, []int64{1, 2}, nil))
+
+	require.NoError(t.T(), feed.live(ctx, &domain.Event{Sequence: 9, SensorID: 1}, 0))
+	require.NoError(t.T(), feed.live(ctx, &domain.Event{Sequence: 12, SensorID: 2}, 0))
+	require.NoError(t.T(), feed.live(ctx, &domain.Event{Sequence: 13, SensorID: 3}, 0))
+	assert.Equal(t.T(), []int64{12}, written, "Отданы события до подписки или чужого датчика")
+
+	// брокер не доставил 11 и 14, события из хранилища дополняют рассылку
+	gomock.InOrder(
+		erMock.EXPECT().GetLastSequence(ctx).Return(int64(15), nil),
+		erMock.EXPECT().ReplaySensorEvents(ctx, int64(1), domain.ReplayQuery{AfterSequence: 10, UntilSequence: 15, Limit: feedReplayPage}).
+			Return([]domain.Event{{Sequence: 11, SensorID: 1}, {Sequence: 15, SensorID: 1}}, nil),
+		erMock.EXPECT().ReplaySensorEvents(ctx, int64(2), domain.ReplayQuery{AfterSequence: 10, UntilSequence: 15, Limit: feedReplayPage}).
+			Return([]domain.Event{{Sequence: 12, SensorID: 2}, {Sequence: 14, SensorID: 2}}, nil),
+	)
+	require.NoError(t.T(), feed.live(ctx, &domain.Event{Sequence: 15, SensorID: 1}, 2))
+	require.NoError(t.T(), feed.live(ctx, &domain.Event{Sequence: 14, SensorID: 2}, 2))
+	require.NoError(t.T(), feed.live(ctx, &domain.Event{Sequence: 16, SensorID: 2}, 2))
+	assert.Equal(t.T(), []int64{12, 15, 11, 14, 16}, written)
+	assert.Equal(t.T(), map[int64]int64{1: 15, 2: 15}, feed.watermarks)
+	assert.Equal(t.T(), map[int64]int64{16: 2}, feed.delivered)
+}
+
+func (t *testSuite) TestEventBrokerPolicies() {
+	publish := func(policy SlowConsumerPolicy) (*EventBroker, []int64) {
+		b := NewEventBroker()
+		ch := b.SubscribeWithPolicy("conn", policy, 1, 2, 3)
+		for i := range buffer + 2 {
+			b.Publish(int64(i%3+1), &domain.Event{SensorID: int64(i%3 + 1), Payload: int64(i)})
+		}
+		var payloads []int64
+		for len(ch) > 0 {
+			payloads = append(payloads, (<-ch).Payload)
+		}
+		return b, payloads
+	}
+
+	b, payloads := publish(SlowConsumerDropNewest)
+	assert.Equal(t.T(), []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, payloads)
+	assert.Equal(t.T(), int64(2), b.Dropped("conn"))
+	assert.Equal(t.T(), int64(2), b.Missed("conn"), "Недоставленные события не дозагружаются")
+
+	b, payloads = publish(SlowConsumerDropOldest)
+	assert.Equal(t.T(), []int64{2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, payloads)
+	assert.Equal(t.T(), int64(2), b.Dropped("conn"))
+	assert.Zero(t.T(), b.Missed("conn"))
+
+	b, payloads = publish(SlowConsumerCoalesce)
+	assert.Equal(t.T(), []int64{8, 9, 10, 11}, payloads, "В канале не только последние события датчиков")
+	assert.Equal(t.T(), int64(8), b.Dropped("conn"))
+	assert.Zero(t.T(), b.Missed("conn"))
+
+	b, payloads = publish(SlowConsumerDisconnect)
+	assert.Equal(t.T(), []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, payloads)
+	assert.Equal(t.T(), int64(1), b.Dropped("conn"), "Событие разослано в закрытый канал")
+	assert.True(t.T(), b.Disconnected("conn"))
+	assert.NotPanics(t.T(), func() { b.Unsubscribe("conn") })
+	assert.Empty(t.T(), b.ids)
+}
+
+func (t *testSuite) TestStreamSlowConsumer() {
+	engine := gin.Default()
+	urMock := usecase.NewMockUserRepository(t.ctrl)
+	urMock.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(int64(1))).Return(&domain.User{ID: 1}, nil).AnyTimes()
+	sorMock := usecase.NewMockSensorOwnerRepository(t.ctrl)
+	sorMock.EXPECT().GetSensorsByUserID(gomock.Any(), gomock.Eq(int64(1))).Return([]domain.SensorOwner{
+		{UserID: 1, SensorID: 1, Role: domain.SensorRoleViewer},
+	}, nil).AnyTimes()
+	srMock := usecase.NewMockSensorRepository(t.ctrl)
+	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Eq(int64(1))).Return(&domain.Sensor{ID: 1}, nil).AnyTimes()
+	erMock := usecase.NewMockEventRepository(t.ctrl)
+	erMock.EXPECT().GetLastSequence(gomock.Any()).Return(int64(0), nil).AnyTimes()
+
+	uc := UseCases{
+		Auth:   usecase.NewAuth(urMock, []byte("test secret")),
+		Event:  usecase.NewEvent(erMock, srMock, sorMock, nil, nil, nil),
+		Sensor: usecase.NewSensor(srMock, sorMock, nil, nil, nil, nil),
+	}
+
+	ws := NewWebSocketHandler(uc)
+	ws.lagReport = time.Millisecond * 10
+	setupRouter(engine, uc, ws)
+
+	srv := httptest.NewServer(engine)
+	defer srv.Close()
+
+	srvURL, _ := url.Parse(srv.URL)
+	srvURL.Scheme = "ws"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	_, resp, err := websocket.Dial(ctx, srvURL.String()+"/stream?slow_consumer=fastest", t.dialOptions(uc))
+	require.Error(t.T(), err)
+	assert.Equal(t.T(), http.StatusBadRequest, resp.StatusCode)
+
+	conn, _, err := websocket.Dial(ctx, srvURL.String()+"/stream?slow_consumer=disconnect", t.dialOptions(uc))
+	require.NoError(t.T(), err)
+	defer conn.CloseNow()
+	require.NoError(t.T(), conn.Write(ctx, websocket.MessageText, []byte(`{"type": "subscribe", "id": "a", "sensor_ids": [1]}`)))
+	_, _, err = conn.Read(ctx)
+	require.NoError(t.T(), err)
+
+	ws.eb.mu.RLock()
+	var sub *subscription
+	for _, s := range ws.eb.subscriptions {
+		sub = s
+	}
+	ws.eb.mu.RUnlock()
+	require.NotNil(t.T(), sub)
+	assert.Equal(t.T(), SlowConsumerDisconnect, sub.policy)
+
+	sub.dropped.Add(3)
+	_, msg, err := conn.Read(ctx)
+	require.NoError(t.T(), err)
+	var frame models.StreamFrame
+	require.NoError(t.T(), json.Unmarshal(msg, &frame))
+	assert.Equal(t.T(), "lag", *frame.Type)
+	assert.Equal(t.T(), int64(3), frame.Dropped)
+
+	sub.mu.Lock()
+	sub.disconnect()
+	sub.mu.Unlock()
+	_, _, err = conn.Read(ctx)
+	assert.Equal(t.T(), websocket.StatusPolicyViolation, websocket.CloseStatus(err))
+	var closeErr websocket.CloseError
+	if assert.ErrorAs(t.T(), err, &closeErr) {
+		assert.Equal(t.T(), slowConsumerReason, closeErr.Reason)
+	}
+}
+
+func (t *testSuite) TestEventsStream() {
+	engine := gin.Default()
+	urMock := usecase.NewMockUserRepository(t.ctrl)
+	urMock.EXPECT().GetUserByID(gomock.Any(), gomock.Eq(int64(1))).Return(&domain.User{ID: 1}, nil).AnyTimes()
+	sorMock := usecase.NewMockSensorOwnerRepository(t.ctrl)
+	sorMock.EXPECT().GetSensorsByUserID(gomock.Any(), gomock.Eq(int64(1))).Return([]domain.SensorOwner{
+		{UserID: 1, SensorID: 1, Role: domain.SensorRoleViewer},
+		{UserID: 1, SensorID: 2, Role: domain.SensorRoleViewer},
+	}, nil).AnyTimes()
+	srMock := usecase.NewMockSensorRepository(t.ctrl)
+	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Eq(int64(1))).Return(&domain.Sensor{ID: 1}, nil).AnyTimes()
+	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Eq(int64(2))).Return(&domain.Sensor{ID: 2}, nil).AnyTimes()
+	srMock.EXPECT().GetSensorByID(gomock.Any(), gomock.Eq(int64(9))).Return(nil, usecase.ErrSensorNotFound).AnyTimes()
+	erMock := usecase.NewMockEventRepository(t.ctrl)
+	erMock.EXPECT().GetLastSequence(gomock.Any()).Return(int64(8), nil).AnyTimes()
+	erMock.EXPECT().ReplaySensorEvents(gomock.Any(), gomock.Eq(int64(1)), gomock.Any()).
+		Return([]domain.Event{{Sequence: 6, SensorID: 1, Payload: 6}, {Sequence: 8, SensorID: 1, Payload: 8}}, nil)
+	erMock.EXPECT().ReplaySensorEvents(gomock.Any(), gomock.Eq(int64(2)), gomock.Any()).
+		Return([]domain.Event{{Sequence: 7, SensorID: 2, Payload: 7}}, nil)
+
+	uc := UseCases{
+		Auth:   usecase.NewAuth(urMock, []byte("test secret")),
+		Event:  usecase.NewEvent(erMock, srMock, sorMock, nil, nil, nil),
+		Sensor: usecase.NewSensor(srMock, sorMock, nil, nil, nil, nil),
+	}
+
+	ws := NewWebSocketHandler(uc)
+	ws.keepAlive = 20 * time.Millisecond
+	setupRouter(engine, uc, ws)
+
+	srv := httptest.NewServer(engine)
+	defer srv.Close()
+
+	tokens, err := uc.Auth.IssueTokens(1)
+	require.NoError(t.T(), err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	get := func(query string, lastEventID string) *http.Response {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events/stream?"+query, nil)
+		require.NoError(t.T(), err)
+		req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t.T(), err)
+		return resp
+	}
+
+	resp := get("", "")
+	_ = resp.Body.Close()
+	assert.Equal(t.T(), http.StatusBadRequest, resp.StatusCode)
+	resp = get("sensor_id=1&sensor_id=9", "")
+	_ = resp.Body.Close()
+	assert.Equal(t.T(), http.StatusNotFound, resp.StatusCode)
+	resp = get("sensor_id=1", "x")
+	_ = resp.Body.Close()
+	assert.Equal(t.T(), http.StatusUnprocessableEntity, resp.StatusCode)
+
+	// EventSource не выставляет заголовки, поэтому токен передаётся в параметре access_token
+	eventSource := func(accept string) int {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+			srv.URL+"/events/stream?sensor_id=9&access_token="+tokens.AccessToken, nil)
+		require.NoError(t.T(), err)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t.T(), err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t.T(), http.StatusNotFound, eventSource("text/event-stream"))
+	assert.Equal(t.T(), http.StatusNotFound, eventSource("application/json;q=0.5, text/event-stream"))
+	assert.Equal(t.T(), http.StatusUnauthorized, eventSource(""), "Токен из параметра принят не для потока событий")
+
+	resp = get("sensor_id=1&sensor_id=2", "5")
+	defer resp.Body.Close()
+	require.Equal(t.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(t.T(), "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// события, сохранённые во время дозагрузки, приходят и из хранилища, и из рассылки
+	ws.eb.Publish(1, &domain.Event{Sequence: 8, SensorID: 1, Payload: 8})
+	ws.eb.Publish(2, &domain.Event{Sequence: 9, SensorID: 2, Payload: 9})
+	ws.eb.Publish(3, &domain.Event{Sequence: 10, SensorID: 3, Payload: 10})
+
+	var ids []string
+	var keepAlive bool
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() && (len(ids) < 4 || !keepAlive) {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			ids = append(ids, strings.TrimPrefix(line, "id: "))
+		case strings.HasPrefix(line, "data: "):
+			var event domain.Event
+			require.NoError(t.T(), json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+			assert.Equal(t.T(), ids[len(ids)-1], strconv.FormatInt(event.Sequence, 10))
+		case line == ": keep-alive":
+			keepAlive = true
+		}
+	}
+	assert.Equal(t.T(), []string{"6", "7", "8", "9"}, ids)
+	assert.True(t.T(), keepAlive, "Поток без событий не поддерживается комментариями")
+}
+
+func (t *testSuite) TestContentLengthMiddleware_Stream() {
+	engine := gin.New()
+	var brw *bufferedResponseWriter
+	engine.HEAD("/stream", ContentLengthMiddleware(), func(c *gin.Context) {
+		brw, _ = c.Writer.(*bufferedResponseWriter)
+		for range 3 {
+			_, _ = io.WriteString(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+		}
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/stream", nil))
+	require.NotNil(t.T(), brw)
+	assert.Nil(t.T(), brw.buffer, "Поток копируется в память")
+	assert.Empty(t.T(), w.Header().Get("Content-Length"))
+}
+
 func TestWebSocketHandler(t *testing.T) {
 	ts := new(testSuite)
 	defer func() {
@@ -0,0 +1,327 @@
+package importfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/models"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+)
+
+// Format - формат файла импорта
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+var (
+	// ErrInvalidFile - файл импорта не удалось прочитать: неизвестный формат или нет обязательных столбцов
+	ErrInvalidFile = errors.New("invalid import file")
+	// ErrInvalidSensorJSON - запись датчика синтаксически невалидна
+	ErrInvalidSensorJSON = errors.New("invalid sensor json")
+	// ErrInvalidSensor - запись датчика не прошла проверку модели
+	ErrInvalidSensor = errors.New("invalid sensor")
+	// ErrInvalidEventJSON - событие синтаксически невалидно
+	ErrInvalidEventJSON = errors.New("invalid event json")
+	// ErrInvalidEvent - событие не прошло проверку модели
+	ErrInvalidEvent = errors.New("invalid event")
+	// errUnsupportedPayload - payload события не число, не логическое значение, не строка и не объект с ними
+	errUnsupportedPayload = errors.New("unsupported event payload")
+)
+
+// FormatOf - формат файла импорта по расширению имени файла
+func FormatOf(filename string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV, true
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON, true
+	}
+	return "", false
+}
+
+// NewSensorReader - читает датчики из файла импорта. В NDJSON датчик задаётся так же, как в POST /sensors,
+// в CSV первая строка - заголовок со столбцами serial_number, type и необязательными description, is_active, home_id.
+// Датчик без is_active считается активным
+func NewSensorReader(r io.Reader, format Format) (usecase.ImportReader[domain.Sensor], error) {
+	switch format {
+	case FormatNDJSON:
+		return &ndjsonReader[domain.Sensor]{r: bufio.NewReader(r), decode: decodeSensor}, nil
+	case FormatCSV:
+		reader, err := newCSVReader(r, []string{"serial_number", "type"}, csvSensor)
+		if err != nil {
+			return nil, err
+		}
+		return reader, nil
+	}
+	return nil, ErrInvalidFile
+}
+
+// NewEventReader - читает события из файла импорта. В NDJSON событие задаётся так же, как в POST /events,
+// в CSV первая строка - заголовок со столбцами sensor_serial_number, timestamp и необязательными event_id, payload, value.
+// Нецелое значение события задаётся в value как JSON, поэтому CSV выгрузки истории можно импортировать обратно.
+// В отличие от POST /events время события обязательно
+func NewEventReader(r io.Reader, format Format) (usecase.ImportReader[domain.Event], error) {
+	switch format {
+	case FormatNDJSON:
+		return &ndjsonReader[domain.Event]{r: bufio.NewReader(r), decode: decodeEvent}, nil
+	case FormatCSV:
+		reader, err := newCSVReader(r, []string{"sensor_serial_number", "timestamp"}, csvEvent)
+		if err != nil {
+			return nil, err
+		}
+		return reader, nil
+	}
+	return nil, ErrInvalidFile
+}
+
+// ndjsonReader - записи NDJSON по одной в строке, пустые строки пропускаются
+type ndjsonReader[T any] struct {
+	r      *bufio.Reader
+	line   int
+	decode func(line []byte) (T, error)
+}
+
+func (r *ndjsonReader[T]) Next() (int, T, error) {
+	var record T
+	for {
+		line, err := r.r.ReadBytes('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || len(line) == 0) {
+			return r.line, record, err
+		}
+		r.line++
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		record, err = r.decode(line)
+		if err != nil {
+			return r.line, record, errors.Join(usecase.ErrInvalidImportRecord, err)
+		}
+		return r.line, record, nil
+	}
+}
+
+// csvRecord - строка CSV с доступом к значениям по именам столбцов заголовка
+type csvRecord struct {
+	columns map[string]int
+	fields  []string
+}
+
+// Get - значение столбца, пустая строка - столбца нет
+func (r csvRecord) Get(column string) string {
+	i, ok := r.columns[column]
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(r.fields[i])
+}
+
+type csvReader[T any] struct {
+	r       *csv.Reader
+	columns map[string]int
+	decode  func(record csvRecord) (T, error)
+}
+
+// newCSVReader - читает заголовок CSV и проверяет, что в нём есть обязательные столбцы. Пустой файл не содержит записей
+func newCSVReader[T any](r io.Reader, required []string, decode func(csvRecord) (T, error)) (*csvReader[T], error) {
+	reader := &csvReader[T]{r: csv.NewReader(r), columns: make(map[string]int), decode: decode}
+	header, err := reader.r.Read()
+	if errors.Is(err, io.EOF) {
+		return reader, nil
+	}
+	if err != nil {
+		return nil, errors.Join(ErrInvalidFile, err)
+	}
+	for i, column := range header {
+		reader.columns[strings.TrimSpace(column)] = i
+	}
+	for _, column := range required {
+		if _, ok := reader.columns[column]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", ErrInvalidFile, column)
+		}
+	}
+	return reader, nil
+}
+
+func (r *csvReader[T]) Next() (int, T, error) {
+	var record T
+	fields, err := r.r.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr.StartLine, record, errors.Join(usecase.ErrInvalidImportRecord, ErrInvalidFile, err)
+	}
+	if err != nil {
+		return 0, record, err
+	}
+	line, _ := r.r.FieldPos(0)
+	record, err = r.decode(csvRecord{columns: r.columns, fields: fields})
+	if err != nil {
+		return line, record, errors.Join(usecase.ErrInvalidImportRecord, err)
+	}
+	return line, record, nil
+}
+
+func decodeSensor(line []byte) (domain.Sensor, error) {
+	var sensor models.SensorToCreate
+	if err := json.Unmarshal(line, &sensor); err != nil {
+		return domain.Sensor{}, errors.Join(ErrInvalidSensorJSON, err)
+	}
+	return toDomainSensor(&sensor)
+}
+
+func csvSensor(record csvRecord) (domain.Sensor, error) {
+	sensor := models.SensorToCreate{
+		SerialNumber: swag.String(record.Get("serial_number")),
+		Type:         swag.String(record.Get("type")),
+		Description:  swag.String(record.Get("description")),
+		IsActive:     swag.Bool(true),
+	}
+	if value := record.Get("is_active"); value != "" {
+		isActive, err := strconv.ParseBool(value)
+		if err != nil {
+			return domain.Sensor{}, errors.Join(ErrInvalidSensor, err)
+		}
+		sensor.IsActive = &isActive
+	}
+	if value := record.Get("home_id"); value != "" {
+		homeID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return domain.Sensor{}, errors.Join(ErrInvalidSensor, err)
+		}
+		sensor.HomeID = homeID
+	}
+	return toDomainSensor(&sensor)
+}
+
+func toDomainSensor(sensor *models.SensorToCreate) (domain.Sensor, error) {
+	if err := sensor.Validate(nil); err != nil {
+		return domain.Sensor{}, errors.Join(ErrInvalidSensor, err)
+	}
+	return domain.Sensor{
+		Type:         domain.SensorType(*sensor.Type),
+		SerialNumber: *sensor.SerialNumber,
+		Description:  *sensor.Description,
+		IsActive:     *sensor.IsActive,
+		HomeID:       sensor.HomeID,
+	}, nil
+}
+
+func decodeEvent(line []byte) (domain.Event, error) {
+	var event models.SensorEvent
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if err := decoder.Decode(&event); err != nil {
+		return domain.Event{}, errors.Join(ErrInvalidEventJSON, err)
+	}
+	result, err := ToDomainEvent(&event)
+	if err != nil {
+		return domain.Event{}, err
+	}
+	return *result, nil
+}
+
+func csvEvent(record csvRecord) (domain.Event, error) {
+	timestamp, err := time.Parse(time.RFC3339Nano, record.Get("timestamp"))
+	if err != nil {
+		return domain.Event{}, errors.Join(ErrInvalidEvent, err)
+	}
+	event := models.SensorEvent{
+		SensorSerialNumber: swag.String(record.Get("sensor_serial_number")),
+		Timestamp:          strfmt.DateTime(timestamp),
+		EventID:            record.Get("event_id"),
+	}
+	// value задан для нецелых значений, целое значение берётся из payload
+	raw := record.Get("value")
+	if raw == "" {
+		raw = record.Get("payload")
+	}
+	if raw != "" {
+		decoder := json.NewDecoder(strings.NewReader(raw))
+		decoder.UseNumber()
+		if err := decoder.Decode(&event.Payload); err != nil {
+			return domain.Event{}, errors.Join(ErrInvalidEvent, err)
+		}
+	}
+	result, err := ToDomainEvent(&event)
+	if err != nil {
+		return domain.Event{}, err
+	}
+	return *result, nil
+}
+
+// ToDomainEvent - проверяет модель события и разбирает его payload, время события не подставляется.
+// Событие из файла импорта и событие из запроса разбираются одинаково
+func ToDomainEvent(event *models.SensorEvent) (*domain.Event, error) {
+	if err := event.Validate(nil); err != nil {
+		return nil, errors.Join(ErrInvalidEvent, err)
+	}
+	payload, value, err := parseEventPayload(event.Payload)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidEvent, err)
+	}
+	return &domain.Event{
+		Payload:            payload,
+		Value:              value,
+		Timestamp:          time.Time(event.Timestamp),
+		SensorSerialNumber: *event.SensorSerialNumber,
+		EventID:            event.EventID,
+	}, nil
+}
+
+// parseEventPayload - разбирает payload события, прочитанный с UseNumber. Целое число попадает в Payload,
+// остальные значения - в Value, логическое значение дополнительно попадает в Payload как 0 или 1
+func parseEventPayload(raw any) (int64, any, error) {
+	if channels, ok := raw.(map[string]any); ok {
+		value := make(map[string]any, len(channels))
+		for name, channel := range channels {
+			parsed, err := parseScalarPayload(channel)
+			if err != nil {
+				return 0, nil, err
+			}
+			value[name] = parsed
+		}
+		return 0, value, nil
+	}
+	value, err := parseScalarPayload(raw)
+	if err != nil {
+		return 0, nil, err
+	}
+	switch v := value.(type) {
+	case int64:
+		return v, nil, nil
+	case bool:
+		if v {
+			return 1, v, nil
+		}
+		return 0, v, nil
+	}
+	return 0, value, nil
+}
+
+func parseScalarPayload(raw any) (any, error) {
+	switch v := raw.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		return v.Float64()
+	case bool, string:
+		return v, nil
+	}
+	return nil, errUnsupportedPayload
+}
@@ -0,0 +1,93 @@
+package importfile
+
+import (
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatOf(t *testing.T) {
+	tests := map[string]Format{
+		"sensors.csv":    FormatCSV,
+		"SENSORS.CSV":    FormatCSV,
+		"events.ndjson":  FormatNDJSON,
+		"events.jsonl":   FormatNDJSON,
+		"events.json":    "",
+		"events":         "",
+		"dir.csv/events": "",
+	}
+	for filename, want := range tests {
+		format, ok := FormatOf(filename)
+		assert.Equal(t, want, format, filename)
+		assert.Equal(t, want != "", ok, filename)
+	}
+}
+
+func TestNewSensorReader(t *testing.T) {
+	t.Run("ok, csv records numbered by file line", func(t *testing.T) {
+		reader, err := NewSensorReader(strings.NewReader(
+			"serial_number,type,is_active\n0000000001,cc,false\n0000000002,cc,maybe\n"), FormatCSV)
+		require.NoError(t, err)
+
+		line, sensor, err := reader.Next()
+		require.NoError(t, err)
+		assert.Equal(t, 2, line)
+		assert.Equal(t, domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeContactClosure}, sensor)
+
+		line, _, err = reader.Next()
+		assert.Equal(t, 3, line)
+		assert.ErrorIs(t, err, usecase.ErrInvalidImportRecord)
+		assert.ErrorIs(t, err, ErrInvalidSensor)
+
+		_, _, err = reader.Next()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("ok, ndjson blank lines skipped", func(t *testing.T) {
+		reader, err := NewSensorReader(strings.NewReader(
+			"\n{\"serial_number\": \"0000000001\", \"type\": \"cc\", \"description\": \"\", \"is_active\": true}\n{"), FormatNDJSON)
+		require.NoError(t, err)
+
+		line, sensor, err := reader.Next()
+		require.NoError(t, err)
+		assert.Equal(t, 2, line)
+		assert.True(t, sensor.IsActive)
+
+		line, _, err = reader.Next()
+		assert.Equal(t, 3, line)
+		assert.ErrorIs(t, err, ErrInvalidSensorJSON)
+	})
+
+	t.Run("fail, missing column", func(t *testing.T) {
+		_, err := NewSensorReader(strings.NewReader("type\ncc\n"), FormatCSV)
+		assert.ErrorIs(t, err, ErrInvalidFile)
+	})
+}
+
+func TestNewEventReader(t *testing.T) {
+	reader, err := NewEventReader(strings.NewReader(
+		"sensor_serial_number,timestamp,payload,value\n"+
+			"0000000001,2024-01-01T00:00:00Z,1,\n"+
+			"0000000001,2024-01-01T00:00:01Z,,21.5\n"+
+			"0000000001,,1,\n"), FormatCSV)
+	require.NoError(t, err)
+
+	_, event, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), event.Payload)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), event.Timestamp)
+
+	_, event, err = reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 21.5, event.Value)
+
+	line, _, err := reader.Next()
+	assert.Equal(t, 4, line)
+	assert.ErrorIs(t, err, ErrInvalidEvent, "Событие без времени принято")
+}
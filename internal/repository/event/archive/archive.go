@@ -0,0 +1,91 @@
+package archive
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"homework/internal/domain"
+	"os"
+	"path/filepath"
+)
+
+// EventArchive - архив удалённых событий в каталоге. Каждая порция событий датчика пишется в отдельный файл
+// NDJSON, сжатый gzip. Имя файла составлено из id датчика и id первого и последнего события, поэтому
+// повторная запись той же порции перезаписывает файл, а не дублирует события
+type EventArchive struct {
+	dir string
+}
+
+func NewEventArchive(dir string) *EventArchive {
+	return &EventArchive{
+		dir: dir,
+	}
+}
+
+func (a *EventArchive) ArchiveEvents(ctx context.Context, id int64, events []domain.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	dir := filepath.Join(a.dir, fmt.Sprintf("sensor-%d", id))
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	name := filepath.Join(dir, fmt.Sprintf("%d-%d.ndjson.gz", events[0].ID, events[len(events)-1].ID))
+
+	// Файл пишется под временным именем и переименовывается, только когда записан целиком
+	file, err := os.CreateTemp(dir, ".archive-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if err := writeEvents(file, events); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), name)
+}
+
+func writeEvents(file *os.File, events []domain.Event) error {
+	zw := gzip.NewWriter(file)
+	encoder := json.NewEncoder(zw)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// ReadEvents - читает события из файла архива
+func ReadEvents(name string) ([]domain.Event, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	var events []domain.Event
+	decoder := json.NewDecoder(zr)
+	for decoder.More() {
+		var event domain.Event
+		if err := decoder.Decode(&event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
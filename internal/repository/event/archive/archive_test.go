@@ -0,0 +1,46 @@
+package archive
+
+import (
+	"context"
+	"homework/internal/domain"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventArchive_ArchiveEvents(t *testing.T) {
+	t.Run("ok, events are written to a compressed file", func(t *testing.T) {
+		dir := t.TempDir()
+		archive := NewEventArchive(dir)
+		value := 21.5
+		events := []domain.Event{
+			{ID: 3, Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), SensorID: 7, SensorSerialNumber: "1234567890", Payload: 10},
+			{ID: 5, Timestamp: time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), SensorID: 7, SensorSerialNumber: "1234567890", CalibratedValue: &value, Unit: "°C"},
+		}
+
+		require.NoError(t, archive.ArchiveEvents(context.Background(), 7, events))
+		require.NoError(t, archive.ArchiveEvents(context.Background(), 7, events))
+
+		files, err := os.ReadDir(filepath.Join(dir, "sensor-7"))
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, "3-5.ndjson.gz", files[0].Name())
+
+		archived, err := ReadEvents(filepath.Join(dir, "sensor-7", files[0].Name()))
+		require.NoError(t, err)
+		assert.Equal(t, events, archived)
+	})
+
+	t.Run("ok, nothing is written without events", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, NewEventArchive(dir).ArchiveEvents(context.Background(), 7, nil))
+
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+}
@@ -1,21 +1,43 @@
 package inmemory
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/inmemory"
 	"homework/internal/usecase"
+	"slices"
 	"sync"
 	"time"
 )
 
 type EventRepository struct {
-	eventsById map[int64][]*domain.Event
-	mu         sync.Mutex
+	eventsById   map[int64][]*domain.Event
+	rollups      map[rollupKey]*domain.HistoryRollup
+	lastID       int64
+	lastSequence int64
+	dedupWindow  time.Duration
+	mu           sync.Mutex
 }
 
-func NewEventRepository() *EventRepository {
-	return &EventRepository{eventsById: make(map[int64][]*domain.Event)}
+func NewEventRepository(options ...func(*EventRepository)) *EventRepository {
+	r := &EventRepository{
+		eventsById:  make(map[int64][]*domain.Event),
+		rollups:     make(map[rollupKey]*domain.HistoryRollup),
+		dedupWindow: usecase.DefaultEventDedupWindow,
+	}
+	for _, o := range options {
+		o(r)
+	}
+	return r
+}
+
+// WithDedupWindow - задаёт, сколько времени после получения события повтор с тем же EventID не сохраняется
+func WithDedupWindow(window time.Duration) func(*EventRepository) {
+	return func(r *EventRepository) {
+		r.dedupWindow = window
+	}
 }
 
 func (r *EventRepository) SaveEvent(ctx context.Context, event *domain.Event) error {
@@ -27,16 +49,123 @@ func (r *EventRepository) SaveEvent(ctx context.Context, event *domain.Event) er
 	if event == nil {
 		return errors.New("event is nil")
 	}
+	if r.isDuplicate(event) {
+		return usecase.ErrEventDuplicate
+	}
+	r.lastID++
+	r.lastSequence++
+	event.ID, event.Sequence = r.lastID, r.lastSequence
 	r.eventsById[event.SensorID] = append(r.eventsById[event.SensorID], event)
+	transaction.OnRollback(ctx, func() {
+		r.removeEvents([]*domain.Event{event})
+	})
+	r.addRollups(ctx, []domain.Event{*event})
 	return nil
 }
 
+func (r *EventRepository) SaveEvents(ctx context.Context, events []domain.Event) ([]error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	errs := make([]error, len(events))
+	saved := make([]*domain.Event, 0, len(events))
+	for i, event := range events {
+		if r.isDuplicate(&event) {
+			errs[i] = usecase.ErrEventDuplicate
+			continue
+		}
+		r.lastID++
+		r.lastSequence++
+		event.ID, event.Sequence = r.lastID, r.lastSequence
+		events[i].ID, events[i].Sequence = event.ID, event.Sequence
+		r.eventsById[event.SensorID] = append(r.eventsById[event.SensorID], &event)
+		saved = append(saved, &event)
+	}
+	transaction.OnRollback(ctx, func() {
+		r.removeEvents(saved)
+	})
+	rollupEvents := make([]domain.Event, 0, len(saved))
+	for _, event := range saved {
+		rollupEvents = append(rollupEvents, *event)
+	}
+	r.addRollups(ctx, rollupEvents)
+	return errs, nil
+}
+
+// rollupKey - накопленные агрегаты однозначно определяются датчиком, длительностью, началом интервала и домом
+type rollupKey struct {
+	sensorID   int64
+	resolution time.Duration
+	start      int64
+	homeID     int64
+}
+
+// addRollups - добавляет сохранённые события в накопленные агрегаты. При откате транзакции агрегаты восстанавливаются
+func (r *EventRepository) addRollups(ctx context.Context, events []domain.Event) {
+	for _, delta := range domain.RollupEvents(events) {
+		key := rollupKey{sensorID: delta.SensorID, resolution: delta.Resolution, start: delta.Start.UnixNano(), homeID: delta.HomeID}
+		rollup, ok := r.rollups[key]
+		if !ok {
+			rollup = &domain.HistoryRollup{
+				SensorID:   delta.SensorID,
+				HomeID:     delta.HomeID,
+				Resolution: delta.Resolution,
+				Start:      delta.Start,
+			}
+			r.rollups[key] = rollup
+		}
+		previous := *rollup
+		transaction.OnRollback(ctx, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if !ok {
+				delete(r.rollups, key)
+				return
+			}
+			*rollup = previous
+		})
+		rollup.Merge(delta)
+	}
+}
+
+// removeEvents - убирает сохранённые события при откате транзакции
+func (r *EventRepository) removeEvents(saved []*domain.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, event := range saved {
+		events := slices.DeleteFunc(r.eventsById[event.SensorID], func(e *domain.Event) bool {
+			return e == event
+		})
+		if len(events) == 0 {
+			delete(r.eventsById, event.SensorID)
+			continue
+		}
+		r.eventsById[event.SensorID] = events
+	}
+}
+
+// isDuplicate - проверяет, есть ли у датчика событие с тем же EventID, полученное в пределах окна дедупликации
+func (r *EventRepository) isDuplicate(event *domain.Event) bool {
+	if event.EventID == "" {
+		return false
+	}
+	since := event.ReceivedAt.Add(-r.dedupWindow)
+	for _, saved := range r.eventsById[event.SensorID] {
+		if saved.EventID == event.EventID && saved.ReceivedAt.After(since) {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *EventRepository) GetLastEventBySensorID(ctx context.Context, id int64) (*domain.Event, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	events, ok := r.eventsById[id]
-	if !ok {
+	events := r.eventsById[id]
+	if len(events) == 0 {
 		return nil, usecase.ErrEventNotFound
 	}
 	lastEvent := events[len(events)-1]
@@ -48,7 +177,9 @@ func (r *EventRepository) GetLastEventBySensorID(ctx context.Context, id int64)
 	return lastEvent, nil
 }
 
-func (r *EventRepository) GetSensorHistory(ctx context.Context, id int64, start, end time.Time) ([]domain.Event, error) {
+func (r *EventRepository) GetSensorHistory(ctx context.Context, id int64, query domain.HistoryQuery) ([]domain.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -58,9 +189,241 @@ func (r *EventRepository) GetSensorHistory(ctx context.Context, id int64, start,
 	}
 	var history []domain.Event
 	for _, event := range events {
-		if event.Timestamp.After(start) && event.Timestamp.Before(end) {
-			history = append(history, *event)
+		if event.Timestamp.Before(query.Start) || event.Timestamp.After(query.End) {
+			continue
+		}
+		if query.HomeID != nil && event.HomeID != *query.HomeID {
+			continue
+		}
+		if query.After != nil {
+			position := compareHistoryPosition(*event, *query.After)
+			if query.Descending && position >= 0 || !query.Descending && position <= 0 {
+				continue
+			}
+		}
+		history = append(history, *event)
+	}
+	slices.SortFunc(history, func(a, b domain.Event) int {
+		if query.Descending {
+			return compareHistoryPosition(b, domain.HistoryCursor{Timestamp: a.Timestamp, ID: a.ID})
 		}
+		return compareHistoryPosition(a, domain.HistoryCursor{Timestamp: b.Timestamp, ID: b.ID})
+	})
+	if query.Limit > 0 && len(history) > query.Limit {
+		history = history[:query.Limit]
 	}
 	return history, nil
 }
+
+// ReplaySensorEvents - возвращает события датчика для дозагрузки потока в порядке последовательности
+func (r *EventRepository) ReplaySensorEvents(ctx context.Context, id int64, query domain.ReplayQuery) ([]domain.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var events []domain.Event
+	for _, event := range r.eventsById[id] {
+		if event.Sequence <= query.AfterSequence || query.UntilSequence != 0 && event.Sequence > query.UntilSequence ||
+			event.Timestamp.Before(query.Since) || query.HomeID != nil && event.HomeID != *query.HomeID {
+			continue
+		}
+		events = append(events, *event)
+	}
+	slices.SortFunc(events, func(a, b domain.Event) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+	if len(events) > query.Limit {
+		events = events[:query.Limit]
+	}
+	return events, nil
+}
+
+// GetLastSequence - возвращает последнюю выданную последовательность. События откаченных транзакций
+// оставляют в последовательностях пропуски, как и в хранилище postgres
+func (r *EventRepository) GetLastSequence(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.lastSequence, nil
+}
+
+// StreamSensorHistory - передаёт в fn события, отобранные как в GetSensorHistory. Выборка копируется заранее,
+// чтобы fn не выполнялась под блокировкой репозитория
+func (r *EventRepository) StreamSensorHistory(ctx context.Context, id int64, query domain.HistoryQuery, fn func(domain.Event) error) error {
+	query.After = nil
+	history, err := r.GetSensorHistory(ctx, id, query)
+	if err != nil {
+		return err
+	}
+	for _, event := range history {
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSensorHistoryAggregates - считает агрегаты числовых значений событий, отобранных как в GetSensorHistory
+func (r *EventRepository) GetSensorHistoryAggregates(ctx context.Context, id int64, query domain.HistoryAggregateQuery) ([]domain.HistoryBucket, error) {
+	events, err := r.GetSensorHistory(ctx, id, domain.HistoryQuery{Start: query.Start, End: query.End, HomeID: query.HomeID})
+	if err != nil {
+		return nil, err
+	}
+	var buckets []domain.HistoryBucket
+	var sum float64
+	for _, event := range events {
+		value, ok := event.NumericValue()
+		if !ok {
+			continue
+		}
+		start := event.Timestamp.Truncate(query.Bucket)
+		if len(buckets) == 0 || !buckets[len(buckets)-1].Start.Equal(start) {
+			minValue, maxValue, first := value, value, value
+			buckets = append(buckets, domain.HistoryBucket{Start: start, Min: &minValue, Max: &maxValue, First: &first})
+			sum = 0
+		}
+		bucket := &buckets[len(buckets)-1]
+		bucket.Count++
+		sum += value
+		*bucket.Min = min(*bucket.Min, value)
+		*bucket.Max = max(*bucket.Max, value)
+		avg := sum / float64(bucket.Count)
+		bucket.Avg = &avg
+		bucket.Last = &value
+	}
+	return buckets, nil
+}
+
+// GetSensorRollups - возвращает накопленные агрегаты датчика, упорядоченные по началу интервала и дому
+func (r *EventRepository) GetSensorRollups(ctx context.Context, id int64, query domain.HistoryAggregateQuery) ([]domain.HistoryRollup, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if _, ok := r.eventsById[id]; !ok {
+		return nil, usecase.ErrSensorNotFound
+	}
+	var rollups []domain.HistoryRollup
+	for key, rollup := range r.rollups {
+		if key.sensorID != id || key.resolution != query.Bucket {
+			continue
+		}
+		if rollup.Start.Before(query.Start) || !rollup.Start.Before(query.End) {
+			continue
+		}
+		if query.HomeID != nil && rollup.HomeID != *query.HomeID {
+			continue
+		}
+		rollups = append(rollups, *rollup)
+	}
+	slices.SortFunc(rollups, func(a, b domain.HistoryRollup) int {
+		if c := a.Start.Compare(b.Start); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.HomeID, b.HomeID)
+	})
+	return rollups, nil
+}
+
+// CountEventsBefore - считает события датчика, время которых раньше before
+func (r *EventRepository) CountEventsBefore(ctx context.Context, id int64, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	var count int64
+	for _, event := range r.eventsById[id] {
+		if event.Timestamp.Before(before) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// DeleteEventsBefore - удаляет самые старые события датчика. Датчик без событий остаётся известным репозиторию,
+// при откате транзакции события возвращаются
+func (r *EventRepository) DeleteEventsBefore(ctx context.Context, id int64, before time.Time, limit int) ([]domain.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var expired []*domain.Event
+	for _, event := range r.eventsById[id] {
+		if event.Timestamp.Before(before) {
+			expired = append(expired, event)
+		}
+	}
+	slices.SortFunc(expired, func(a, b *domain.Event) int {
+		return compareHistoryPosition(*a, domain.HistoryCursor{Timestamp: b.Timestamp, ID: b.ID})
+	})
+	if len(expired) > limit {
+		expired = expired[:limit]
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+	previous := r.eventsById[id]
+	r.eventsById[id] = slices.DeleteFunc(slices.Clone(previous), func(event *domain.Event) bool {
+		return slices.Contains(expired, event)
+	})
+	transaction.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.eventsById[id] = previous
+	})
+	deleted := make([]domain.Event, 0, len(expired))
+	for _, event := range expired {
+		deleted = append(deleted, *event)
+	}
+	return deleted, nil
+}
+
+// DeleteRollupsBefore - удаляет накопленные агрегаты датчика за интервалы, закончившиеся не позже before
+func (r *EventRepository) DeleteRollupsBefore(ctx context.Context, id int64, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for key, rollup := range r.rollups {
+		if key.sensorID != id || rollup.Start.Add(rollup.Resolution).After(before) {
+			continue
+		}
+		delete(r.rollups, key)
+		transaction.OnRollback(ctx, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.rollups[key] = rollup
+		})
+	}
+	return nil
+}
+
+// compareHistoryPosition - сравнивает место события в истории с позицией курсора
+func compareHistoryPosition(event domain.Event, cursor domain.HistoryCursor) int {
+	if c := event.Timestamp.Compare(cursor.Timestamp); c != 0 {
+		return c
+	}
+	return cmp.Compare(event.ID, cursor.ID)
+}
+
+func (r *EventRepository) DeleteEventsBySensorID(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	delete(r.eventsById, id)
+	for key := range r.rollups {
+		if key.sensorID == id {
+			delete(r.rollups, key)
+		}
+	}
+	return nil
+}
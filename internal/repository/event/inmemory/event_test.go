@@ -2,7 +2,9 @@ package inmemory
 
 import (
 	"context"
+	"errors"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/inmemory"
 	"homework/internal/usecase"
 	"sync"
 	"testing"
@@ -60,6 +62,28 @@ func TestEventRepository_SaveEvent(t *testing.T) {
 		assert.Equal(t, event.Payload, actualEvent.Payload)
 	})
 
+	t.Run("ok, duplicate within dedup window", func(t *testing.T) {
+		er := NewEventRepository(WithDedupWindow(time.Hour))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		now := time.Now()
+		event := domain.Event{Timestamp: now, ReceivedAt: now, SensorID: 1, EventID: "retry", Payload: 1}
+		require.NoError(t, er.SaveEvent(ctx, &event))
+
+		retry := event
+		retry.ReceivedAt = now.Add(time.Minute)
+		assert.ErrorIs(t, er.SaveEvent(ctx, &retry), usecase.ErrEventDuplicate)
+
+		late := event
+		late.ReceivedAt = now.Add(2 * time.Hour)
+		assert.NoError(t, er.SaveEvent(ctx, &late), "Повтор после окна дедупликации должен сохраниться")
+
+		withoutID := domain.Event{Timestamp: now, ReceivedAt: now, SensorID: 1}
+		assert.NoError(t, er.SaveEvent(ctx, &withoutID))
+		assert.NoError(t, er.SaveEvent(ctx, &withoutID), "События без EventID не должны отсеиваться")
+	})
+
 	t.Run("ok, collision test", func(t *testing.T) {
 		er := NewEventRepository()
 		ctx, cancel := context.WithCancel(context.Background())
@@ -92,6 +116,135 @@ func TestEventRepository_SaveEvent(t *testing.T) {
 	})
 }
 
+func TestEventRepository_SaveEvents(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := er.SaveEvents(ctx, []domain.Event{{SensorID: 1}})
+		assert.ErrorIs(t, err, context.Canceled)
+
+		_, err = er.GetLastEventBySensorID(context.Background(), 1)
+		assert.ErrorIs(t, err, usecase.ErrEventNotFound)
+	})
+
+	t.Run("ok, save batch for several sensors", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		now := time.Now()
+		events := []domain.Event{
+			{Timestamp: now, SensorID: 1, Payload: 1},
+			{Timestamp: now.Add(time.Second), SensorID: 1, Payload: 2},
+			{Timestamp: now, SensorID: 2, Payload: 3},
+		}
+		errs, err := er.SaveEvents(ctx, events)
+		require.NoError(t, err)
+		assert.Equal(t, []error{nil, nil, nil}, errs)
+		events[1].Payload = 20
+
+		lastEvent, err := er.GetLastEventBySensorID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), lastEvent.Payload, "Сохранённое событие изменилось вместе с пакетом")
+
+		history, err := er.GetSensorHistory(ctx, 2, domain.HistoryQuery{Start: now.Add(-time.Second), End: now.Add(time.Second)})
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.NotZero(t, history[0].ID, "Событию не присвоен идентификатор")
+		assert.Equal(t, []domain.Event{events[2]}, history, "Идентификатор не проставлен событию пакета")
+	})
+
+	t.Run("ok, duplicates are skipped", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		now := time.Now()
+		require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: now, ReceivedAt: now, SensorID: 1, EventID: "a", Payload: 1}))
+
+		errs, err := er.SaveEvents(ctx, []domain.Event{
+			{Timestamp: now, ReceivedAt: now, SensorID: 1, EventID: "a", Payload: 2},
+			{Timestamp: now, ReceivedAt: now, SensorID: 1, EventID: "b", Payload: 3},
+			{Timestamp: now, ReceivedAt: now, SensorID: 1, EventID: "b", Payload: 4},
+			{Timestamp: now, ReceivedAt: now, SensorID: 2, EventID: "a", Payload: 5},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []error{usecase.ErrEventDuplicate, nil, usecase.ErrEventDuplicate, nil}, errs)
+
+		history, err := er.GetSensorHistory(ctx, 1, domain.HistoryQuery{Start: now.Add(-time.Second), End: now.Add(time.Second)})
+		require.NoError(t, err)
+		assert.Len(t, history, 2)
+	})
+
+	t.Run("ok, rolled back with transaction", func(t *testing.T) {
+		er := NewEventRepository()
+		tr := transaction.NewTransactor()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		now := time.Now()
+		require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: now, SensorID: 1, Payload: 1}))
+
+		someErr := errors.New("some error")
+		err := tr.WithinTransaction(ctx, func(ctx context.Context) error {
+			require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: now.Add(time.Second), SensorID: 1, Payload: 2}))
+			_, err := er.SaveEvents(ctx, []domain.Event{{Timestamp: now, SensorID: 2, Payload: 3}})
+			require.NoError(t, err)
+			return someErr
+		})
+		assert.ErrorIs(t, err, someErr)
+
+		lastEvent, err := er.GetLastEventBySensorID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), lastEvent.Payload, "Событие из отменённой транзакции осталось")
+		_, err = er.GetLastEventBySensorID(ctx, 2)
+		assert.ErrorIs(t, err, usecase.ErrEventNotFound)
+	})
+}
+
+func TestEventRepository_ReplaySensorEvents(t *testing.T) {
+	er := NewEventRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	homeID := int64(4)
+	events := []domain.Event{
+		{Timestamp: now.Add(time.Second), SensorID: 1, Payload: 1},
+		{Timestamp: now, SensorID: 1, Payload: 2, HomeID: homeID},
+		{Timestamp: now, SensorID: 2, Payload: 3},
+		{Timestamp: now.Add(-time.Second), SensorID: 1, Payload: 4, HomeID: homeID},
+		{Timestamp: now, SensorID: 1, Payload: 5, HomeID: homeID},
+	}
+	_, err := er.SaveEvents(ctx, events)
+	require.NoError(t, err)
+	for i := 1; i < len(events); i++ {
+		assert.Greater(t, events[i].Sequence, events[i-1].Sequence, "Последовательности событий пакета не возрастают")
+	}
+
+	replayed, err := er.ReplaySensorEvents(ctx, 1, domain.ReplayQuery{AfterSequence: events[0].Sequence, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Event{events[1], events[3]}, replayed)
+
+	replayed, err = er.ReplaySensorEvents(ctx, 1, domain.ReplayQuery{Since: now, HomeID: &homeID, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Event{events[1], events[4]}, replayed)
+
+	replayed, err = er.ReplaySensorEvents(ctx, 1, domain.ReplayQuery{UntilSequence: events[3].Sequence, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Event{events[0], events[1], events[3]}, replayed)
+
+	replayed, err = er.ReplaySensorEvents(ctx, 3, domain.ReplayQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, replayed)
+
+	last, err := er.GetLastSequence(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, events[4].Sequence, last)
+}
+
 func TestEventRepository_GetLastEventBySensorID(t *testing.T) {
 	t.Run("fail, ctx cancelled", func(t *testing.T) {
 		er := NewEventRepository()
@@ -223,7 +376,7 @@ func TestEventRepository_GetSensorHistory(t *testing.T) {
 				tc.prepare(er)
 			}
 
-			history, err := er.GetSensorHistory(ctx, tc.sensorID, start, end)
+			history, err := er.GetSensorHistory(ctx, tc.sensorID, domain.HistoryQuery{Start: start, End: end})
 
 			if tc.expectedErr != nil {
 				assert.ErrorIs(t, err, tc.expectedErr)
@@ -240,3 +393,373 @@ func TestEventRepository_GetSensorHistory(t *testing.T) {
 		})
 	}
 }
+
+func TestEventRepository_GetSensorHistoryPagination(t *testing.T) {
+	er := NewEventRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	homeID := int64(7)
+	// Два события с одинаковым временем, чтобы проверить порядок по id
+	for i, ts := range []time.Time{now, now.Add(time.Second), now.Add(time.Second), now.Add(2 * time.Second)} {
+		event := &domain.Event{Timestamp: ts, SensorID: 1, Payload: int64(i)}
+		if i != 0 {
+			event.HomeID = homeID
+		}
+		require.NoError(t, er.SaveEvent(ctx, event))
+	}
+
+	payloads := func(events []domain.Event) []int64 {
+		result := make([]int64, 0, len(events))
+		for _, event := range events {
+			result = append(result, event.Payload)
+		}
+		return result
+	}
+	query := domain.HistoryQuery{Start: now, End: now.Add(2 * time.Second)}
+
+	t.Run("ok, ascending pages", func(t *testing.T) {
+		q := query
+		q.Limit = 2
+		first, err := er.GetSensorHistory(ctx, 1, q)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{0, 1}, payloads(first))
+
+		q.After = &domain.HistoryCursor{Timestamp: first[1].Timestamp, ID: first[1].ID}
+		second, err := er.GetSensorHistory(ctx, 1, q)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{2, 3}, payloads(second))
+	})
+
+	t.Run("ok, descending pages", func(t *testing.T) {
+		q := query
+		q.Limit = 3
+		q.Descending = true
+		first, err := er.GetSensorHistory(ctx, 1, q)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{3, 2, 1}, payloads(first))
+
+		q.After = &domain.HistoryCursor{Timestamp: first[2].Timestamp, ID: first[2].ID}
+		second, err := er.GetSensorHistory(ctx, 1, q)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{0}, payloads(second))
+	})
+
+	t.Run("ok, filtered by home", func(t *testing.T) {
+		q := query
+		q.HomeID = &homeID
+		history, err := er.GetSensorHistory(ctx, 1, q)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1, 2, 3}, payloads(history))
+	})
+}
+
+func TestEventRepository_StreamSensorHistory(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("fail, unknown sensor", func(t *testing.T) {
+		er := NewEventRepository()
+		err := er.StreamSensorHistory(context.Background(), 1, domain.HistoryQuery{Start: start, End: start},
+			func(domain.Event) error { return nil })
+		assert.ErrorIs(t, err, usecase.ErrSensorNotFound)
+	})
+
+	t.Run("ok, events streamed in order until fn fails", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		for _, offset := range []time.Duration{2 * time.Minute, 0, time.Minute} {
+			require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: start.Add(offset), SensorID: 1}))
+		}
+		query := domain.HistoryQuery{Start: start, End: start.Add(time.Hour), Descending: true}
+
+		var streamed []time.Time
+		require.NoError(t, er.StreamSensorHistory(ctx, 1, query, func(event domain.Event) error {
+			streamed = append(streamed, event.Timestamp)
+			return nil
+		}))
+		assert.Equal(t, []time.Time{start.Add(2 * time.Minute), start.Add(time.Minute), start}, streamed)
+
+		stop := errors.New("stop")
+		var count int
+		err := er.StreamSensorHistory(ctx, 1, query, func(domain.Event) error {
+			count++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, count)
+	})
+}
+
+func TestEventRepository_GetSensorHistoryAggregates(t *testing.T) {
+	t.Run("err, sensor not found", func(t *testing.T) {
+		er := NewEventRepository()
+		_, err := er.GetSensorHistoryAggregates(context.Background(), 1, domain.HistoryAggregateQuery{Bucket: time.Minute})
+		assert.ErrorIs(t, err, usecase.ErrSensorNotFound)
+	})
+
+	t.Run("ok, buckets with numeric values", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		calibrated := 10.5
+		for _, event := range []domain.Event{
+			{Timestamp: start.Add(50 * time.Second), Payload: 3},
+			{Timestamp: start.Add(10 * time.Second), Payload: 1},
+			{Timestamp: start.Add(20 * time.Second), Value: "open"},
+			{Timestamp: start.Add(30 * time.Second), Payload: 2},
+			{Timestamp: start.Add(2*time.Minute + time.Second), Value: 4.5},
+			{Timestamp: start.Add(2*time.Minute + 2*time.Second), Payload: 100, CalibratedValue: &calibrated},
+			{Timestamp: start.Add(time.Hour), Payload: 7},
+		} {
+			event.SensorID = 1
+			require.NoError(t, er.SaveEvent(ctx, &event))
+		}
+
+		buckets, err := er.GetSensorHistoryAggregates(ctx, 1, domain.HistoryAggregateQuery{
+			Start:  start,
+			End:    start.Add(5 * time.Minute),
+			Bucket: time.Minute,
+		})
+		require.NoError(t, err)
+		value := func(v float64) *float64 { return &v }
+		assert.Equal(t, []domain.HistoryBucket{
+			{Start: start, Count: 3, Min: value(1), Max: value(3), Avg: value(2), First: value(1), Last: value(3)},
+			{Start: start.Add(2 * time.Minute), Count: 2, Min: value(4.5), Max: value(10.5), Avg: value(7.5), First: value(4.5), Last: value(10.5)},
+		}, buckets)
+	})
+}
+
+func TestEventRepository_GetSensorRollups(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	homeID := int64(3)
+
+	t.Run("ok, rollups follow saved events", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: start.Add(time.Minute), SensorID: 1, Payload: 4}))
+		errs, err := er.SaveEvents(ctx, []domain.Event{
+			{Timestamp: start.Add(30 * time.Minute), SensorID: 1, Payload: 8},
+			{Timestamp: start.Add(10 * time.Second), SensorID: 1, Payload: 2},
+			{Timestamp: start.Add(2 * time.Minute), SensorID: 1, Value: "open"},
+			{Timestamp: start.Add(time.Hour), SensorID: 1, Payload: 1, HomeID: homeID},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []error{nil, nil, nil, nil}, errs)
+
+		rollups, err := er.GetSensorRollups(ctx, 1, domain.HistoryAggregateQuery{
+			Start:  start,
+			End:    start.Add(2 * time.Hour),
+			Bucket: time.Hour,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []domain.HistoryRollup{
+			{SensorID: 1, Resolution: time.Hour, Start: start, Count: 3, Sum: 14, Min: 2, Max: 8,
+				FirstTimestamp: start.Add(10 * time.Second), First: 2, LastTimestamp: start.Add(30 * time.Minute), Last: 8},
+			{SensorID: 1, HomeID: homeID, Resolution: time.Hour, Start: start.Add(time.Hour), Count: 1, Sum: 1, Min: 1, Max: 1,
+				FirstTimestamp: start.Add(time.Hour), First: 1, LastTimestamp: start.Add(time.Hour), Last: 1},
+		}, rollups)
+
+		rollups, err = er.GetSensorRollups(ctx, 1, domain.HistoryAggregateQuery{
+			Start:  start.Truncate(24 * time.Hour),
+			End:    start.Truncate(24 * time.Hour).Add(24 * time.Hour),
+			Bucket: 24 * time.Hour,
+			HomeID: &homeID,
+		})
+		require.NoError(t, err)
+		if assert.Len(t, rollups, 1) {
+			assert.Equal(t, int64(1), rollups[0].Count, "Агрегаты другого дома попали в выборку")
+		}
+
+		require.NoError(t, er.DeleteEventsBySensorID(ctx, 1))
+		require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: start.Add(3 * time.Hour), SensorID: 1}))
+		rollups, err = er.GetSensorRollups(ctx, 1, domain.HistoryAggregateQuery{
+			Start:  start,
+			End:    start.Add(2 * time.Hour),
+			Bucket: time.Hour,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, rollups, "Агрегаты удалённых событий остались")
+	})
+
+	t.Run("ok, rolled back with transaction", func(t *testing.T) {
+		er := NewEventRepository()
+		tr := transaction.NewTransactor()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: start, SensorID: 1, Payload: 1}))
+		err := tr.WithinTransaction(ctx, func(ctx context.Context) error {
+			require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: start.Add(time.Minute), SensorID: 1, Payload: 5}))
+			_, err := er.SaveEvents(ctx, []domain.Event{{Timestamp: start.Add(time.Hour), SensorID: 1, Payload: 7}})
+			require.NoError(t, err)
+			return errors.New("some error")
+		})
+		require.Error(t, err)
+
+		rollups, err := er.GetSensorRollups(ctx, 1, domain.HistoryAggregateQuery{
+			Start:  start,
+			End:    start.Add(2 * time.Hour),
+			Bucket: time.Hour,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []domain.HistoryRollup{
+			{SensorID: 1, Resolution: time.Hour, Start: start, Count: 1, Sum: 1, Min: 1, Max: 1,
+				FirstTimestamp: start, First: 1, LastTimestamp: start, Last: 1},
+		}, rollups)
+	})
+}
+
+func TestEventRepository_DeleteEventsBySensorID(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.ErrorIs(t, er.DeleteEventsBySensorID(ctx, 1), context.Canceled)
+	})
+
+	t.Run("ok, only sensor events deleted", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: time.Now(), SensorID: 1}))
+		require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: time.Now(), SensorID: 2}))
+
+		assert.NoError(t, er.DeleteEventsBySensorID(ctx, 1))
+
+		_, err := er.GetLastEventBySensorID(ctx, 1)
+		assert.ErrorIs(t, err, usecase.ErrEventNotFound)
+		_, err = er.GetLastEventBySensorID(ctx, 2)
+		assert.NoError(t, err)
+	})
+}
+
+func TestEventRepository_DeleteEventsBefore(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := er.DeleteEventsBefore(ctx, 1, start, 10)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, oldest events deleted in batches", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		for _, offset := range []time.Duration{2 * time.Hour, -time.Minute, -3 * time.Hour, -2 * time.Hour} {
+			require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: start.Add(offset), SensorID: 1}))
+		}
+		require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: start.Add(-time.Hour), SensorID: 2}))
+
+		count, err := er.CountEventsBefore(ctx, 1, start)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+
+		deleted, err := er.DeleteEventsBefore(ctx, 1, start, 2)
+		require.NoError(t, err)
+		if assert.Len(t, deleted, 2) {
+			assert.Equal(t, start.Add(-3*time.Hour), deleted[0].Timestamp)
+			assert.Equal(t, start.Add(-2*time.Hour), deleted[1].Timestamp)
+		}
+		deleted, err = er.DeleteEventsBefore(ctx, 1, start, 2)
+		require.NoError(t, err)
+		assert.Len(t, deleted, 1)
+		deleted, err = er.DeleteEventsBefore(ctx, 1, start, 2)
+		require.NoError(t, err)
+		assert.Empty(t, deleted)
+
+		count, err = er.CountEventsBefore(ctx, 2, start)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count, "Удалены события другого датчика")
+		last, err := er.GetLastEventBySensorID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, start.Add(2*time.Hour), last.Timestamp)
+	})
+
+	t.Run("ok, sensor without events remains known", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: start.Add(-time.Hour), SensorID: 1}))
+		_, err := er.DeleteEventsBefore(ctx, 1, start, 10)
+		require.NoError(t, err)
+
+		_, err = er.GetLastEventBySensorID(ctx, 1)
+		assert.ErrorIs(t, err, usecase.ErrEventNotFound)
+		history, err := er.GetSensorHistory(ctx, 1, domain.HistoryQuery{Start: start.Add(-2 * time.Hour), End: start})
+		assert.NoError(t, err)
+		assert.Empty(t, history)
+	})
+
+	t.Run("ok, rolled back with transaction", func(t *testing.T) {
+		er := NewEventRepository()
+		tr := transaction.NewTransactor()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: start.Add(-time.Hour), SensorID: 1, Payload: 1}))
+		err := tr.WithinTransaction(ctx, func(ctx context.Context) error {
+			_, err := er.DeleteEventsBefore(ctx, 1, start, 10)
+			require.NoError(t, err)
+			require.NoError(t, er.DeleteRollupsBefore(ctx, 1, start))
+			return errors.New("some error")
+		})
+		require.Error(t, err)
+
+		count, err := er.CountEventsBefore(ctx, 1, start)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		rollups, err := er.GetSensorRollups(ctx, 1, domain.HistoryAggregateQuery{
+			Start:  start.Add(-time.Hour),
+			End:    start,
+			Bucket: time.Hour,
+		})
+		require.NoError(t, err)
+		assert.Len(t, rollups, 1)
+	})
+}
+
+func TestEventRepository_DeleteRollupsBefore(t *testing.T) {
+	t.Run("ok, only whole expired intervals deleted", func(t *testing.T) {
+		er := NewEventRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: start.Add(-time.Minute), SensorID: 1, Payload: 1}))
+		require.NoError(t, er.SaveEvent(ctx, &domain.Event{Timestamp: start.Add(time.Minute), SensorID: 1, Payload: 2}))
+
+		require.NoError(t, er.DeleteRollupsBefore(ctx, 1, start.Add(30*time.Minute)))
+
+		rollups, err := er.GetSensorRollups(ctx, 1, domain.HistoryAggregateQuery{
+			Start:  start.Add(-time.Hour),
+			End:    start.Add(time.Hour),
+			Bucket: time.Hour,
+		})
+		require.NoError(t, err)
+		if assert.Len(t, rollups, 1) {
+			assert.Equal(t, start, rollups[0].Start)
+		}
+		rollups, err = er.GetSensorRollups(ctx, 1, domain.HistoryAggregateQuery{
+			Start:  start.Truncate(24 * time.Hour),
+			End:    start.Truncate(24 * time.Hour).Add(24 * time.Hour),
+			Bucket: 24 * time.Hour,
+		})
+		require.NoError(t, err)
+		assert.Len(t, rollups, 1, "Удалён агрегат незакончившегося интервала")
+	})
+}
@@ -0,0 +1,63 @@
+package inmemory
+
+import (
+	"cmp"
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// retentionKey - политика однозначно определяется типом датчика или датчиком
+type retentionKey struct {
+	sensorType domain.SensorType
+	sensorID   int64
+}
+
+type RetentionRepository struct {
+	policies map[retentionKey]domain.RetentionPolicy
+	mu       sync.Mutex
+}
+
+func NewRetentionRepository() *RetentionRepository {
+	return &RetentionRepository{
+		policies: make(map[retentionKey]domain.RetentionPolicy),
+	}
+}
+
+func (r *RetentionRepository) SaveRetentionPolicy(ctx context.Context, policy domain.RetentionPolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.policies[retentionKey{sensorType: policy.SensorType, sensorID: policy.SensorID}] = policy
+	return nil
+}
+
+func (r *RetentionRepository) DeleteRetentionPolicy(ctx context.Context, policy domain.RetentionPolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	key := retentionKey{sensorType: policy.SensorType, sensorID: policy.SensorID}
+	if _, ok := r.policies[key]; !ok {
+		return usecase.ErrRetentionPolicyNotFound
+	}
+	delete(r.policies, key)
+	return nil
+}
+
+func (r *RetentionRepository) GetRetentionPolicies(ctx context.Context) ([]domain.RetentionPolicy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slices.SortedFunc(maps.Values(r.policies), func(a, b domain.RetentionPolicy) int {
+		return cmp.Or(cmp.Compare(a.SensorType, b.SensorType), cmp.Compare(a.SensorID, b.SensorID))
+	}), nil
+}
@@ -0,0 +1,47 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetentionRepository(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		rr := NewRetentionRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := rr.SaveRetentionPolicy(ctx, domain.RetentionPolicy{SensorID: 1, Retention: time.Hour})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, policies saved, replaced and deleted", func(t *testing.T) {
+		rr := NewRetentionRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.NoError(t, rr.SaveRetentionPolicy(ctx, domain.RetentionPolicy{SensorID: 2, Retention: time.Hour}))
+		assert.NoError(t, rr.SaveRetentionPolicy(ctx, domain.RetentionPolicy{SensorType: domain.SensorTypeADC, Retention: time.Hour}))
+		assert.NoError(t, rr.SaveRetentionPolicy(ctx, domain.RetentionPolicy{SensorID: 2, Retention: 2 * time.Hour}))
+
+		policies, err := rr.GetRetentionPolicies(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.RetentionPolicy{
+			{SensorID: 2, Retention: 2 * time.Hour},
+			{SensorType: domain.SensorTypeADC, Retention: time.Hour},
+		}, policies)
+
+		assert.NoError(t, rr.DeleteRetentionPolicy(ctx, domain.RetentionPolicy{SensorID: 2}))
+		err = rr.DeleteRetentionPolicy(ctx, domain.RetentionPolicy{SensorID: 2})
+		assert.ErrorIs(t, err, usecase.ErrRetentionPolicyNotFound)
+
+		policies, err = rr.GetRetentionPolicies(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.RetentionPolicy{{SensorType: domain.SensorTypeADC, Retention: time.Hour}}, policies)
+	})
+}
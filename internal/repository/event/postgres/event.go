@@ -2,8 +2,10 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
 	"homework/internal/usecase"
 	"time"
 
@@ -13,34 +15,213 @@ import (
 
 var ErrEventNotFound = errors.New("event not found")
 
+// sequenceLockPollInterval - как часто GetLastSequence проверяет, завершились ли транзакции, выделявшие последовательности
+const sequenceLockPollInterval = 5 * time.Millisecond
+
 type EventRepository struct {
-	pool *pgxpool.Pool
+	pool        *pgxpool.Pool
+	dedupWindow time.Duration
+}
+
+func NewEventRepository(pool *pgxpool.Pool, options ...func(*EventRepository)) *EventRepository {
+	r := &EventRepository{
+		pool:        pool,
+		dedupWindow: usecase.DefaultEventDedupWindow,
+	}
+	for _, o := range options {
+		o(r)
+	}
+	return r
 }
 
-func NewEventRepository(pool *pgxpool.Pool) *EventRepository {
-	return &EventRepository{
-		pool,
+// WithDedupWindow - задаёт, сколько времени после получения события повтор с тем же EventID не сохраняется
+func WithDedupWindow(window time.Duration) func(*EventRepository) {
+	return func(r *EventRepository) {
+		r.dedupWindow = window
 	}
 }
 
 const (
 	saveEventQuery = `
-		INSERT INTO events (timestamp, sensor_serial_number, sensor_id, payload)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO events (timestamp, sensor_serial_number, sensor_id, payload, value, home_id, calibrated_value, unit, received_at, event_id, sequence)
+		VALUES ($1, $2, $3, $4, $5, nullif($6, 0), $7, $8, $9, nullif($10, ''), $11)
+		RETURNING id
+	`
+
+	lockEventIDsQuery = `
+		SELECT pg_advisory_xact_lock(key)
+		FROM (
+			SELECT DISTINCT hashtextextended(event_id, sensor_id) AS key
+			FROM unnest($1::bigint[], $2::text[]) AS k(sensor_id, event_id)
+			ORDER BY key
+		) AS keys
+	`
+
+	findDuplicateEventsQuery = `
+		SELECT DISTINCT e.sensor_id, e.event_id
+		FROM events e
+		JOIN unnest($1::bigint[], $2::text[], $3::timestamp[]) AS k(sensor_id, event_id, since)
+			ON e.sensor_id = k.sensor_id AND e.event_id = k.event_id AND e.received_at > k.since
+	`
+
+	// allocateEventIDsQuery - id событий пакета выделяются заранее, чтобы вернуть их без чтения сохранённых строк
+	allocateEventIDsQuery = `
+		SELECT nextval(pg_get_serial_sequence('events', 'id'))
+		FROM generate_series(1, $1)
+	`
+
+	// lockEventSequencesQuery - разделяемая блокировка до конца транзакции, под которой выделяются последовательности.
+	// Транзакции с событиями друг другу не мешают, а GetLastSequence по ней находит транзакции, которые ещё могут
+	// сохранить события с выделенными последовательностями. Ключ из двух чисел не пересекается с блокировками EventID
+	lockEventSequencesQuery = `SELECT pg_advisory_xact_lock_shared(0, 0)`
+
+	allocateEventSequencesQuery = `
+		SELECT nextval('events_sequence_seq')
+		FROM generate_series(1, $1)
+	`
+
+	// getAllocatedSequenceQuery - последняя выделенная последовательность, 0 - последовательности не выделялись
+	getAllocatedSequenceQuery = `
+		SELECT CASE WHEN is_called THEN last_value ELSE last_value - 1 END
+		FROM events_sequence_seq
+	`
+
+	// getSequenceLockHoldersQuery - другие транзакции, которые держат блокировку выделения последовательностей,
+	// из списка $1, если он задан
+	getSequenceLockHoldersQuery = `
+		SELECT virtualtransaction
+		FROM pg_locks
+		WHERE locktype = 'advisory' AND granted AND pid <> pg_backend_pid()
+			AND database = (SELECT oid FROM pg_database WHERE datname = current_database())
+			AND classid = 0 AND objid = 0 AND objsubid = 2
+			AND ($1::text[] IS NULL OR virtualtransaction = ANY($1))
 	`
 
 	getLastEventQuery = `
-		SELECT timestamp, sensor_serial_number, sensor_id, payload
+		SELECT id, timestamp, sensor_serial_number, sensor_id, payload, value, coalesce(home_id, 0), calibrated_value, unit, received_at, coalesce(event_id, ''), sequence
 		FROM events
 		WHERE sensor_id = $1
-		ORDER BY timestamp DESC
+		ORDER BY timestamp DESC, id DESC
 		LIMIT 1
 	`
 
 	getSensorHistoryQuery = `
-		SELECT timestamp, sensor_serial_number, sensor_id, payload
+		SELECT id, timestamp, sensor_serial_number, sensor_id, payload, value, coalesce(home_id, 0), calibrated_value, unit, received_at, coalesce(event_id, ''), sequence
+		FROM events
+		WHERE sensor_id = $1 AND timestamp BETWEEN $2 AND $3
+			AND ($4::bigint IS NULL OR coalesce(home_id, 0) = $4)
+			AND ($5::timestamp IS NULL OR (timestamp, id) > ($5, $6::bigint))
+		ORDER BY timestamp, id
+		LIMIT $7
+	`
+
+	getSensorHistoryDescQuery = `
+		SELECT id, timestamp, sensor_serial_number, sensor_id, payload, value, coalesce(home_id, 0), calibrated_value, unit, received_at, coalesce(event_id, ''), sequence
 		FROM events
-		WHERE sensor_id = $1 AND timestamp BETWEEN $2 AND $3 
+		WHERE sensor_id = $1 AND timestamp BETWEEN $2 AND $3
+			AND ($4::bigint IS NULL OR coalesce(home_id, 0) = $4)
+			AND ($5::timestamp IS NULL OR (timestamp, id) < ($5, $6::bigint))
+		ORDER BY timestamp DESC, id DESC
+		LIMIT $7
+	`
+
+	replaySensorEventsQuery = `
+		SELECT id, timestamp, sensor_serial_number, sensor_id, payload, value, coalesce(home_id, 0), calibrated_value, unit, received_at, coalesce(event_id, ''), sequence
+		FROM events
+		WHERE sensor_id = $1 AND sequence > $2 AND ($6::bigint = 0 OR sequence <= $6)
+			AND ($3::timestamp IS NULL OR timestamp >= $3)
+			AND ($4::bigint IS NULL OR coalesce(home_id, 0) = $4)
+		ORDER BY sequence
+		LIMIT $5
+	`
+
+	// declareHistoryExportQuery - серверный курсор по запросу истории, выборка читается из него порциями
+	declareHistoryExportQuery = `DECLARE history_export NO SCROLL CURSOR FOR `
+
+	fetchHistoryExportQuery = `FETCH 1000 FROM history_export`
+
+	closeHistoryExportQuery = `CLOSE history_export`
+
+	// getSensorHistoryAggregatesQuery - числовое значение события выбирается как в domain.Event.NumericValue,
+	// интервалы выравниваются по началу суток
+	getSensorHistoryAggregatesQuery = `
+		SELECT date_bin($4::bigint * interval '1 microsecond', timestamp, timestamp '2000-01-01') AS bucket,
+			count(*), min(value), max(value), avg(value),
+			(array_agg(value ORDER BY timestamp, id))[1],
+			(array_agg(value ORDER BY timestamp DESC, id DESC))[1]
+		FROM (
+			SELECT timestamp, id, coalesce(calibrated_value, CASE
+				WHEN value IS NULL OR jsonb_typeof(value) IN ('null', 'boolean') THEN payload
+				WHEN jsonb_typeof(value) = 'number' THEN (value #>> '{}')::double precision
+			END) AS value
+			FROM events
+			WHERE sensor_id = $1 AND timestamp BETWEEN $2 AND $3
+				AND ($5::bigint IS NULL OR coalesce(home_id, 0) = $5)
+		) e
+		WHERE value IS NOT NULL
+		GROUP BY bucket
+		ORDER BY bucket
+	`
+
+	// saveRollupsQuery - добавляет агрегаты сохранённых событий к накопленным. Длительность интервала хранится в секундах
+	saveRollupsQuery = `
+		INSERT INTO event_rollups (sensor_id, resolution, bucket, home_id, count, sum, min, max,
+			first_timestamp, first_value, last_timestamp, last_value)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (sensor_id, resolution, bucket, home_id) DO UPDATE SET
+			count = event_rollups.count + excluded.count,
+			sum = event_rollups.sum + excluded.sum,
+			min = least(event_rollups.min, excluded.min),
+			max = greatest(event_rollups.max, excluded.max),
+			first_timestamp = CASE WHEN excluded.first_timestamp < event_rollups.first_timestamp
+				THEN excluded.first_timestamp ELSE event_rollups.first_timestamp END,
+			first_value = CASE WHEN excluded.first_timestamp < event_rollups.first_timestamp
+				THEN excluded.first_value ELSE event_rollups.first_value END,
+			last_timestamp = CASE WHEN excluded.last_timestamp >= event_rollups.last_timestamp
+				THEN excluded.last_timestamp ELSE event_rollups.last_timestamp END,
+			last_value = CASE WHEN excluded.last_timestamp >= event_rollups.last_timestamp
+				THEN excluded.last_value ELSE event_rollups.last_value END
+	`
+
+	getSensorRollupsQuery = `
+		SELECT sensor_id, home_id, bucket, count, sum, min, max, first_timestamp, first_value, last_timestamp, last_value
+		FROM event_rollups
+		WHERE sensor_id = $1 AND resolution = $2 AND bucket >= $3 AND bucket < $4
+			AND ($5::bigint IS NULL OR home_id = $5)
+		ORDER BY bucket, home_id
+	`
+
+	deleteEventsBySensorIDQuery = `
+		DELETE FROM events
+		WHERE sensor_id = $1
+	`
+
+	countEventsBeforeQuery = `
+		SELECT count(*)
+		FROM events
+		WHERE sensor_id = $1 AND timestamp < $2
+	`
+
+	deleteEventsBeforeQuery = `
+		DELETE FROM events
+		WHERE id IN (
+			SELECT id
+			FROM events
+			WHERE sensor_id = $1 AND timestamp < $2
+			ORDER BY timestamp, id
+			LIMIT $3
+		)
+		RETURNING id, timestamp, sensor_serial_number, sensor_id, payload, value, coalesce(home_id, 0), calibrated_value, unit, received_at, coalesce(event_id, ''), sequence
+	`
+
+	deleteRollupsBeforeQuery = `
+		DELETE FROM event_rollups
+		WHERE sensor_id = $1 AND bucket + resolution * interval '1 second' <= $2
+	`
+
+	deleteRollupsBySensorIDQuery = `
+		DELETE FROM event_rollups
+		WHERE sensor_id = $1
 	`
 
 	checkSensorExistsQuery = `
@@ -52,22 +233,217 @@ const (
 	`
 )
 
+// SaveEvent - сохраняет событие в транзакции вместе с накопленными агрегатами.
+// Событие с EventID сохраняется в транзакции вместе с поиском повтора
 func (r *EventRepository) SaveEvent(ctx context.Context, event *domain.Event) error {
-	_, err := r.pool.Exec(
-		ctx,
-		saveEventQuery,
-		event.Timestamp,
-		event.SensorSerialNumber,
-		event.SensorID,
-		event.Payload,
-	)
-	return err
+	if event.EventID != "" {
+		events := []domain.Event{*event}
+		errs, err := r.SaveEvents(ctx, events)
+		if err != nil {
+			return err
+		}
+		event.ID, event.Sequence = events[0].ID, events[0].Sequence
+		return errs[0]
+	}
+	value, err := marshalJSON(event.Value)
+	if err != nil {
+		return err
+	}
+	return pgx.BeginFunc(ctx, transaction.Conn(ctx, r.pool), func(tx pgx.Tx) error {
+		sequences, err := allocateEventSequences(ctx, tx, 1)
+		if err != nil {
+			return err
+		}
+		event.Sequence = sequences[0]
+		err = tx.QueryRow(
+			ctx,
+			saveEventQuery,
+			event.Timestamp,
+			event.SensorSerialNumber,
+			event.SensorID,
+			event.Payload,
+			value,
+			event.HomeID,
+			event.CalibratedValue,
+			event.Unit,
+			event.ReceivedAt,
+			event.EventID,
+			event.Sequence,
+		).Scan(&event.ID)
+		if err != nil {
+			return err
+		}
+		return saveRollups(ctx, tx, []domain.Event{*event})
+	})
+}
+
+// eventColumns - столбцы events, которые заполняются при пакетном сохранении
+var eventColumns = []string{"id", "timestamp", "sensor_serial_number", "sensor_id", "payload", "value", "home_id", "calibrated_value", "unit", "received_at", "event_id", "sequence"}
+
+// eventKey - событие с EventID однозначно определяется датчиком и EventID
+type eventKey struct {
+	sensorID int64
+	eventID  string
+}
+
+// SaveEvents - сохраняет пакет событий одной командой COPY, поэтому пакет сохраняется целиком или не сохраняется вовсе.
+// На время поиска повторов берутся advisory-блокировки по EventID, чтобы одновременные повторы не сохранились оба.
+// Сохранённым событиям пакета проставляются id и последовательности
+func (r *EventRepository) SaveEvents(ctx context.Context, events []domain.Event) ([]error, error) {
+	errs := make([]error, len(events))
+	err := pgx.BeginFunc(ctx, transaction.Conn(ctx, r.pool), func(tx pgx.Tx) error {
+		duplicates, err := r.findDuplicates(ctx, tx, events)
+		if err != nil {
+			return err
+		}
+		count := 0
+		for i, event := range events {
+			if event.EventID != "" {
+				key := eventKey{sensorID: event.SensorID, eventID: event.EventID}
+				if duplicates[key] {
+					errs[i] = usecase.ErrEventDuplicate
+					continue
+				}
+				duplicates[key] = true
+			}
+			count++
+		}
+		if count == 0 {
+			return nil
+		}
+		ids, err := allocateEventIDs(ctx, tx, count)
+		if err != nil {
+			return err
+		}
+		sequences, err := allocateEventSequences(ctx, tx, count)
+		if err != nil {
+			return err
+		}
+		rows := make([][]any, 0, count)
+		for i := range events {
+			if errs[i] != nil {
+				continue
+			}
+			events[i].ID = ids[len(rows)]
+			events[i].Sequence = sequences[len(rows)]
+			row, err := eventRow(events[i])
+			if err != nil {
+				return err
+			}
+			rows = append(rows, row)
+		}
+		if _, err = tx.CopyFrom(ctx, pgx.Identifier{"events"}, eventColumns, pgx.CopyFromRows(rows)); err != nil {
+			return err
+		}
+		saved := make([]domain.Event, 0, len(rows))
+		for i, event := range events {
+			if errs[i] == nil {
+				saved = append(saved, event)
+			}
+		}
+		return saveRollups(ctx, tx, saved)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+// allocateEventIDs - выделяет count id событий из последовательности таблицы events
+func allocateEventIDs(ctx context.Context, tx pgx.Tx, count int) ([]int64, error) {
+	rows, err := tx.Query(ctx, allocateEventIDsQuery, count)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+// allocateEventSequences - выделяет последовательности событий пакета под блокировкой lockEventSequencesQuery
+func allocateEventSequences(ctx context.Context, tx pgx.Tx, count int) ([]int64, error) {
+	if _, err := tx.Exec(ctx, lockEventSequencesQuery); err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx, allocateEventSequencesQuery, count)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+// saveRollups - добавляет события к накопленным агрегатам. Агрегаты пакета сначала складываются по интервалам,
+// поэтому на каждый интервал приходится один запрос
+func saveRollups(ctx context.Context, tx pgx.Tx, events []domain.Event) error {
+	rollups := domain.RollupEvents(events)
+	if len(rollups) == 0 {
+		return nil
+	}
+	batch := &pgx.Batch{}
+	for _, rollup := range rollups {
+		batch.Queue(saveRollupsQuery, rollup.SensorID, int64(rollup.Resolution.Seconds()), rollup.Start, rollup.HomeID,
+			rollup.Count, rollup.Sum, rollup.Min, rollup.Max, rollup.FirstTimestamp, rollup.First, rollup.LastTimestamp,
+			rollup.Last)
+	}
+	return tx.SendBatch(ctx, batch).Close()
+}
+
+// findDuplicates - блокирует EventID пакета до конца транзакции и возвращает те из них,
+// что уже сохранены в пределах окна дедупликации
+func (r *EventRepository) findDuplicates(ctx context.Context, tx pgx.Tx, events []domain.Event) (map[eventKey]bool, error) {
+	duplicates := make(map[eventKey]bool)
+	var sensorIDs []int64
+	var eventIDs []string
+	var since []time.Time
+	for _, event := range events {
+		if event.EventID == "" {
+			continue
+		}
+		sensorIDs = append(sensorIDs, event.SensorID)
+		eventIDs = append(eventIDs, event.EventID)
+		since = append(since, event.ReceivedAt.Add(-r.dedupWindow))
+	}
+	if len(eventIDs) == 0 {
+		return duplicates, nil
+	}
+	if _, err := tx.Exec(ctx, lockEventIDsQuery, sensorIDs, eventIDs); err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx, findDuplicateEventsQuery, sensorIDs, eventIDs, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key eventKey
+		if err := rows.Scan(&key.sensorID, &key.eventID); err != nil {
+			return nil, err
+		}
+		duplicates[key] = true
+	}
+	return duplicates, rows.Err()
+}
+
+// eventRow - значения столбцов eventColumns для события
+func eventRow(event domain.Event) ([]any, error) {
+	value, err := marshalJSON(event.Value)
+	if err != nil {
+		return nil, err
+	}
+	var homeID *int64
+	if event.HomeID != 0 {
+		homeID = &event.HomeID
+	}
+	var eventID *string
+	if event.EventID != "" {
+		eventID = &event.EventID
+	}
+	return []any{event.ID, event.Timestamp, event.SensorSerialNumber, event.SensorID, event.Payload, value, homeID,
+		event.CalibratedValue, event.Unit, event.ReceivedAt, eventID, event.Sequence}, nil
 }
 
 func (r *EventRepository) GetLastEventBySensorID(ctx context.Context, id int64) (*domain.Event, error) {
-	row := r.pool.QueryRow(ctx, getLastEventQuery, id)
+	row := transaction.Conn(ctx, r.pool).QueryRow(ctx, getLastEventQuery, id)
 	event := &domain.Event{}
-	if err := row.Scan(&event.Timestamp, &event.SensorSerialNumber, &event.SensorID, &event.Payload); err != nil {
+	if err := scanEvent(row, event); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrEventNotFound
 		}
@@ -76,16 +452,32 @@ func (r *EventRepository) GetLastEventBySensorID(ctx context.Context, id int64)
 	return event, nil
 }
 
-func (r *EventRepository) GetSensorHistory(ctx context.Context, id int64, start, end time.Time) ([]domain.Event, error) {
+// GetSensorHistory - возвращает события датчика по параметрам выборки в порядке времени и id
+func (r *EventRepository) GetSensorHistory(ctx context.Context, id int64, query domain.HistoryQuery) ([]domain.Event, error) {
 	var check bool
-	err := r.pool.QueryRow(ctx, checkSensorExistsQuery, id).Scan(&check)
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, checkSensorExistsQuery, id).Scan(&check)
 	if err != nil {
 		return nil, err
 	}
 	if !check {
 		return nil, usecase.ErrSensorNotFound
 	}
-	rows, err := r.pool.Query(ctx, getSensorHistoryQuery, id, start, end)
+	historyQuery := getSensorHistoryQuery
+	if query.Descending {
+		historyQuery = getSensorHistoryDescQuery
+	}
+	var afterTimestamp *time.Time
+	var afterID *int64
+	if query.After != nil {
+		afterTimestamp = &query.After.Timestamp
+		afterID = &query.After.ID
+	}
+	var limit *int
+	if query.Limit > 0 {
+		limit = &query.Limit
+	}
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, historyQuery, id, query.Start, query.End, query.HomeID,
+		afterTimestamp, afterID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -93,12 +485,259 @@ func (r *EventRepository) GetSensorHistory(ctx context.Context, id int64, start,
 	defer rows.Close()
 	for rows.Next() {
 		var event domain.Event
-		err := rows.Scan(&event.Timestamp, &event.SensorSerialNumber, &event.SensorID, &event.Payload)
+		err := scanEvent(rows, &event)
 		if err != nil {
 			return nil, err
 		}
 		events = append(events, event)
 	}
 
-	return events, nil
+	return events, rows.Err()
+}
+
+// ReplaySensorEvents - возвращает события датчика для дозагрузки потока в порядке последовательности.
+// Для несуществующего датчика возвращается пустой список
+func (r *EventRepository) ReplaySensorEvents(ctx context.Context, id int64, query domain.ReplayQuery) ([]domain.Event, error) {
+	var since *time.Time
+	if !query.Since.IsZero() {
+		since = &query.Since
+	}
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, replaySensorEventsQuery, id, query.AfterSequence, since,
+		query.HomeID, query.Limit, query.UntilSequence)
+	if err != nil {
+		return nil, err
+	}
+	var events []domain.Event
+	defer rows.Close()
+	for rows.Next() {
+		var event domain.Event
+		if err := scanEvent(rows, &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// GetLastSequence - возвращает последовательность, до которой включительно все события уже видны в хранилище.
+// Последовательности выделяются без общей блокировки и становятся видны не в порядке выделения, поэтому сначала
+// читается последняя выделенная последовательность, а затем ожидаются транзакции, которые держали блокировку
+// выделения в этот момент: только они могут ещё сохранить события с ней или меньшими. Транзакции, начавшие
+// выделять последовательности позже, получат большие, и их GetLastSequence не ждёт
+func (r *EventRepository) GetLastSequence(ctx context.Context) (int64, error) {
+	conn := transaction.Conn(ctx, r.pool)
+	var sequence int64
+	if err := conn.QueryRow(ctx, getAllocatedSequenceQuery).Scan(&sequence); err != nil {
+		return 0, err
+	}
+	var holders []string
+	for {
+		rows, err := conn.Query(ctx, getSequenceLockHoldersQuery, holders)
+		if err != nil {
+			return 0, err
+		}
+		holders, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return 0, err
+		}
+		if len(holders) == 0 {
+			return sequence, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(sequenceLockPollInterval):
+		}
+	}
+}
+
+// StreamSensorHistory - передаёт события датчика в fn по мере чтения из серверного курсора, не накапливая выборку в памяти.
+// Курсор живёт в транзакции, поэтому выгрузка видит события на момент своего начала
+func (r *EventRepository) StreamSensorHistory(ctx context.Context, id int64, query domain.HistoryQuery, fn func(domain.Event) error) error {
+	var check bool
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, checkSensorExistsQuery, id).Scan(&check)
+	if err != nil {
+		return err
+	}
+	if !check {
+		return usecase.ErrSensorNotFound
+	}
+	historyQuery := getSensorHistoryQuery
+	if query.Descending {
+		historyQuery = getSensorHistoryDescQuery
+	}
+	var limit *int
+	if query.Limit > 0 {
+		limit = &query.Limit
+	}
+	return pgx.BeginFunc(ctx, transaction.Conn(ctx, r.pool), func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, declareHistoryExportQuery+historyQuery, id, query.Start, query.End, query.HomeID,
+			nil, nil, limit)
+		if err != nil {
+			return err
+		}
+		for {
+			fetched, err := fetchHistory(ctx, tx, fn)
+			if err != nil {
+				return err
+			}
+			if fetched == 0 {
+				break
+			}
+		}
+		_, err = tx.Exec(ctx, closeHistoryExportQuery)
+		return err
+	})
+}
+
+// fetchHistory - читает из курсора выгрузки очередную порцию событий и возвращает их число
+func fetchHistory(ctx context.Context, tx pgx.Tx, fn func(domain.Event) error) (int, error) {
+	rows, err := tx.Query(ctx, fetchHistoryExportQuery)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var fetched int
+	for rows.Next() {
+		var event domain.Event
+		if err := scanEvent(rows, &event); err != nil {
+			return fetched, err
+		}
+		fetched++
+		if err := fn(event); err != nil {
+			return fetched, err
+		}
+	}
+	return fetched, rows.Err()
+}
+
+// GetSensorHistoryAggregates - считает агрегаты по интервалам в базе, возвращаются только интервалы с событиями
+func (r *EventRepository) GetSensorHistoryAggregates(ctx context.Context, id int64, query domain.HistoryAggregateQuery) ([]domain.HistoryBucket, error) {
+	var check bool
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, checkSensorExistsQuery, id).Scan(&check)
+	if err != nil {
+		return nil, err
+	}
+	if !check {
+		return nil, usecase.ErrSensorNotFound
+	}
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, getSensorHistoryAggregatesQuery, id, query.Start, query.End,
+		query.Bucket.Microseconds(), query.HomeID)
+	if err != nil {
+		return nil, err
+	}
+	var buckets []domain.HistoryBucket
+	defer rows.Close()
+	for rows.Next() {
+		var bucket domain.HistoryBucket
+		err := rows.Scan(&bucket.Start, &bucket.Count, &bucket.Min, &bucket.Max, &bucket.Avg, &bucket.First, &bucket.Last)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+// GetSensorRollups - возвращает накопленные агрегаты датчика, упорядоченные по началу интервала и дому
+func (r *EventRepository) GetSensorRollups(ctx context.Context, id int64, query domain.HistoryAggregateQuery) ([]domain.HistoryRollup, error) {
+	var check bool
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, checkSensorExistsQuery, id).Scan(&check)
+	if err != nil {
+		return nil, err
+	}
+	if !check {
+		return nil, usecase.ErrSensorNotFound
+	}
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, getSensorRollupsQuery, id, int64(query.Bucket.Seconds()),
+		query.Start, query.End, query.HomeID)
+	if err != nil {
+		return nil, err
+	}
+	var rollups []domain.HistoryRollup
+	defer rows.Close()
+	for rows.Next() {
+		rollup := domain.HistoryRollup{Resolution: query.Bucket}
+		err := rows.Scan(&rollup.SensorID, &rollup.HomeID, &rollup.Start, &rollup.Count, &rollup.Sum, &rollup.Min,
+			&rollup.Max, &rollup.FirstTimestamp, &rollup.First, &rollup.LastTimestamp, &rollup.Last)
+		if err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, rollup)
+	}
+
+	return rollups, rows.Err()
+}
+
+// DeleteEventsBySensorID - удаляет события датчика вместе с его накопленными агрегатами
+func (r *EventRepository) DeleteEventsBySensorID(ctx context.Context, id int64) error {
+	return pgx.BeginFunc(ctx, transaction.Conn(ctx, r.pool), func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, deleteEventsBySensorIDQuery, id); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, deleteRollupsBySensorIDQuery, id)
+		return err
+	})
+}
+
+// CountEventsBefore - считает события датчика, время которых раньше before
+func (r *EventRepository) CountEventsBefore(ctx context.Context, id int64, before time.Time) (int64, error) {
+	var count int64
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, countEventsBeforeQuery, id, before).Scan(&count)
+	return count, err
+}
+
+// DeleteEventsBefore - удаляет самые старые события датчика одним запросом и возвращает их
+func (r *EventRepository) DeleteEventsBefore(ctx context.Context, id int64, before time.Time, limit int) ([]domain.Event, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, deleteEventsBeforeQuery, id, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	var events []domain.Event
+	defer rows.Close()
+	for rows.Next() {
+		var event domain.Event
+		if err := scanEvent(rows, &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// DeleteRollupsBefore - удаляет накопленные агрегаты датчика за интервалы, закончившиеся не позже before
+func (r *EventRepository) DeleteRollupsBefore(ctx context.Context, id int64, before time.Time) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, deleteRollupsBeforeQuery, id, before)
+	return err
+}
+
+func scanEvent(row pgx.Row, event *domain.Event) error {
+	var value []byte
+	err := row.Scan(&event.ID, &event.Timestamp, &event.SensorSerialNumber, &event.SensorID, &event.Payload, &value, &event.HomeID,
+		&event.CalibratedValue, &event.Unit, &event.ReceivedAt, &event.EventID, &event.Sequence)
+	if err != nil {
+		return err
+	}
+	event.Value, err = unmarshalJSON(value)
+	return err
+}
+
+// marshalJSON - значение события хранится в jsonb, отсутствующее значение - NULL
+func marshalJSON(value any) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return json.Marshal(value)
+}
+
+// unmarshalJSON - числа из jsonb читаются как float64, в том числе целые значения каналов
+func unmarshalJSON(data []byte) (any, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var value any
+	err := json.Unmarshal(data, &value)
+	return value, err
 }
@@ -3,6 +3,8 @@ package postgres
 import (
 	"context"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
 	"homework/pkg/pg_test"
 	"testing"
 	"time"
@@ -31,6 +33,15 @@ func (suite *EventTestSuite) TearDownSuite() {
 	suite.testDB.TearDown()
 }
 
+// createSensor - добавляет датчик, без которого история событий не выбирается
+func (suite *EventTestSuite) createSensor(ctx context.Context, serialNumber string) int64 {
+	var id int64
+	err := suite.testDbInstance.QueryRow(ctx, "INSERT INTO sensors (serial_number, type) VALUES ($1, 'adc') RETURNING id",
+		serialNumber).Scan(&id)
+	suite.Require().NoError(err)
+	return id
+}
+
 func (suite *EventTestSuite) TestEventRepository_SaveEvent() {
 	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second) //nolint: govet // test stub
 
@@ -73,6 +84,440 @@ func (suite *EventTestSuite) TestEventRepository_GetLastEventBySensorID() {
 	assert.Equal(suite.T(), secondEvent, *event)
 }
 
+func (suite *EventTestSuite) TestEventRepository_TypedValue() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	values := []any{21.4, true, "open", map[string]any{"temp": 21.4, "rh": 40.5}}
+	for i, value := range values {
+		event := domain.Event{
+			Timestamp:          time.Now().Truncate(time.Microsecond).Add(time.Duration(i) * time.Second).In(time.UTC),
+			SensorSerialNumber: "4567890123",
+			SensorID:           4,
+			Value:              value,
+		}
+		assert.Nil(suite.T(), suite.repo.SaveEvent(ctx, &event))
+
+		got, err := suite.repo.GetLastEventBySensorID(ctx, 4)
+		assert.Nil(suite.T(), err)
+		assert.Equal(suite.T(), event, *got)
+	}
+}
+
+func (suite *EventTestSuite) TestEventRepository_CalibratedValue() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	calibratedValue := 1.65
+	event := domain.Event{
+		Timestamp:          time.Now().Truncate(time.Microsecond).In(time.UTC),
+		SensorSerialNumber: "4567890124",
+		SensorID:           5,
+		Payload:            2048,
+		CalibratedValue:    &calibratedValue,
+		Unit:               "V",
+	}
+	assert.Nil(suite.T(), suite.repo.SaveEvent(ctx, &event))
+
+	got, err := suite.repo.GetLastEventBySensorID(ctx, 5)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), event, *got)
+}
+
+func (suite *EventTestSuite) TestEventRepository_ReceivedAt() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	receivedAt := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	event := domain.Event{
+		Timestamp:          receivedAt.Add(-time.Hour),
+		ReceivedAt:         receivedAt,
+		SensorSerialNumber: "4567890125",
+		SensorID:           6,
+		Payload:            1,
+	}
+	assert.Nil(suite.T(), suite.repo.SaveEvent(ctx, &event))
+
+	got, err := suite.repo.GetLastEventBySensorID(ctx, 6)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), event, *got)
+}
+
+func (suite *EventTestSuite) TestEventRepository_SaveEvents() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	calibratedValue := 2.5
+	events := []domain.Event{
+		{Timestamp: now, ReceivedAt: now, SensorSerialNumber: "4567890126", SensorID: 7, Payload: 1, HomeID: 3},
+		{Timestamp: now.Add(time.Second), ReceivedAt: now, SensorSerialNumber: "4567890127", SensorID: 8, Value: 21.5,
+			CalibratedValue: &calibratedValue, Unit: "V"},
+	}
+	errs, err := suite.repo.SaveEvents(ctx, events)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []error{nil, nil}, errs)
+
+	for _, event := range events {
+		got, err := suite.repo.GetLastEventBySensorID(ctx, event.SensorID)
+		assert.Nil(suite.T(), err)
+		assert.NotZero(suite.T(), got.ID)
+		event.ID = got.ID
+		assert.Equal(suite.T(), event, *got)
+	}
+}
+
+func (suite *EventTestSuite) TestEventRepository_GetSensorHistoryPagination() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	homeID := int64(4)
+	sensorID := suite.createSensor(ctx, "4567890129")
+	for i, ts := range []time.Time{now, now.Add(time.Second), now.Add(time.Second), now.Add(2 * time.Second)} {
+		event := domain.Event{Timestamp: ts, SensorSerialNumber: "4567890129", SensorID: sensorID, Payload: int64(i)}
+		if i != 0 {
+			event.HomeID = homeID
+		}
+		assert.Nil(suite.T(), suite.repo.SaveEvent(ctx, &event))
+	}
+
+	payloads := func(events []domain.Event) []int64 {
+		result := make([]int64, 0, len(events))
+		for _, event := range events {
+			result = append(result, event.Payload)
+		}
+		return result
+	}
+
+	query := domain.HistoryQuery{Start: now, End: now.Add(2 * time.Second), Limit: 2}
+	first, err := suite.repo.GetSensorHistory(ctx, sensorID, query)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int64{0, 1}, payloads(first))
+
+	query.After = &domain.HistoryCursor{Timestamp: first[1].Timestamp, ID: first[1].ID}
+	second, err := suite.repo.GetSensorHistory(ctx, sensorID, query)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int64{2, 3}, payloads(second))
+
+	desc, err := suite.repo.GetSensorHistory(ctx, sensorID, domain.HistoryQuery{
+		Start:      now,
+		End:        now.Add(2 * time.Second),
+		Descending: true,
+		HomeID:     &homeID,
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int64{3, 2, 1}, payloads(desc))
+}
+
+func (suite *EventTestSuite) TestEventRepository_ReplaySensorEvents() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	homeID := int64(4)
+	sensorID := suite.createSensor(ctx, "4567890130")
+	events := make([]domain.Event, 4)
+	for i := range events {
+		events[i] = domain.Event{Timestamp: now.Add(-time.Duration(i) * time.Second), SensorSerialNumber: "4567890130",
+			SensorID: sensorID, Payload: int64(i)}
+		if i != 0 {
+			events[i].HomeID = homeID
+		}
+	}
+	_, err := suite.repo.SaveEvents(ctx, events)
+	assert.Nil(suite.T(), err)
+	single := domain.Event{Timestamp: now, SensorSerialNumber: "4567890130", SensorID: sensorID, Payload: 4}
+	assert.Nil(suite.T(), suite.repo.SaveEvent(ctx, &single))
+	assert.Greater(suite.T(), single.Sequence, events[3].Sequence, "Последовательности событий не возрастают")
+
+	sequences := func(events []domain.Event) []int64 {
+		result := make([]int64, 0, len(events))
+		for _, event := range events {
+			result = append(result, event.Sequence)
+		}
+		return result
+	}
+
+	replayed, err := suite.repo.ReplaySensorEvents(ctx, sensorID, domain.ReplayQuery{
+		AfterSequence: events[0].Sequence,
+		HomeID:        &homeID,
+		Limit:         2,
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int64{events[1].Sequence, events[2].Sequence}, sequences(replayed))
+
+	replayed, err = suite.repo.ReplaySensorEvents(ctx, sensorID, domain.ReplayQuery{Since: now.Add(-time.Second), Limit: 10})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int64{events[0].Sequence, events[1].Sequence, single.Sequence}, sequences(replayed))
+
+	replayed, err = suite.repo.ReplaySensorEvents(ctx, sensorID, domain.ReplayQuery{UntilSequence: events[1].Sequence, Limit: 10})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int64{events[0].Sequence, events[1].Sequence}, sequences(replayed))
+
+	last, err := suite.repo.GetLastSequence(ctx)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), single.Sequence, last)
+}
+
+func (suite *EventTestSuite) TestEventRepository_GetLastSequence_WaitsForUncommittedEvents() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sensorID := suite.createSensor(ctx, "4567890131")
+	saved := make(chan int64, 1)
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- transaction.NewTransactor(suite.testDbInstance).WithinTransaction(ctx, func(ctx context.Context) error {
+			event := domain.Event{Timestamp: time.Now(), SensorSerialNumber: "4567890131", SensorID: sensorID, Payload: 1}
+			if err := suite.repo.SaveEvent(ctx, &event); err != nil {
+				return err
+			}
+			saved <- event.Sequence
+			<-release
+			return nil
+		})
+	}()
+	var uncommitted int64
+	select {
+	case uncommitted = <-saved:
+	case err := <-done:
+		suite.Require().NoError(err)
+	}
+
+	later := domain.Event{Timestamp: time.Now(), SensorSerialNumber: "4567890131", SensorID: sensorID, Payload: 2}
+	suite.Require().NoError(suite.repo.SaveEvent(ctx, &later), "Незафиксированная транзакция остановила приём событий")
+	assert.Greater(suite.T(), later.Sequence, uncommitted)
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer waitCancel()
+	_, err := suite.repo.GetLastSequence(waitCtx)
+	assert.ErrorIs(suite.T(), err, context.DeadlineExceeded, "Отдана последовательность незафиксированного события")
+
+	close(release)
+	suite.Require().NoError(<-done)
+	last, err := suite.repo.GetLastSequence(ctx)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), later.Sequence, last)
+}
+
+func (suite *EventTestSuite) TestEventRepository_SaveEvents_Duplicates() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	event := domain.Event{Timestamp: now, ReceivedAt: now, SensorSerialNumber: "4567890128", SensorID: 9, Payload: 1, EventID: "retry"}
+	assert.Nil(suite.T(), suite.repo.SaveEvent(ctx, &event))
+
+	retry := event
+	retry.Payload = 2
+	retry.ReceivedAt = now.Add(time.Minute)
+	assert.ErrorIs(suite.T(), suite.repo.SaveEvent(ctx, &retry), usecase.ErrEventDuplicate)
+
+	errs, err := suite.repo.SaveEvents(ctx, []domain.Event{
+		retry,
+		{Timestamp: now.Add(time.Second), ReceivedAt: now, SensorSerialNumber: "4567890128", SensorID: 9, Payload: 3, EventID: "next"},
+		{Timestamp: now.Add(time.Second), ReceivedAt: now, SensorSerialNumber: "4567890128", SensorID: 9, Payload: 4, EventID: "next"},
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []error{usecase.ErrEventDuplicate, nil, usecase.ErrEventDuplicate}, errs)
+
+	got, err := suite.repo.GetLastEventBySensorID(ctx, 9)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(3), got.Payload)
+	assert.Equal(suite.T(), "next", got.EventID)
+
+	late := event
+	late.ReceivedAt = now.Add(usecase.DefaultEventDedupWindow + time.Minute)
+	assert.Nil(suite.T(), suite.repo.SaveEvent(ctx, &late), "Повтор после окна дедупликации должен сохраниться")
+}
+
+func (suite *EventTestSuite) TestEventRepository_GetSensorHistoryAggregates() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	calibrated := 10.5
+	sensorID := suite.createSensor(ctx, "4567890130")
+	for _, event := range []domain.Event{
+		{Timestamp: start.Add(50 * time.Second), Payload: 3},
+		{Timestamp: start.Add(10 * time.Second), Payload: 1},
+		{Timestamp: start.Add(20 * time.Second), Value: "open"},
+		{Timestamp: start.Add(30 * time.Second), Payload: 2},
+		{Timestamp: start.Add(2*time.Minute + time.Second), Value: 4.5},
+		{Timestamp: start.Add(2*time.Minute + 2*time.Second), Payload: 100, CalibratedValue: &calibrated},
+		{Timestamp: start.Add(time.Hour), Payload: 7},
+	} {
+		event.SensorSerialNumber = "4567890130"
+		event.SensorID = sensorID
+		assert.Nil(suite.T(), suite.repo.SaveEvent(ctx, &event))
+	}
+
+	buckets, err := suite.repo.GetSensorHistoryAggregates(ctx, sensorID, domain.HistoryAggregateQuery{
+		Start:  start,
+		End:    start.Add(5 * time.Minute),
+		Bucket: time.Minute,
+	})
+	assert.Nil(suite.T(), err)
+	value := func(v float64) *float64 { return &v }
+	assert.Equal(suite.T(), []domain.HistoryBucket{
+		{Start: start, Count: 3, Min: value(1), Max: value(3), Avg: value(2), First: value(1), Last: value(3)},
+		{Start: start.Add(2 * time.Minute), Count: 2, Min: value(4.5), Max: value(10.5), Avg: value(7.5), First: value(4.5), Last: value(10.5)},
+	}, buckets)
+}
+
+func (suite *EventTestSuite) TestEventRepository_GetSensorRollups() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	sensorID := suite.createSensor(ctx, "4567890131")
+	assert.Nil(suite.T(), suite.repo.SaveEvent(ctx, &domain.Event{Timestamp: start.Add(time.Minute),
+		SensorSerialNumber: "4567890131", SensorID: sensorID, Payload: 4}))
+	errs, err := suite.repo.SaveEvents(ctx, []domain.Event{
+		{Timestamp: start.Add(30 * time.Minute), SensorSerialNumber: "4567890131", SensorID: sensorID, Payload: 8},
+		{Timestamp: start.Add(10 * time.Second), SensorSerialNumber: "4567890131", SensorID: sensorID, Payload: 2},
+		{Timestamp: start.Add(2 * time.Minute), SensorSerialNumber: "4567890131", SensorID: sensorID, Value: "open"},
+		{Timestamp: start.Add(time.Hour), SensorSerialNumber: "4567890131", SensorID: sensorID, Payload: 1, HomeID: 3},
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []error{nil, nil, nil, nil}, errs)
+
+	rollups, err := suite.repo.GetSensorRollups(ctx, sensorID, domain.HistoryAggregateQuery{
+		Start:  start,
+		End:    start.Add(2 * time.Hour),
+		Bucket: time.Hour,
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.HistoryRollup{
+		{SensorID: sensorID, Resolution: time.Hour, Start: start, Count: 3, Sum: 14, Min: 2, Max: 8,
+			FirstTimestamp: start.Add(10 * time.Second), First: 2, LastTimestamp: start.Add(30 * time.Minute), Last: 8},
+		{SensorID: sensorID, HomeID: 3, Resolution: time.Hour, Start: start.Add(time.Hour), Count: 1, Sum: 1, Min: 1, Max: 1,
+			FirstTimestamp: start.Add(time.Hour), First: 1, LastTimestamp: start.Add(time.Hour), Last: 1},
+	}, rollups)
+
+	homeID := int64(3)
+	rollups, err = suite.repo.GetSensorRollups(ctx, sensorID, domain.HistoryAggregateQuery{
+		Start:  start.Truncate(24 * time.Hour),
+		End:    start.Truncate(24 * time.Hour).Add(24 * time.Hour),
+		Bucket: 24 * time.Hour,
+		HomeID: &homeID,
+	})
+	assert.Nil(suite.T(), err)
+	if assert.Len(suite.T(), rollups, 1) {
+		assert.Equal(suite.T(), int64(1), rollups[0].Count)
+	}
+
+	assert.Nil(suite.T(), suite.repo.DeleteEventsBySensorID(ctx, sensorID))
+	rollups, err = suite.repo.GetSensorRollups(ctx, sensorID, domain.HistoryAggregateQuery{
+		Start:  start,
+		End:    start.Add(2 * time.Hour),
+		Bucket: time.Hour,
+	})
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), rollups)
+}
+
+func (suite *EventTestSuite) TestEventRepository_DeleteEventsBySensorID() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := suite.repo.SaveEvent(ctx, &domain.Event{
+		Timestamp:          time.Now().In(time.UTC),
+		SensorSerialNumber: "5678901234",
+		SensorID:           3,
+		Payload:            1,
+	})
+	assert.Nil(suite.T(), err)
+
+	assert.Nil(suite.T(), suite.repo.DeleteEventsBySensorID(ctx, 3))
+
+	_, err = suite.repo.GetLastEventBySensorID(ctx, 3)
+	assert.ErrorIs(suite.T(), err, ErrEventNotFound)
+}
+
+func (suite *EventTestSuite) TestEventRepository_StreamSensorHistory() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	sensorID := suite.createSensor(ctx, "4567890133")
+	// событий больше порции курсора, чтобы выгрузка прочитала его в несколько запросов
+	events := make([]domain.Event, 2500)
+	for i := range events {
+		events[i] = domain.Event{
+			Timestamp:          start.Add(time.Duration(i) * time.Second),
+			SensorSerialNumber: "4567890133",
+			SensorID:           sensorID,
+			Payload:            int64(i),
+		}
+	}
+	_, err := suite.repo.SaveEvents(ctx, events)
+	assert.Nil(suite.T(), err)
+
+	var payloads []int64
+	err = suite.repo.StreamSensorHistory(ctx, sensorID, domain.HistoryQuery{Start: start, End: start.Add(time.Hour)},
+		func(event domain.Event) error {
+			payloads = append(payloads, event.Payload)
+			return nil
+		})
+	assert.Nil(suite.T(), err)
+	if assert.Len(suite.T(), payloads, len(events)) {
+		assert.Equal(suite.T(), int64(0), payloads[0])
+		assert.Equal(suite.T(), int64(len(events)-1), payloads[len(payloads)-1])
+	}
+
+	err = suite.repo.StreamSensorHistory(ctx, 999999, domain.HistoryQuery{Start: start, End: start},
+		func(domain.Event) error { return nil })
+	assert.ErrorIs(suite.T(), err, usecase.ErrSensorNotFound)
+}
+
+func (suite *EventTestSuite) TestEventRepository_DeleteEventsBefore() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	sensorID := suite.createSensor(ctx, "4567890132")
+	for _, offset := range []time.Duration{time.Hour, -time.Minute, -3 * time.Hour, -2 * time.Hour} {
+		assert.Nil(suite.T(), suite.repo.SaveEvent(ctx, &domain.Event{
+			Timestamp:          start.Add(offset),
+			SensorSerialNumber: "4567890132",
+			SensorID:           sensorID,
+			Payload:            1,
+		}))
+	}
+
+	count, err := suite.repo.CountEventsBefore(ctx, sensorID, start)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(3), count)
+
+	deleted, err := suite.repo.DeleteEventsBefore(ctx, sensorID, start, 2)
+	assert.Nil(suite.T(), err)
+	if assert.Len(suite.T(), deleted, 2) {
+		assert.Equal(suite.T(), start.Add(-3*time.Hour), deleted[0].Timestamp.UTC())
+		assert.Equal(suite.T(), start.Add(-2*time.Hour), deleted[1].Timestamp.UTC())
+	}
+	deleted, err = suite.repo.DeleteEventsBefore(ctx, sensorID, start, 2)
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), deleted, 1)
+
+	count, err = suite.repo.CountEventsBefore(ctx, sensorID, start)
+	assert.Nil(suite.T(), err)
+	assert.Zero(suite.T(), count)
+
+	assert.Nil(suite.T(), suite.repo.DeleteRollupsBefore(ctx, sensorID, start))
+	rollups, err := suite.repo.GetSensorRollups(ctx, sensorID, domain.HistoryAggregateQuery{
+		Start:  start.Add(-3 * time.Hour),
+		End:    start.Add(2 * time.Hour),
+		Bucket: time.Hour,
+	})
+	assert.Nil(suite.T(), err)
+	if assert.Len(suite.T(), rollups, 1) {
+		assert.Equal(suite.T(), start.Add(time.Hour), rollups[0].Start.UTC())
+	}
+}
+
 func TestEventTestSuite(t *testing.T) {
 	suite.Run(t, new(EventTestSuite))
 }
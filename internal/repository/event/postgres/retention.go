@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	saveRetentionPolicyQuery = `
+		INSERT INTO retention_policies (sensor_type, sensor_id, retention)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (sensor_type, sensor_id) DO UPDATE SET retention = excluded.retention
+	`
+
+	deleteRetentionPolicyQuery = `
+		DELETE FROM retention_policies
+		WHERE sensor_type = $1 AND sensor_id = $2
+	`
+
+	getRetentionPoliciesQuery = `
+		SELECT sensor_type, sensor_id, retention
+		FROM retention_policies
+		ORDER BY sensor_type, sensor_id
+	`
+)
+
+// RetentionRepository - политики хранения событий, срок хранения хранится в секундах
+type RetentionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRetentionRepository(pool *pgxpool.Pool) *RetentionRepository {
+	return &RetentionRepository{
+		pool: pool,
+	}
+}
+
+func (r *RetentionRepository) SaveRetentionPolicy(ctx context.Context, policy domain.RetentionPolicy) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, saveRetentionPolicyQuery, policy.SensorType, policy.SensorID,
+		int64(policy.Retention/time.Second))
+	return err
+}
+
+func (r *RetentionRepository) DeleteRetentionPolicy(ctx context.Context, policy domain.RetentionPolicy) error {
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, deleteRetentionPolicyQuery, policy.SensorType, policy.SensorID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrRetentionPolicyNotFound
+	}
+	return nil
+}
+
+func (r *RetentionRepository) GetRetentionPolicies(ctx context.Context) ([]domain.RetentionPolicy, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, getRetentionPoliciesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []domain.RetentionPolicy
+	for rows.Next() {
+		var policy domain.RetentionPolicy
+		var retention int64
+		if err := rows.Scan(&policy.SensorType, &policy.SensorID, &retention); err != nil {
+			return nil, err
+		}
+		policy.Retention = time.Duration(retention) * time.Second
+		result = append(result, policy)
+	}
+	return result, rows.Err()
+}
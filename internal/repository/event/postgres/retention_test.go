@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RetentionTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *RetentionRepository
+}
+
+func (suite *RetentionTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewRetentionRepository(suite.testDbInstance)
+}
+
+func (suite *RetentionTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *RetentionTestSuite) TestRetentionRepository() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.Nil(suite.T(), suite.repo.SaveRetentionPolicy(ctx, domain.RetentionPolicy{SensorID: 2, Retention: time.Hour}))
+	assert.Nil(suite.T(), suite.repo.SaveRetentionPolicy(ctx, domain.RetentionPolicy{SensorType: domain.SensorTypeADC, Retention: 0}))
+	assert.Nil(suite.T(), suite.repo.SaveRetentionPolicy(ctx, domain.RetentionPolicy{SensorID: 2, Retention: 48 * time.Hour}))
+
+	policies, err := suite.repo.GetRetentionPolicies(ctx)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.RetentionPolicy{
+		{SensorID: 2, Retention: 48 * time.Hour},
+		{SensorType: domain.SensorTypeADC, Retention: 0},
+	}, policies)
+
+	assert.Nil(suite.T(), suite.repo.DeleteRetentionPolicy(ctx, domain.RetentionPolicy{SensorID: 2}))
+	err = suite.repo.DeleteRetentionPolicy(ctx, domain.RetentionPolicy{SensorID: 2})
+	assert.ErrorIs(suite.T(), err, usecase.ErrRetentionPolicyNotFound)
+}
+
+func TestRetentionTestSuite(t *testing.T) {
+	suite.Run(t, new(RetentionTestSuite))
+}
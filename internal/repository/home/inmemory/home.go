@@ -0,0 +1,152 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/inmemory"
+	"homework/internal/usecase"
+	"slices"
+	"sync"
+)
+
+type HomeRepository struct {
+	homesByID   map[int64]*domain.Home
+	homeMembers map[int64][]domain.HomeMember
+	nextID      int64
+	mu          sync.Mutex
+}
+
+func NewHomeRepository() *HomeRepository {
+	return &HomeRepository{
+		homesByID:   make(map[int64]*domain.Home),
+		homeMembers: make(map[int64][]domain.HomeMember),
+		nextID:      1,
+	}
+}
+
+func (r *HomeRepository) SaveHome(ctx context.Context, home *domain.Home) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if home == nil {
+		return errors.New("nil home")
+	}
+	if home.ID == 0 {
+		home.ID = r.nextID
+		r.nextID++
+	}
+	id := home.ID
+	prev, exists := r.homesByID[id]
+	transaction.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if !exists {
+			delete(r.homesByID, id)
+			return
+		}
+		r.homesByID[id] = prev
+	})
+	r.homesByID[home.ID] = home
+	return nil
+}
+
+func (r *HomeRepository) GetHomeByID(ctx context.Context, id int64) (*domain.Home, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	home, ok := r.homesByID[id]
+	if !ok {
+		return nil, usecase.ErrHomeNotFound
+	}
+	return home, nil
+}
+
+// GetHomeByIDForUpdate - транзакции здесь и так выполняются по одной, поэтому блокировать нечего
+func (r *HomeRepository) GetHomeByIDForUpdate(ctx context.Context, id int64) (*domain.Home, error) {
+	return r.GetHomeByID(ctx, id)
+}
+
+func (r *HomeRepository) GetHomes(ctx context.Context) ([]domain.Home, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	homes := make([]domain.Home, 0, len(r.homesByID))
+	for _, home := range r.homesByID {
+		homes = append(homes, *home)
+	}
+	return homes, nil
+}
+
+func (r *HomeRepository) SaveHomeMember(ctx context.Context, member domain.HomeMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.rememberMembers(ctx, member.HomeID)
+	members := r.homeMembers[member.HomeID]
+	for i, existing := range members {
+		if existing.UserID == member.UserID {
+			members[i].Role = member.Role
+			return nil
+		}
+	}
+	r.homeMembers[member.HomeID] = append(members, member)
+	return nil
+}
+
+func (r *HomeRepository) GetHomeMembers(ctx context.Context, homeID int64) ([]domain.HomeMember, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slices.Clone(r.homeMembers[homeID]), nil
+}
+
+func (r *HomeRepository) GetHomesByUserID(ctx context.Context, userID int64) ([]domain.HomeMember, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var homeMembers []domain.HomeMember
+	for _, members := range r.homeMembers {
+		for _, member := range members {
+			if member.UserID == userID {
+				homeMembers = append(homeMembers, member)
+			}
+		}
+	}
+	return homeMembers, nil
+}
+
+func (r *HomeRepository) DeleteHomeMember(ctx context.Context, homeID, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.rememberMembers(ctx, homeID)
+	r.homeMembers[homeID] = slices.DeleteFunc(r.homeMembers[homeID], func(member domain.HomeMember) bool {
+		return member.UserID == userID
+	})
+	return nil
+}
+
+// rememberMembers - при откате возвращает участников дома к текущему составу. Вызывается под r.mu
+func (r *HomeRepository) rememberMembers(ctx context.Context, homeID int64) {
+	prev := slices.Clone(r.homeMembers[homeID])
+	transaction.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.homeMembers[homeID] = prev
+	})
+}
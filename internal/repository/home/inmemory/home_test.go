@@ -0,0 +1,131 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/inmemory"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHomeRepository_SaveHome(t *testing.T) {
+	t.Run("err, home is nil", func(t *testing.T) {
+		hr := NewHomeRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.Error(t, hr.SaveHome(ctx, nil))
+	})
+
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		hr := NewHomeRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := hr.SaveHome(ctx, &domain.Home{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, save and get one", func(t *testing.T) {
+		hr := NewHomeRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		home := &domain.Home{Name: "Квартира", CreatedAt: time.Now()}
+		assert.NoError(t, hr.SaveHome(ctx, home))
+		assert.NotZero(t, home.ID)
+
+		got, err := hr.GetHomeByID(ctx, home.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, home, got)
+
+		list, err := hr.GetHomes(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Home{*home}, list)
+	})
+
+	t.Run("fail, not found", func(t *testing.T) {
+		hr := NewHomeRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		_, err := hr.GetHomeByID(ctx, 1)
+		assert.ErrorIs(t, err, usecase.ErrHomeNotFound)
+	})
+}
+
+func TestHomeRepository_HomeMembers(t *testing.T) {
+	t.Run("fail, ctx deadline exceeded", func(t *testing.T) {
+		hr := NewHomeRepository()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+
+		time.Sleep(time.Millisecond)
+		err := hr.SaveHomeMember(ctx, domain.HomeMember{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("ok, save, update and delete", func(t *testing.T) {
+		hr := NewHomeRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.NoError(t, hr.SaveHomeMember(ctx, domain.HomeMember{HomeID: 1, UserID: 1, Role: domain.SensorRoleOwner}))
+		assert.NoError(t, hr.SaveHomeMember(ctx, domain.HomeMember{HomeID: 1, UserID: 2, Role: domain.SensorRoleViewer}))
+		assert.NoError(t, hr.SaveHomeMember(ctx, domain.HomeMember{HomeID: 2, UserID: 2, Role: domain.SensorRoleOwner}))
+
+		// повторное сохранение меняет роль, а не добавляет участника
+		assert.NoError(t, hr.SaveHomeMember(ctx, domain.HomeMember{HomeID: 1, UserID: 2, Role: domain.SensorRoleMember}))
+
+		members, err := hr.GetHomeMembers(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.HomeMember{
+			{HomeID: 1, UserID: 1, Role: domain.SensorRoleOwner},
+			{HomeID: 1, UserID: 2, Role: domain.SensorRoleMember},
+		}, members)
+
+		homes, err := hr.GetHomesByUserID(ctx, 2)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []domain.HomeMember{
+			{HomeID: 1, UserID: 2, Role: domain.SensorRoleMember},
+			{HomeID: 2, UserID: 2, Role: domain.SensorRoleOwner},
+		}, homes)
+
+		assert.NoError(t, hr.DeleteHomeMember(ctx, 1, 2))
+
+		members, err = hr.GetHomeMembers(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.HomeMember{{HomeID: 1, UserID: 1, Role: domain.SensorRoleOwner}}, members)
+	})
+}
+
+func TestHomeRepository_Rollback(t *testing.T) {
+	hr := NewHomeRepository()
+	tr := transaction.NewTransactor()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	home := &domain.Home{Name: "Дом"}
+	assert.NoError(t, hr.SaveHome(ctx, home))
+	assert.NoError(t, hr.SaveHomeMember(ctx, domain.HomeMember{HomeID: home.ID, UserID: 1, Role: domain.SensorRoleOwner}))
+
+	someErr := errors.New("some error")
+	err := tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		assert.NoError(t, hr.SaveHome(ctx, &domain.Home{Name: "Новый дом"}))
+		assert.NoError(t, hr.SaveHomeMember(ctx, domain.HomeMember{HomeID: home.ID, UserID: 1, Role: domain.SensorRoleMember}))
+		assert.NoError(t, hr.DeleteHomeMember(ctx, home.ID, 1))
+		return someErr
+	})
+	assert.ErrorIs(t, err, someErr)
+
+	homes, err := hr.GetHomes(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, homes, 1, "Дом из откаченной транзакции остался")
+	members, err := hr.GetHomeMembers(ctx, home.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.HomeMember{{HomeID: home.ID, UserID: 1, Role: domain.SensorRoleOwner}}, members)
+}
@@ -0,0 +1,85 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/inmemory"
+	"homework/internal/usecase"
+	"sync"
+)
+
+type RoomRepository struct {
+	roomsByID map[int64]*domain.Room
+	nextID    int64
+	mu        sync.Mutex
+}
+
+func NewRoomRepository() *RoomRepository {
+	return &RoomRepository{
+		roomsByID: make(map[int64]*domain.Room),
+		nextID:    1,
+	}
+}
+
+func (r *RoomRepository) SaveRoom(ctx context.Context, room *domain.Room) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if room == nil {
+		return errors.New("nil room")
+	}
+	if room.ID == 0 {
+		room.ID = r.nextID
+		r.nextID++
+	}
+	r.roomsByID[room.ID] = room
+	return nil
+}
+
+func (r *RoomRepository) GetRoomByID(ctx context.Context, id int64) (*domain.Room, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	room, ok := r.roomsByID[id]
+	if !ok {
+		return nil, usecase.ErrRoomNotFound
+	}
+	return room, nil
+}
+
+func (r *RoomRepository) GetRoomsByHomeID(ctx context.Context, homeID int64) ([]domain.Room, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var rooms []domain.Room
+	for _, room := range r.roomsByID {
+		if room.HomeID == homeID {
+			rooms = append(rooms, *room)
+		}
+	}
+	return rooms, nil
+}
+
+func (r *RoomRepository) DeleteRoom(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if room, ok := r.roomsByID[id]; ok {
+		transaction.OnRollback(ctx, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.roomsByID[id] = room
+		})
+	}
+	delete(r.roomsByID, id)
+	return nil
+}
@@ -0,0 +1,58 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoomRepository_SaveRoom(t *testing.T) {
+	t.Run("err, room is nil", func(t *testing.T) {
+		rr := NewRoomRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.Error(t, rr.SaveRoom(ctx, nil))
+	})
+
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		rr := NewRoomRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := rr.SaveRoom(ctx, &domain.Room{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, save, rename and delete", func(t *testing.T) {
+		rr := NewRoomRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		kitchen := &domain.Room{HomeID: 1, Name: "Кухня", CreatedAt: time.Now()}
+		hallway := &domain.Room{HomeID: 2, Name: "Прихожая", CreatedAt: time.Now()}
+		assert.NoError(t, rr.SaveRoom(ctx, kitchen))
+		assert.NoError(t, rr.SaveRoom(ctx, hallway))
+		assert.NotEqual(t, kitchen.ID, hallway.ID)
+
+		kitchen.Name = "Кухня-столовая"
+		assert.NoError(t, rr.SaveRoom(ctx, kitchen))
+
+		got, err := rr.GetRoomByID(ctx, kitchen.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Кухня-столовая", got.Name)
+
+		rooms, err := rr.GetRoomsByHomeID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Room{*kitchen}, rooms)
+
+		assert.NoError(t, rr.DeleteRoom(ctx, kitchen.ID))
+
+		_, err = rr.GetRoomByID(ctx, kitchen.ID)
+		assert.ErrorIs(t, err, usecase.ErrRoomNotFound)
+	})
+}
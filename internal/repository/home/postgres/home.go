@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	saveHomeQuery = `
+		INSERT INTO homes (name, created_at)
+		VALUES ($1, $2)
+		RETURNING id
+	`
+
+	updateHomeQuery = `
+		UPDATE homes
+		SET name = $1
+		WHERE id = $2
+	`
+
+	getHomeByIDQuery = `
+		SELECT id, name, created_at
+		FROM homes
+		WHERE id = $1
+	`
+
+	getHomeByIDForUpdateQuery = `
+		SELECT id, name, created_at
+		FROM homes
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	getHomesQuery = `
+		SELECT id, name, created_at
+		FROM homes
+	`
+
+	saveHomeMemberQuery = `
+		INSERT INTO homes_users (home_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (home_id, user_id) DO UPDATE SET role = excluded.role
+	`
+
+	getHomeMembersQuery = `
+		SELECT home_id, user_id, role
+		FROM homes_users
+		WHERE home_id = $1
+	`
+
+	getHomesByUserIDQuery = `
+		SELECT home_id, user_id, role
+		FROM homes_users
+		WHERE user_id = $1
+	`
+
+	deleteHomeMemberQuery = `
+		DELETE FROM homes_users
+		WHERE home_id = $1 AND user_id = $2
+	`
+)
+
+type HomeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewHomeRepository(pool *pgxpool.Pool) *HomeRepository {
+	return &HomeRepository{
+		pool: pool,
+	}
+}
+
+func (r *HomeRepository) SaveHome(ctx context.Context, home *domain.Home) error {
+	if home.ID == 0 {
+		return transaction.Conn(ctx, r.pool).QueryRow(ctx, saveHomeQuery, home.Name, home.CreatedAt).Scan(&home.ID)
+	}
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, updateHomeQuery, home.Name, home.ID)
+	return err
+}
+
+func (r *HomeRepository) GetHomeByID(ctx context.Context, id int64) (*domain.Home, error) {
+	var home domain.Home
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, getHomeByIDQuery, id).Scan(&home.ID, &home.Name, &home.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ErrHomeNotFound
+	}
+	return &home, err
+}
+
+// GetHomeByIDForUpdate - возвращает дом, блокируя строку до конца транзакции
+func (r *HomeRepository) GetHomeByIDForUpdate(ctx context.Context, id int64) (*domain.Home, error) {
+	var home domain.Home
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, getHomeByIDForUpdateQuery, id).Scan(&home.ID, &home.Name, &home.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ErrHomeNotFound
+	}
+	return &home, err
+}
+
+func (r *HomeRepository) GetHomes(ctx context.Context) ([]domain.Home, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, getHomesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var homes []domain.Home
+	for rows.Next() {
+		var home domain.Home
+		if err := rows.Scan(&home.ID, &home.Name, &home.CreatedAt); err != nil {
+			return nil, err
+		}
+		homes = append(homes, home)
+	}
+	return homes, rows.Err()
+}
+
+func (r *HomeRepository) SaveHomeMember(ctx context.Context, member domain.HomeMember) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, saveHomeMemberQuery, member.HomeID, member.UserID, member.Role)
+	return err
+}
+
+func (r *HomeRepository) GetHomeMembers(ctx context.Context, homeID int64) ([]domain.HomeMember, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, getHomeMembersQuery, homeID)
+	if err != nil {
+		return nil, err
+	}
+	return scanHomeMembers(rows)
+}
+
+func (r *HomeRepository) GetHomesByUserID(ctx context.Context, userID int64) ([]domain.HomeMember, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, getHomesByUserIDQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	return scanHomeMembers(rows)
+}
+
+func (r *HomeRepository) DeleteHomeMember(ctx context.Context, homeID, userID int64) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, deleteHomeMemberQuery, homeID, userID)
+	return err
+}
+
+func scanHomeMembers(rows pgx.Rows) ([]domain.HomeMember, error) {
+	defer rows.Close()
+
+	var members []domain.HomeMember
+	for rows.Next() {
+		var member domain.HomeMember
+		if err := rows.Scan(&member.HomeID, &member.UserID, &member.Role); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type HomeTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *HomeRepository
+}
+
+func (suite *HomeTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewHomeRepository(suite.testDbInstance)
+}
+
+func (suite *HomeTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *HomeTestSuite) TestHomeRepository_SaveHome() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	home := domain.Home{
+		Name:      "test_home",
+		CreatedAt: time.Now().Truncate(time.Microsecond).In(time.UTC),
+	}
+	err := suite.repo.SaveHome(ctx, &home)
+
+	assert.Nil(suite.T(), err)
+	assert.NotZero(suite.T(), home.ID)
+
+	got, err := suite.repo.GetHomeByID(ctx, home.ID)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), home, *got)
+
+	homes, err := suite.repo.GetHomes(ctx)
+
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), homes, home)
+}
+
+func (suite *HomeTestSuite) TestHomeRepository_GetHomeByID_NotFound() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := suite.repo.GetHomeByID(ctx, 100500)
+
+	assert.ErrorIs(suite.T(), err, usecase.ErrHomeNotFound)
+}
+
+func (suite *HomeTestSuite) TestHomeRepository_HomeMembers() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := suite.repo.SaveHomeMember(ctx, domain.HomeMember{HomeID: 10, UserID: 1, Role: domain.SensorRoleOwner})
+	assert.Nil(suite.T(), err)
+
+	err = suite.repo.SaveHomeMember(ctx, domain.HomeMember{HomeID: 10, UserID: 2, Role: domain.SensorRoleViewer})
+	assert.Nil(suite.T(), err)
+
+	err = suite.repo.SaveHomeMember(ctx, domain.HomeMember{HomeID: 10, UserID: 2, Role: domain.SensorRoleMember})
+	assert.Nil(suite.T(), err)
+
+	members, err := suite.repo.GetHomeMembers(ctx, 10)
+
+	assert.Nil(suite.T(), err)
+	assert.ElementsMatch(suite.T(), []domain.HomeMember{
+		{HomeID: 10, UserID: 1, Role: domain.SensorRoleOwner},
+		{HomeID: 10, UserID: 2, Role: domain.SensorRoleMember},
+	}, members)
+
+	homes, err := suite.repo.GetHomesByUserID(ctx, 2)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.HomeMember{{HomeID: 10, UserID: 2, Role: domain.SensorRoleMember}}, homes)
+
+	err = suite.repo.DeleteHomeMember(ctx, 10, 2)
+	assert.Nil(suite.T(), err)
+
+	homes, err = suite.repo.GetHomesByUserID(ctx, 2)
+
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), homes)
+}
+
+func TestHomeTestSuite(t *testing.T) {
+	suite.Run(t, new(HomeTestSuite))
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	saveRoomQuery = `
+		INSERT INTO rooms (home_id, name, created_at)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+
+	updateRoomQuery = `
+		UPDATE rooms
+		SET name = $1
+		WHERE id = $2
+	`
+
+	getRoomByIDQuery = `
+		SELECT id, home_id, name, created_at
+		FROM rooms
+		WHERE id = $1
+	`
+
+	getRoomsByHomeIDQuery = `
+		SELECT id, home_id, name, created_at
+		FROM rooms
+		WHERE home_id = $1
+	`
+
+	deleteRoomQuery = `
+		DELETE FROM rooms
+		WHERE id = $1
+	`
+)
+
+type RoomRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRoomRepository(pool *pgxpool.Pool) *RoomRepository {
+	return &RoomRepository{
+		pool: pool,
+	}
+}
+
+func (r *RoomRepository) SaveRoom(ctx context.Context, room *domain.Room) error {
+	if room.ID == 0 {
+		return transaction.Conn(ctx, r.pool).QueryRow(ctx, saveRoomQuery, room.HomeID, room.Name, room.CreatedAt).Scan(&room.ID)
+	}
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, updateRoomQuery, room.Name, room.ID)
+	return err
+}
+
+func (r *RoomRepository) GetRoomByID(ctx context.Context, id int64) (*domain.Room, error) {
+	var room domain.Room
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, getRoomByIDQuery, id).Scan(&room.ID, &room.HomeID, &room.Name, &room.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ErrRoomNotFound
+	}
+	return &room, err
+}
+
+func (r *RoomRepository) GetRoomsByHomeID(ctx context.Context, homeID int64) ([]domain.Room, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, getRoomsByHomeIDQuery, homeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rooms []domain.Room
+	for rows.Next() {
+		var room domain.Room
+		if err := rows.Scan(&room.ID, &room.HomeID, &room.Name, &room.CreatedAt); err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, room)
+	}
+	return rooms, rows.Err()
+}
+
+func (r *RoomRepository) DeleteRoom(ctx context.Context, id int64) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, deleteRoomQuery, id)
+	return err
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RoomTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *RoomRepository
+}
+
+func (suite *RoomTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewRoomRepository(suite.testDbInstance)
+}
+
+func (suite *RoomTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *RoomTestSuite) TestRoomRepository_SaveRoom() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	room := domain.Room{
+		HomeID:    1,
+		Name:      "test_room",
+		CreatedAt: time.Now().Truncate(time.Microsecond).In(time.UTC),
+	}
+	err := suite.repo.SaveRoom(ctx, &room)
+
+	assert.Nil(suite.T(), err)
+	assert.NotZero(suite.T(), room.ID)
+
+	room.Name = "test_room_2"
+	err = suite.repo.SaveRoom(ctx, &room)
+
+	assert.Nil(suite.T(), err)
+
+	got, err := suite.repo.GetRoomByID(ctx, room.ID)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), room, *got)
+
+	rooms, err := suite.repo.GetRoomsByHomeID(ctx, 1)
+
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), rooms, room)
+}
+
+func (suite *RoomTestSuite) TestRoomRepository_DeleteRoom() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	room := domain.Room{
+		HomeID:    2,
+		Name:      "test_room_3",
+		CreatedAt: time.Now().Truncate(time.Microsecond).In(time.UTC),
+	}
+	err := suite.repo.SaveRoom(ctx, &room)
+
+	assert.Nil(suite.T(), err)
+
+	err = suite.repo.DeleteRoom(ctx, room.ID)
+
+	assert.Nil(suite.T(), err)
+
+	_, err = suite.repo.GetRoomByID(ctx, room.ID)
+
+	assert.ErrorIs(suite.T(), err, usecase.ErrRoomNotFound)
+}
+
+func TestRoomTestSuite(t *testing.T) {
+	suite.Run(t, new(RoomTestSuite))
+}
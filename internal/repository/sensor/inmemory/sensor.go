@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/inmemory"
 	"homework/internal/usecase"
 	"sync"
 	"time"
@@ -32,15 +33,121 @@ func (r *SensorRepository) SaveSensor(ctx context.Context, sensor *domain.Sensor
 		return errors.New("nil sensor")
 	}
 
-	sensor.ID = int64(len(r.sensorsById) + 1)
-	sensor.RegisteredAt = time.Now()
+	if sensor.ID == 0 {
+		sensor.ID = int64(len(r.sensorsById) + 1)
+		sensor.RegisteredAt = time.Now()
+	}
+
+	// при откате новый датчик удаляется, а существующий заменяется сохранённым ранее объектом
+	id := sensor.ID
+	prev, exists := r.sensorsById[id]
+	transaction.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.sensorsBySN, r.sensorsById[id].SerialNumber)
+		if !exists {
+			delete(r.sensorsById, id)
+			return
+		}
+		r.sensorsById[id] = prev
+		r.sensorsBySN[prev.SerialNumber] = prev
+	})
 
 	r.sensorsById[sensor.ID] = sensor
 	r.sensorsBySN[sensor.SerialNumber] = sensor
 	return nil
 }
 
+// SaveSensorState - сохраняет состояние датчика, не трогая остальные поля. Состояние не меняется,
+// если датчик удалён или уже сохранено состояние по более новому событию
+func (r *SensorRepository) SaveSensorState(ctx context.Context, sensor *domain.Sensor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	prev, ok := r.sensorsById[sensor.ID]
+	if !ok || !prev.DeletedAt.IsZero() || sensor.LastActivity.Before(prev.LastActivity) {
+		return nil
+	}
+	updated := *prev
+	updated.CurrentState = sensor.CurrentState
+	updated.CurrentValue = sensor.CurrentValue
+	updated.CalibratedState = sensor.CalibratedState
+	updated.LastActivity = sensor.LastActivity
+
+	transaction.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.sensorsById[prev.ID] = prev
+		r.sensorsBySN[prev.SerialNumber] = prev
+	})
+	r.sensorsById[updated.ID] = &updated
+	r.sensorsBySN[updated.SerialNumber] = &updated
+	return nil
+}
+
+// SaveSensorRoom - переносит датчик в комнату, не трогая остальные поля
+func (r *SensorRepository) SaveSensorRoom(ctx context.Context, sensorID, roomID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	prev, ok := r.sensorsById[sensorID]
+	if !ok || !prev.DeletedAt.IsZero() {
+		return nil
+	}
+	r.replaceRoom(ctx, prev, roomID)
+	return nil
+}
+
+// ClearRoomSensors - убирает из комнаты все её датчики
+func (r *SensorRepository) ClearRoomSensors(ctx context.Context, roomID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, s := range r.sensorsById {
+		if s.RoomID == roomID {
+			r.replaceRoom(ctx, s, 0)
+		}
+	}
+	return nil
+}
+
+// replaceRoom - заменяет датчик копией с другой комнатой, при откате возвращает прежний объект.
+// Вызывается под r.mu
+func (r *SensorRepository) replaceRoom(ctx context.Context, prev *domain.Sensor, roomID int64) {
+	updated := *prev
+	updated.RoomID = roomID
+	transaction.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.sensorsById[prev.ID] = prev
+		r.sensorsBySN[prev.SerialNumber] = prev
+	})
+	r.sensorsById[updated.ID] = &updated
+	r.sensorsBySN[updated.SerialNumber] = &updated
+}
+
 func (r *SensorRepository) GetSensors(ctx context.Context) ([]domain.Sensor, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sensors := make([]domain.Sensor, 0, len(r.sensorsById))
+	for _, s := range r.sensorsById {
+		if s.DeletedAt.IsZero() {
+			sensors = append(sensors, *s)
+		}
+	}
+	return sensors, nil
+}
+
+// GetAllSensors - возвращает все датчики, включая удалённые
+func (r *SensorRepository) GetAllSensors(ctx context.Context) ([]domain.Sensor, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -61,6 +168,22 @@ func (r *SensorRepository) GetSensorByID(ctx context.Context, id int64) (*domain
 	return r.sensorsById[id], nil
 }
 
+// GetSensorByIDForUpdate - возвращает копию датчика. Транзакции здесь и так выполняются по одной,
+// а копия не даёт изменениям попасть в хранилище до сохранения
+func (r *SensorRepository) GetSensorByIDForUpdate(ctx context.Context, id int64) (*domain.Sensor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sensor, ok := r.sensorsById[id]
+	if !ok {
+		return nil, usecase.ErrSensorNotFound
+	}
+	copied := *sensor
+	return &copied, nil
+}
+
 func (r *SensorRepository) GetSensorBySerialNumber(ctx context.Context, sn string) (*domain.Sensor, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -70,3 +193,33 @@ func (r *SensorRepository) GetSensorBySerialNumber(ctx context.Context, sn strin
 	}
 	return r.sensorsBySN[sn], nil
 }
+
+func (r *SensorRepository) GetSensorsByHomeID(ctx context.Context, homeID int64) ([]domain.Sensor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var sensors []domain.Sensor
+	for _, s := range r.sensorsById {
+		if s.HomeID == homeID && s.DeletedAt.IsZero() {
+			sensors = append(sensors, *s)
+		}
+	}
+	return sensors, nil
+}
+
+func (r *SensorRepository) GetSensorsByRoomID(ctx context.Context, roomID int64) ([]domain.Sensor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var sensors []domain.Sensor
+	for _, s := range r.sensorsById {
+		if s.RoomID == roomID && s.DeletedAt.IsZero() {
+			sensors = append(sensors, *s)
+		}
+	}
+	return sensors, nil
+}
@@ -0,0 +1,63 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/inmemory"
+	"homework/internal/usecase"
+	"sync"
+)
+
+type SensorKeyRepository struct {
+	keys map[int64]domain.SensorKey
+	mu   sync.Mutex
+}
+
+func NewSensorKeyRepository() *SensorKeyRepository {
+	return &SensorKeyRepository{
+		keys: make(map[int64]domain.SensorKey),
+	}
+}
+
+func (r *SensorKeyRepository) SaveSensorKey(ctx context.Context, key domain.SensorKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	prev, exists := r.keys[key.SensorID]
+	transaction.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if exists {
+			r.keys[key.SensorID] = prev
+		} else {
+			delete(r.keys, key.SensorID)
+		}
+	})
+	r.keys[key.SensorID] = key
+	return nil
+}
+
+func (r *SensorKeyRepository) GetSensorKey(ctx context.Context, sensorID int64) (*domain.SensorKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	key, ok := r.keys[sensorID]
+	if !ok {
+		return nil, usecase.ErrSensorKeyNotFound
+	}
+	return &key, nil
+}
+
+func (r *SensorKeyRepository) DeleteSensorKey(ctx context.Context, sensorID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	delete(r.keys, sensorID)
+	return nil
+}
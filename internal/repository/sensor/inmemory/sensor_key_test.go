@@ -0,0 +1,101 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/inmemory"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensorKeyRepository_SaveSensorKey(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		skr := NewSensorKeyRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := skr.SaveSensorKey(ctx, domain.SensorKey{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, save replaces previous key", func(t *testing.T) {
+		skr := NewSensorKeyRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.NoError(t, skr.SaveSensorKey(ctx, domain.SensorKey{SensorID: 1, KeyHash: []byte("first")}))
+		assert.NoError(t, skr.SaveSensorKey(ctx, domain.SensorKey{SensorID: 1, KeyHash: []byte("second")}))
+
+		key, err := skr.GetSensorKey(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("second"), key.KeyHash)
+	})
+
+	t.Run("ok, rollback restores previous key", func(t *testing.T) {
+		skr := NewSensorKeyRepository()
+		tr := transaction.NewTransactor()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.NoError(t, skr.SaveSensorKey(ctx, domain.SensorKey{SensorID: 1, KeyHash: []byte("first")}))
+		someErr := errors.New("some error")
+		err := tr.WithinTransaction(ctx, func(ctx context.Context) error {
+			assert.NoError(t, skr.SaveSensorKey(ctx, domain.SensorKey{SensorID: 1, KeyHash: []byte("second")}))
+			assert.NoError(t, skr.SaveSensorKey(ctx, domain.SensorKey{SensorID: 2, KeyHash: []byte("new")}))
+			return someErr
+		})
+		assert.ErrorIs(t, err, someErr)
+
+		key, err := skr.GetSensorKey(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("first"), key.KeyHash)
+		_, err = skr.GetSensorKey(ctx, 2)
+		assert.ErrorIs(t, err, usecase.ErrSensorKeyNotFound)
+	})
+}
+
+func TestSensorKeyRepository_GetSensorKey(t *testing.T) {
+	t.Run("fail, ctx deadline exceeded", func(t *testing.T) {
+		skr := NewSensorKeyRepository()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+
+		_, err := skr.GetSensorKey(ctx, 1)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("fail, key not found", func(t *testing.T) {
+		skr := NewSensorKeyRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		_, err := skr.GetSensorKey(ctx, 1)
+		assert.ErrorIs(t, err, usecase.ErrSensorKeyNotFound)
+	})
+}
+
+func TestSensorKeyRepository_DeleteSensorKey(t *testing.T) {
+	t.Run("ok, deleted key not found", func(t *testing.T) {
+		skr := NewSensorKeyRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.NoError(t, skr.SaveSensorKey(ctx, domain.SensorKey{SensorID: 1, KeyHash: []byte("hash")}))
+		assert.NoError(t, skr.DeleteSensorKey(ctx, 1))
+
+		_, err := skr.GetSensorKey(ctx, 1)
+		assert.ErrorIs(t, err, usecase.ErrSensorKeyNotFound)
+	})
+
+	t.Run("ok, delete missing key", func(t *testing.T) {
+		skr := NewSensorKeyRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.NoError(t, skr.DeleteSensorKey(ctx, 1))
+	})
+}
@@ -2,8 +2,10 @@ package inmemory
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/inmemory"
 	"homework/internal/usecase"
 	"math/rand/v2"
 	"strings"
@@ -102,6 +104,28 @@ func TestSensorRepository_SaveSensor(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, sensors, 1000)
 	})
+
+	t.Run("ok, new sensor removed on rollback", func(t *testing.T) {
+		sr := NewSensorRepository()
+		tr := transaction.NewTransactor()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		someErr := errors.New("some error")
+		var id int64
+		err := tr.WithinTransaction(ctx, func(ctx context.Context) error {
+			sensor := &domain.Sensor{SerialNumber: "0012345679", Type: domain.SensorTypeContactClosure}
+			assert.NoError(t, sr.SaveSensor(ctx, sensor))
+			id = sensor.ID
+			return someErr
+		})
+		assert.ErrorIs(t, err, someErr)
+
+		_, err = sr.GetSensorByID(ctx, id)
+		assert.ErrorIs(t, err, usecase.ErrSensorNotFound)
+		_, err = sr.GetSensorBySerialNumber(ctx, "0012345679")
+		assert.ErrorIs(t, err, usecase.ErrSensorNotFound)
+	})
 }
 
 func TestSensorRepository_GetSensors(t *testing.T) {
@@ -246,6 +270,120 @@ func TestSensorRepository_GetSensorBySerialNumber(t *testing.T) {
 	})
 }
 
+func TestSensorRepository_GetSensorsByHomeID(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := sr.GetSensorsByHomeID(ctx, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, only home sensors", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.NoError(t, sr.SaveSensor(ctx, &domain.Sensor{SerialNumber: "0000000001", HomeID: 1}))
+		assert.NoError(t, sr.SaveSensor(ctx, &domain.Sensor{SerialNumber: "0000000002", HomeID: 2}))
+		assert.NoError(t, sr.SaveSensor(ctx, &domain.Sensor{SerialNumber: "0000000003"}))
+
+		sensors, err := sr.GetSensorsByHomeID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, sensors, 1)
+		assert.Equal(t, "0000000001", sensors[0].SerialNumber)
+	})
+}
+
+func TestSensorRepository_GetSensorsByRoomID(t *testing.T) {
+	t.Run("ok, only room sensors", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.NoError(t, sr.SaveSensor(ctx, &domain.Sensor{SerialNumber: "0000000001", HomeID: 1, RoomID: 1}))
+		assert.NoError(t, sr.SaveSensor(ctx, &domain.Sensor{SerialNumber: "0000000002", HomeID: 1}))
+
+		sensors, err := sr.GetSensorsByRoomID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, sensors, 1)
+		assert.Equal(t, "0000000001", sensors[0].SerialNumber)
+	})
+}
+
+func TestSensorRepository_DeletedSensor(t *testing.T) {
+	t.Run("ok, deleted sensor excluded from lists", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sensor := &domain.Sensor{SerialNumber: "0000000001", HomeID: 1, RoomID: 1}
+		assert.NoError(t, sr.SaveSensor(ctx, sensor))
+		sensor.DeletedAt = time.Now()
+		assert.NoError(t, sr.SaveSensor(ctx, sensor))
+
+		sensors, err := sr.GetSensors(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, sensors)
+		sensors, err = sr.GetSensorsByHomeID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Empty(t, sensors)
+		sensors, err = sr.GetSensorsByRoomID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Empty(t, sensors)
+
+		got, err := sr.GetSensorBySerialNumber(ctx, "0000000001")
+		assert.NoError(t, err)
+		assert.False(t, got.DeletedAt.IsZero())
+
+		sensors, err = sr.GetAllSensors(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, sensors, 1, "Удалённый датчик должен оставаться в полном списке")
+	})
+}
+
+func TestSensorRepository_SaveSensorState(t *testing.T) {
+	t.Run("ok, only state changed", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		now := time.Now()
+		sensor := &domain.Sensor{SerialNumber: "0000000001", Description: "old", IsActive: true, LastActivity: now}
+		assert.NoError(t, sr.SaveSensor(ctx, sensor))
+		// датчик изменён после того, как событие его прочитало
+		assert.NoError(t, sr.SaveSensor(ctx, &domain.Sensor{ID: sensor.ID, SerialNumber: "0000000001", Description: "new", LastActivity: now}))
+
+		assert.NoError(t, sr.SaveSensorState(ctx, &domain.Sensor{ID: sensor.ID, CurrentState: 5, LastActivity: now.Add(time.Second)}))
+		got, err := sr.GetSensorByID(ctx, sensor.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), got.CurrentState)
+		assert.Equal(t, "new", got.Description, "Изменение датчика перезаписано состоянием")
+		assert.False(t, got.IsActive)
+
+		assert.NoError(t, sr.SaveSensorState(ctx, &domain.Sensor{ID: sensor.ID, CurrentState: 4, LastActivity: now}))
+		got, err = sr.GetSensorByID(ctx, sensor.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), got.CurrentState, "Более старое событие перезаписало состояние")
+	})
+
+	t.Run("ok, deleted sensor not changed", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sensor := &domain.Sensor{SerialNumber: "0000000001", DeletedAt: time.Now()}
+		assert.NoError(t, sr.SaveSensor(ctx, sensor))
+
+		assert.NoError(t, sr.SaveSensorState(ctx, &domain.Sensor{ID: sensor.ID, CurrentState: 5, LastActivity: time.Now()}))
+		got, err := sr.GetSensorByID(ctx, sensor.ID)
+		assert.NoError(t, err)
+		assert.Zero(t, got.CurrentState)
+		assert.False(t, got.DeletedAt.IsZero(), "Удалённый датчик восстановлен")
+	})
+}
+
 func generateRandomNumbersString() string {
 	r := rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), 1024))
 
@@ -256,6 +394,71 @@ func generateRandomNumbersString() string {
 	return strings.Join(digits, "")
 }
 
+func TestSensorRepository_GetSensorByIDForUpdate(t *testing.T) {
+	t.Run("fail, not found", func(t *testing.T) {
+		sr := NewSensorRepository()
+		_, err := sr.GetSensorByIDForUpdate(context.Background(), 1)
+		assert.ErrorIs(t, err, usecase.ErrSensorNotFound)
+	})
+
+	t.Run("ok, changes not stored before save", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sensor := &domain.Sensor{SerialNumber: "0000000001", Description: "old"}
+		assert.NoError(t, sr.SaveSensor(ctx, sensor))
+
+		locked, err := sr.GetSensorByIDForUpdate(ctx, sensor.ID)
+		assert.NoError(t, err)
+		locked.Description = "new"
+		got, err := sr.GetSensorByID(ctx, sensor.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "old", got.Description)
+
+		assert.NoError(t, sr.SaveSensor(ctx, locked))
+		got, err = sr.GetSensorByID(ctx, sensor.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "new", got.Description)
+	})
+}
+
+func TestSensorRepository_SaveSensorRoom(t *testing.T) {
+	t.Run("ok, only room changed", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sensor := &domain.Sensor{SerialNumber: "0000000001", HomeID: 1}
+		assert.NoError(t, sr.SaveSensor(ctx, sensor))
+		assert.NoError(t, sr.SaveSensorState(ctx, &domain.Sensor{ID: sensor.ID, CurrentState: 5, LastActivity: time.Now()}))
+
+		assert.NoError(t, sr.SaveSensorRoom(ctx, sensor.ID, 3))
+		got, err := sr.GetSensorByID(ctx, sensor.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), got.RoomID)
+		assert.Equal(t, int64(5), got.CurrentState, "Перенос в комнату затёр состояние")
+	})
+
+	t.Run("ok, room sensors cleared", func(t *testing.T) {
+		sr := NewSensorRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.NoError(t, sr.SaveSensor(ctx, &domain.Sensor{SerialNumber: "0000000001", RoomID: 3}))
+		assert.NoError(t, sr.SaveSensor(ctx, &domain.Sensor{SerialNumber: "0000000002", RoomID: 3}))
+		assert.NoError(t, sr.SaveSensor(ctx, &domain.Sensor{SerialNumber: "0000000003", RoomID: 4}))
+
+		assert.NoError(t, sr.ClearRoomSensors(ctx, 3))
+		sensors, err := sr.GetSensorsByRoomID(ctx, 3)
+		assert.NoError(t, err)
+		assert.Empty(t, sensors)
+		sensors, err = sr.GetSensorsByRoomID(ctx, 4)
+		assert.NoError(t, err)
+		assert.Len(t, sensors, 1)
+	})
+}
+
 func TestGenerateRandomNumbersString(t *testing.T) {
 	for i := 0; i < 1000; i++ {
 		sn := generateRandomNumbersString()
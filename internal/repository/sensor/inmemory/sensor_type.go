@@ -0,0 +1,68 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type SensorTypeRepository struct {
+	types map[domain.SensorType]domain.SensorTypeDefinition
+	mu    sync.Mutex
+}
+
+// NewSensorTypeRepository - создаёт реестр, в котором уже есть встроенные типы датчиков
+func NewSensorTypeRepository() *SensorTypeRepository {
+	types := make(map[domain.SensorType]domain.SensorTypeDefinition)
+	for _, definition := range domain.BuiltinSensorTypes() {
+		types[definition.Type] = definition
+	}
+	return &SensorTypeRepository{
+		types: types,
+	}
+}
+
+func (r *SensorTypeRepository) SaveSensorType(ctx context.Context, definition domain.SensorTypeDefinition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if _, ok := r.types[definition.Type]; ok {
+		return usecase.ErrSensorTypeAlreadyExists
+	}
+	r.types[definition.Type] = definition
+	return nil
+}
+
+func (r *SensorTypeRepository) GetSensorType(ctx context.Context, sensorType domain.SensorType) (*domain.SensorTypeDefinition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	definition, ok := r.types[sensorType]
+	if !ok {
+		return nil, usecase.ErrSensorTypeNotFound
+	}
+	return &definition, nil
+}
+
+func (r *SensorTypeRepository) GetSensorTypes(ctx context.Context) ([]domain.SensorTypeDefinition, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result := make([]domain.SensorTypeDefinition, 0, len(r.types))
+	for _, definition := range r.types {
+		result = append(result, definition)
+	}
+	slices.SortFunc(result, func(a, b domain.SensorTypeDefinition) int {
+		return strings.Compare(string(a.Type), string(b.Type))
+	})
+	return result, nil
+}
@@ -0,0 +1,89 @@
+package inmemory
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensorTypeRepository_SaveSensorType(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		str := NewSensorTypeRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := str.SaveSensorType(ctx, domain.SensorTypeDefinition{Type: "pressure"})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, saved type found", func(t *testing.T) {
+		str := NewSensorTypeRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		pressure := domain.SensorTypeDefinition{
+			Type:      "pressure",
+			Name:      "Давление",
+			ValueKind: domain.SensorValueKindInteger,
+			MinValue:  300,
+			MaxValue:  1100,
+			Unit:      "гПа",
+		}
+		assert.NoError(t, str.SaveSensorType(ctx, pressure))
+
+		definition, err := str.GetSensorType(ctx, "pressure")
+		assert.NoError(t, err)
+		assert.Equal(t, pressure, *definition)
+	})
+
+	t.Run("fail, builtin type is not overwritten", func(t *testing.T) {
+		str := NewSensorTypeRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		err := str.SaveSensorType(ctx, domain.SensorTypeDefinition{Type: domain.SensorTypeHumidity, Name: "Другая влажность"})
+		assert.ErrorIs(t, err, usecase.ErrSensorTypeAlreadyExists)
+
+		definition, err := str.GetSensorType(ctx, domain.SensorTypeHumidity)
+		assert.NoError(t, err)
+		assert.Equal(t, "Влажность", definition.Name)
+	})
+}
+
+func TestSensorTypeRepository_GetSensorType(t *testing.T) {
+	t.Run("fail, ctx deadline exceeded", func(t *testing.T) {
+		str := NewSensorTypeRepository()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+
+		_, err := str.GetSensorType(ctx, domain.SensorTypeADC)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("fail, type not found", func(t *testing.T) {
+		str := NewSensorTypeRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		_, err := str.GetSensorType(ctx, "pressure")
+		assert.ErrorIs(t, err, usecase.ErrSensorTypeNotFound)
+	})
+}
+
+func TestSensorTypeRepository_GetSensorTypes(t *testing.T) {
+	t.Run("ok, builtin types sorted by code", func(t *testing.T) {
+		str := NewSensorTypeRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		types, err := str.GetSensorTypes(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, types, len(domain.BuiltinSensorTypes()))
+		assert.Equal(t, domain.SensorTypeADC, types[0].Type)
+		assert.Equal(t, domain.SensorTypeTemperature, types[len(types)-1].Type)
+	})
+}
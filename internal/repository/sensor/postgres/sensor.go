@@ -2,8 +2,10 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
 	"homework/internal/usecase"
 	"time"
 
@@ -13,8 +15,8 @@ import (
 
 const (
 	saveSensorQuery = `
-		INSERT INTO sensors (serial_number, type, current_state, description, is_active, registered_at, last_activity)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO sensors (serial_number, type, current_state, description, is_active, registered_at, last_activity, home_id, room_id, deleted_at, current_value, unit, calibration, calibrated_state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, nullif($8, 0), nullif($9, 0), $10, $11, $12, $13, $14)
 		RETURNING id
 	`
 
@@ -26,25 +28,78 @@ const (
 		    description = $4, 
 		    is_active = $5, 
 		    registered_at = $6, 
-		    last_activity = $7
-		WHERE id = $8
+		    last_activity = $7,
+		    home_id = nullif($8, 0),
+		    room_id = nullif($9, 0),
+		    deleted_at = $10,
+		    current_value = $11,
+		    unit = $12,
+		    calibration = $13,
+		    calibrated_state = $14
+		WHERE id = $15
+	`
+
+	saveSensorStateQuery = `
+		UPDATE sensors
+		SET current_state = $1,
+		    current_value = $2,
+		    calibrated_state = $3,
+		    last_activity = $4
+		WHERE id = $5 AND last_activity <= $4 AND deleted_at IS NULL
+	`
+
+	saveSensorRoomQuery = `
+		UPDATE sensors
+		SET room_id = nullif($1, 0)
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	clearRoomSensorsQuery = `
+		UPDATE sensors
+		SET room_id = NULL
+		WHERE room_id = $1
 	`
 
 	getSensorsQuery = `
-		SELECT id, serial_number, type, current_state, description, is_active, registered_at, last_activity
+		SELECT id, serial_number, type, current_state, description, is_active, registered_at, last_activity, coalesce(home_id, 0), coalesce(room_id, 0), deleted_at, current_value, unit, calibration, calibrated_state
+		FROM sensors
+		WHERE deleted_at IS NULL
+	`
+
+	getAllSensorsQuery = `
+		SELECT id, serial_number, type, current_state, description, is_active, registered_at, last_activity, coalesce(home_id, 0), coalesce(room_id, 0), deleted_at, current_value, unit, calibration, calibrated_state
 		FROM sensors
 	`
 
 	getSensorByIDQuery = `
-		SELECT id, serial_number, type, current_state, description, is_active, registered_at, last_activity
+		SELECT id, serial_number, type, current_state, description, is_active, registered_at, last_activity, coalesce(home_id, 0), coalesce(room_id, 0), deleted_at, current_value, unit, calibration, calibrated_state
 		FROM sensors
 		WHERE id = $1
 	`
 
+	getSensorByIDForUpdateQuery = `
+		SELECT id, serial_number, type, current_state, description, is_active, registered_at, last_activity, coalesce(home_id, 0), coalesce(room_id, 0), deleted_at, current_value, unit, calibration, calibrated_state
+		FROM sensors
+		WHERE id = $1
+		FOR UPDATE
+	`
+
 	getSensorBySerialQuery = `
-		SELECT id, serial_number, type, current_state, description, is_active, registered_at, last_activity
+		SELECT id, serial_number, type, current_state, description, is_active, registered_at, last_activity, coalesce(home_id, 0), coalesce(room_id, 0), deleted_at, current_value, unit, calibration, calibrated_state
 		FROM sensors
 		WHERE serial_number = $1`
+
+	getSensorsByHomeIDQuery = `
+		SELECT id, serial_number, type, current_state, description, is_active, registered_at, last_activity, coalesce(home_id, 0), coalesce(room_id, 0), deleted_at, current_value, unit, calibration, calibrated_state
+		FROM sensors
+		WHERE home_id = $1 AND deleted_at IS NULL
+	`
+
+	getSensorsByRoomIDQuery = `
+		SELECT id, serial_number, type, current_state, description, is_active, registered_at, last_activity, coalesce(home_id, 0), coalesce(room_id, 0), deleted_at, current_value, unit, calibration, calibrated_state
+		FROM sensors
+		WHERE room_id = $1 AND deleted_at IS NULL
+	`
 )
 
 type SensorRepository struct {
@@ -58,18 +113,99 @@ func NewSensorRepository(pool *pgxpool.Pool) *SensorRepository {
 }
 
 func (r *SensorRepository) SaveSensor(ctx context.Context, sensor *domain.Sensor) error {
+	currentValue, err := marshalJSON(sensor.CurrentValue)
+	if err != nil {
+		return err
+	}
+	var calibration []byte
+	if sensor.Calibration != nil {
+		if calibration, err = json.Marshal(sensor.Calibration); err != nil {
+			return err
+		}
+	}
 	if sensor.ID == 0 {
 		sensor.RegisteredAt = time.Now()
-		return r.pool.QueryRow(ctx, saveSensorQuery, sensor.SerialNumber, sensor.Type, sensor.CurrentState,
-			sensor.Description, sensor.IsActive, sensor.RegisteredAt, sensor.LastActivity).Scan(&sensor.ID)
+		return transaction.Conn(ctx, r.pool).QueryRow(ctx, saveSensorQuery, sensor.SerialNumber, sensor.Type, sensor.CurrentState,
+			sensor.Description, sensor.IsActive, sensor.RegisteredAt, sensor.LastActivity, sensor.HomeID, sensor.RoomID, nullTime(sensor.DeletedAt),
+			currentValue, sensor.Unit, calibration, sensor.CalibratedState).Scan(&sensor.ID)
 	}
-	_, err := r.pool.Exec(ctx, saveSensorQueryWithID, sensor.SerialNumber, sensor.Type, sensor.CurrentState,
-		sensor.Description, sensor.IsActive, sensor.RegisteredAt, sensor.LastActivity, sensor.ID)
+	_, err = transaction.Conn(ctx, r.pool).Exec(ctx, saveSensorQueryWithID, sensor.SerialNumber, sensor.Type, sensor.CurrentState,
+		sensor.Description, sensor.IsActive, sensor.RegisteredAt, sensor.LastActivity, sensor.HomeID, sensor.RoomID, nullTime(sensor.DeletedAt),
+		currentValue, sensor.Unit, calibration, sensor.CalibratedState, sensor.ID)
+	return err
+}
+
+// SaveSensorState - сохраняет состояние датчика, не трогая остальные поля. Состояние не меняется,
+// если датчик удалён или уже сохранено состояние по более новому событию
+func (r *SensorRepository) SaveSensorState(ctx context.Context, sensor *domain.Sensor) error {
+	currentValue, err := marshalJSON(sensor.CurrentValue)
+	if err != nil {
+		return err
+	}
+	_, err = transaction.Conn(ctx, r.pool).Exec(ctx, saveSensorStateQuery, sensor.CurrentState, currentValue,
+		sensor.CalibratedState, sensor.LastActivity, sensor.ID)
+	return err
+}
+
+// SaveSensorRoom - переносит датчик в комнату, не трогая остальные поля
+func (r *SensorRepository) SaveSensorRoom(ctx context.Context, sensorID, roomID int64) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, saveSensorRoomQuery, roomID, sensorID)
+	return err
+}
+
+// ClearRoomSensors - убирает из комнаты все её датчики одним запросом
+func (r *SensorRepository) ClearRoomSensors(ctx context.Context, roomID int64) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, clearRoomSensorsQuery, roomID)
 	return err
 }
 
 func (r *SensorRepository) GetSensors(ctx context.Context) ([]domain.Sensor, error) {
-	rows, err := r.pool.Query(ctx, getSensorsQuery)
+	return r.querySensors(ctx, getSensorsQuery)
+}
+
+// GetAllSensors - возвращает все датчики, включая удалённые
+func (r *SensorRepository) GetAllSensors(ctx context.Context) ([]domain.Sensor, error) {
+	return r.querySensors(ctx, getAllSensorsQuery)
+}
+
+func (r *SensorRepository) GetSensorByID(ctx context.Context, id int64) (*domain.Sensor, error) {
+	var s domain.Sensor
+	err := scanSensor(transaction.Conn(ctx, r.pool).QueryRow(ctx, getSensorByIDQuery, id), &s)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ErrSensorNotFound
+	}
+	return &s, err
+}
+
+// GetSensorByIDForUpdate - возвращает датчик, блокируя строку до конца транзакции
+func (r *SensorRepository) GetSensorByIDForUpdate(ctx context.Context, id int64) (*domain.Sensor, error) {
+	var s domain.Sensor
+	err := scanSensor(transaction.Conn(ctx, r.pool).QueryRow(ctx, getSensorByIDForUpdateQuery, id), &s)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ErrSensorNotFound
+	}
+	return &s, err
+}
+
+func (r *SensorRepository) GetSensorBySerialNumber(ctx context.Context, sn string) (*domain.Sensor, error) {
+	var s domain.Sensor
+	err := scanSensor(transaction.Conn(ctx, r.pool).QueryRow(ctx, getSensorBySerialQuery, sn), &s)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ErrSensorNotFound
+	}
+	return &s, err
+}
+
+func (r *SensorRepository) GetSensorsByHomeID(ctx context.Context, homeID int64) ([]domain.Sensor, error) {
+	return r.querySensors(ctx, getSensorsByHomeIDQuery, homeID)
+}
+
+func (r *SensorRepository) GetSensorsByRoomID(ctx context.Context, roomID int64) ([]domain.Sensor, error) {
+	return r.querySensors(ctx, getSensorsByRoomIDQuery, roomID)
+}
+
+func (r *SensorRepository) querySensors(ctx context.Context, query string, args ...any) ([]domain.Sensor, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -77,27 +213,18 @@ func (r *SensorRepository) GetSensors(ctx context.Context) ([]domain.Sensor, err
 	var sensors []domain.Sensor
 	for rows.Next() {
 		var s domain.Sensor
-		err := rows.Scan(
-			&s.ID,
-			&s.SerialNumber,
-			&s.Type,
-			&s.CurrentState,
-			&s.Description,
-			&s.IsActive,
-			&s.RegisteredAt,
-			&s.LastActivity,
-		)
-		if err != nil {
+		if err := scanSensor(rows, &s); err != nil {
 			return nil, err
 		}
 		sensors = append(sensors, s)
 	}
-	return sensors, nil
+	return sensors, rows.Err()
 }
 
-func (r *SensorRepository) GetSensorByID(ctx context.Context, id int64) (*domain.Sensor, error) {
-	var s domain.Sensor
-	err := r.pool.QueryRow(ctx, getSensorByIDQuery, id).Scan(
+func scanSensor(row pgx.Row, s *domain.Sensor) error {
+	var deletedAt *time.Time
+	var currentValue, calibration []byte
+	err := row.Scan(
 		&s.ID,
 		&s.SerialNumber,
 		&s.Type,
@@ -106,27 +233,43 @@ func (r *SensorRepository) GetSensorByID(ctx context.Context, id int64) (*domain
 		&s.IsActive,
 		&s.RegisteredAt,
 		&s.LastActivity,
+		&s.HomeID,
+		&s.RoomID,
+		&deletedAt,
+		&currentValue,
+		&s.Unit,
+		&calibration,
+		&s.CalibratedState,
 	)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, usecase.ErrSensorNotFound
+	if err != nil {
+		return err
 	}
-	return &s, err
+	if deletedAt != nil {
+		s.DeletedAt = *deletedAt
+	}
+	if calibration != nil {
+		if err := json.Unmarshal(calibration, &s.Calibration); err != nil {
+			return err
+		}
+	}
+	if currentValue != nil {
+		return json.Unmarshal(currentValue, &s.CurrentValue)
+	}
+	return nil
 }
 
-func (r *SensorRepository) GetSensorBySerialNumber(ctx context.Context, sn string) (*domain.Sensor, error) {
-	var s domain.Sensor
-	err := r.pool.QueryRow(ctx, getSensorBySerialQuery, sn).Scan(
-		&s.ID,
-		&s.SerialNumber,
-		&s.Type,
-		&s.CurrentState,
-		&s.Description,
-		&s.IsActive,
-		&s.RegisteredAt,
-		&s.LastActivity,
-	)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, usecase.ErrSensorNotFound
+// marshalJSON - значение сохраняется в jsonb, nil - как NULL
+func marshalJSON(value any) ([]byte, error) {
+	if value == nil {
+		return nil, nil
 	}
-	return &s, err
+	return json.Marshal(value)
+}
+
+// nullTime - нулевое время сохраняется в базе как NULL
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
 }
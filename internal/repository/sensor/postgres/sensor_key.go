@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	saveSensorKeyQuery = `
+		INSERT INTO sensors_keys (sensor_id, key_hash, created_at, salt)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (sensor_id) DO UPDATE
+		SET key_hash = excluded.key_hash,
+		    created_at = excluded.created_at,
+		    salt = excluded.salt
+	`
+
+	getSensorKeyQuery = `
+		SELECT sensor_id, key_hash, created_at, salt
+		FROM sensors_keys
+		WHERE sensor_id = $1
+	`
+
+	deleteSensorKeyQuery = `
+		DELETE FROM sensors_keys
+		WHERE sensor_id = $1
+	`
+)
+
+type SensorKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSensorKeyRepository(pool *pgxpool.Pool) *SensorKeyRepository {
+	return &SensorKeyRepository{
+		pool: pool,
+	}
+}
+
+func (r *SensorKeyRepository) SaveSensorKey(ctx context.Context, key domain.SensorKey) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, saveSensorKeyQuery, key.SensorID, key.KeyHash, key.CreatedAt, key.Salt)
+	return err
+}
+
+func (r *SensorKeyRepository) GetSensorKey(ctx context.Context, sensorID int64) (*domain.SensorKey, error) {
+	var k domain.SensorKey
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, getSensorKeyQuery, sensorID).Scan(&k.SensorID, &k.KeyHash, &k.CreatedAt, &k.Salt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ErrSensorKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *SensorKeyRepository) DeleteSensorKey(ctx context.Context, sensorID int64) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, deleteSensorKeyQuery, sensorID)
+	return err
+}
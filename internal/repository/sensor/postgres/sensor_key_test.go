@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SensorKeyTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *SensorKeyRepository
+}
+
+func (suite *SensorKeyTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewSensorKeyRepository(suite.testDbInstance)
+}
+
+func (suite *SensorKeyTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *SensorKeyTestSuite) TestSensorKeyRepository_SaveSensorKey() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond)
+	err := suite.repo.SaveSensorKey(ctx, domain.SensorKey{SensorID: 1, KeyHash: []byte("first"), CreatedAt: now})
+	assert.NoError(suite.T(), err)
+
+	err = suite.repo.SaveSensorKey(ctx, domain.SensorKey{SensorID: 1, KeyHash: []byte("second"), Salt: []byte("salt"), CreatedAt: now})
+	assert.NoError(suite.T(), err)
+
+	key, err := suite.repo.GetSensorKey(ctx, 1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []byte("second"), key.KeyHash)
+	assert.Equal(suite.T(), []byte("salt"), key.Salt)
+	assert.Equal(suite.T(), now.Unix(), key.CreatedAt.Unix())
+}
+
+func (suite *SensorKeyTestSuite) TestSensorKeyRepository_DeleteSensorKey() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := suite.repo.SaveSensorKey(ctx, domain.SensorKey{SensorID: 2, KeyHash: []byte("hash"), CreatedAt: time.Now()})
+	assert.NoError(suite.T(), err)
+
+	assert.NoError(suite.T(), suite.repo.DeleteSensorKey(ctx, 2))
+
+	_, err = suite.repo.GetSensorKey(ctx, 2)
+	assert.ErrorIs(suite.T(), err, usecase.ErrSensorKeyNotFound)
+}
+
+func TestSensorKeyTestSuite(t *testing.T) {
+	suite.Run(t, new(SensorKeyTestSuite))
+}
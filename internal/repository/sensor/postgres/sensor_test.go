@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"homework/internal/domain"
+	"homework/internal/usecase"
 	"homework/pkg/pg_test"
 	"testing"
 	"time"
@@ -169,6 +170,163 @@ func (suite *SensorTestSuite) TestSensorRepository_GetSensorBySerialNumber() {
 	assert.Equal(suite.T(), newSensor, *sensor)
 }
 
+func (suite *SensorTestSuite) TestSensorRepository_GetSensorsByHomeID() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	newSensor := domain.Sensor{
+		SerialNumber: "3987654321",
+		Type:         domain.SensorTypeADC,
+		Description:  "test_desc_6",
+		IsActive:     true,
+		LastActivity: time.Now().Truncate(time.Microsecond).In(time.UTC),
+		HomeID:       42,
+	}
+	err := suite.repo.SaveSensor(ctx, &newSensor)
+
+	assert.Nil(suite.T(), err)
+
+	sensor, err := suite.repo.GetSensorByID(ctx, newSensor.ID)
+
+	assert.Nil(suite.T(), err)
+
+	newSensor.RegisteredAt = sensor.RegisteredAt
+
+	sensors, err := suite.repo.GetSensorsByHomeID(ctx, 42)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []domain.Sensor{newSensor}, sensors)
+}
+
+func (suite *SensorTestSuite) TestSensorRepository_DeletedSensor() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	newSensor := domain.Sensor{
+		SerialNumber: "3987654322",
+		Type:         domain.SensorTypeContactClosure,
+		Description:  "test_desc_7",
+		LastActivity: time.Now().Truncate(time.Microsecond).In(time.UTC),
+		HomeID:       43,
+	}
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &newSensor))
+	newSensor.DeletedAt = time.Now().Truncate(time.Microsecond).In(time.UTC)
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &newSensor))
+
+	sensors, err := suite.repo.GetSensorsByHomeID(ctx, 43)
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), sensors)
+
+	sensor, err := suite.repo.GetSensorByID(ctx, newSensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), newSensor.DeletedAt, sensor.DeletedAt)
+}
+
+func (suite *SensorTestSuite) TestSensorRepository_Calibration() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	calibratedState := 1.65
+	newSensor := domain.Sensor{
+		SerialNumber:    "3987654323",
+		Type:            domain.SensorTypeADC,
+		CurrentState:    2048,
+		CalibratedState: &calibratedState,
+		Unit:            "V",
+		Calibration: &domain.Calibration{
+			Kind:   domain.CalibrationTable,
+			Points: []domain.CalibrationPoint{{Raw: 0, Value: 0}, {Raw: 4096, Value: 3.3}},
+		},
+		LastActivity: time.Now().Truncate(time.Microsecond).In(time.UTC),
+	}
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &newSensor))
+
+	sensor, err := suite.repo.GetSensorByID(ctx, newSensor.ID)
+	assert.Nil(suite.T(), err)
+	newSensor.RegisteredAt = sensor.RegisteredAt
+	assert.Equal(suite.T(), newSensor, *sensor)
+
+	newSensor.Calibration = nil
+	newSensor.CalibratedState = nil
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &newSensor))
+
+	sensor, err = suite.repo.GetSensorByID(ctx, newSensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), sensor.Calibration)
+	assert.Nil(suite.T(), sensor.CalibratedState)
+}
+
+func (suite *SensorTestSuite) TestSensorRepository_SaveSensorState() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Microsecond).In(time.UTC)
+	newSensor := domain.Sensor{
+		SerialNumber: "3987654324",
+		Type:         domain.SensorTypeADC,
+		Description:  "test_desc_8",
+		IsActive:     true,
+		LastActivity: now,
+	}
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &newSensor))
+
+	assert.Nil(suite.T(), suite.repo.SaveSensorState(ctx, &domain.Sensor{ID: newSensor.ID, CurrentState: 5, LastActivity: now.Add(time.Second)}))
+	assert.Nil(suite.T(), suite.repo.SaveSensorState(ctx, &domain.Sensor{ID: newSensor.ID, CurrentState: 4, LastActivity: now}))
+	sensor, err := suite.repo.GetSensorByID(ctx, newSensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(5), sensor.CurrentState, "Более старое событие перезаписало состояние")
+	assert.Equal(suite.T(), "test_desc_8", sensor.Description)
+	assert.True(suite.T(), sensor.IsActive)
+
+	newSensor.DeletedAt = now
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &newSensor))
+	assert.Nil(suite.T(), suite.repo.SaveSensorState(ctx, &domain.Sensor{ID: newSensor.ID, CurrentState: 6, LastActivity: now.Add(time.Minute)}))
+	sensor, err = suite.repo.GetSensorByID(ctx, newSensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), newSensor.CurrentState, sensor.CurrentState, "Событие изменило удалённый датчик")
+	assert.Equal(suite.T(), newSensor.DeletedAt, sensor.DeletedAt)
+}
+
+func (suite *SensorTestSuite) TestSensorRepository_GetSensorByIDForUpdate() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	newSensor := domain.Sensor{
+		SerialNumber: "3987654325",
+		Type:         domain.SensorTypeADC,
+		Description:  "test_desc_9",
+		IsActive:     true,
+	}
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &newSensor))
+
+	sensor, err := suite.repo.GetSensorByIDForUpdate(ctx, newSensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "test_desc_9", sensor.Description)
+
+	_, err = suite.repo.GetSensorByIDForUpdate(ctx, newSensor.ID+1000)
+	assert.ErrorIs(suite.T(), err, usecase.ErrSensorNotFound)
+}
+
+func (suite *SensorTestSuite) TestSensorRepository_SaveSensorRoom() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	newSensor := domain.Sensor{
+		SerialNumber: "3987654326",
+		Type:         domain.SensorTypeADC,
+		Description:  "test_desc_10",
+		IsActive:     true,
+	}
+	assert.Nil(suite.T(), suite.repo.SaveSensor(ctx, &newSensor))
+	assert.Nil(suite.T(), suite.repo.SaveSensorState(ctx, &domain.Sensor{ID: newSensor.ID, CurrentState: 5, LastActivity: time.Now()}))
+
+	assert.Nil(suite.T(), suite.repo.SaveSensorRoom(ctx, newSensor.ID, 0))
+	sensor, err := suite.repo.GetSensorByID(ctx, newSensor.ID)
+	assert.Nil(suite.T(), err)
+	assert.Zero(suite.T(), sensor.RoomID)
+	assert.Equal(suite.T(), int64(5), sensor.CurrentState, "Перенос в комнату затёр состояние")
+}
+
 func TestSensorTestSuite(t *testing.T) {
 	suite.Run(t, new(SensorTestSuite))
 }
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
+	"homework/internal/usecase"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	saveSensorTypeQuery = `
+		INSERT INTO sensor_types (type, name, value_kind, min_value, max_value, unit, channels)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (type) DO NOTHING
+	`
+
+	getSensorTypeQuery = `
+		SELECT type, name, value_kind, min_value, max_value, unit, channels
+		FROM sensor_types
+		WHERE type = $1
+	`
+
+	getSensorTypesQuery = `
+		SELECT type, name, value_kind, min_value, max_value, unit, channels
+		FROM sensor_types
+		ORDER BY type
+	`
+)
+
+type SensorTypeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSensorTypeRepository(pool *pgxpool.Pool) *SensorTypeRepository {
+	return &SensorTypeRepository{
+		pool: pool,
+	}
+}
+
+func (r *SensorTypeRepository) SaveSensorType(ctx context.Context, definition domain.SensorTypeDefinition) error {
+	var channels any
+	if len(definition.Channels) != 0 {
+		channels = definition.Channels
+	}
+	encodedChannels, err := marshalJSON(channels)
+	if err != nil {
+		return err
+	}
+	tag, err := transaction.Conn(ctx, r.pool).Exec(ctx, saveSensorTypeQuery, definition.Type, definition.Name, definition.ValueKind,
+		definition.MinValue, definition.MaxValue, definition.Unit, encodedChannels)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return usecase.ErrSensorTypeAlreadyExists
+	}
+	return nil
+}
+
+func (r *SensorTypeRepository) GetSensorType(ctx context.Context, sensorType domain.SensorType) (*domain.SensorTypeDefinition, error) {
+	var d domain.SensorTypeDefinition
+	err := scanSensorType(transaction.Conn(ctx, r.pool).QueryRow(ctx, getSensorTypeQuery, sensorType), &d)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ErrSensorTypeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *SensorTypeRepository) GetSensorTypes(ctx context.Context) ([]domain.SensorTypeDefinition, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, getSensorTypesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []domain.SensorTypeDefinition
+	for rows.Next() {
+		var d domain.SensorTypeDefinition
+		if err := scanSensorType(rows, &d); err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	return result, rows.Err()
+}
+
+func scanSensorType(row pgx.Row, d *domain.SensorTypeDefinition) error {
+	var channels []byte
+	if err := row.Scan(&d.Type, &d.Name, &d.ValueKind, &d.MinValue, &d.MaxValue, &d.Unit, &channels); err != nil {
+		return err
+	}
+	if channels != nil {
+		return json.Unmarshal(channels, &d.Channels)
+	}
+	return nil
+}
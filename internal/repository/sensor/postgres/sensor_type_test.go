@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"homework/internal/domain"
+	"homework/internal/usecase"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SensorTypeTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	repo *SensorTypeRepository
+}
+
+func (suite *SensorTypeTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.repo = NewSensorTypeRepository(suite.testDbInstance)
+}
+
+func (suite *SensorTypeTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+func (suite *SensorTypeTestSuite) TestSensorTypeRepository_BuiltinTypes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	types, err := suite.repo.GetSensorTypes(ctx)
+	assert.NoError(suite.T(), err)
+	for _, builtin := range domain.BuiltinSensorTypes() {
+		assert.Contains(suite.T(), types, builtin)
+	}
+}
+
+func (suite *SensorTypeTestSuite) TestSensorTypeRepository_SaveSensorType() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pressure := domain.SensorTypeDefinition{
+		Type:      "pressure",
+		Name:      "Давление",
+		ValueKind: domain.SensorValueKindInteger,
+		MinValue:  300,
+		MaxValue:  1100,
+		Unit:      "гПа",
+	}
+	assert.NoError(suite.T(), suite.repo.SaveSensorType(ctx, pressure))
+	assert.ErrorIs(suite.T(), suite.repo.SaveSensorType(ctx, pressure), usecase.ErrSensorTypeAlreadyExists)
+
+	definition, err := suite.repo.GetSensorType(ctx, "pressure")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), pressure, *definition)
+}
+
+func (suite *SensorTypeTestSuite) TestSensorTypeRepository_GetSensorTypeNotFound() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := suite.repo.GetSensorType(ctx, "unknown")
+	assert.ErrorIs(suite.T(), err, usecase.ErrSensorTypeNotFound)
+}
+
+func TestSensorTypeTestSuite(t *testing.T) {
+	suite.Run(t, new(SensorTypeTestSuite))
+}
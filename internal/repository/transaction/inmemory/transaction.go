@@ -0,0 +1,60 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+)
+
+type txKey struct{}
+
+// transaction - действия, которые отменяют изменения репозиториев, в порядке их записи
+type transaction struct {
+	undo []func()
+}
+
+type Transactor struct {
+	mu sync.Mutex
+}
+
+func NewTransactor() *Transactor {
+	return &Transactor{}
+}
+
+// WithinTransaction - выполняет fn, не пуская в это время другие транзакции.
+// Если fn вернула ошибку, изменения, о которых репозитории сообщили через OnRollback, отменяются в обратном порядке.
+// Вложенный вызов выполняется в уже открытой транзакции как точка сохранения: его ошибка отменяет только
+// изменения вложенного вызова, а после успешного вызова они отменяются вместе с внешней транзакцией
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if parent, ok := ctx.Value(txKey{}).(*transaction); ok {
+		tx := &transaction{}
+		if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+			tx.rollback()
+			return err
+		}
+		parent.undo = append(parent.undo, tx.undo...)
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tx := &transaction{}
+	err := fn(context.WithValue(ctx, txKey{}, tx))
+	if err != nil {
+		tx.rollback()
+	}
+	return err
+}
+
+// rollback - отменяет изменения транзакции в обратном порядке
+func (tx *transaction) rollback() {
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+}
+
+// OnRollback - запоминает действие, отменяющее изменение репозитория, если изменение сделано в транзакции.
+// Вне транзакции ничего не делает
+func OnRollback(ctx context.Context, undo func()) {
+	if tx, ok := ctx.Value(txKey{}).(*transaction); ok {
+		tx.undo = append(tx.undo, undo)
+	}
+}
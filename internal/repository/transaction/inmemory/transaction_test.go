@@ -0,0 +1,80 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactor_WithinTransaction(t *testing.T) {
+	t.Run("ok, commit keeps changes", func(t *testing.T) {
+		tr := NewTransactor()
+		var undone bool
+
+		err := tr.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			OnRollback(ctx, func() { undone = true })
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.False(t, undone)
+	})
+
+	t.Run("err, rollback undoes changes in reverse order", func(t *testing.T) {
+		tr := NewTransactor()
+		var undone []int
+		someErr := errors.New("some error")
+
+		err := tr.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			OnRollback(ctx, func() { undone = append(undone, 1) })
+			OnRollback(ctx, func() { undone = append(undone, 2) })
+			return someErr
+		})
+		assert.ErrorIs(t, err, someErr)
+		assert.Equal(t, []int{2, 1}, undone)
+	})
+
+	t.Run("err, nested transaction rolls back with outer one", func(t *testing.T) {
+		tr := NewTransactor()
+		var undone []int
+		someErr := errors.New("some error")
+
+		err := tr.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			OnRollback(ctx, func() { undone = append(undone, 1) })
+			if err := tr.WithinTransaction(ctx, func(ctx context.Context) error {
+				OnRollback(ctx, func() { undone = append(undone, 2) })
+				return nil
+			}); err != nil {
+				return err
+			}
+			return someErr
+		})
+		assert.ErrorIs(t, err, someErr)
+		assert.Equal(t, []int{2, 1}, undone)
+	})
+
+	t.Run("err, failed nested transaction rolls back alone", func(t *testing.T) {
+		tr := NewTransactor()
+		var undone []int
+		someErr := errors.New("some error")
+
+		err := tr.WithinTransaction(context.Background(), func(ctx context.Context) error {
+			OnRollback(ctx, func() { undone = append(undone, 1) })
+			err := tr.WithinTransaction(ctx, func(ctx context.Context) error {
+				OnRollback(ctx, func() { undone = append(undone, 2) })
+				return someErr
+			})
+			assert.ErrorIs(t, err, someErr)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2}, undone, "Внешняя транзакция откачена вместе с вложенной")
+	})
+
+	t.Run("ok, outside transaction nothing is recorded", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			OnRollback(context.Background(), func() { t.Fatal("undo called outside transaction") })
+		})
+	})
+}
@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Querier - общие методы пула соединений и транзакции, через которые репозитории выполняют запросы
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type txKey struct{}
+
+type Transactor struct {
+	pool *pgxpool.Pool
+}
+
+func NewTransactor(pool *pgxpool.Pool) *Transactor {
+	return &Transactor{pool: pool}
+}
+
+// WithinTransaction - выполняет fn в транзакции, которую репозитории находят в контексте через Conn.
+// Транзакция фиксируется, если fn вернула nil, иначе откатывается.
+// Вложенный вызов выполняется в точке сохранения уже открытой транзакции: ошибка fn откатывает только
+// изменения вложенного вызова, и транзакция остаётся пригодной для следующих запросов
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	var db interface {
+		Begin(ctx context.Context) (pgx.Tx, error)
+	} = t.pool
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		db = tx
+	}
+	return pgx.BeginFunc(ctx, db, func(tx pgx.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// Conn - возвращает транзакцию из контекста, а вне транзакции - пул соединений
+func Conn(ctx context.Context, pool *pgxpool.Pool) Querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return pool
+}
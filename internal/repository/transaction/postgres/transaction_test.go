@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"homework/pkg/pg_test"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TransactionTestSuite struct {
+	suite.Suite
+	testDbInstance *pgxpool.Pool
+	testDB         *pg_test.TestDatabase
+
+	tr *Transactor
+}
+
+func (suite *TransactionTestSuite) SetupSuite() {
+	suite.testDB = pg_test.SetupTestDatabase()
+	suite.testDbInstance = suite.testDB.DbInstance
+
+	suite.tr = NewTransactor(suite.testDbInstance)
+}
+
+func (suite *TransactionTestSuite) TearDownSuite() {
+	suite.testDB.TearDown()
+}
+
+// insertUser - создаёт пользователя через соединение из контекста
+func (suite *TransactionTestSuite) insertUser(ctx context.Context, name string) error {
+	_, err := Conn(ctx, suite.testDbInstance).Exec(ctx, "INSERT INTO users (name) VALUES ($1)", name)
+	return err
+}
+
+func (suite *TransactionTestSuite) countUsers(ctx context.Context, name string) int {
+	var count int
+	err := suite.testDbInstance.QueryRow(ctx, "SELECT count(*) FROM users WHERE name = $1", name).Scan(&count)
+	assert.Nil(suite.T(), err)
+	return count
+}
+
+func (suite *TransactionTestSuite) TestTransactor_Commit() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := suite.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := suite.insertUser(ctx, "commit"); err != nil {
+			return err
+		}
+		return suite.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+			return suite.insertUser(ctx, "commit")
+		})
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, suite.countUsers(ctx, "commit"))
+}
+
+func (suite *TransactionTestSuite) TestTransactor_Rollback() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	someErr := errors.New("some error")
+	err := suite.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := suite.insertUser(ctx, "rollback"); err != nil {
+			return err
+		}
+		assert.Equal(suite.T(), 0, suite.countUsers(ctx, "rollback"), "Изменения транзакции видны до её фиксации")
+		return someErr
+	})
+	assert.ErrorIs(suite.T(), err, someErr)
+	assert.Equal(suite.T(), 0, suite.countUsers(ctx, "rollback"))
+}
+
+func (suite *TransactionTestSuite) TestTransactor_NestedRollback() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := suite.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := suite.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := suite.insertUser(ctx, "savepoint"); err != nil {
+				return err
+			}
+			_, err := Conn(ctx, suite.testDbInstance).Exec(ctx, "SELECT 1 / 0")
+			return err
+		})
+		assert.Error(suite.T(), err)
+		// ошибка вложенного вызова не прерывает внешнюю транзакцию
+		return suite.insertUser(ctx, "savepoint-outer")
+	})
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 0, suite.countUsers(ctx, "savepoint"))
+	assert.Equal(suite.T(), 1, suite.countUsers(ctx, "savepoint-outer"))
+}
+
+func TestTransactionTestSuite(t *testing.T) {
+	suite.Run(t, new(TransactionTestSuite))
+}
@@ -3,6 +3,8 @@ package inmemory
 import (
 	"context"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/inmemory"
+	"slices"
 	"sync"
 )
 
@@ -23,6 +25,7 @@ func (r *SensorOwnerRepository) SaveSensorOwner(ctx context.Context, sensorOwner
 	if err := ctx.Err(); err != nil {
 		return err
 	}
+	r.onRollbackRestore(ctx, sensorOwner.UserID)
 	if _, exists := r.sensorOwners[sensorOwner.UserID]; !exists {
 		r.sensorOwners[sensorOwner.UserID] = []domain.SensorOwner{}
 	}
@@ -30,7 +33,24 @@ func (r *SensorOwnerRepository) SaveSensorOwner(ctx context.Context, sensorOwner
 	return nil
 }
 
+func (r *SensorOwnerRepository) UpdateSensorOwner(ctx context.Context, sensorOwner domain.SensorOwner) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.onRollbackRestore(ctx, sensorOwner.UserID)
+	for i, existing := range r.sensorOwners[sensorOwner.UserID] {
+		if existing.SensorID == sensorOwner.SensorID {
+			r.sensorOwners[sensorOwner.UserID][i].Role = sensorOwner.Role
+		}
+	}
+	return nil
+}
+
 func (r *SensorOwnerRepository) GetSensorsByUserID(ctx context.Context, userID int64) ([]domain.SensorOwner, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -38,5 +58,50 @@ func (r *SensorOwnerRepository) GetSensorsByUserID(ctx context.Context, userID i
 	if !ok {
 		return nil, nil
 	}
+	return slices.Clone(sensorOwners), nil
+}
+
+func (r *SensorOwnerRepository) GetSensorOwners(ctx context.Context, sensorID int64) ([]domain.SensorOwner, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var sensorOwners []domain.SensorOwner
+	for _, userSensors := range r.sensorOwners {
+		for _, sensorOwner := range userSensors {
+			if sensorOwner.SensorID == sensorID {
+				sensorOwners = append(sensorOwners, sensorOwner)
+			}
+		}
+	}
 	return sensorOwners, nil
 }
+
+func (r *SensorOwnerRepository) DeleteSensorOwner(ctx context.Context, userID, sensorID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.onRollbackRestore(ctx, userID)
+	r.sensorOwners[userID] = slices.DeleteFunc(r.sensorOwners[userID], func(sensorOwner domain.SensorOwner) bool {
+		return sensorOwner.SensorID == sensorID
+	})
+	return nil
+}
+
+// onRollbackRestore - при откате транзакции возвращает привязки пользователя к текущему состоянию
+func (r *SensorOwnerRepository) onRollbackRestore(ctx context.Context, userID int64) {
+	prev, exists := r.sensorOwners[userID]
+	prev = slices.Clone(prev)
+	transaction.OnRollback(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if exists {
+			r.sensorOwners[userID] = prev
+		} else {
+			delete(r.sensorOwners, userID)
+		}
+	})
+}
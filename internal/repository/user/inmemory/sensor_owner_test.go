@@ -2,7 +2,9 @@ package inmemory
 
 import (
 	"context"
+	"errors"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/inmemory"
 	"math/rand/v2"
 	"sync"
 	"testing"
@@ -70,6 +72,33 @@ func TestSensorOwnerRepository_SaveSensorOwner(t *testing.T) {
 
 		wg.Wait()
 	})
+
+	t.Run("ok, rollback restores bindings", func(t *testing.T) {
+		sor := NewSensorOwnerRepository()
+		tr := transaction.NewTransactor()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		owner := domain.SensorOwner{UserID: 1, SensorID: 1, Role: domain.SensorRoleOwner}
+		assert.NoError(t, sor.SaveSensorOwner(ctx, owner))
+
+		someErr := errors.New("some error")
+		err := tr.WithinTransaction(ctx, func(ctx context.Context) error {
+			assert.NoError(t, sor.UpdateSensorOwner(ctx, domain.SensorOwner{UserID: 1, SensorID: 1, Role: domain.SensorRoleViewer}))
+			assert.NoError(t, sor.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 1, SensorID: 2, Role: domain.SensorRoleMember}))
+			assert.NoError(t, sor.DeleteSensorOwner(ctx, 1, 1))
+			assert.NoError(t, sor.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 2, SensorID: 1, Role: domain.SensorRoleOwner}))
+			return someErr
+		})
+		assert.ErrorIs(t, err, someErr)
+
+		list, err := sor.GetSensorsByUserID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.SensorOwner{owner}, list)
+		list, err = sor.GetSensorsByUserID(ctx, 2)
+		assert.NoError(t, err)
+		assert.Empty(t, list)
+	})
 }
 
 func TestSensorOwnerRepository_GetSensorsByUserID(t *testing.T) {
@@ -155,3 +184,84 @@ func FuzzSensorOwnerRepository_GetSensorsByUserID(f *testing.F) {
 		}
 	})
 }
+
+func TestSensorOwnerRepository_UpdateSensorOwner(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		sor := NewSensorOwnerRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := sor.UpdateSensorOwner(ctx, domain.SensorOwner{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, role changed", func(t *testing.T) {
+		sor := NewSensorOwnerRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.NoError(t, sor.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 1, SensorID: 1, Role: domain.SensorRoleViewer}))
+		assert.NoError(t, sor.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 1, SensorID: 2, Role: domain.SensorRoleViewer}))
+		assert.NoError(t, sor.UpdateSensorOwner(ctx, domain.SensorOwner{UserID: 1, SensorID: 1, Role: domain.SensorRoleMember}))
+
+		sensors, err := sor.GetSensorsByUserID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.SensorOwner{
+			{UserID: 1, SensorID: 1, Role: domain.SensorRoleMember},
+			{UserID: 1, SensorID: 2, Role: domain.SensorRoleViewer},
+		}, sensors)
+	})
+}
+
+func TestSensorOwnerRepository_GetSensorOwners(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		sor := NewSensorOwnerRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := sor.GetSensorOwners(ctx, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, get list", func(t *testing.T) {
+		sor := NewSensorOwnerRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.NoError(t, sor.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 1, SensorID: 1, Role: domain.SensorRoleOwner}))
+		assert.NoError(t, sor.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 2, SensorID: 1, Role: domain.SensorRoleViewer}))
+		assert.NoError(t, sor.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 2, SensorID: 2, Role: domain.SensorRoleOwner}))
+
+		sensorOwners, err := sor.GetSensorOwners(ctx, 1)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []domain.SensorOwner{
+			{UserID: 1, SensorID: 1, Role: domain.SensorRoleOwner},
+			{UserID: 2, SensorID: 1, Role: domain.SensorRoleViewer},
+		}, sensorOwners)
+	})
+}
+
+func TestSensorOwnerRepository_DeleteSensorOwner(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		sor := NewSensorOwnerRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := sor.DeleteSensorOwner(ctx, 1, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("ok, binding deleted", func(t *testing.T) {
+		sor := NewSensorOwnerRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.NoError(t, sor.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 1, SensorID: 1, Role: domain.SensorRoleOwner}))
+		assert.NoError(t, sor.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 1, SensorID: 2, Role: domain.SensorRoleOwner}))
+		assert.NoError(t, sor.DeleteSensorOwner(ctx, 1, 1))
+
+		sensors, err := sor.GetSensorsByUserID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.SensorOwner{{UserID: 1, SensorID: 2, Role: domain.SensorRoleOwner}}, sensors)
+	})
+}
@@ -1,23 +1,27 @@
 package inmemory
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"homework/internal/domain"
 	"homework/internal/usecase"
+	"slices"
 	"sync"
 )
 
 type UserRepository struct {
-	usersByID map[int64]*domain.User
-	nextID    int64
-	mu        sync.Mutex
+	usersByID          map[int64]*domain.User
+	credentialsByLogin map[string]domain.Credentials
+	nextID             int64
+	mu                 sync.Mutex
 }
 
 func NewUserRepository() *UserRepository {
 	return &UserRepository{
-		usersByID: make(map[int64]*domain.User),
-		nextID:    1,
+		usersByID:          make(map[int64]*domain.User),
+		credentialsByLogin: make(map[string]domain.Credentials),
+		nextID:             1,
 	}
 }
 
@@ -30,8 +34,10 @@ func (r *UserRepository) SaveUser(ctx context.Context, user *domain.User) error
 	if user == nil {
 		return errors.New("nil user")
 	}
-	user.ID = r.nextID
-	r.nextID++
+	if user.ID == 0 {
+		user.ID = r.nextID
+		r.nextID++
+	}
 	r.usersByID[user.ID] = user
 	return nil
 }
@@ -46,3 +52,63 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id int64) (*domain.Use
 	}
 	return user, nil
 }
+
+func (r *UserRepository) GetUsers(ctx context.Context) ([]domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	users := make([]domain.User, 0, len(r.usersByID))
+	for _, user := range r.usersByID {
+		users = append(users, *user)
+	}
+	slices.SortFunc(users, func(a, b domain.User) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return users, nil
+}
+
+func (r *UserRepository) DeleteUser(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if _, ok := r.usersByID[id]; !ok {
+		return usecase.ErrUserNotFound
+	}
+	delete(r.usersByID, id)
+	for login, credentials := range r.credentialsByLogin {
+		if credentials.UserID == id {
+			delete(r.credentialsByLogin, login)
+		}
+	}
+	return nil
+}
+
+func (r *UserRepository) SaveCredentials(ctx context.Context, credentials domain.Credentials) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if _, ok := r.credentialsByLogin[credentials.Login]; ok {
+		return usecase.ErrUserAlreadyExists
+	}
+	r.credentialsByLogin[credentials.Login] = credentials
+	return nil
+}
+
+func (r *UserRepository) GetCredentialsByLogin(ctx context.Context, login string) (*domain.Credentials, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	credentials, ok := r.credentialsByLogin[login]
+	if !ok {
+		return nil, usecase.ErrUserNotFound
+	}
+	return &credentials, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"homework/internal/domain"
+	"homework/internal/usecase"
 	"sync"
 	"testing"
 	"time"
@@ -69,3 +70,95 @@ func TestUserRepository_SaveUser(t *testing.T) {
 		wg.Wait()
 	})
 }
+
+func TestUserRepository_Credentials(t *testing.T) {
+	t.Run("fail, ctx cancelled", func(t *testing.T) {
+		ur := NewUserRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := ur.SaveCredentials(ctx, domain.Credentials{})
+		assert.ErrorIs(t, err, context.Canceled)
+
+		_, err = ur.GetCredentialsByLogin(ctx, "login")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("fail, not found", func(t *testing.T) {
+		ur := NewUserRepository()
+
+		_, err := ur.GetCredentialsByLogin(context.Background(), "login")
+		assert.ErrorIs(t, err, usecase.ErrUserNotFound)
+	})
+
+	t.Run("fail, login taken", func(t *testing.T) {
+		ur := NewUserRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.NoError(t, ur.SaveCredentials(ctx, domain.Credentials{UserID: 1, Login: "login"}))
+		err := ur.SaveCredentials(ctx, domain.Credentials{UserID: 2, Login: "login"})
+		assert.ErrorIs(t, err, usecase.ErrUserAlreadyExists)
+	})
+
+	t.Run("ok, save and get", func(t *testing.T) {
+		ur := NewUserRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		credentials := domain.Credentials{
+			UserID:       1,
+			Login:        "login",
+			PasswordHash: []byte("hash"),
+		}
+		assert.NoError(t, ur.SaveCredentials(ctx, credentials))
+
+		got, err := ur.GetCredentialsByLogin(ctx, "login")
+		assert.NoError(t, err)
+		assert.Equal(t, credentials, *got)
+	})
+}
+
+func TestUserRepository_UpdateAndList(t *testing.T) {
+	t.Run("ok, rename keeps id", func(t *testing.T) {
+		ur := NewUserRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		user := &domain.User{Name: "old name"}
+		assert.NoError(t, ur.SaveUser(ctx, user))
+		assert.NoError(t, ur.SaveUser(ctx, &domain.User{Name: "other"}))
+		assert.NoError(t, ur.SaveUser(ctx, &domain.User{ID: user.ID, Name: "new name"}))
+
+		users, err := ur.GetUsers(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.User{{ID: 1, Name: "new name"}, {ID: 2, Name: "other"}}, users)
+	})
+}
+
+func TestUserRepository_DeleteUser(t *testing.T) {
+	t.Run("fail, not found", func(t *testing.T) {
+		ur := NewUserRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assert.ErrorIs(t, ur.DeleteUser(ctx, 1), usecase.ErrUserNotFound)
+	})
+
+	t.Run("ok, user and credentials deleted", func(t *testing.T) {
+		ur := NewUserRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		user := &domain.User{Name: "login"}
+		assert.NoError(t, ur.SaveUser(ctx, user))
+		assert.NoError(t, ur.SaveCredentials(ctx, domain.Credentials{UserID: user.ID, Login: "login"}))
+
+		assert.NoError(t, ur.DeleteUser(ctx, user.ID))
+
+		_, err := ur.GetUserByID(ctx, user.ID)
+		assert.ErrorIs(t, err, usecase.ErrUserNotFound)
+		_, err = ur.GetCredentialsByLogin(ctx, "login")
+		assert.ErrorIs(t, err, usecase.ErrUserNotFound)
+	})
+}
@@ -3,21 +3,40 @@ package postgres
 import (
 	"context"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 const (
 	saveSensorOwnerQuery = `
-		INSERT INTO sensors_users (user_id, sensor_id)
-		VALUES ($1, $2)
+		INSERT INTO sensors_users (user_id, sensor_id, role)
+		VALUES ($1, $2, $3)
+	`
+
+	updateSensorOwnerQuery = `
+		UPDATE sensors_users
+		SET role = $3
+		WHERE user_id = $1 AND sensor_id = $2
 	`
 
 	getSensorsByUserIDQuery = `
-		SELECT user_id, sensor_id
+		SELECT user_id, sensor_id, role
 		FROM sensors_users
 		WHERE user_id = $1
 	`
+
+	getSensorOwnersQuery = `
+		SELECT user_id, sensor_id, role
+		FROM sensors_users
+		WHERE sensor_id = $1
+	`
+
+	deleteSensorOwnerQuery = `
+		DELETE FROM sensors_users
+		WHERE user_id = $1 AND sensor_id = $2
+	`
 )
 
 type SensorOwnerRepository struct {
@@ -31,25 +50,47 @@ func NewSensorOwnerRepository(pool *pgxpool.Pool) *SensorOwnerRepository {
 }
 
 func (r *SensorOwnerRepository) SaveSensorOwner(ctx context.Context, sensorOwner domain.SensorOwner) error {
-	_, err := r.pool.Exec(ctx, saveSensorOwnerQuery, sensorOwner.UserID, sensorOwner.SensorID)
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, saveSensorOwnerQuery, sensorOwner.UserID, sensorOwner.SensorID, sensorOwner.Role)
+	return err
+}
+
+func (r *SensorOwnerRepository) UpdateSensorOwner(ctx context.Context, sensorOwner domain.SensorOwner) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, updateSensorOwnerQuery, sensorOwner.UserID, sensorOwner.SensorID, sensorOwner.Role)
 	return err
 }
 
 func (r *SensorOwnerRepository) GetSensorsByUserID(ctx context.Context, userID int64) ([]domain.SensorOwner, error) {
-	rows, err := r.pool.Query(ctx, getSensorsByUserIDQuery, userID)
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, getSensorsByUserIDQuery, userID)
 	if err != nil {
 		return nil, err
 	}
+	return scanSensorOwners(rows)
+}
+
+func (r *SensorOwnerRepository) GetSensorOwners(ctx context.Context, sensorID int64) ([]domain.SensorOwner, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, getSensorOwnersQuery, sensorID)
+	if err != nil {
+		return nil, err
+	}
+	return scanSensorOwners(rows)
+}
+
+func (r *SensorOwnerRepository) DeleteSensorOwner(ctx context.Context, userID, sensorID int64) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, deleteSensorOwnerQuery, userID, sensorID)
+	return err
+}
+
+func scanSensorOwners(rows pgx.Rows) ([]domain.SensorOwner, error) {
 	defer rows.Close()
 
 	var sensors []domain.SensorOwner
 	for rows.Next() {
 		var so domain.SensorOwner
-		err := rows.Scan(&so.UserID, &so.SensorID)
+		err := rows.Scan(&so.UserID, &so.SensorID, &so.Role)
 		if err != nil {
 			return nil, err
 		}
 		sensors = append(sensors, so)
 	}
-	return sensors, nil
+	return sensors, rows.Err()
 }
@@ -37,6 +37,7 @@ func (suite *SensorOwnerTestSuite) TestSensorOwnerRepository_SaveSensorOwner() {
 	err := suite.repo.SaveSensorOwner(ctx, domain.SensorOwner{
 		UserID:   1,
 		SensorID: 1,
+		Role:     domain.SensorRoleOwner,
 	})
 
 	assert.Nil(suite.T(), err)
@@ -48,6 +49,7 @@ func (suite *SensorOwnerTestSuite) TestSensorOwnerRepository_GetSensorsByUserID(
 	err := suite.repo.SaveSensorOwner(ctx, domain.SensorOwner{
 		UserID:   2,
 		SensorID: 2,
+		Role:     domain.SensorRoleOwner,
 	})
 
 	assert.Nil(suite.T(), err)
@@ -55,6 +57,7 @@ func (suite *SensorOwnerTestSuite) TestSensorOwnerRepository_GetSensorsByUserID(
 	err = suite.repo.SaveSensorOwner(ctx, domain.SensorOwner{
 		UserID:   2,
 		SensorID: 3,
+		Role:     domain.SensorRoleViewer,
 	})
 
 	assert.Nil(suite.T(), err)
@@ -64,11 +67,35 @@ func (suite *SensorOwnerTestSuite) TestSensorOwnerRepository_GetSensorsByUserID(
 	assert.Nil(suite.T(), err)
 
 	assert.ElementsMatch(suite.T(), []domain.SensorOwner{
-		{UserID: 2, SensorID: 2},
-		{UserID: 2, SensorID: 3},
+		{UserID: 2, SensorID: 2, Role: domain.SensorRoleOwner},
+		{UserID: 2, SensorID: 3, Role: domain.SensorRoleViewer},
 	}, sensors)
 }
 
+func (suite *SensorOwnerTestSuite) TestSensorOwnerRepository_UpdateAndDeleteSensorOwner() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NoError(suite.T(), suite.repo.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 3, SensorID: 4, Role: domain.SensorRoleOwner}))
+	assert.NoError(suite.T(), suite.repo.SaveSensorOwner(ctx, domain.SensorOwner{UserID: 4, SensorID: 4, Role: domain.SensorRoleViewer}))
+
+	err := suite.repo.UpdateSensorOwner(ctx, domain.SensorOwner{UserID: 4, SensorID: 4, Role: domain.SensorRoleMember})
+	assert.NoError(suite.T(), err)
+
+	sensorOwners, err := suite.repo.GetSensorOwners(ctx, 4)
+	assert.NoError(suite.T(), err)
+	assert.ElementsMatch(suite.T(), []domain.SensorOwner{
+		{UserID: 3, SensorID: 4, Role: domain.SensorRoleOwner},
+		{UserID: 4, SensorID: 4, Role: domain.SensorRoleMember},
+	}, sensorOwners)
+
+	assert.NoError(suite.T(), suite.repo.DeleteSensorOwner(ctx, 4, 4))
+
+	sensorOwners, err = suite.repo.GetSensorOwners(ctx, 4)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []domain.SensorOwner{{UserID: 3, SensorID: 4, Role: domain.SensorRoleOwner}}, sensorOwners)
+}
+
 func TestSensorOwnerTestSuite(t *testing.T) {
 	suite.Run(t, new(SensorOwnerTestSuite))
 }
@@ -4,24 +4,56 @@ import (
 	"context"
 	"errors"
 	"homework/internal/domain"
+	transaction "homework/internal/repository/transaction/postgres"
 	"homework/internal/usecase"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 const (
 	saveUserQuery = `
-		INSERT INTO users (name)
-		VALUES ($1)
+		INSERT INTO users (name, is_admin)
+		VALUES ($1, $2)
 		RETURNING id`
 
+	saveUserQueryWithID = `
+		UPDATE users
+		SET name = $1, is_admin = $2
+		WHERE id = $3`
+
 	getUserByIDQuery = `
-		SELECT id, name
+		SELECT id, name, is_admin
+		FROM users
+		WHERE id = $1`
+
+	getUsersQuery = `
+		SELECT id, name, is_admin
 		FROM users
+		ORDER BY id`
+
+	deleteUserQuery = `
+		DELETE FROM users
 		WHERE id = $1`
+
+	deleteCredentialsQuery = `
+		DELETE FROM users_credentials
+		WHERE user_id = $1`
+
+	saveCredentialsQuery = `
+		INSERT INTO users_credentials (user_id, login, password_hash)
+		VALUES ($1, $2, $3)`
+
+	getCredentialsByLoginQuery = `
+		SELECT user_id, login, password_hash
+		FROM users_credentials
+		WHERE login = $1`
 )
 
+// uniqueViolationCode - код ошибки postgres при нарушении ограничения уникальности
+const uniqueViolationCode = "23505"
+
 type UserRepository struct {
 	pool *pgxpool.Pool
 }
@@ -33,14 +65,74 @@ func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
 }
 
 func (r *UserRepository) SaveUser(ctx context.Context, user *domain.User) error {
-	return r.pool.QueryRow(ctx, saveUserQuery, user.Name).Scan(&user.ID)
+	if user.ID == 0 {
+		return transaction.Conn(ctx, r.pool).QueryRow(ctx, saveUserQuery, user.Name, user.IsAdmin).Scan(&user.ID)
+	}
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, saveUserQueryWithID, user.Name, user.IsAdmin, user.ID)
+	return err
 }
 
 func (r *UserRepository) GetUserByID(ctx context.Context, id int64) (*domain.User, error) {
 	var user domain.User
-	err := r.pool.QueryRow(ctx, getUserByIDQuery, id).Scan(&user.ID, &user.Name)
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, getUserByIDQuery, id).Scan(&user.ID, &user.Name, &user.IsAdmin)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, usecase.ErrUserNotFound
 	}
 	return &user, err
 }
+
+func (r *UserRepository) GetUsers(ctx context.Context) ([]domain.User, error) {
+	rows, err := transaction.Conn(ctx, r.pool).Query(ctx, getUsersQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []domain.User
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.ID, &user.Name, &user.IsAdmin); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// DeleteUser - удаляет пользователя вместе с учётными данными в одной транзакции
+func (r *UserRepository) DeleteUser(ctx context.Context, id int64) error {
+	return pgx.BeginFunc(ctx, transaction.Conn(ctx, r.pool), func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, deleteCredentialsQuery, id); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, deleteUserQuery, id)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return usecase.ErrUserNotFound
+		}
+		return nil
+	})
+}
+
+func (r *UserRepository) SaveCredentials(ctx context.Context, credentials domain.Credentials) error {
+	_, err := transaction.Conn(ctx, r.pool).Exec(ctx, saveCredentialsQuery, credentials.UserID, credentials.Login, credentials.PasswordHash)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return usecase.ErrUserAlreadyExists
+	}
+	return err
+}
+
+func (r *UserRepository) GetCredentialsByLogin(ctx context.Context, login string) (*domain.Credentials, error) {
+	var credentials domain.Credentials
+	err := transaction.Conn(ctx, r.pool).QueryRow(ctx, getCredentialsByLoginQuery, login).Scan(
+		&credentials.UserID,
+		&credentials.Login,
+		&credentials.PasswordHash,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, usecase.ErrUserNotFound
+	}
+	return &credentials, err
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"homework/internal/domain"
+	"homework/internal/usecase"
 	"homework/pkg/pg_test"
 	"testing"
 	"time"
@@ -61,6 +62,57 @@ func (suite *UserTestSuite) TestUserRepository_GetUserByID() {
 	assert.Equal(suite.T(), name, user.Name)
 }
 
+func (suite *UserTestSuite) TestUserRepository_RenameAndDelete() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user := &domain.User{Name: "petya ivanov"}
+	assert.Nil(suite.T(), suite.repo.SaveUser(ctx, user))
+	assert.Nil(suite.T(), suite.repo.SaveCredentials(ctx, domain.Credentials{
+		UserID:       user.ID,
+		Login:        "petya",
+		PasswordHash: []byte("hash"),
+	}))
+
+	user.Name = "pyotr ivanov"
+	assert.Nil(suite.T(), suite.repo.SaveUser(ctx, user))
+	users, err := suite.repo.GetUsers(ctx)
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), users, *user)
+
+	assert.Nil(suite.T(), suite.repo.DeleteUser(ctx, user.ID))
+	_, err = suite.repo.GetUserByID(ctx, user.ID)
+	assert.ErrorIs(suite.T(), err, usecase.ErrUserNotFound)
+	_, err = suite.repo.GetCredentialsByLogin(ctx, "petya")
+	assert.ErrorIs(suite.T(), err, usecase.ErrUserNotFound)
+
+	assert.ErrorIs(suite.T(), suite.repo.DeleteUser(ctx, user.ID), usecase.ErrUserNotFound)
+}
+
+func (suite *UserTestSuite) TestUserRepository_Credentials() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	credentials := domain.Credentials{
+		UserID:       1,
+		Login:        "vasya",
+		PasswordHash: []byte("hash"),
+	}
+
+	err := suite.repo.SaveCredentials(ctx, credentials)
+	assert.Nil(suite.T(), err)
+
+	err = suite.repo.SaveCredentials(ctx, domain.Credentials{UserID: 2, Login: "vasya", PasswordHash: []byte("hash")})
+	assert.ErrorIs(suite.T(), err, usecase.ErrUserAlreadyExists)
+
+	got, err := suite.repo.GetCredentialsByLogin(ctx, "vasya")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), credentials, *got)
+
+	_, err = suite.repo.GetCredentialsByLogin(ctx, "petya")
+	assert.ErrorIs(suite.T(), err, usecase.ErrUserNotFound)
+}
+
 func TestUserTestSuite(t *testing.T) {
 	suite.Run(t, new(UserTestSuite))
 }
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+)
+
+// sensorRoleRank - старшинство ролей: роль разрешает всё, что разрешают младшие
+var sensorRoleRank = map[domain.SensorRole]int{
+	domain.SensorRoleViewer: 1,
+	domain.SensorRoleMember: 2,
+	domain.SensorRoleOwner:  3,
+}
+
+// checkSensorAccess - проверяет, что вызывающий пользователь имеет доступ к датчику с ролью не ниже required.
+// Роль берётся старшая из привязки к датчику и участия в доме датчика.
+// Чужие датчики неотличимы от несуществующих, поэтому возвращается ErrSensorNotFound,
+// а при недостаточной роли - ErrSensorAccessDenied. Удалённый датчик не найден ни для кого.
+func checkSensorAccess(ctx context.Context, sor SensorOwnerRepository, hr HomeRepository, sensor *domain.Sensor, required domain.SensorRole) error {
+	if !sensor.DeletedAt.IsZero() {
+		return ErrSensorNotFound
+	}
+	userID, ok := restrictedCaller(ctx)
+	if !ok {
+		return nil
+	}
+	role, bound, err := getSensorRole(ctx, sor, userID, sensor.ID)
+	if err != nil {
+		return err
+	}
+	if sensor.HomeID != 0 {
+		homeRole, member, err := getHomeRole(ctx, hr, userID, sensor.HomeID)
+		if err != nil {
+			return err
+		}
+		if member && (!bound || sensorRoleRank[homeRole] > sensorRoleRank[role]) {
+			role, bound = homeRole, true
+		}
+	}
+	if !bound {
+		return ErrSensorNotFound
+	}
+	if sensorRoleRank[role] < sensorRoleRank[required] {
+		return ErrSensorAccessDenied
+	}
+	return nil
+}
+
+// checkSensorAccessByID - то же, что checkSensorAccess, но датчик запрашивается
+// только если права вызывающего действительно надо проверять
+func checkSensorAccessByID(ctx context.Context, sr SensorRepository, sor SensorOwnerRepository, hr HomeRepository, sensorID int64, required domain.SensorRole) error {
+	if _, ok := restrictedCaller(ctx); !ok {
+		return nil
+	}
+	sensor, err := sr.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+	return checkSensorAccess(ctx, sor, hr, sensor, required)
+}
+
+// checkHomeAccess - проверяет, что вызывающий пользователь состоит в доме с ролью не ниже required.
+// Чужие дома неотличимы от несуществующих.
+func checkHomeAccess(ctx context.Context, hr HomeRepository, homeID int64, required domain.SensorRole) error {
+	userID, ok := restrictedCaller(ctx)
+	if !ok {
+		return nil
+	}
+	role, member, err := getHomeRole(ctx, hr, userID, homeID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return ErrHomeNotFound
+	}
+	if sensorRoleRank[role] < sensorRoleRank[required] {
+		return ErrSensorAccessDenied
+	}
+	return nil
+}
+
+// checkUserAccess - проверяет, что вызывающий пользователь запрашивает собственные данные
+func checkUserAccess(ctx context.Context, userID int64) error {
+	callerID, ok := restrictedCaller(ctx)
+	if ok && callerID != userID {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func getSensorRole(ctx context.Context, sor SensorOwnerRepository, userID, sensorID int64) (domain.SensorRole, bool, error) {
+	sensorOwners, err := sor.GetSensorsByUserID(ctx, userID)
+	if err != nil {
+		return "", false, err
+	}
+	for _, sensorOwner := range sensorOwners {
+		if sensorOwner.SensorID == sensorID {
+			return sensorOwner.Role, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func getHomeRole(ctx context.Context, hr HomeRepository, userID, homeID int64) (domain.SensorRole, bool, error) {
+	homeMembers, err := hr.GetHomesByUserID(ctx, userID)
+	if err != nil {
+		return "", false, err
+	}
+	for _, homeMember := range homeMembers {
+		if homeMember.HomeID == homeID {
+			return homeMember.Role, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func isValidSensorRole(role domain.SensorRole) bool {
+	_, ok := sensorRoleRank[role]
+	return ok
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+type callerKey struct{}
+
+type caller struct {
+	userID  int64
+	isAdmin bool
+}
+
+// WithCaller - возвращает контекст, в котором usecase выполняются от имени пользователя userID.
+// Вызовы без пользователя в контексте считаются внутренними и не ограничиваются.
+func WithCaller(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller{userID: userID})
+}
+
+// WithAdmin - возвращает контекст, в котором usecase выполняются от имени администратора userID.
+// Администратор, как и внутренние вызовы, не ограничен домами и привязками к датчикам.
+func WithAdmin(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller{userID: userID, isAdmin: true})
+}
+
+// CallerFromContext - возвращает id пользователя, от имени которого выполняется вызов
+func CallerFromContext(ctx context.Context) (int64, bool) {
+	c, ok := ctx.Value(callerKey{}).(caller)
+	return c.userID, ok
+}
+
+// restrictedCaller - возвращает id пользователя, права которого надо проверять.
+// Для внутренних вызовов и администраторов ok равен false.
+func restrictedCaller(ctx context.Context) (int64, bool) {
+	c, ok := ctx.Value(callerKey{}).(caller)
+	return c.userID, ok && !c.isAdmin
+}
+
+type Auth struct {
+	ur     UserRepository
+	secret []byte
+	now    func() time.Time
+}
+
+func NewAuth(ur UserRepository, secret []byte) *Auth {
+	return &Auth{
+		ur:     ur,
+		secret: secret,
+		now:    time.Now,
+	}
+}
+
+func (a *Auth) Login(ctx context.Context, login, password string) (*domain.Tokens, error) {
+	credentials, err := a.ur.GetCredentialsByLogin(ctx, login)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword(credentials.PasswordHash, []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return a.IssueTokens(credentials.UserID)
+}
+
+func (a *Auth) Refresh(ctx context.Context, refreshToken string) (*domain.Tokens, error) {
+	user, err := a.resolve(ctx, refreshToken, domain.TokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+	return a.IssueTokens(user.ID)
+}
+
+func (a *Auth) Authenticate(ctx context.Context, accessToken string) (*domain.User, error) {
+	return a.resolve(ctx, accessToken, domain.TokenTypeAccess)
+}
+
+func (a *Auth) IssueTokens(userID int64) (*domain.Tokens, error) {
+	now := a.now()
+	subject := strconv.FormatInt(userID, 10)
+	accessToken, err := signToken(a.secret, tokenClaims{
+		Subject:   subject,
+		Type:      domain.TokenTypeAccess,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(accessTokenTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := signToken(a.secret, tokenClaims{
+		Subject:   subject,
+		Type:      domain.TokenTypeRefresh,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(refreshTokenTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    now.Add(accessTokenTTL),
+	}, nil
+}
+
+func (a *Auth) resolve(ctx context.Context, token string, tokenType domain.TokenType) (*domain.User, error) {
+	claims, err := parseToken(a.secret, token, tokenType, a.now())
+	if err != nil {
+		return nil, err
+	}
+	userID, err := claims.userID()
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	user, err := a.ur.GetUserByID(ctx, userID)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func hashPassword(password string) ([]byte, error) {
+	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+}
@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testSecret = []byte("test secret")
+
+func Test_auth_Login(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	t.Run("fail, unknown login", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetCredentialsByLogin(ctx, "Homer Simpson").Times(1).Return(nil, ErrUserNotFound)
+
+		a := NewAuth(ur, testSecret)
+
+		_, err := a.Login(ctx, "Homer Simpson", "password")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("fail, wrong password", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetCredentialsByLogin(ctx, "Homer Simpson").Times(1).Return(&domain.Credentials{
+			UserID:       1,
+			Login:        "Homer Simpson",
+			PasswordHash: passwordHash,
+		}, nil)
+
+		a := NewAuth(ur, testSecret)
+
+		_, err := a.Login(ctx, "Homer Simpson", "donuts!!")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("fail, repo fail", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		expectedError := errors.New("doh")
+		ur.EXPECT().GetCredentialsByLogin(ctx, gomock.Any()).Times(1).Return(nil, expectedError)
+
+		a := NewAuth(ur, testSecret)
+
+		_, err := a.Login(ctx, "Homer Simpson", "password")
+		assert.ErrorIs(t, err, expectedError)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetCredentialsByLogin(ctx, "Homer Simpson").Times(1).Return(&domain.Credentials{
+			UserID:       1,
+			Login:        "Homer Simpson",
+			PasswordHash: passwordHash,
+		}, nil)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(&domain.User{ID: 1, Name: "Homer Simpson"}, nil)
+
+		a := NewAuth(ur, testSecret)
+
+		tokens, err := a.Login(ctx, "Homer Simpson", "password")
+		require.NoError(t, err)
+		assert.NotEqual(t, tokens.AccessToken, tokens.RefreshToken)
+
+		user, err := a.Authenticate(ctx, tokens.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), user.ID)
+	})
+}
+
+func Test_auth_Authenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, malformed token", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		a := NewAuth(nil, testSecret)
+
+		_, err := a.Authenticate(ctx, "not a token")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("fail, signed with another secret", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		tokens, err := NewAuth(nil, []byte("another secret")).IssueTokens(1)
+		require.NoError(t, err)
+
+		a := NewAuth(nil, testSecret)
+
+		_, err = a.Authenticate(ctx, tokens.AccessToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("fail, tampered claims", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		a := NewAuth(nil, testSecret)
+		tokens, err := a.IssueTokens(1)
+		require.NoError(t, err)
+		other, err := a.IssueTokens(2)
+		require.NoError(t, err)
+
+		parts := strings.Split(tokens.AccessToken, ".")
+		otherParts := strings.Split(other.AccessToken, ".")
+
+		_, err = a.Authenticate(ctx, parts[0]+"."+otherParts[1]+"."+parts[2])
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("fail, refresh token used as access token", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		a := NewAuth(nil, testSecret)
+		tokens, err := a.IssueTokens(1)
+		require.NoError(t, err)
+
+		_, err = a.Authenticate(ctx, tokens.RefreshToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("fail, token expired", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		a := NewAuth(nil, testSecret)
+		tokens, err := a.IssueTokens(1)
+		require.NoError(t, err)
+
+		a.now = func() time.Time {
+			return time.Now().Add(accessTokenTTL + time.Second)
+		}
+
+		_, err = a.Authenticate(ctx, tokens.AccessToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("fail, user deleted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(nil, ErrUserNotFound)
+
+		a := NewAuth(ur, testSecret)
+		tokens, err := a.IssueTokens(1)
+		require.NoError(t, err)
+
+		_, err = a.Authenticate(ctx, tokens.AccessToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func Test_auth_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, access token used as refresh token", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		a := NewAuth(nil, testSecret)
+		tokens, err := a.IssueTokens(1)
+		require.NoError(t, err)
+
+		_, err = a.Refresh(ctx, tokens.AccessToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(&domain.User{ID: 1}, nil)
+
+		a := NewAuth(ur, testSecret)
+		tokens, err := a.IssueTokens(1)
+		require.NoError(t, err)
+
+		refreshed, err := a.Refresh(ctx, tokens.RefreshToken)
+		require.NoError(t, err)
+		assert.NotEmpty(t, refreshed.AccessToken)
+	})
+}
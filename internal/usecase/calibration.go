@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"homework/internal/domain"
+	"math"
+)
+
+// maxPolynomialDegree - наибольшая степень многочлена калибровки
+const maxPolynomialDegree = 5
+
+// checkCalibration - проверяет калибровку датчика. Калибровать можно только числовые значения
+func checkCalibration(kind domain.SensorValueKind, calibration *domain.Calibration) error {
+	if kind != domain.SensorValueKindInteger && kind != domain.SensorValueKindFloat {
+		return ErrInvalidCalibration
+	}
+	switch calibration.Kind {
+	case domain.CalibrationLinear:
+		if calibration.Scale == 0 || !isFinite(calibration.Scale) || !isFinite(calibration.Offset) ||
+			len(calibration.Coefficients) != 0 || len(calibration.Points) != 0 {
+			return ErrInvalidCalibration
+		}
+	case domain.CalibrationPolynomial:
+		if len(calibration.Coefficients) < 2 || len(calibration.Coefficients) > maxPolynomialDegree+1 ||
+			calibration.Scale != 0 || calibration.Offset != 0 || len(calibration.Points) != 0 {
+			return ErrInvalidCalibration
+		}
+		for _, coefficient := range calibration.Coefficients {
+			if !isFinite(coefficient) {
+				return ErrInvalidCalibration
+			}
+		}
+	case domain.CalibrationTable:
+		if len(calibration.Points) < 2 ||
+			calibration.Scale != 0 || calibration.Offset != 0 || len(calibration.Coefficients) != 0 {
+			return ErrInvalidCalibration
+		}
+		for i, point := range calibration.Points {
+			if !isFinite(point.Raw) || !isFinite(point.Value) {
+				return ErrInvalidCalibration
+			}
+			if i > 0 && point.Raw <= calibration.Points[i-1].Raw {
+				return ErrInvalidCalibration
+			}
+		}
+	default:
+		return ErrInvalidCalibration
+	}
+	return nil
+}
+
+// calibrate - переводит сырое значение в откалиброванное.
+// Возвращает nil, если значение не числовое, калибровка не задана или результат не конечен
+func calibrate(calibration *domain.Calibration, payload int64, value any) *float64 {
+	if calibration == nil {
+		return nil
+	}
+	var raw float64
+	switch v := value.(type) {
+	case nil:
+		raw = float64(payload)
+	case float64:
+		raw = v
+	default:
+		return nil
+	}
+	var result float64
+	switch calibration.Kind {
+	case domain.CalibrationLinear:
+		result = calibration.Scale*raw + calibration.Offset
+	case domain.CalibrationPolynomial:
+		for i := len(calibration.Coefficients) - 1; i >= 0; i-- {
+			result = result*raw + calibration.Coefficients[i]
+		}
+	case domain.CalibrationTable:
+		result = interpolate(calibration.Points, raw)
+	default:
+		return nil
+	}
+	if !isFinite(result) {
+		return nil
+	}
+	return &result
+}
+
+// interpolate - линейная интерполяция по таблице, за её пределами берётся крайняя точка
+func interpolate(points []domain.CalibrationPoint, raw float64) float64 {
+	if raw <= points[0].Raw {
+		return points[0].Value
+	}
+	for i := 1; i < len(points); i++ {
+		if raw <= points[i].Raw {
+			left, right := points[i-1], points[i]
+			return left.Value + (right.Value-left.Value)*(raw-left.Raw)/(right.Raw-left.Raw)
+		}
+	}
+	return points[len(points)-1].Value
+}
+
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"homework/internal/domain"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_calibrate(t *testing.T) {
+	table := &domain.Calibration{
+		Kind:   domain.CalibrationTable,
+		Points: []domain.CalibrationPoint{{Raw: 0, Value: -40}, {Raw: 100, Value: 10}, {Raw: 200, Value: 110}},
+	}
+	tests := []struct {
+		name        string
+		calibration *domain.Calibration
+		payload     int64
+		value       any
+		want        *float64
+	}{
+		{name: "no calibration", payload: 10},
+		{name: "linear", calibration: &domain.Calibration{Kind: domain.CalibrationLinear, Scale: 2, Offset: -1}, payload: 10, want: ptr(19.0)},
+		{name: "linear float value", calibration: &domain.Calibration{Kind: domain.CalibrationLinear, Scale: 2}, value: 1.25, want: ptr(2.5)},
+		{name: "polynomial", calibration: &domain.Calibration{Kind: domain.CalibrationPolynomial, Coefficients: []float64{1, 0, 2}}, payload: 3, want: ptr(19.0)},
+		{name: "table below", calibration: table, payload: -5, want: ptr(-40.0)},
+		{name: "table between", calibration: table, payload: 150, want: ptr(60.0)},
+		{name: "table above", calibration: table, payload: 500, want: ptr(110.0)},
+		{name: "not numeric", calibration: table, value: true},
+		{name: "overflow", calibration: &domain.Calibration{Kind: domain.CalibrationLinear, Scale: math.MaxFloat64}, value: math.MaxFloat64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, calibrate(tt.calibration, tt.payload, tt.value))
+		})
+	}
+}
+
+func Test_checkCalibration(t *testing.T) {
+	tests := []struct {
+		name        string
+		kind        domain.SensorValueKind
+		calibration domain.Calibration
+		wantErr     bool
+	}{
+		{name: "linear", kind: domain.SensorValueKindInteger, calibration: domain.Calibration{Kind: domain.CalibrationLinear, Scale: 0.001}},
+		{name: "linear zero scale", kind: domain.SensorValueKindInteger, calibration: domain.Calibration{Kind: domain.CalibrationLinear}, wantErr: true},
+		{name: "linear with points", kind: domain.SensorValueKindInteger, calibration: domain.Calibration{Kind: domain.CalibrationLinear, Scale: 1, Points: []domain.CalibrationPoint{{}}}, wantErr: true},
+		{name: "polynomial", kind: domain.SensorValueKindFloat, calibration: domain.Calibration{Kind: domain.CalibrationPolynomial, Coefficients: []float64{0, 1, 0.5}}},
+		{name: "polynomial constant", kind: domain.SensorValueKindFloat, calibration: domain.Calibration{Kind: domain.CalibrationPolynomial, Coefficients: []float64{1}}, wantErr: true},
+		{name: "polynomial NaN", kind: domain.SensorValueKindFloat, calibration: domain.Calibration{Kind: domain.CalibrationPolynomial, Coefficients: []float64{0, math.NaN()}}, wantErr: true},
+		{name: "table", kind: domain.SensorValueKindInteger, calibration: domain.Calibration{Kind: domain.CalibrationTable, Points: []domain.CalibrationPoint{{Raw: 0, Value: 1}, {Raw: 1, Value: 0}}}},
+		{name: "table unsorted", kind: domain.SensorValueKindInteger, calibration: domain.Calibration{Kind: domain.CalibrationTable, Points: []domain.CalibrationPoint{{Raw: 1}, {Raw: 1}}}, wantErr: true},
+		{name: "table single point", kind: domain.SensorValueKindInteger, calibration: domain.Calibration{Kind: domain.CalibrationTable, Points: []domain.CalibrationPoint{{Raw: 1}}}, wantErr: true},
+		{name: "binary sensor", kind: domain.SensorValueKindBinary, calibration: domain.Calibration{Kind: domain.CalibrationLinear, Scale: 1}, wantErr: true},
+		{name: "unknown kind", kind: domain.SensorValueKindInteger, calibration: domain.Calibration{Kind: "spline"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCalibration(tt.kind, &tt.calibration)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCalibration)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func ptr(value float64) *float64 {
+	return &value
+}
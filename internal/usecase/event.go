@@ -1,44 +1,221 @@
 package usecase
 
 import (
+	"cmp"
 	"context"
 	"homework/internal/domain"
+	"slices"
 	"time"
 )
 
+const (
+	// DefaultMaxClockSkew - насколько время события по часам устройства может опережать время сервера
+	DefaultMaxClockSkew = 5 * time.Minute
+	// MaxEventBatchSize - наибольшее число событий в одном пакете
+	MaxEventBatchSize = 1000
+	// DefaultHistoryLimit - число событий в странице истории, если оно не задано
+	DefaultHistoryLimit = 1000
+	// MaxHistoryLimit - наибольшее число событий в странице истории
+	MaxHistoryLimit = 10000
+	// MaxHistoryBuckets - наибольшее число интервалов в агрегации истории
+	MaxHistoryBuckets = 10000
+	// MaxExportSensors - наибольшее число датчиков в одной выгрузке истории
+	MaxExportSensors = 100
+	// DefaultEventDedupWindow - сколько времени после получения события повтор с тем же EventID не сохраняется
+	DefaultEventDedupWindow = 24 * time.Hour
+)
+
 type Event struct {
-	er EventRepository
-	sr SensorRepository
+	er           EventRepository
+	sr           SensorRepository
+	sor          SensorOwnerRepository
+	hr           HomeRepository
+	str          SensorTypeRepository
+	tr           Transactor
+	now          func() time.Time
+	maxClockSkew time.Duration
+}
+
+func NewEvent(er EventRepository, sr SensorRepository, sor SensorOwnerRepository, hr HomeRepository, str SensorTypeRepository, tr Transactor, options ...func(*Event)) *Event {
+	e := &Event{
+		er:           er,
+		sr:           sr,
+		sor:          sor,
+		hr:           hr,
+		str:          str,
+		tr:           tr,
+		now:          time.Now,
+		maxClockSkew: DefaultMaxClockSkew,
+	}
+	for _, o := range options {
+		o(e)
+	}
+	return e
 }
 
-func NewEvent(er EventRepository, sr SensorRepository) *Event {
-	return &Event{
-		er: er,
-		sr: sr,
+// WithMaxClockSkew - задаёт, насколько время события может опережать время сервера
+func WithMaxClockSkew(skew time.Duration) func(*Event) {
+	return func(e *Event) {
+		e.maxClockSkew = skew
 	}
 }
 
+// ReceiveEvent - сохраняет событие и обновляет состояние датчика.
+// События удалённых и отключённых датчиков не принимаются, чтобы не портить их состояние,
+// значение события должно укладываться в диапазон типа датчика.
+// Если у датчика задана калибровка, вместе с сырым значением сохраняется откалиброванное.
+// Событие, пришедшее позже более нового, только попадает в историю и не меняет состояние датчика.
+// Повтор уже сохранённого события возвращает ErrEventDuplicate и не меняет ни историю, ни состояние датчика.
+// Событие и состояние датчика сохраняются в одной транзакции. Сохраняется только состояние датчика, поэтому
+// одновременное изменение или удаление датчика не перезаписывается, а из одновременных событий состояние задаёт более новое.
 func (e *Event) ReceiveEvent(ctx context.Context, event *domain.Event) error {
-	if event.Timestamp.IsZero() {
-		return ErrInvalidEventTimestamp
+	if err := e.checkTimestamp(event, e.now()); err != nil {
+		return err
 	}
 	sensor, err := e.sr.GetSensorBySerialNumber(ctx, event.SensorSerialNumber)
 	if err != nil {
 		return err
 	}
-	sensor.CurrentState = event.Payload
-	sensor.LastActivity = time.Now()
-	event.SensorID = sensor.ID
-	if err := e.er.SaveEvent(ctx, event); err != nil {
+	if err := e.prepareEvent(ctx, sensor, event, domain.SensorRoleMember); err != nil {
 		return err
 	}
-	return e.sr.SaveSensor(ctx, sensor)
+	return e.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := e.er.SaveEvent(ctx, event); err != nil {
+			return err
+		}
+		if !applyEvent(sensor, event) {
+			return nil
+		}
+		return e.sr.SaveSensorState(ctx, sensor)
+	})
+}
+
+// ReceiveEvents - сохраняет пакет событий одной операцией хранилища и обновляет состояние датчиков в той же транзакции.
+// Каждое событие проверяется так же, как в ReceiveEvent, отклонённые события не мешают сохранению остальных.
+// Пакет не проверяет ключи датчиков, поэтому пользователь отправляет в нём события только датчиков,
+// которыми владеет; администратору и внутренним вызовам доступны все датчики.
+// Возвращает ошибку для каждого события пакета, nil - событие сохранено, ErrEventDuplicate - событие уже было сохранено.
+// Сохранённым событиям пакета проставляются ID и Sequence.
+func (e *Event) ReceiveEvents(ctx context.Context, events []domain.Event) ([]error, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(events) > MaxEventBatchSize {
+		return nil, ErrEventBatchTooLarge
+	}
+	now := e.now()
+	errs := make([]error, len(events))
+	sensors := make(map[string]*domain.Sensor)
+	accepted := make([]domain.Event, 0, len(events))
+	for i := range events {
+		event := &events[i]
+		if errs[i] = e.checkTimestamp(event, now); errs[i] != nil {
+			continue
+		}
+		sensor, ok := sensors[event.SensorSerialNumber]
+		if !ok {
+			sensor, errs[i] = e.sr.GetSensorBySerialNumber(ctx, event.SensorSerialNumber)
+			if errs[i] != nil {
+				continue
+			}
+			sensors[event.SensorSerialNumber] = sensor
+		}
+		if errs[i] = e.prepareEvent(ctx, sensor, event, domain.SensorRoleOwner); errs[i] != nil {
+			continue
+		}
+		accepted = append(accepted, *event)
+	}
+	if len(accepted) == 0 {
+		return errs, nil
+	}
+	err := e.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		saved, err := e.er.SaveEvents(ctx, accepted)
+		if err != nil {
+			return err
+		}
+		j := 0
+		for i := range errs {
+			if errs[i] != nil {
+				continue
+			}
+			errs[i] = saved[j]
+			events[i].ID, events[i].Sequence = accepted[j].ID, accepted[j].Sequence
+			j++
+		}
+		var changed []*domain.Sensor
+		for i := range events {
+			sensor := sensors[events[i].SensorSerialNumber]
+			if errs[i] != nil || !applyEvent(sensor, &events[i]) || slices.Contains(changed, sensor) {
+				continue
+			}
+			changed = append(changed, sensor)
+		}
+		for _, sensor := range changed {
+			if err := e.sr.SaveSensorState(ctx, sensor); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
+
+// checkTimestamp - проверяет время события и отмечает время его получения
+func (e *Event) checkTimestamp(event *domain.Event, now time.Time) error {
+	if event.Timestamp.IsZero() {
+		return ErrInvalidEventTimestamp
+	}
+	event.ReceivedAt = now
+	if event.Timestamp.After(event.ReceivedAt.Add(e.maxClockSkew)) {
+		return ErrEventFromFuture
+	}
+	return nil
+}
+
+// prepareEvent - проверяет, что датчик может принять событие от вызывающего с ролью не ниже required,
+// и дополняет событие данными датчика
+func (e *Event) prepareEvent(ctx context.Context, sensor *domain.Sensor, event *domain.Event, required domain.SensorRole) error {
+	if err := checkSensorAccess(ctx, e.sor, e.hr, sensor, required); err != nil {
+		return err
+	}
+	if !sensor.IsActive {
+		return ErrSensorInactive
+	}
+	if err := checkSensorValue(ctx, e.str, sensor.Type, sensor.Calibration, event); err != nil {
+		return err
+	}
+	event.CalibratedValue = calibrate(sensor.Calibration, event.Payload, event.Value)
+	if event.CalibratedValue != nil {
+		event.Unit = sensor.Unit
+	}
+	event.SensorID = sensor.ID
+	event.HomeID = sensor.HomeID
+	return nil
+}
+
+// applyEvent - выставляет состояние датчика по событию, если оно не старше последнего.
+// Возвращает false, если состояние не изменилось
+func applyEvent(sensor *domain.Sensor, event *domain.Event) bool {
+	if event.Timestamp.Before(sensor.LastActivity) {
+		return false
+	}
+	sensor.CurrentState = event.Payload
+	sensor.CurrentValue = event.Value
+	sensor.CalibratedState = event.CalibratedValue
+	sensor.LastActivity = event.Timestamp
+	return true
 }
 
 func (e *Event) GetLastEventBySensorID(ctx context.Context, id int64) (*domain.Event, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
+	if err := checkSensorAccessByID(ctx, e.sr, e.sor, e.hr, id, domain.SensorRoleViewer); err != nil {
+		return nil, err
+	}
 	event, err := e.er.GetLastEventBySensorID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -46,6 +223,262 @@ func (e *Event) GetLastEventBySensorID(ctx context.Context, id int64) (*domain.E
 	return event, nil
 }
 
-func (e *Event) GetSensorHistory(ctx context.Context, id int64, start, end time.Time) ([]domain.Event, error) {
-	return e.er.GetSensorHistory(ctx, id, start, end)
+// GetSensorHistory - возвращает страницу событий датчика за период, упорядоченных по времени.
+// Если в странице поместились не все события, Next указывает, откуда продолжить выборку.
+// Пользователю видны только события, полученные, пока датчик принадлежал его текущему дому.
+func (e *Event) GetSensorHistory(ctx context.Context, id int64, query domain.HistoryQuery) (*domain.HistoryPage, error) {
+	if query.Limit < 0 || query.Limit > MaxHistoryLimit || query.End.Before(query.Start) {
+		return nil, ErrInvalidHistoryQuery
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultHistoryLimit
+	}
+	homeID, err := e.historyHome(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	query.HomeID = homeID
+	limit := query.Limit
+	// лишнее событие показывает, что за страницей есть продолжение
+	query.Limit++
+	events, err := e.er.GetSensorHistory(ctx, id, query)
+	if err != nil {
+		return nil, err
+	}
+	page := &domain.HistoryPage{Events: events}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		last := page.Events[limit-1]
+		page.Next = &domain.HistoryCursor{Timestamp: last.Timestamp, ID: last.ID}
+	}
+	return page, nil
+}
+
+// ExportSensorHistory - передаёт в fn события датчиков за период: датчики по порядку ids, события каждого датчика -
+// по времени и ID. Доступ ко всем датчикам проверяется до начала выгрузки, поэтому ошибки доступа возвращаются
+// раньше первого события
+func (e *Event) ExportSensorHistory(ctx context.Context, ids []int64, query domain.HistoryQuery, fn func(domain.Event) error) error {
+	if len(ids) == 0 || len(ids) > MaxExportSensors || query.End.Before(query.Start) {
+		return ErrInvalidHistoryQuery
+	}
+	homes, err := e.historyHomes(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i, id := range ids {
+		sensorQuery := domain.HistoryQuery{
+			Start:      query.Start,
+			End:        query.End,
+			Descending: query.Descending,
+			HomeID:     homes[i],
+		}
+		if err := e.er.StreamSensorHistory(ctx, id, sensorQuery, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplayEvents - возвращает не больше query.Limit событий датчиков ids по параметрам дозагрузки
+// в порядке последовательности. Используется, чтобы отдать клиенту потока события, которые он пропустил.
+// query.HomeID не учитывается: события каждого датчика ограничиваются домами, доступными вызывающему
+func (e *Event) ReplayEvents(ctx context.Context, ids []int64, query domain.ReplayQuery) ([]domain.Event, error) {
+	if query.Limit < 1 || query.Limit > MaxHistoryLimit || query.AfterSequence < 0 || query.UntilSequence < 0 {
+		return nil, ErrInvalidHistoryQuery
+	}
+	homes, err := e.historyHomes(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	var events []domain.Event
+	for i, id := range ids {
+		query.HomeID = homes[i]
+		sensorEvents, err := e.er.ReplaySensorEvents(ctx, id, query)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, sensorEvents...)
+	}
+	slices.SortFunc(events, func(a, b domain.Event) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+	if len(events) > query.Limit {
+		events = events[:query.Limit]
+	}
+	return events, nil
+}
+
+// GetLastSequence - возвращает последовательность, до которой включительно все события уже видны в хранилище,
+// с неё начинается поток без дозагрузки
+func (e *Event) GetLastSequence(ctx context.Context) (int64, error) {
+	return e.er.GetLastSequence(ctx)
+}
+
+// GetSensorHistoryAggregates - возвращает агрегаты числовых значений событий датчика по интервалам периода.
+// Интервалы без событий заполняются по query.Fill
+func (e *Event) GetSensorHistoryAggregates(ctx context.Context, id int64, query domain.HistoryAggregateQuery) ([]domain.HistoryBucket, error) {
+	if query.Fill == "" {
+		query.Fill = domain.HistoryFillNone
+	}
+	switch query.Fill {
+	case domain.HistoryFillNone, domain.HistoryFillNull, domain.HistoryFillPrevious:
+	default:
+		return nil, ErrInvalidHistoryQuery
+	}
+	if query.Bucket <= 0 || query.End.Before(query.Start) ||
+		query.End.Truncate(query.Bucket).Sub(query.Start.Truncate(query.Bucket))/query.Bucket >= MaxHistoryBuckets {
+		return nil, ErrInvalidHistoryQuery
+	}
+	homeID, err := e.historyHome(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	query.HomeID = homeID
+	buckets, err := e.aggregateHistory(ctx, id, query)
+	if err != nil {
+		return nil, err
+	}
+	if query.Fill == domain.HistoryFillNone {
+		return buckets, nil
+	}
+	return fillHistoryBuckets(buckets, query), nil
+}
+
+// aggregateHistory - берёт интервалы, целиком лежащие в периоде, из накопленных агрегатов самой крупной подходящей
+// длительности. Неполные интервалы на краях периода и интервалы без подходящих накопленных агрегатов считаются по событиям
+func (e *Event) aggregateHistory(ctx context.Context, id int64, query domain.HistoryAggregateQuery) ([]domain.HistoryBucket, error) {
+	resolution := rollupResolution(query.Bucket)
+	from := query.Start.Truncate(query.Bucket)
+	if from.Before(query.Start) {
+		from = from.Add(query.Bucket)
+	}
+	to := query.End.Truncate(query.Bucket)
+	if resolution == 0 || !from.Before(to) {
+		return e.er.GetSensorHistoryAggregates(ctx, id, query)
+	}
+
+	var buckets []domain.HistoryBucket
+	if query.Start.Before(from) {
+		head := query
+		head.End = from.Add(-time.Nanosecond)
+		headBuckets, err := e.er.GetSensorHistoryAggregates(ctx, id, head)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, headBuckets...)
+	}
+	rollupQuery := query
+	rollupQuery.Start, rollupQuery.End, rollupQuery.Bucket = from, to, resolution
+	rollups, err := e.er.GetSensorRollups(ctx, id, rollupQuery)
+	if err != nil {
+		return nil, err
+	}
+	buckets = append(buckets, mergeRollups(rollups, query.Bucket)...)
+	// конец периода входит в выборку, поэтому интервал, начинающийся в to, всегда неполный
+	tail := query
+	tail.Start = to
+	tailBuckets, err := e.er.GetSensorHistoryAggregates(ctx, id, tail)
+	if err != nil {
+		return nil, err
+	}
+	return append(buckets, tailBuckets...), nil
+}
+
+// rollupResolution - самая крупная длительность накопленных агрегатов, из которых складывается интервал bucket,
+// 0 - таких нет
+func rollupResolution(bucket time.Duration) time.Duration {
+	var resolution time.Duration
+	for _, r := range domain.RollupResolutions {
+		if bucket%r == 0 && r > resolution {
+			resolution = r
+		}
+	}
+	return resolution
+}
+
+// mergeRollups - складывает упорядоченные по времени накопленные агрегаты в интервалы длительности bucket
+func mergeRollups(rollups []domain.HistoryRollup, bucket time.Duration) []domain.HistoryBucket {
+	var merged []domain.HistoryRollup
+	for _, rollup := range rollups {
+		start := rollup.Start.Truncate(bucket)
+		if len(merged) == 0 || !merged[len(merged)-1].Start.Equal(start) {
+			merged = append(merged, domain.HistoryRollup{Start: start})
+		}
+		merged[len(merged)-1].Merge(rollup)
+	}
+	buckets := make([]domain.HistoryBucket, 0, len(merged))
+	for _, rollup := range merged {
+		buckets = append(buckets, rollup.Bucket())
+	}
+	return buckets
+}
+
+// historyHomes - дома, события которых видны вызывающему в истории датчиков ids. Доступ ко всем датчикам
+// проверяется заранее, для администратора и внутренних вызовов проверяется только существование датчиков
+func (e *Event) historyHomes(ctx context.Context, ids []int64) ([]*int64, error) {
+	homes := make([]*int64, len(ids))
+	for i, id := range ids {
+		if _, ok := restrictedCaller(ctx); !ok {
+			if _, err := e.sr.GetSensorByID(ctx, id); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		homeID, err := e.historyHome(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		homes[i] = homeID
+	}
+	return homes, nil
+}
+
+// historyHome - проверяет доступ пользователя к истории датчика и возвращает дом, события которого ему видны.
+// Для вызовов без пользователя история не ограничена
+func (e *Event) historyHome(ctx context.Context, id int64) (*int64, error) {
+	if _, ok := restrictedCaller(ctx); !ok {
+		return nil, nil
+	}
+	sensor, err := e.sr.GetSensorByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSensorAccess(ctx, e.sor, e.hr, sensor, domain.SensorRoleViewer); err != nil {
+		return nil, err
+	}
+	return &sensor.HomeID, nil
+}
+
+// fillHistoryBuckets - дополняет упорядоченные интервалы с событиями пустыми интервалами периода.
+// Интервалы до первого события остаются без значений и при заполнении предыдущим значением
+func fillHistoryBuckets(buckets []domain.HistoryBucket, query domain.HistoryAggregateQuery) []domain.HistoryBucket {
+	end := query.End.Truncate(query.Bucket)
+	result := make([]domain.HistoryBucket, 0, end.Sub(query.Start.Truncate(query.Bucket))/query.Bucket+1)
+	var previous *float64
+	for start := query.Start.Truncate(query.Bucket); !start.After(end); start = start.Add(query.Bucket) {
+		if len(buckets) > 0 && !buckets[0].Start.After(start) {
+			result = append(result, buckets[0])
+			previous = buckets[0].Last
+			buckets = buckets[1:]
+			continue
+		}
+		bucket := domain.HistoryBucket{Start: start}
+		if query.Fill == domain.HistoryFillPrevious && previous != nil {
+			bucket.Min, bucket.Max, bucket.Avg, bucket.First, bucket.Last = previous, previous, previous, previous, previous
+		}
+		result = append(result, bucket)
+	}
+	return result
+}
+
+// PurgeSensorEvents - безвозвратно удаляет все события датчика, доступно только владельцу
+func (e *Event) PurgeSensorEvents(ctx context.Context, id int64) error {
+	sensor, err := e.sr.GetSensorByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkSensorAccess(ctx, e.sor, e.hr, sensor, domain.SensorRoleOwner); err != nil {
+		return err
+	}
+	return e.er.DeleteEventsBySensorID(ctx, id)
 }
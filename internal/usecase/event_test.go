@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"homework/internal/domain"
+	"math"
 	"testing"
 	"time"
 
@@ -19,7 +20,7 @@ func Test_event_ReceiveEvent(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
-		e := NewEvent(nil, nil)
+		e := NewEvent(nil, nil, nil, nil, nil, newTransactor(ctrl))
 
 		err := e.ReceiveEvent(ctx, &domain.Event{})
 		assert.ErrorIs(t, err, ErrInvalidEventTimestamp)
@@ -33,7 +34,7 @@ func Test_event_ReceiveEvent(t *testing.T) {
 
 		sr.EXPECT().GetSensorBySerialNumber(ctx, gomock.Any()).Times(1).Return(nil, ErrSensorNotFound)
 
-		e := NewEvent(nil, sr)
+		e := NewEvent(nil, sr, nil, nil, nil, newTransactor(ctrl))
 
 		err := e.ReceiveEvent(ctx, &domain.Event{
 			Timestamp: time.Now(),
@@ -41,6 +42,30 @@ func Test_event_ReceiveEvent(t *testing.T) {
 		assert.ErrorIs(t, err, ErrSensorNotFound)
 	})
 
+	t.Run("err, sensor of another user", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID: 1,
+		}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Times(1).Return(nil, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(0)
+
+		e := NewEvent(er, sr, sor, nil, nil, newTransactor(ctrl))
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "0123456789",
+		})
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+
 	t.Run("err, event save error", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -48,14 +73,15 @@ func Test_event_ReceiveEvent(t *testing.T) {
 		sr := NewMockSensorRepository(ctrl)
 
 		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
-			ID: 1,
+			ID:       1,
+			IsActive: true,
 		}, nil)
 
 		er := NewMockEventRepository(ctrl)
 		expectedError := errors.New("some error")
 		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Return(expectedError)
 
-		e := NewEvent(er, sr)
+		e := NewEvent(er, sr, nil, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
 
 		err := e.ReceiveEvent(ctx, &domain.Event{
 			Timestamp:          time.Now(),
@@ -71,15 +97,16 @@ func Test_event_ReceiveEvent(t *testing.T) {
 		sr := NewMockSensorRepository(ctrl)
 
 		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
-			ID: 1,
+			ID:       1,
+			IsActive: true,
 		}, nil)
 		expectedError := errors.New("some error")
-		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Times(1).Return(expectedError)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).Times(1).Times(1).Return(expectedError)
 
 		er := NewMockEventRepository(ctrl)
 		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Return(nil)
 
-		e := NewEvent(er, sr)
+		e := NewEvent(er, sr, nil, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
 
 		err := e.ReceiveEvent(ctx, &domain.Event{
 			Timestamp:          time.Now(),
@@ -95,9 +122,10 @@ func Test_event_ReceiveEvent(t *testing.T) {
 		sr := NewMockSensorRepository(ctrl)
 
 		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
-			ID: 1,
+			ID:       1,
+			IsActive: true,
 		}, nil)
-		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
 			assert.Equal(t, int64(8), s.CurrentState)
 			assert.NotEmpty(t, s.LastActivity)
 		})
@@ -110,13 +138,528 @@ func Test_event_ReceiveEvent(t *testing.T) {
 			return nil
 		})
 
-		e := NewEvent(er, sr)
+		e := NewEvent(er, sr, nil, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "0123456789",
+			Payload:            8,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("err, sensor is inactive", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID: 1,
+		}, nil)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).Times(0)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(0)
+
+		e := NewEvent(er, sr, nil, nil, nil, newTransactor(ctrl))
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "0123456789",
+		})
+		assert.ErrorIs(t, err, ErrSensorInactive)
+	})
+
+	t.Run("err, sensor is deleted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID:        1,
+			IsActive:  true,
+			DeletedAt: time.Now(),
+		}, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(0)
+
+		e := NewEvent(er, sr, nil, nil, nil, newTransactor(ctrl))
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "0123456789",
+		})
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+
+	t.Run("ok, typed values", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		climate := domain.BuiltinSensorTypes()[len(domain.BuiltinSensorTypes())-1]
+		assert.Equal(t, domain.SensorTypeClimate, climate.Type)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").AnyTimes().Return(&domain.Sensor{
+			ID:       1,
+			Type:     domain.SensorTypeClimate,
+			IsActive: true,
+		}, nil)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).Do(func(_ context.Context, s *domain.Sensor) {
+			assert.Equal(t, map[string]any{"temp": 21.4, "rh": int64(40)}, s.CurrentValue)
+		})
+
+		str := NewMockSensorTypeRepository(ctrl)
+		str.EXPECT().GetSensorType(ctx, domain.SensorTypeClimate).AnyTimes().Return(&climate, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Return(nil)
+
+		e := NewEvent(er, sr, nil, nil, str, newTransactor(ctrl))
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "0123456789",
+			Value:              map[string]any{"temp": 21.4, "rh": int64(40)},
+		})
+		assert.NoError(t, err)
+
+		for _, value := range []any{nil, 21.4, map[string]any{"temp": "warm"}, map[string]any{"wind": 3.5}, map[string]any{}} {
+			err := e.ReceiveEvent(ctx, &domain.Event{
+				Timestamp:          time.Now(),
+				SensorSerialNumber: "0123456789",
+				Value:              value,
+			})
+			assert.ErrorIs(t, err, ErrInvalidSensorValue, value)
+		}
+
+		err = e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "0123456789",
+			Value:              map[string]any{"rh": 101.5},
+		})
+		assert.ErrorIs(t, err, ErrSensorValueOutOfRange)
+	})
+
+	t.Run("ok, calibrated value saved next to raw", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID:          1,
+			Type:        domain.SensorTypeADC,
+			IsActive:    true,
+			Unit:        "V",
+			Calibration: &domain.Calibration{Kind: domain.CalibrationLinear, Scale: 0.5, Offset: 1},
+		}, nil)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
+			assert.Equal(t, int64(10), s.CurrentState)
+			if assert.NotNil(t, s.CalibratedState) {
+				assert.Equal(t, 6.0, *s.CalibratedState)
+			}
+		})
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, event *domain.Event) {
+			assert.Equal(t, int64(10), event.Payload)
+			if assert.NotNil(t, event.CalibratedValue) {
+				assert.Equal(t, 6.0, *event.CalibratedValue)
+			}
+			assert.Equal(t, "V", event.Unit)
+		})
+
+		e := NewEvent(er, sr, nil, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+
 		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "0123456789",
+			Payload:            10,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("range checked against calibrated value", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(2).Return(&domain.Sensor{
+			ID:          1,
+			Type:        domain.SensorTypeADC,
+			IsActive:    true,
+			Calibration: &domain.Calibration{Kind: domain.CalibrationLinear, Scale: -1, Offset: 20},
+		}, nil)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).Times(1)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1)
+
+		str := NewMockSensorTypeRepository(ctrl)
+		str.EXPECT().GetSensorType(ctx, domain.SensorTypeADC).Times(2).Return(&domain.SensorTypeDefinition{
+			Type:      domain.SensorTypeADC,
+			ValueKind: domain.SensorValueKindInteger,
+			MinValue:  0,
+			MaxValue:  10,
+		}, nil)
+
+		e := NewEvent(er, sr, nil, nil, str, newTransactor(ctrl))
+
+		// сырое 16 вне диапазона, но откалиброванное 4 в нём
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "0123456789",
+			Payload:            16,
+		})
+		assert.NoError(t, err)
+
+		// сырое 8 в диапазоне, но откалиброванное 12 - нет
+		err = e.ReceiveEvent(ctx, &domain.Event{
 			Timestamp:          time.Now(),
 			SensorSerialNumber: "0123456789",
 			Payload:            8,
 		})
+		assert.ErrorIs(t, err, ErrSensorValueOutOfRange)
+	})
+
+	t.Run("ok, out-of-order event does not change state", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		lastActivity := time.Now().Add(-time.Minute)
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID:           1,
+			Type:         domain.SensorTypeContactClosure,
+			IsActive:     true,
+			CurrentState: 5,
+			LastActivity: lastActivity,
+		}, nil)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).Times(0)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, event *domain.Event) {
+			assert.Equal(t, lastActivity.Add(-time.Hour), event.Timestamp)
+			assert.False(t, event.ReceivedAt.IsZero())
+		})
+
+		e := NewEvent(er, sr, nil, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          lastActivity.Add(-time.Hour),
+			SensorSerialNumber: "0123456789",
+			Payload:            1,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("ok, newer device timestamp updates state", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID:           1,
+			Type:         domain.SensorTypeContactClosure,
+			IsActive:     true,
+			LastActivity: now.Add(-time.Hour),
+		}, nil)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
+			assert.Equal(t, int64(1), s.CurrentState)
+			assert.Equal(t, now.Add(-time.Minute), s.LastActivity)
+		})
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, event *domain.Event) {
+			assert.Equal(t, now, event.ReceivedAt)
+		})
+
+		e := NewEvent(er, sr, nil, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+		e.now = func() time.Time { return now }
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          now.Add(-time.Minute),
+			SensorSerialNumber: "0123456789",
+			Payload:            1,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("err, timestamp too far in the future", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, gomock.Any()).Times(0)
+
+		e := NewEvent(nil, sr, nil, nil, nil, newTransactor(ctrl), WithMaxClockSkew(time.Second))
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now().Add(time.Minute),
+			SensorSerialNumber: "0123456789",
+		})
+		assert.ErrorIs(t, err, ErrEventFromFuture)
+	})
+
+	t.Run("err, value out of type range", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID:       1,
+			Type:     domain.SensorTypeHumidity,
+			IsActive: true,
+		}, nil)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).Times(0)
+
+		str := NewMockSensorTypeRepository(ctrl)
+		str.EXPECT().GetSensorType(ctx, domain.SensorTypeHumidity).Return(&domain.SensorTypeDefinition{
+			Type:      domain.SensorTypeHumidity,
+			ValueKind: domain.SensorValueKindInteger,
+			MinValue:  0,
+			MaxValue:  100,
+			Unit:      "%",
+		}, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(0)
+
+		e := NewEvent(er, sr, nil, nil, str, newTransactor(ctrl))
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "0123456789",
+			Payload:            101,
+		})
+		assert.ErrorIs(t, err, ErrSensorValueOutOfRange)
+	})
+
+	t.Run("ok, event and sensor saved in one transaction", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		txCtx := context.WithValue(ctx, struct{}{}, "tx")
+
+		tr := NewMockTransactor(ctrl)
+		tr.EXPECT().WithinTransaction(ctx, gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, fn func(ctx context.Context) error) error {
+				return fn(txCtx)
+			})
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID:       1,
+			Type:     domain.SensorTypeContactClosure,
+			IsActive: true,
+		}, nil)
+		sr.EXPECT().SaveSensorState(txCtx, gomock.Any()).Times(1).Return(nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(txCtx, gomock.Any()).Times(1).Return(nil)
+
+		e := NewEvent(er, sr, nil, nil, newUnboundedSensorTypeRepository(ctrl), tr)
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "0123456789",
+			Payload:            1,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("err, duplicate does not change state", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID:       1,
+			Type:     domain.SensorTypeContactClosure,
+			IsActive: true,
+		}, nil)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).Times(0)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvent(ctx, gomock.Any()).Times(1).Return(ErrEventDuplicate)
+
+		e := NewEvent(er, sr, nil, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+
+		err := e.ReceiveEvent(ctx, &domain.Event{
+			Timestamp:          time.Now(),
+			SensorSerialNumber: "0123456789",
+			EventID:            "retry",
+			Payload:            1,
+		})
+		assert.ErrorIs(t, err, ErrEventDuplicate)
+	})
+}
+
+func Test_event_ReceiveEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("err, batch too large", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		e := NewEvent(nil, nil, nil, nil, nil, newTransactor(ctrl))
+
+		_, err := e.ReceiveEvents(ctx, make([]domain.Event, MaxEventBatchSize+1))
+		assert.ErrorIs(t, err, ErrEventBatchTooLarge)
+	})
+
+	t.Run("ok, rejected events do not block the rest", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		now := time.Now()
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID:       1,
+			Type:     domain.SensorTypeContactClosure,
+			IsActive: true,
+			HomeID:   2,
+		}, nil)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "1111111111").Times(1).Return(nil, ErrSensorNotFound)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "2222222222").Times(1).Return(&domain.Sensor{
+			ID:   3,
+			Type: domain.SensorTypeContactClosure,
+		}, nil)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
+			assert.Equal(t, int64(1), s.ID)
+			assert.Equal(t, int64(20), s.CurrentState, "Состояние выставлено не по самому новому событию")
+		})
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvents(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, events []domain.Event) {
+			if assert.Len(t, events, 2) {
+				assert.Equal(t, int64(20), events[0].Payload)
+				assert.Equal(t, int64(10), events[1].Payload)
+				assert.Equal(t, int64(2), events[1].HomeID)
+				events[0].ID, events[1].ID = 5, 6
+				events[0].Sequence, events[1].Sequence = 7, 8
+			}
+		}).Return([]error{nil, nil}, nil)
+
+		e := NewEvent(er, sr, nil, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+
+		events := []domain.Event{
+			{Timestamp: now, SensorSerialNumber: "0123456789", Payload: 20},
+			{SensorSerialNumber: "0123456789", Payload: 30},
+			{Timestamp: now.Add(-time.Minute), SensorSerialNumber: "0123456789", Payload: 10},
+			{Timestamp: now, SensorSerialNumber: "1111111111", Payload: 1},
+			{Timestamp: now, SensorSerialNumber: "2222222222", Payload: 1},
+		}
+		errs, err := e.ReceiveEvents(ctx, events)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{5, 0, 6, 0, 0}, []int64{events[0].ID, events[1].ID, events[2].ID, events[3].ID, events[4].ID},
+			"ID сохранённых событий не проставлены")
+		assert.Equal(t, []int64{7, 8}, []int64{events[0].Sequence, events[2].Sequence})
+		if assert.Len(t, errs, 5) {
+			assert.NoError(t, errs[0])
+			assert.ErrorIs(t, errs[1], ErrInvalidEventTimestamp)
+			assert.NoError(t, errs[2])
+			assert.ErrorIs(t, errs[3], ErrSensorNotFound)
+			assert.ErrorIs(t, errs[4], ErrSensorInactive)
+		}
+	})
+
+	t.Run("err, batch not saved", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID:       1,
+			Type:     domain.SensorTypeContactClosure,
+			IsActive: true,
+		}, nil)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).Times(0)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvents(ctx, gomock.Any()).Times(1).Return(nil, errors.New("some error"))
+
+		e := NewEvent(er, sr, nil, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+
+		_, err := e.ReceiveEvents(ctx, []domain.Event{{Timestamp: time.Now(), SensorSerialNumber: "0123456789"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("ok, only owned sensors accept events from user", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID:       1,
+			Type:     domain.SensorTypeContactClosure,
+			IsActive: true,
+		}, nil)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "1111111111").Times(1).Return(&domain.Sensor{
+			ID:       2,
+			Type:     domain.SensorTypeContactClosure,
+			IsActive: true,
+		}, nil)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
+			assert.Equal(t, int64(2), s.ID)
+		})
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Times(2).Return([]domain.SensorOwner{
+			{UserID: 7, SensorID: 1, Role: domain.SensorRoleMember},
+			{UserID: 7, SensorID: 2, Role: domain.SensorRoleOwner},
+		}, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvents(ctx, gomock.Len(1)).Times(1).Return([]error{nil}, nil)
+
+		e := NewEvent(er, sr, sor, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+
+		errs, err := e.ReceiveEvents(ctx, []domain.Event{
+			{Timestamp: time.Now(), SensorSerialNumber: "0123456789", Payload: 1},
+			{Timestamp: time.Now(), SensorSerialNumber: "1111111111", Payload: 1},
+		})
+		assert.NoError(t, err)
+		if assert.Len(t, errs, 2) {
+			assert.ErrorIs(t, errs[0], ErrSensorAccessDenied, "Участник отправил событие в пакете без ключа датчика")
+			assert.NoError(t, errs[1])
+		}
+	})
+
+	t.Run("ok, duplicates do not change state", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		now := time.Now()
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0123456789").Times(1).Return(&domain.Sensor{
+			ID:       1,
+			Type:     domain.SensorTypeContactClosure,
+			IsActive: true,
+		}, nil)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, s *domain.Sensor) {
+			assert.Equal(t, int64(10), s.CurrentState, "Состояние выставлено по повтору события")
+		})
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvents(ctx, gomock.Any()).Times(1).Return([]error{nil, ErrEventDuplicate, nil}, nil)
+
+		e := NewEvent(er, sr, nil, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+
+		errs, err := e.ReceiveEvents(ctx, []domain.Event{
+			{Timestamp: now.Add(-time.Minute), SensorSerialNumber: "0123456789", EventID: "a", Payload: 10},
+			{SensorSerialNumber: "0123456789", EventID: "d", Payload: 1},
+			{Timestamp: now, SensorSerialNumber: "0123456789", EventID: "b", Payload: 20},
+			{Timestamp: now.Add(-2 * time.Minute), SensorSerialNumber: "0123456789", EventID: "c", Payload: 30},
+		})
 		assert.NoError(t, err)
+		if assert.Len(t, errs, 4) {
+			assert.NoError(t, errs[0])
+			assert.ErrorIs(t, errs[1], ErrInvalidEventTimestamp)
+			assert.ErrorIs(t, errs[2], ErrEventDuplicate)
+			assert.NoError(t, errs[3])
+		}
 	})
 }
 
@@ -130,7 +673,7 @@ func Test_event_GetLastEventBySensorID(t *testing.T) {
 
 		er := NewMockEventRepository(ctrl)
 		er.EXPECT().GetLastEventBySensorID(ctx, int64(1)).Times(1).Return(nil, ErrSensorNotFound)
-		e := NewEvent(er, nil)
+		e := NewEvent(er, nil, nil, nil, nil, newTransactor(ctrl))
 
 		_, err := e.GetLastEventBySensorID(ctx, int64(1))
 		assert.ErrorIs(t, err, ErrSensorNotFound)
@@ -150,7 +693,7 @@ func Test_event_GetLastEventBySensorID(t *testing.T) {
 			Payload:            8,
 		}, nil)
 
-		e := NewEvent(er, sr)
+		e := NewEvent(er, sr, nil, nil, nil, newTransactor(ctrl))
 		event, err := e.GetLastEventBySensorID(ctx, int64(1))
 		assert.NoError(t, err)
 		assert.NotNil(t, event)
@@ -171,7 +714,7 @@ func Test_event_GetSensorHistory(t *testing.T) {
 		sr := NewMockSensorRepository(ctrl)
 
 		er := NewMockEventRepository(ctrl)
-		er.EXPECT().GetSensorHistory(ctx, int64(1), gomock.Any(), gomock.Any()).Times(1).Return([]domain.Event{
+		er.EXPECT().GetSensorHistory(ctx, int64(1), gomock.Any()).Times(1).Return([]domain.Event{
 			{
 				Timestamp:          time.Now(),
 				SensorID:           1,
@@ -186,10 +729,529 @@ func Test_event_GetSensorHistory(t *testing.T) {
 			},
 		}, nil)
 
-		e := NewEvent(er, sr)
-		history, err := e.GetSensorHistory(ctx, int64(1), time.Now(), time.Now())
+		e := NewEvent(er, sr, nil, nil, nil, newTransactor(ctrl))
+		history, err := e.GetSensorHistory(ctx, int64(1), domain.HistoryQuery{Start: time.Now(), End: time.Now()})
 		assert.NoError(t, err)
 		assert.NotNil(t, history)
-		assert.Equal(t, 2, len(history))
+		assert.Equal(t, 2, len(history.Events))
+		assert.Nil(t, history.Next)
+	})
+
+	t.Run("ok, next cursor when page is full", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		now := time.Now()
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().GetSensorHistory(ctx, int64(1), gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, _ int64, query domain.HistoryQuery) ([]domain.Event, error) {
+				assert.Equal(t, 3, query.Limit, "Репозиторий должен вернуть на событие больше страницы")
+				assert.True(t, query.Descending)
+				return []domain.Event{
+					{ID: 3, Timestamp: now, Payload: 3},
+					{ID: 2, Timestamp: now.Add(-time.Minute), Payload: 2},
+					{ID: 1, Timestamp: now.Add(-2 * time.Minute), Payload: 1},
+				}, nil
+			})
+
+		e := NewEvent(er, nil, nil, nil, nil, newTransactor(ctrl))
+		page, err := e.GetSensorHistory(ctx, int64(1), domain.HistoryQuery{
+			Start:      now.Add(-time.Hour),
+			End:        now,
+			Limit:      2,
+			Descending: true,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, page.Events, 2)
+		assert.Equal(t, &domain.HistoryCursor{Timestamp: now.Add(-time.Minute), ID: 2}, page.Next)
+	})
+
+	t.Run("ok, default limit", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().GetSensorHistory(ctx, int64(1), gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, _ int64, query domain.HistoryQuery) ([]domain.Event, error) {
+				assert.Equal(t, DefaultHistoryLimit+1, query.Limit)
+				return nil, nil
+			})
+
+		e := NewEvent(er, nil, nil, nil, nil, newTransactor(ctrl))
+		page, err := e.GetSensorHistory(ctx, int64(1), domain.HistoryQuery{})
+		assert.NoError(t, err)
+		assert.Empty(t, page.Events)
+	})
+
+	t.Run("err, invalid query", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		e := NewEvent(nil, nil, nil, nil, nil, newTransactor(ctrl))
+		now := time.Now()
+		for _, query := range []domain.HistoryQuery{
+			{Start: now, End: now.Add(-time.Second)},
+			{Start: now, End: now, Limit: -1},
+			{Start: now, End: now, Limit: MaxHistoryLimit + 1},
+		} {
+			_, err := e.GetSensorHistory(ctx, int64(1), query)
+			assert.ErrorIs(t, err, ErrInvalidHistoryQuery)
+		}
+	})
+
+	t.Run("ok, events of previous home hidden", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, HomeID: 2}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Times(1).Return(nil, nil)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 2, UserID: 7, Role: domain.SensorRoleViewer},
+		}, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().GetSensorHistory(ctx, int64(1), gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, _ int64, query domain.HistoryQuery) ([]domain.Event, error) {
+				if assert.NotNil(t, query.HomeID, "История не ограничена текущим домом датчика") {
+					assert.Equal(t, int64(2), *query.HomeID)
+				}
+				return []domain.Event{{SensorID: 1, HomeID: 2, Payload: 9}}, nil
+			})
+
+		e := NewEvent(er, sr, sor, hr, nil, newTransactor(ctrl))
+		history, err := e.GetSensorHistory(ctx, int64(1), domain.HistoryQuery{Start: time.Now(), End: time.Now()})
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Event{{SensorID: 1, HomeID: 2, Payload: 9}}, history.Events)
+	})
+
+	t.Run("err, sensor of another home", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, HomeID: 2}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Times(1).Return(nil, nil)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 3, UserID: 7, Role: domain.SensorRoleOwner},
+		}, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().GetSensorHistory(ctx, gomock.Any(), gomock.Any()).Times(0)
+
+		e := NewEvent(er, sr, sor, hr, nil, newTransactor(ctrl))
+		_, err := e.GetSensorHistory(ctx, int64(1), domain.HistoryQuery{Start: time.Now(), End: time.Now()})
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+}
+
+func Test_event_ExportSensorHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	query := domain.HistoryQuery{Start: now.Add(-time.Hour), End: now}
+
+	t.Run("ok, sensors exported in order", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(2)).Return(&domain.Sensor{ID: 2}, nil)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Return(&domain.Sensor{ID: 1}, nil)
+
+		er := NewMockEventRepository(ctrl)
+		stream := func(events ...domain.Event) func(context.Context, int64, domain.HistoryQuery, func(domain.Event) error) error {
+			return func(_ context.Context, _ int64, q domain.HistoryQuery, fn func(domain.Event) error) error {
+				assert.Equal(t, query, q)
+				for _, event := range events {
+					if err := fn(event); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+		}
+		gomock.InOrder(
+			er.EXPECT().StreamSensorHistory(ctx, int64(2), gomock.Any(), gomock.Any()).DoAndReturn(
+				stream(domain.Event{SensorID: 2, Payload: 1}, domain.Event{SensorID: 2, Payload: 2})),
+			er.EXPECT().StreamSensorHistory(ctx, int64(1), gomock.Any(), gomock.Any()).DoAndReturn(
+				stream(domain.Event{SensorID: 1, Payload: 3})),
+		)
+
+		e := NewEvent(er, sr, nil, nil, nil, newTransactor(ctrl))
+		var exported []domain.Event
+		err := e.ExportSensorHistory(ctx, []int64{2, 1}, query, func(event domain.Event) error {
+			exported = append(exported, event)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Event{
+			{SensorID: 2, Payload: 1},
+			{SensorID: 2, Payload: 2},
+			{SensorID: 1, Payload: 3},
+		}, exported)
+	})
+
+	t.Run("err, access checked before export", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Return(&domain.Sensor{ID: 1, HomeID: 2}, nil)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Return(&domain.Sensor{ID: 3, HomeID: 3}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).AnyTimes().Return(nil, nil)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).AnyTimes().Return([]domain.HomeMember{
+			{HomeID: 2, UserID: 7, Role: domain.SensorRoleViewer},
+		}, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().StreamSensorHistory(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		e := NewEvent(er, sr, sor, hr, nil, newTransactor(ctrl))
+		err := e.ExportSensorHistory(ctx, []int64{1, 3}, query, func(domain.Event) error { return nil })
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+
+	t.Run("err, invalid query", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		e := NewEvent(nil, nil, nil, nil, nil, newTransactor(ctrl))
+		fn := func(domain.Event) error { return nil }
+		assert.ErrorIs(t, e.ExportSensorHistory(ctx, nil, query, fn), ErrInvalidHistoryQuery)
+		assert.ErrorIs(t, e.ExportSensorHistory(ctx, make([]int64, MaxExportSensors+1), query, fn), ErrInvalidHistoryQuery)
+		assert.ErrorIs(t, e.ExportSensorHistory(ctx, []int64{1}, domain.HistoryQuery{Start: now, End: now.Add(-time.Second)}, fn),
+			ErrInvalidHistoryQuery)
+	})
+}
+
+func Test_event_ReplayEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, sensors merged by sequence", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Return(&domain.Sensor{ID: 1, HomeID: 2}, nil)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Return(&domain.Sensor{ID: 3, HomeID: 2}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).AnyTimes().Return(nil, nil)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).AnyTimes().Return([]domain.HomeMember{
+			{HomeID: 2, UserID: 7, Role: domain.SensorRoleViewer},
+		}, nil)
+
+		homeID := int64(2)
+		er := NewMockEventRepository(ctrl)
+		query := domain.ReplayQuery{AfterSequence: 10, Since: time.Unix(100, 0), Limit: 3, HomeID: &homeID}
+		er.EXPECT().ReplaySensorEvents(ctx, int64(1), query).Return([]domain.Event{
+			{Sequence: 11, SensorID: 1}, {Sequence: 14, SensorID: 1}, {Sequence: 15, SensorID: 1},
+		}, nil)
+		er.EXPECT().ReplaySensorEvents(ctx, int64(3), query).Return([]domain.Event{
+			{Sequence: 12, SensorID: 3},
+		}, nil)
+
+		e := NewEvent(er, sr, sor, hr, nil, newTransactor(ctrl))
+		events, err := e.ReplayEvents(ctx, []int64{1, 3}, domain.ReplayQuery{AfterSequence: 10, Since: time.Unix(100, 0), Limit: 3})
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Event{{Sequence: 11, SensorID: 1}, {Sequence: 12, SensorID: 3}, {Sequence: 14, SensorID: 1}}, events)
+	})
+
+	t.Run("err, foreign sensor", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Return(&domain.Sensor{ID: 3, HomeID: 3}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).AnyTimes().Return(nil, nil)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).AnyTimes().Return(nil, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().ReplaySensorEvents(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		e := NewEvent(er, sr, sor, hr, nil, newTransactor(ctrl))
+		_, err := e.ReplayEvents(ctx, []int64{3}, domain.ReplayQuery{Limit: 10})
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+
+	t.Run("err, invalid query", func(t *testing.T) {
+		e := NewEvent(nil, nil, nil, nil, nil, newTransactor(ctrl))
+		_, err := e.ReplayEvents(context.Background(), []int64{1}, domain.ReplayQuery{})
+		assert.ErrorIs(t, err, ErrInvalidHistoryQuery)
+		_, err = e.ReplayEvents(context.Background(), []int64{1}, domain.ReplayQuery{Limit: MaxHistoryLimit + 1})
+		assert.ErrorIs(t, err, ErrInvalidHistoryQuery)
+		_, err = e.ReplayEvents(context.Background(), []int64{1}, domain.ReplayQuery{AfterSequence: -1, Limit: 1})
+		assert.ErrorIs(t, err, ErrInvalidHistoryQuery)
+	})
+}
+
+func Test_event_GetSensorHistoryAggregates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	start := time.Date(2024, 5, 1, 10, 0, 30, 0, time.UTC)
+	value := func(v float64) *float64 { return &v }
+
+	t.Run("err, invalid query", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		e := NewEvent(nil, nil, nil, nil, nil, newTransactor(ctrl))
+		for _, query := range []domain.HistoryAggregateQuery{
+			{Start: start, End: start.Add(time.Hour)},
+			{Start: start, End: start.Add(-time.Hour), Bucket: time.Minute},
+			{Start: start, End: start.Add(time.Hour), Bucket: time.Minute, Fill: "linear"},
+			{Start: start, End: start.Add(MaxHistoryBuckets * time.Minute), Bucket: time.Minute},
+		} {
+			_, err := e.GetSensorHistoryAggregates(ctx, int64(1), query)
+			assert.ErrorIs(t, err, ErrInvalidHistoryQuery)
+		}
+	})
+
+	t.Run("ok, without fill", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		buckets := []domain.HistoryBucket{{Start: start.Truncate(time.Minute), Count: 1, Last: value(1)}}
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().GetSensorHistoryAggregates(ctx, int64(1), gomock.Any()).Times(1).Return(buckets, nil)
+
+		e := NewEvent(er, nil, nil, nil, nil, newTransactor(ctrl))
+		got, err := e.GetSensorHistoryAggregates(ctx, int64(1), domain.HistoryAggregateQuery{
+			Start:  start,
+			End:    start.Add(5 * time.Minute),
+			Bucket: time.Minute,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, buckets, got)
+	})
+
+	t.Run("ok, gaps filled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().GetSensorHistoryAggregates(ctx, int64(1), gomock.Any()).Times(2).Return([]domain.HistoryBucket{
+			{Start: start.Truncate(time.Minute).Add(time.Minute), Count: 2, Min: value(1), Max: value(3), Avg: value(2), First: value(1), Last: value(3)},
+			{Start: start.Truncate(time.Minute).Add(3 * time.Minute), Count: 1, Min: value(5), Max: value(5), Avg: value(5), First: value(5), Last: value(5)},
+		}, nil)
+
+		e := NewEvent(er, nil, nil, nil, nil, newTransactor(ctrl))
+		query := domain.HistoryAggregateQuery{Start: start, End: start.Add(4 * time.Minute), Bucket: time.Minute, Fill: domain.HistoryFillNull}
+		got, err := e.GetSensorHistoryAggregates(ctx, int64(1), query)
+		assert.NoError(t, err)
+		if assert.Len(t, got, 5) {
+			for i, bucket := range got {
+				assert.Equal(t, start.Truncate(time.Minute).Add(time.Duration(i)*time.Minute), bucket.Start)
+			}
+			assert.Nil(t, got[0].Last)
+			assert.Equal(t, int64(2), got[1].Count)
+			assert.Equal(t, int64(0), got[2].Count)
+			assert.Nil(t, got[2].Avg)
+			assert.Nil(t, got[4].Avg)
+		}
+
+		query.Fill = domain.HistoryFillPrevious
+		got, err = e.GetSensorHistoryAggregates(ctx, int64(1), query)
+		assert.NoError(t, err)
+		if assert.Len(t, got, 5) {
+			assert.Nil(t, got[0].Last, "Интервал до первого события заполнен")
+			assert.Equal(t, int64(0), got[2].Count)
+			assert.Equal(t, value(3), got[2].Min)
+			assert.Equal(t, value(3), got[2].Avg)
+			assert.Equal(t, value(5), got[4].First)
+		}
+	})
+
+	t.Run("ok, whole intervals from rollups", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		hour := start.Truncate(time.Hour)
+		query := domain.HistoryAggregateQuery{Start: start, End: hour.Add(3*time.Hour + time.Minute), Bucket: time.Hour}
+		er := NewMockEventRepository(ctrl)
+		gomock.InOrder(
+			er.EXPECT().GetSensorHistoryAggregates(ctx, int64(1), gomock.Any()).Times(1).DoAndReturn(
+				func(_ context.Context, _ int64, q domain.HistoryAggregateQuery) ([]domain.HistoryBucket, error) {
+					assert.Equal(t, start, q.Start)
+					assert.True(t, q.End.Before(hour.Add(time.Hour)), "Начало периода считается по событиям следующего интервала")
+					return []domain.HistoryBucket{{Start: hour, Count: 1, Last: value(1)}}, nil
+				}),
+			er.EXPECT().GetSensorRollups(ctx, int64(1), gomock.Any()).Times(1).DoAndReturn(
+				func(_ context.Context, _ int64, q domain.HistoryAggregateQuery) ([]domain.HistoryRollup, error) {
+					assert.Equal(t, hour.Add(time.Hour), q.Start)
+					assert.Equal(t, hour.Add(3*time.Hour), q.End)
+					assert.Equal(t, time.Hour, q.Bucket)
+					return []domain.HistoryRollup{
+						{Start: hour.Add(time.Hour), HomeID: 1, Count: 2, Sum: 6, Min: 2, Max: 4,
+							FirstTimestamp: hour.Add(61 * time.Minute), First: 2, LastTimestamp: hour.Add(62 * time.Minute), Last: 4},
+						{Start: hour.Add(time.Hour), HomeID: 2, Count: 1, Sum: 9, Min: 9, Max: 9,
+							FirstTimestamp: hour.Add(90 * time.Minute), First: 9, LastTimestamp: hour.Add(90 * time.Minute), Last: 9},
+					}, nil
+				}),
+			er.EXPECT().GetSensorHistoryAggregates(ctx, int64(1), gomock.Any()).Times(1).DoAndReturn(
+				func(_ context.Context, _ int64, q domain.HistoryAggregateQuery) ([]domain.HistoryBucket, error) {
+					assert.Equal(t, hour.Add(3*time.Hour), q.Start)
+					assert.Equal(t, query.End, q.End)
+					return nil, nil
+				}),
+		)
+
+		e := NewEvent(er, nil, nil, nil, nil, newTransactor(ctrl))
+		got, err := e.GetSensorHistoryAggregates(ctx, int64(1), query)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.HistoryBucket{
+			{Start: hour, Count: 1, Last: value(1)},
+			{Start: hour.Add(time.Hour), Count: 3, Min: value(2), Max: value(9), Avg: value(5), First: value(2), Last: value(9)},
+		}, got)
+	})
+
+	t.Run("ok, restricted to current home", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, HomeID: 2}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Times(1).Return(nil, nil)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 2, UserID: 7, Role: domain.SensorRoleViewer},
+		}, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().GetSensorHistoryAggregates(ctx, int64(1), gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, _ int64, query domain.HistoryAggregateQuery) ([]domain.HistoryBucket, error) {
+				if assert.NotNil(t, query.HomeID, "Агрегаты не ограничены текущим домом датчика") {
+					assert.Equal(t, int64(2), *query.HomeID)
+				}
+				return nil, nil
+			})
+
+		e := NewEvent(er, sr, sor, hr, nil, newTransactor(ctrl))
+		_, err := e.GetSensorHistoryAggregates(ctx, int64(1), domain.HistoryAggregateQuery{
+			Start:  start,
+			End:    start.Add(time.Hour),
+			Bucket: time.Hour,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("err, sensor of another home", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, HomeID: 2}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Times(1).Return(nil, nil)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return(nil, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().GetSensorHistoryAggregates(ctx, gomock.Any(), gomock.Any()).Times(0)
+
+		e := NewEvent(er, sr, sor, hr, nil, newTransactor(ctrl))
+		_, err := e.GetSensorHistoryAggregates(ctx, int64(1), domain.HistoryAggregateQuery{
+			Start:  start,
+			End:    start.Add(time.Hour),
+			Bucket: time.Hour,
+		})
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+}
+
+func Test_event_PurgeSensorEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, owner purges events", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Return(&domain.Sensor{ID: 1}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Return([]domain.SensorOwner{
+			{UserID: 7, SensorID: 1, Role: domain.SensorRoleOwner},
+		}, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().DeleteEventsBySensorID(ctx, int64(1)).Times(1).Return(nil)
+
+		e := NewEvent(er, sr, sor, nil, nil, newTransactor(ctrl))
+
+		assert.NoError(t, e.PurgeSensorEvents(ctx, 1))
 	})
+
+	t.Run("fail, member cannot purge", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Return(&domain.Sensor{ID: 1}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Return([]domain.SensorOwner{
+			{UserID: 7, SensorID: 1, Role: domain.SensorRoleMember},
+		}, nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().DeleteEventsBySensorID(ctx, gomock.Any()).Times(0)
+
+		e := NewEvent(er, sr, sor, nil, nil, newTransactor(ctrl))
+
+		assert.ErrorIs(t, e.PurgeSensorEvents(ctx, 1), ErrSensorAccessDenied)
+	})
+}
+
+// newUnboundedSensorTypeRepository - реестр, в котором любой тип датчика известен и принимает любые значения
+func newUnboundedSensorTypeRepository(ctrl *gomock.Controller) *MockSensorTypeRepository {
+	str := NewMockSensorTypeRepository(ctrl)
+	str.EXPECT().GetSensorType(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, sensorType domain.SensorType) (*domain.SensorTypeDefinition, error) {
+			return &domain.SensorTypeDefinition{
+				Type:      sensorType,
+				ValueKind: domain.SensorValueKindInteger,
+				MinValue:  math.MinInt64,
+				MaxValue:  math.MaxInt64,
+			}, nil
+		})
+	return str
+}
+
+// newTransactor - транзакция, которая просто выполняет переданную функцию
+func newTransactor(ctrl *gomock.Controller) *MockTransactor {
+	tr := NewMockTransactor(ctrl)
+	tr.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(ctx)
+		})
+	return tr
 }
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"time"
+)
+
+type Home struct {
+	hr  HomeRepository
+	ur  UserRepository
+	sr  SensorRepository
+	tr  Transactor
+	now func() time.Time
+}
+
+func NewHome(hr HomeRepository, ur UserRepository, sr SensorRepository, tr Transactor) *Home {
+	return &Home{
+		hr:  hr,
+		ur:  ur,
+		sr:  sr,
+		tr:  tr,
+		now: time.Now,
+	}
+}
+
+// CreateHome - создаёт дом, вызывающий пользователь становится его владельцем.
+// Дом и владелец сохраняются в одной транзакции, чтобы не остался дом без владельца
+func (h *Home) CreateHome(ctx context.Context, home *domain.Home) (*domain.Home, error) {
+	if home == nil {
+		return nil, errors.New("nil home")
+	}
+	if home.Name == "" {
+		return nil, ErrInvalidHomeName
+	}
+	home.CreatedAt = h.now()
+	err := h.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := h.hr.SaveHome(ctx, home); err != nil {
+			return err
+		}
+		userID, ok := CallerFromContext(ctx)
+		if !ok {
+			return nil
+		}
+		return h.hr.SaveHomeMember(ctx, domain.HomeMember{
+			HomeID: home.ID,
+			UserID: userID,
+			Role:   domain.SensorRoleOwner,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return home, nil
+}
+
+// GetHomes - возвращает дома, в которых состоит вызывающий пользователь.
+// Администратор и внутренние вызовы получают все дома.
+func (h *Home) GetHomes(ctx context.Context) ([]domain.Home, error) {
+	userID, ok := restrictedCaller(ctx)
+	if !ok {
+		return h.hr.GetHomes(ctx)
+	}
+	homeMembers, err := h.hr.GetHomesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	homes := make([]domain.Home, 0, len(homeMembers))
+	for _, homeMember := range homeMembers {
+		home, err := h.hr.GetHomeByID(ctx, homeMember.HomeID)
+		if err != nil {
+			return nil, err
+		}
+		homes = append(homes, *home)
+	}
+	return homes, nil
+}
+
+func (h *Home) GetHomeByID(ctx context.Context, id int64) (*domain.Home, error) {
+	home, err := h.hr.GetHomeByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkHomeAccess(ctx, h.hr, id, domain.SensorRoleViewer); err != nil {
+		return nil, err
+	}
+	return home, nil
+}
+
+func (h *Home) GetHomeMembers(ctx context.Context, homeID int64) ([]domain.HomeMember, error) {
+	if _, err := h.GetHomeByID(ctx, homeID); err != nil {
+		return nil, err
+	}
+	return h.hr.GetHomeMembers(ctx, homeID)
+}
+
+func (h *Home) GetHomeSensors(ctx context.Context, homeID int64) ([]domain.Sensor, error) {
+	if _, err := h.GetHomeByID(ctx, homeID); err != nil {
+		return nil, err
+	}
+	return h.sr.GetSensorsByHomeID(ctx, homeID)
+}
+
+// SetHomeMember - добавляет пользователя в дом или меняет его роль, доступно только владельцу дома.
+// Последнего владельца понизить нельзя. Проверки и запись выполняются в транзакции над заблокированным домом,
+// чтобы два владельца, одновременно понижающие друг друга, не оставили дом без владельца.
+func (h *Home) SetHomeMember(ctx context.Context, homeID, userID int64, role domain.SensorRole) error {
+	if !isValidSensorRole(role) {
+		return ErrWrongSensorRole
+	}
+	return h.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := h.hr.GetHomeByIDForUpdate(ctx, homeID); err != nil {
+			return err
+		}
+		if err := checkHomeAccess(ctx, h.hr, homeID, domain.SensorRoleOwner); err != nil {
+			return err
+		}
+		if _, err := h.ur.GetUserByID(ctx, userID); err != nil {
+			return err
+		}
+		if role != domain.SensorRoleOwner {
+			if err := h.checkOtherOwnerExists(ctx, homeID, userID); err != nil {
+				return err
+			}
+		}
+		return h.hr.SaveHomeMember(ctx, domain.HomeMember{
+			HomeID: homeID,
+			UserID: userID,
+			Role:   role,
+		})
+	})
+}
+
+// RemoveHomeMember - исключает пользователя из дома.
+// Исключать других может только владелец, выйти самому может любой участник, кроме последнего владельца.
+// Как и SetHomeMember, выполняется в транзакции над заблокированным домом.
+func (h *Home) RemoveHomeMember(ctx context.Context, homeID, userID int64) error {
+	return h.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := h.hr.GetHomeByIDForUpdate(ctx, homeID); err != nil {
+			return err
+		}
+		required := domain.SensorRoleOwner
+		if callerID, ok := CallerFromContext(ctx); ok && callerID == userID {
+			required = domain.SensorRoleViewer
+		}
+		if err := checkHomeAccess(ctx, h.hr, homeID, required); err != nil {
+			return err
+		}
+		_, member, err := getHomeRole(ctx, h.hr, userID, homeID)
+		if err != nil {
+			return err
+		}
+		if !member {
+			return ErrUserNotFound
+		}
+		if err := h.checkOtherOwnerExists(ctx, homeID, userID); err != nil {
+			return err
+		}
+		return h.hr.DeleteHomeMember(ctx, homeID, userID)
+	})
+}
+
+// checkOtherOwnerExists - проверяет, что после лишения userID прав владельца у дома останется владелец
+func (h *Home) checkOtherOwnerExists(ctx context.Context, homeID, userID int64) error {
+	homeMembers, err := h.hr.GetHomeMembers(ctx, homeID)
+	if err != nil {
+		return err
+	}
+	isOwner := false
+	for _, homeMember := range homeMembers {
+		if homeMember.Role != domain.SensorRoleOwner {
+			continue
+		}
+		if homeMember.UserID != userID {
+			return nil
+		}
+		isOwner = true
+	}
+	if isOwner {
+		return ErrLastHomeOwner
+	}
+	return nil
+}
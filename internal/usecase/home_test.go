@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_home_CreateHome(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, empty name", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		h := NewHome(nil, nil, nil, newTransactor(ctrl))
+
+		_, err := h.CreateHome(ctx, &domain.Home{})
+		assert.ErrorIs(t, err, ErrInvalidHomeName)
+	})
+
+	t.Run("ok, caller becomes owner", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().SaveHome(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, home *domain.Home) error {
+			home.ID = 2
+			return nil
+		})
+		hr.EXPECT().SaveHomeMember(ctx, domain.HomeMember{HomeID: 2, UserID: 7, Role: domain.SensorRoleOwner}).Times(1).Return(nil)
+
+		h := NewHome(hr, nil, nil, newTransactor(ctrl))
+
+		home, err := h.CreateHome(ctx, &domain.Home{Name: "Квартира"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), home.ID)
+		assert.False(t, home.CreatedAt.IsZero())
+	})
+
+	t.Run("fail, home and owner saved in one transaction", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+		txCtx := context.WithValue(ctx, struct{}{}, "tx")
+		someErr := errors.New("some error")
+
+		tr := NewMockTransactor(ctrl)
+		tr.EXPECT().WithinTransaction(ctx, gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, fn func(ctx context.Context) error) error {
+				return fn(txCtx)
+			})
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().SaveHome(txCtx, gomock.Any()).Times(1).Return(nil)
+		hr.EXPECT().SaveHomeMember(txCtx, gomock.Any()).Times(1).Return(someErr)
+
+		h := NewHome(hr, nil, nil, tr)
+
+		home, err := h.CreateHome(ctx, &domain.Home{Name: "Квартира"})
+		assert.ErrorIs(t, err, someErr)
+		assert.Nil(t, home)
+	})
+}
+
+func Test_home_GetHomes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, only caller homes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomes(ctx).Times(0)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 2, UserID: 7, Role: domain.SensorRoleMember},
+		}, nil)
+		hr.EXPECT().GetHomeByID(ctx, int64(2)).Times(1).Return(&domain.Home{ID: 2}, nil)
+
+		h := NewHome(hr, nil, nil, newTransactor(ctrl))
+
+		list, err := h.GetHomes(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Home{{ID: 2}}, list)
+	})
+
+	t.Run("ok, admin gets all homes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithAdmin(context.Background(), 7))
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomes(ctx).Times(1).Return([]domain.Home{{ID: 2}, {ID: 3}}, nil)
+
+		h := NewHome(hr, nil, nil, newTransactor(ctrl))
+
+		list, err := h.GetHomes(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, list, 2)
+	})
+}
+
+func Test_home_GetHomeSensors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, home of another user", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(2)).Times(1).Return(&domain.Home{ID: 2}, nil)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return(nil, nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorsByHomeID(ctx, gomock.Any()).Times(0)
+
+		h := NewHome(hr, nil, sr, newTransactor(ctrl))
+
+		_, err := h.GetHomeSensors(ctx, 2)
+		assert.ErrorIs(t, err, ErrHomeNotFound)
+	})
+
+	t.Run("ok, member gets sensors", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(2)).Times(1).Return(&domain.Home{ID: 2}, nil)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 2, UserID: 7, Role: domain.SensorRoleViewer},
+		}, nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorsByHomeID(ctx, int64(2)).Times(1).Return([]domain.Sensor{{ID: 1, HomeID: 2}}, nil)
+
+		h := NewHome(hr, nil, sr, newTransactor(ctrl))
+
+		list, err := h.GetHomeSensors(ctx, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Sensor{{ID: 1, HomeID: 2}}, list)
+	})
+}
+
+func Test_home_SetHomeMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, owner adds member", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 1))
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByIDForUpdate(ctx, int64(2)).Times(1).Return(&domain.Home{ID: 2}, nil)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(1)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 2, UserID: 1, Role: domain.SensorRoleOwner},
+		}, nil)
+		hr.EXPECT().GetHomeMembers(ctx, int64(2)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 2, UserID: 1, Role: domain.SensorRoleOwner},
+		}, nil)
+		hr.EXPECT().SaveHomeMember(ctx, domain.HomeMember{HomeID: 2, UserID: 5, Role: domain.SensorRoleMember}).Times(1).Return(nil)
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(5)).Times(1).Return(&domain.User{ID: 5}, nil)
+
+		h := NewHome(hr, ur, nil, newTransactor(ctrl))
+
+		assert.NoError(t, h.SetHomeMember(ctx, 2, 5, domain.SensorRoleMember))
+	})
+
+	t.Run("fail, member cannot add members", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 5))
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByIDForUpdate(ctx, int64(2)).Times(1).Return(&domain.Home{ID: 2}, nil)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(5)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 2, UserID: 5, Role: domain.SensorRoleMember},
+		}, nil)
+		hr.EXPECT().SaveHomeMember(ctx, gomock.Any()).Times(0)
+
+		h := NewHome(hr, nil, nil, newTransactor(ctrl))
+
+		assert.ErrorIs(t, h.SetHomeMember(ctx, 2, 6, domain.SensorRoleViewer), ErrSensorAccessDenied)
+	})
+
+	t.Run("fail, last owner downgrade", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 1))
+		defer cancel()
+
+		owner := []domain.HomeMember{{HomeID: 2, UserID: 1, Role: domain.SensorRoleOwner}}
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByIDForUpdate(ctx, int64(2)).Times(1).Return(&domain.Home{ID: 2}, nil)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(1)).Times(1).Return(owner, nil)
+		hr.EXPECT().GetHomeMembers(ctx, int64(2)).Times(1).Return(owner, nil)
+		hr.EXPECT().SaveHomeMember(ctx, gomock.Any()).Times(0)
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Times(1).Return(&domain.User{ID: 1}, nil)
+
+		h := NewHome(hr, ur, nil, newTransactor(ctrl))
+
+		assert.ErrorIs(t, h.SetHomeMember(ctx, 2, 1, domain.SensorRoleViewer), ErrLastHomeOwner)
+	})
+
+	t.Run("ok, last owner check runs on locked home", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 1))
+		defer cancel()
+		txCtx := context.WithValue(ctx, struct{}{}, "tx")
+
+		tr := NewMockTransactor(ctrl)
+		tr.EXPECT().WithinTransaction(ctx, gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, fn func(ctx context.Context) error) error {
+				return fn(txCtx)
+			})
+
+		hr := NewMockHomeRepository(ctrl)
+		owners := []domain.HomeMember{
+			{HomeID: 2, UserID: 1, Role: domain.SensorRoleOwner},
+			{HomeID: 2, UserID: 5, Role: domain.SensorRoleOwner},
+		}
+		gomock.InOrder(
+			hr.EXPECT().GetHomeByIDForUpdate(txCtx, int64(2)).Times(1).Return(&domain.Home{ID: 2}, nil),
+			hr.EXPECT().GetHomesByUserID(txCtx, int64(1)).Times(1).Return(owners[:1], nil),
+			hr.EXPECT().GetHomeMembers(txCtx, int64(2)).Times(1).Return(owners, nil),
+			hr.EXPECT().SaveHomeMember(txCtx, domain.HomeMember{HomeID: 2, UserID: 5, Role: domain.SensorRoleMember}).Times(1).Return(nil),
+		)
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(txCtx, int64(5)).Times(1).Return(&domain.User{ID: 5}, nil)
+
+		h := NewHome(hr, ur, nil, tr)
+
+		assert.NoError(t, h.SetHomeMember(ctx, 2, 5, domain.SensorRoleMember))
+	})
+}
+
+func Test_home_RemoveHomeMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, member leaves", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 5))
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByIDForUpdate(ctx, int64(2)).Times(1).Return(&domain.Home{ID: 2}, nil)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(5)).Times(2).Return([]domain.HomeMember{
+			{HomeID: 2, UserID: 5, Role: domain.SensorRoleViewer},
+		}, nil)
+		hr.EXPECT().GetHomeMembers(ctx, int64(2)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 2, UserID: 1, Role: domain.SensorRoleOwner},
+			{HomeID: 2, UserID: 5, Role: domain.SensorRoleViewer},
+		}, nil)
+		hr.EXPECT().DeleteHomeMember(ctx, int64(2), int64(5)).Times(1).Return(nil)
+
+		h := NewHome(hr, nil, nil, newTransactor(ctrl))
+
+		assert.NoError(t, h.RemoveHomeMember(ctx, 2, 5))
+	})
+
+	t.Run("fail, not a member", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByIDForUpdate(ctx, int64(2)).Times(1).Return(&domain.Home{ID: 2}, nil)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(5)).Times(1).Return(nil, nil)
+		hr.EXPECT().DeleteHomeMember(ctx, gomock.Any(), gomock.Any()).Times(0)
+
+		h := NewHome(hr, nil, nil, newTransactor(ctrl))
+
+		assert.ErrorIs(t, h.RemoveHomeMember(ctx, 2, 5), ErrUserNotFound)
+	})
+}
@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"io"
+)
+
+// MaxImportErrors - сколько причин отклонения записей попадает в отчёт импорта, остальные записи только подсчитываются
+const MaxImportErrors = 100
+
+// errImportDryRun - откатывает транзакцию проверочного импорта
+var errImportDryRun = errors.New("import dry run")
+
+// ImportReader - записи файла импорта по порядку
+type ImportReader[T any] interface {
+	// Next - возвращает номер строки очередной записи и запись, после последней записи - io.EOF.
+	// Запись, которую не удалось разобрать, возвращается с ошибкой ErrInvalidImportRecord, чтение после неё продолжается
+	Next() (int, T, error)
+}
+
+type Import struct {
+	s         *Sensor
+	e         *Event
+	tr        Transactor
+	batchSize int
+}
+
+func NewImport(s *Sensor, e *Event, tr Transactor) *Import {
+	return &Import{
+		s:         s,
+		e:         e,
+		tr:        tr,
+		batchSize: MaxEventBatchSize,
+	}
+}
+
+// Import - регистрирует недостающие датчики и сохраняет события с их исходным временем, сначала все датчики, затем события.
+// Любой из файлов может отсутствовать. Датчики регистрируются как в Sensor.RegisterSensor, уже зарегистрированные
+// датчики только подсчитываются, а ключи новых датчиков возвращаются в отчёте. События сохраняются пакетами и проверяются как в Event.ReceiveEvents, повторы событий
+// с тем же EventID не сохраняются. Отклонённые записи не мешают импорту остальных и попадают в отчёт.
+// При dryRun импорт выполняется в одной транзакции, которая затем откатывается, отчёт при этом тот же, что и при импорте:
+// каждая запись датчика и каждый пакет событий сохраняются в своей точке сохранения, и ошибка одной записи
+// не прерывает транзакцию для остальных, а пакет событий откатывается сразу после проверки.
+// При ошибке хранилища импорт прерывается, отчёт описывает уже импортированные записи
+func (i *Import) Import(ctx context.Context, sensors ImportReader[domain.Sensor], events ImportReader[domain.Event], dryRun bool) (*domain.ImportReport, error) {
+	report := &domain.ImportReport{DryRun: dryRun}
+	run := func(ctx context.Context) error {
+		if sensors != nil {
+			if err := i.importSensors(ctx, sensors, report); err != nil {
+				return err
+			}
+		}
+		if events != nil {
+			var accepted dryRunEvents
+			if dryRun {
+				accepted = make(dryRunEvents)
+			}
+			return i.importEvents(ctx, events, report, accepted)
+		}
+		return nil
+	}
+	if !dryRun {
+		return report, run(ctx)
+	}
+	err := i.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := run(ctx); err != nil {
+			return err
+		}
+		return errImportDryRun
+	})
+	if errors.Is(err, errImportDryRun) {
+		return report, nil
+	}
+	return report, err
+}
+
+func (i *Import) importSensors(ctx context.Context, sensors ImportReader[domain.Sensor], report *domain.ImportReport) error {
+	for {
+		line, sensor, err := sensors.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err == nil {
+			var registered *domain.RegisteredSensor
+			// запись регистрируется в точке сохранения, чтобы её ошибка не прерывала транзакцию проверочного импорта
+			err = i.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+				var err error
+				registered, err = i.s.RegisterSensor(ctx, &sensor)
+				return err
+			})
+			switch {
+			case err == nil && registered.APIKey != "":
+				report.SensorsCreated++
+				if !report.DryRun {
+					report.SensorKeys = append(report.SensorKeys, domain.ImportSensorKey{
+						SerialNumber: registered.SerialNumber,
+						APIKey:       registered.APIKey,
+					})
+				}
+				continue
+			case err == nil:
+				report.SensorsExisting++
+				continue
+			}
+		}
+		if !isSensorRecordError(err) {
+			return err
+		}
+		report.SensorsRejected++
+		addImportError(report, domain.ImportSourceSensors, line, err)
+	}
+}
+
+// dryRunEventKey - датчик и EventID события
+type dryRunEventKey struct {
+	serialNumber string
+	eventID      string
+}
+
+// dryRunEvents - события с EventID, принятые проверочным импортом. Пакеты событий проверочного импорта
+// откатываются сразу после проверки, чтобы не держать блокировку последовательностей событий, завершения которой
+// ждёт открытие потоков событий, поэтому повторы событий из прошлых пакетов находятся по этому множеству
+type dryRunEvents map[dryRunEventKey]struct{}
+
+// importItem - запись файла событий, ожидающая сохранения пакетом
+type importItem struct {
+	line  int
+	event domain.Event
+	err   error
+}
+
+func (i *Import) importEvents(ctx context.Context, events ImportReader[domain.Event], report *domain.ImportReport, accepted dryRunEvents) error {
+	items := make([]importItem, 0, i.batchSize)
+	for {
+		line, event, err := events.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, ErrInvalidImportRecord) {
+			return err
+		}
+		items = append(items, importItem{line: line, event: event, err: err})
+		if len(items) < i.batchSize {
+			continue
+		}
+		if err := i.saveEvents(ctx, items, report, accepted); err != nil {
+			return err
+		}
+		items = items[:0]
+	}
+	return i.saveEvents(ctx, items, report, accepted)
+}
+
+// saveEvents - сохраняет пакет записей событий и учитывает их в отчёте в порядке файла.
+// При проверочном импорте accepted не nil, и сохранение пакета сразу откатывается
+func (i *Import) saveEvents(ctx context.Context, items []importItem, report *domain.ImportReport, accepted dryRunEvents) error {
+	batch := make([]domain.Event, 0, len(items))
+	for k := range items {
+		key := dryRunEventKey{serialNumber: items[k].event.SensorSerialNumber, eventID: items[k].event.EventID}
+		if _, ok := accepted[key]; ok && items[k].err == nil && key.eventID != "" {
+			items[k].err = ErrEventDuplicate
+		}
+		if items[k].err == nil {
+			batch = append(batch, items[k].event)
+		}
+	}
+	var errs []error
+	if len(batch) > 0 {
+		err := i.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+			var err error
+			if errs, err = i.e.ReceiveEvents(ctx, batch); err != nil {
+				return err
+			}
+			if accepted != nil {
+				return errImportDryRun
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errImportDryRun) {
+			return err
+		}
+	}
+	j := 0
+	for _, item := range items {
+		err := item.err
+		if err == nil {
+			err = errs[j]
+			j++
+		}
+		switch {
+		case err == nil:
+			report.EventsImported++
+			if accepted != nil && item.event.EventID != "" {
+				accepted[dryRunEventKey{serialNumber: item.event.SensorSerialNumber, eventID: item.event.EventID}] = struct{}{}
+			}
+		case errors.Is(err, ErrEventDuplicate):
+			report.EventsDuplicate++
+		default:
+			report.EventsRejected++
+			addImportError(report, domain.ImportSourceEvents, item.line, err)
+		}
+	}
+	return nil
+}
+
+// isSensorRecordError - ошибка относится к записи датчика, а не к хранилищу, и импорт можно продолжать
+func isSensorRecordError(err error) bool {
+	for _, target := range []error{ErrInvalidImportRecord, ErrWrongSensorSerialNumber, ErrWrongSensorType,
+		ErrSensorAlreadyExists, ErrHomeNotFound, ErrSensorAccessDenied} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func addImportError(report *domain.ImportReport, source domain.ImportSource, line int, err error) {
+	if len(report.Errors) < MaxImportErrors {
+		report.Errors = append(report.Errors, domain.ImportError{Source: source, Line: line, Err: err})
+	}
+}
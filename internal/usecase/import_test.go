@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// importRecord - запись файла импорта или ошибка её разбора
+type importRecord[T any] struct {
+	record T
+	err    error
+}
+
+// sliceImportReader - файл импорта, каждая запись которого занимает одну строку
+type sliceImportReader[T any] struct {
+	records []importRecord[T]
+	line    int
+}
+
+func (r *sliceImportReader[T]) Next() (int, T, error) {
+	var record T
+	if r.line == len(r.records) {
+		return r.line, record, io.EOF
+	}
+	r.line++
+	return r.line, r.records[r.line-1].record, r.records[r.line-1].err
+}
+
+func Test_import_Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+
+	t.Run("ok, records counted in file order", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000001").Return(nil, ErrSensorNotFound)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000002").AnyTimes().Return(&domain.Sensor{
+			ID:       2,
+			Type:     domain.SensorTypeContactClosure,
+			IsActive: true,
+		}, nil)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000009").Return(nil, ErrSensorNotFound)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).AnyTimes().Return(nil)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).AnyTimes().Return(nil)
+
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().SaveSensorKey(ctx, gomock.Any()).Times(1).Return(nil)
+
+		er := NewMockEventRepository(ctrl)
+		gomock.InOrder(
+			er.EXPECT().SaveEvents(ctx, gomock.Any()).Do(func(_ context.Context, events []domain.Event) {
+				if assert.Len(t, events, 1) {
+					assert.Equal(t, now.Add(-time.Hour), events[0].Timestamp, "Исходное время события не сохранено")
+				}
+			}).Return([]error{nil}, nil),
+			er.EXPECT().SaveEvents(ctx, gomock.Len(1)).Return([]error{ErrEventDuplicate}, nil),
+		)
+
+		str := newUnboundedSensorTypeRepository(ctrl)
+		tr := newTransactor(ctrl)
+		i := NewImport(NewSensor(sr, nil, skr, nil, str, tr), NewEvent(er, sr, nil, nil, str, tr), tr)
+		i.batchSize = 2
+
+		report, err := i.Import(ctx, &sliceImportReader[domain.Sensor]{records: []importRecord[domain.Sensor]{
+			{record: domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeContactClosure, IsActive: true}},
+			{record: domain.Sensor{SerialNumber: "0000000002", Type: domain.SensorTypeContactClosure, IsActive: true}},
+			{err: ErrInvalidImportRecord},
+		}}, &sliceImportReader[domain.Event]{records: []importRecord[domain.Event]{
+			{record: domain.Event{Timestamp: now.Add(-time.Hour), SensorSerialNumber: "0000000002", Payload: 1}},
+			{err: ErrInvalidImportRecord},
+			{record: domain.Event{Timestamp: now, SensorSerialNumber: "0000000002", EventID: "a"}},
+			{record: domain.Event{Timestamp: now, SensorSerialNumber: "0000000009"}},
+		}}, false)
+		assert.NoError(t, err)
+		if assert.Len(t, report.SensorKeys, 1) {
+			assert.Equal(t, "0000000001", report.SensorKeys[0].SerialNumber)
+			assert.NotEmpty(t, report.SensorKeys[0].APIKey, "Ключ нового датчика не попал в отчёт")
+		}
+		report.SensorKeys = nil
+		assert.Equal(t, &domain.ImportReport{
+			SensorsCreated:  1,
+			SensorsExisting: 1,
+			SensorsRejected: 1,
+			EventsImported:  1,
+			EventsDuplicate: 1,
+			EventsRejected:  2,
+			Errors: []domain.ImportError{
+				{Source: domain.ImportSourceSensors, Line: 3, Err: ErrInvalidImportRecord},
+				{Source: domain.ImportSourceEvents, Line: 2, Err: ErrInvalidImportRecord},
+				{Source: domain.ImportSourceEvents, Line: 4, Err: ErrSensorNotFound},
+			},
+		}, report)
+	})
+
+	t.Run("ok, dry run rolled back", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000001").Return(nil, ErrSensorNotFound)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Return(nil)
+
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().SaveSensorKey(ctx, gomock.Any()).Return(nil)
+
+		var committed []error
+		tr := NewMockTransactor(ctrl)
+		tr.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+			func(ctx context.Context, fn func(ctx context.Context) error) error {
+				err := fn(ctx)
+				committed = append(committed, err)
+				return err
+			})
+
+		i := NewImport(NewSensor(sr, nil, skr, nil, newUnboundedSensorTypeRepository(ctrl), tr), nil, tr)
+
+		report, err := i.Import(ctx, &sliceImportReader[domain.Sensor]{records: []importRecord[domain.Sensor]{
+			{record: domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeContactClosure}},
+		}}, nil, true)
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.ImportReport{DryRun: true, SensorsCreated: 1}, report)
+		if assert.NotEmpty(t, committed) {
+			assert.Error(t, committed[len(committed)-1], "Транзакция проверочного импорта не откачена")
+		}
+	})
+
+	t.Run("ok, dry run event batches rolled back at once", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000002").AnyTimes().Return(&domain.Sensor{
+			ID:       2,
+			Type:     domain.SensorTypeContactClosure,
+			IsActive: true,
+		}, nil)
+		sr.EXPECT().SaveSensorState(ctx, gomock.Any()).AnyTimes().Return(nil)
+
+		er := NewMockEventRepository(ctrl)
+		er.EXPECT().SaveEvents(ctx, gomock.Len(1)).Times(1).Return([]error{nil}, nil)
+
+		var batches []error
+		tr := NewMockTransactor(ctrl)
+		tr.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+			func(ctx context.Context, fn func(ctx context.Context) error) error {
+				err := fn(ctx)
+				batches = append(batches, err)
+				return err
+			})
+
+		i := NewImport(nil, NewEvent(er, sr, nil, nil, newUnboundedSensorTypeRepository(ctrl), tr), tr)
+		i.batchSize = 1
+
+		report, err := i.Import(ctx, nil, &sliceImportReader[domain.Event]{records: []importRecord[domain.Event]{
+			{record: domain.Event{Timestamp: now, SensorSerialNumber: "0000000002", EventID: "a"}},
+			{record: domain.Event{Timestamp: now, SensorSerialNumber: "0000000002", EventID: "a"}},
+		}}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.ImportReport{DryRun: true, EventsImported: 1, EventsDuplicate: 1}, report,
+			"Повтор события из откаченного пакета не найден")
+		// транзакция ReceiveEvents вложена в транзакцию пакета, а та - в транзакцию всего импорта
+		if assert.Len(t, batches, 3) {
+			assert.ErrorIs(t, batches[1], errImportDryRun, "Пакет проверочного импорта не откачен сразу")
+		}
+	})
+
+	t.Run("err, storage error stops import", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		storageErr := errors.New("storage error")
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "0000000001").Return(nil, storageErr)
+
+		tr := newTransactor(ctrl)
+		i := NewImport(NewSensor(sr, nil, nil, nil, newUnboundedSensorTypeRepository(ctrl), tr), nil, tr)
+
+		_, err := i.Import(ctx, &sliceImportReader[domain.Sensor]{records: []importRecord[domain.Sensor]{
+			{record: domain.Sensor{SerialNumber: "0000000001", Type: domain.SensorTypeContactClosure}},
+			{record: domain.Sensor{SerialNumber: "0000000002", Type: domain.SensorTypeContactClosure}},
+		}}, nil, false)
+		assert.ErrorIs(t, err, storageErr)
+	})
+
+	t.Run("ok, report errors limited", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		records := make([]importRecord[domain.Event], MaxImportErrors+50)
+		for j := range records {
+			records[j].err = ErrInvalidImportRecord
+		}
+		i := NewImport(nil, nil, newTransactor(ctrl))
+
+		report, err := i.Import(ctx, nil, &sliceImportReader[domain.Event]{records: records}, false)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(records)), report.EventsRejected)
+		assert.Len(t, report.Errors, MaxImportErrors)
+	})
+}
@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"homework/internal/domain"
+	"slices"
+	"time"
+)
+
+// DefaultRetentionBatchSize - сколько событий удаляется за одну транзакцию очистки
+const DefaultRetentionBatchSize = 1000
+
+type Retention struct {
+	rr               RetentionRepository
+	er               EventRepository
+	sr               SensorRepository
+	str              SensorTypeRepository
+	tr               Transactor
+	archive          EventArchive
+	now              func() time.Time
+	defaultRetention time.Duration
+	keepRollups      bool
+	batchSize        int
+}
+
+func NewRetention(rr RetentionRepository, er EventRepository, sr SensorRepository, str SensorTypeRepository, tr Transactor, options ...func(*Retention)) *Retention {
+	r := &Retention{
+		rr:          rr,
+		er:          er,
+		sr:          sr,
+		str:         str,
+		tr:          tr,
+		now:         time.Now,
+		keepRollups: true,
+		batchSize:   DefaultRetentionBatchSize,
+	}
+	for _, o := range options {
+		o(r)
+	}
+	return r
+}
+
+// WithDefaultRetention - задаёт общий срок хранения событий, 0 - события хранятся бессрочно
+func WithDefaultRetention(retention time.Duration) func(*Retention) {
+	return func(r *Retention) {
+		r.defaultRetention = retention
+	}
+}
+
+// WithEventArchive - перед удалением события сохраняются в архив
+func WithEventArchive(archive EventArchive) func(*Retention) {
+	return func(r *Retention) {
+		r.archive = archive
+	}
+}
+
+// WithRollupsPruning - вместе с событиями удаляются накопленные агрегаты интервалов, целиком вышедших за срок хранения.
+// По умолчанию накопленные агрегаты хранятся бессрочно
+func WithRollupsPruning() func(*Retention) {
+	return func(r *Retention) {
+		r.keepRollups = false
+	}
+}
+
+// WithRetentionBatchSize - задаёт, сколько событий удаляется за одну транзакцию очистки
+func WithRetentionBatchSize(size int) func(*Retention) {
+	return func(r *Retention) {
+		r.batchSize = size
+	}
+}
+
+// GetRetentionPolicies - возвращает общую политику хранения и политики типов датчиков и датчиков,
+// доступно только администратору
+func (r *Retention) GetRetentionPolicies(ctx context.Context) ([]domain.RetentionPolicy, error) {
+	if _, ok := restrictedCaller(ctx); ok {
+		return nil, ErrAdminRequired
+	}
+	policies, err := r.rr.GetRetentionPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append([]domain.RetentionPolicy{{Retention: r.defaultRetention}}, policies...), nil
+}
+
+// SetRetentionPolicy - задаёт срок хранения событий типа датчика или датчика, доступно только администратору.
+// Политика датчика важнее политики его типа, а политика типа - общей
+func (r *Retention) SetRetentionPolicy(ctx context.Context, policy domain.RetentionPolicy) error {
+	if _, ok := restrictedCaller(ctx); ok {
+		return ErrAdminRequired
+	}
+	if err := checkRetentionPolicyKey(policy); err != nil {
+		return err
+	}
+	if policy.Retention < 0 {
+		return ErrInvalidRetentionPolicy
+	}
+	if policy.SensorID != 0 {
+		if _, err := r.sr.GetSensorByID(ctx, policy.SensorID); err != nil {
+			return err
+		}
+	} else if _, err := r.str.GetSensorType(ctx, policy.SensorType); err != nil {
+		return err
+	}
+	return r.rr.SaveRetentionPolicy(ctx, policy)
+}
+
+// DeleteRetentionPolicy - удаляет политику типа датчика или датчика, после этого действует политика уровнем выше.
+// Доступно только администратору
+func (r *Retention) DeleteRetentionPolicy(ctx context.Context, policy domain.RetentionPolicy) error {
+	if _, ok := restrictedCaller(ctx); ok {
+		return ErrAdminRequired
+	}
+	if err := checkRetentionPolicyKey(policy); err != nil {
+		return err
+	}
+	return r.rr.DeleteRetentionPolicy(ctx, policy)
+}
+
+// PreviewRetention - возвращает датчики, события которых удалит очередная очистка, и число таких событий.
+// Доступно только администратору
+func (r *Retention) PreviewRetention(ctx context.Context) ([]domain.RetentionPreview, error) {
+	if _, ok := restrictedCaller(ctx); ok {
+		return nil, ErrAdminRequired
+	}
+	plan, err := r.retentionPlan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	preview := make([]domain.RetentionPreview, 0, len(plan))
+	for _, item := range plan {
+		item.Events, err = r.er.CountEventsBefore(ctx, item.SensorID, item.Before)
+		if err != nil {
+			return nil, err
+		}
+		if item.Events > 0 {
+			preview = append(preview, item)
+		}
+	}
+	return preview, nil
+}
+
+// PruneExpiredEvents - удаляет события с истёкшим сроком хранения и возвращает их число. События удаляются
+// порциями, каждая порция - в своей транзакции вместе с записью в архив, поэтому очистку можно прервать в любой момент
+func (r *Retention) PruneExpiredEvents(ctx context.Context) (int64, error) {
+	plan, err := r.retentionPlan(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var pruned int64
+	for _, item := range plan {
+		for {
+			if err := ctx.Err(); err != nil {
+				return pruned, err
+			}
+			var deleted int
+			err := r.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+				events, err := r.er.DeleteEventsBefore(ctx, item.SensorID, item.Before, r.batchSize)
+				if err != nil {
+					return err
+				}
+				deleted = len(events)
+				if r.archive == nil || len(events) == 0 {
+					return nil
+				}
+				return r.archive.ArchiveEvents(ctx, item.SensorID, events)
+			})
+			if err != nil {
+				return pruned, err
+			}
+			pruned += int64(deleted)
+			if deleted < r.batchSize {
+				break
+			}
+		}
+		if !r.keepRollups {
+			if err := r.er.DeleteRollupsBefore(ctx, item.SensorID, item.Before); err != nil {
+				return pruned, err
+			}
+		}
+	}
+	return pruned, nil
+}
+
+// retentionPlan - сроки хранения, действующие для всех датчиков, включая удалённые.
+// Датчики, события которых хранятся бессрочно, пропускаются
+func (r *Retention) retentionPlan(ctx context.Context) ([]domain.RetentionPreview, error) {
+	policies, err := r.rr.GetRetentionPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[domain.SensorType]time.Duration)
+	bySensor := make(map[int64]time.Duration)
+	for _, policy := range policies {
+		if policy.SensorID != 0 {
+			bySensor[policy.SensorID] = policy.Retention
+		} else {
+			byType[policy.SensorType] = policy.Retention
+		}
+	}
+	sensors, err := r.sr.GetAllSensors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := r.now()
+	var plan []domain.RetentionPreview
+	for _, sensor := range sensors {
+		retention, ok := bySensor[sensor.ID]
+		if !ok {
+			retention, ok = byType[sensor.Type]
+		}
+		if !ok {
+			retention = r.defaultRetention
+		}
+		if retention == 0 {
+			continue
+		}
+		plan = append(plan, domain.RetentionPreview{
+			SensorID:     sensor.ID,
+			SerialNumber: sensor.SerialNumber,
+			SensorType:   sensor.Type,
+			Retention:    retention,
+			Before:       now.Add(-retention),
+		})
+	}
+	slices.SortFunc(plan, func(a, b domain.RetentionPreview) int {
+		return cmp.Compare(a.SensorID, b.SensorID)
+	})
+	return plan, nil
+}
+
+// checkRetentionPolicyKey - политика задаётся либо для типа датчика, либо для датчика
+func checkRetentionPolicyKey(policy domain.RetentionPolicy) error {
+	if (policy.SensorID == 0) == (policy.SensorType == "") {
+		return ErrInvalidRetentionPolicy
+	}
+	return nil
+}
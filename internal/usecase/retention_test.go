@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_retention_SetRetentionPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, admin sets sensor and type policies", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithAdmin(context.Background(), 1))
+		defer cancel()
+
+		rr := NewMockRetentionRepository(ctrl)
+		sr := NewMockSensorRepository(ctrl)
+		str := NewMockSensorTypeRepository(ctrl)
+		sensorPolicy := domain.RetentionPolicy{SensorID: 5, Retention: 30 * 24 * time.Hour}
+		typePolicy := domain.RetentionPolicy{SensorType: domain.SensorTypeADC, Retention: 0}
+		sr.EXPECT().GetSensorByID(ctx, int64(5)).Return(&domain.Sensor{ID: 5}, nil)
+		str.EXPECT().GetSensorType(ctx, domain.SensorTypeADC).Return(&domain.SensorTypeDefinition{Type: domain.SensorTypeADC}, nil)
+		rr.EXPECT().SaveRetentionPolicy(ctx, sensorPolicy).Return(nil)
+		rr.EXPECT().SaveRetentionPolicy(ctx, typePolicy).Return(nil)
+
+		r := NewRetention(rr, nil, sr, str, nil)
+
+		assert.NoError(t, r.SetRetentionPolicy(ctx, sensorPolicy))
+		assert.NoError(t, r.SetRetentionPolicy(ctx, typePolicy))
+	})
+
+	t.Run("fail, user is not admin", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 1))
+		defer cancel()
+
+		rr := NewMockRetentionRepository(ctrl)
+		rr.EXPECT().SaveRetentionPolicy(gomock.Any(), gomock.Any()).Times(0)
+
+		r := NewRetention(rr, nil, nil, nil, nil)
+
+		err := r.SetRetentionPolicy(ctx, domain.RetentionPolicy{SensorID: 5, Retention: time.Hour})
+		assert.ErrorIs(t, err, ErrAdminRequired)
+	})
+
+	t.Run("fail, invalid policy", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rr := NewMockRetentionRepository(ctrl)
+		rr.EXPECT().SaveRetentionPolicy(gomock.Any(), gomock.Any()).Times(0)
+
+		r := NewRetention(rr, nil, nil, nil, nil)
+
+		for _, policy := range []domain.RetentionPolicy{
+			{Retention: time.Hour},
+			{SensorID: 5, SensorType: domain.SensorTypeADC, Retention: time.Hour},
+			{SensorID: 5, Retention: -time.Hour},
+		} {
+			assert.ErrorIs(t, r.SetRetentionPolicy(ctx, policy), ErrInvalidRetentionPolicy)
+		}
+	})
+
+	t.Run("fail, sensor not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rr := NewMockRetentionRepository(ctrl)
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(5)).Return(nil, ErrSensorNotFound)
+		rr.EXPECT().SaveRetentionPolicy(gomock.Any(), gomock.Any()).Times(0)
+
+		r := NewRetention(rr, nil, sr, nil, nil)
+
+		err := r.SetRetentionPolicy(ctx, domain.RetentionPolicy{SensorID: 5, Retention: time.Hour})
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+}
+
+func Test_retention_PreviewRetention(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	sensors := []domain.Sensor{
+		{ID: 3, SerialNumber: "3", Type: domain.SensorTypeADC},
+		{ID: 1, SerialNumber: "1", Type: domain.SensorTypeTemperature},
+		{ID: 2, SerialNumber: "2", Type: domain.SensorTypeTemperature},
+		{ID: 4, SerialNumber: "4", Type: domain.SensorTypeHumidity},
+	}
+	policies := []domain.RetentionPolicy{
+		{SensorType: domain.SensorTypeTemperature, Retention: 10 * 24 * time.Hour},
+		{SensorID: 2, Retention: 2 * 24 * time.Hour},
+		{SensorType: domain.SensorTypeHumidity, Retention: 0},
+	}
+
+	t.Run("ok, sensor policy overrides type policy, type policy overrides default", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rr := NewMockRetentionRepository(ctrl)
+		er := NewMockEventRepository(ctrl)
+		sr := NewMockSensorRepository(ctrl)
+		rr.EXPECT().GetRetentionPolicies(ctx).Return(policies, nil)
+		sr.EXPECT().GetAllSensors(ctx).Return(sensors, nil)
+		er.EXPECT().CountEventsBefore(ctx, int64(1), now.Add(-10*24*time.Hour)).Return(int64(7), nil)
+		er.EXPECT().CountEventsBefore(ctx, int64(2), now.Add(-2*24*time.Hour)).Return(int64(0), nil)
+		er.EXPECT().CountEventsBefore(ctx, int64(3), now.Add(-30*24*time.Hour)).Return(int64(4), nil)
+
+		r := NewRetention(rr, er, sr, nil, nil, WithDefaultRetention(30*24*time.Hour))
+		r.now = func() time.Time { return now }
+
+		preview, err := r.PreviewRetention(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.RetentionPreview{
+			{SensorID: 1, SerialNumber: "1", SensorType: domain.SensorTypeTemperature, Retention: 10 * 24 * time.Hour,
+				Before: now.Add(-10 * 24 * time.Hour), Events: 7},
+			{SensorID: 3, SerialNumber: "3", SensorType: domain.SensorTypeADC, Retention: 30 * 24 * time.Hour,
+				Before: now.Add(-30 * 24 * time.Hour), Events: 4},
+		}, preview)
+	})
+
+	t.Run("fail, user is not admin", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 1))
+		defer cancel()
+
+		r := NewRetention(nil, nil, nil, nil, nil)
+
+		_, err := r.PreviewRetention(ctx)
+		assert.ErrorIs(t, err, ErrAdminRequired)
+	})
+}
+
+func Test_retention_PruneExpiredEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := now.Add(-24 * time.Hour)
+	batch := func(ids ...int64) []domain.Event {
+		events := make([]domain.Event, len(ids))
+		for i, id := range ids {
+			events[i] = domain.Event{ID: id, SensorID: 1}
+		}
+		return events
+	}
+
+	t.Run("ok, events are deleted in batches and archived", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rr := NewMockRetentionRepository(ctrl)
+		er := NewMockEventRepository(ctrl)
+		sr := NewMockSensorRepository(ctrl)
+		rr.EXPECT().GetRetentionPolicies(ctx).Return(nil, nil)
+		sr.EXPECT().GetAllSensors(ctx).Return([]domain.Sensor{{ID: 1}}, nil)
+		gomock.InOrder(
+			er.EXPECT().DeleteEventsBefore(ctx, int64(1), before, 2).Return(batch(1, 2), nil),
+			er.EXPECT().DeleteEventsBefore(ctx, int64(1), before, 2).Return(batch(3), nil),
+		)
+		er.EXPECT().DeleteRollupsBefore(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		archive := NewMockEventArchive(ctrl)
+		gomock.InOrder(
+			archive.EXPECT().ArchiveEvents(ctx, int64(1), batch(1, 2)).Return(nil),
+			archive.EXPECT().ArchiveEvents(ctx, int64(1), batch(3)).Return(nil),
+		)
+
+		r := NewRetention(rr, er, sr, nil, newTransactor(ctrl),
+			WithDefaultRetention(24*time.Hour), WithRetentionBatchSize(2), WithEventArchive(archive))
+		r.now = func() time.Time { return now }
+
+		pruned, err := r.PruneExpiredEvents(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), pruned)
+	})
+
+	t.Run("ok, rollups are pruned when not kept", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rr := NewMockRetentionRepository(ctrl)
+		er := NewMockEventRepository(ctrl)
+		sr := NewMockSensorRepository(ctrl)
+		rr.EXPECT().GetRetentionPolicies(ctx).Return(nil, nil)
+		sr.EXPECT().GetAllSensors(ctx).Return([]domain.Sensor{{ID: 1}}, nil)
+		er.EXPECT().DeleteEventsBefore(ctx, int64(1), before, DefaultRetentionBatchSize).Return(nil, nil)
+		er.EXPECT().DeleteRollupsBefore(ctx, int64(1), before).Return(nil)
+
+		r := NewRetention(rr, er, sr, nil, newTransactor(ctrl), WithDefaultRetention(24*time.Hour), WithRollupsPruning())
+		r.now = func() time.Time { return now }
+
+		pruned, err := r.PruneExpiredEvents(ctx)
+		assert.NoError(t, err)
+		assert.Zero(t, pruned)
+	})
+
+	t.Run("fail, archive error stops pruning", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rr := NewMockRetentionRepository(ctrl)
+		er := NewMockEventRepository(ctrl)
+		sr := NewMockSensorRepository(ctrl)
+		rr.EXPECT().GetRetentionPolicies(ctx).Return(nil, nil)
+		sr.EXPECT().GetAllSensors(ctx).Return([]domain.Sensor{{ID: 1}}, nil)
+		er.EXPECT().DeleteEventsBefore(ctx, int64(1), before, DefaultRetentionBatchSize).Return(batch(1), nil)
+
+		archiveErr := errors.New("disk is full")
+		archive := NewMockEventArchive(ctrl)
+		archive.EXPECT().ArchiveEvents(ctx, int64(1), batch(1)).Return(archiveErr)
+
+		r := NewRetention(rr, er, sr, nil, newTransactor(ctrl), WithDefaultRetention(24*time.Hour), WithEventArchive(archive))
+		r.now = func() time.Time { return now }
+
+		pruned, err := r.PruneExpiredEvents(ctx)
+		assert.ErrorIs(t, err, archiveErr)
+		assert.Zero(t, pruned)
+	})
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"time"
+)
+
+type Room struct {
+	rr  RoomRepository
+	hr  HomeRepository
+	sr  SensorRepository
+	sor SensorOwnerRepository
+	tr  Transactor
+	now func() time.Time
+}
+
+func NewRoom(rr RoomRepository, hr HomeRepository, sr SensorRepository, sor SensorOwnerRepository, tr Transactor) *Room {
+	return &Room{
+		rr:  rr,
+		hr:  hr,
+		sr:  sr,
+		sor: sor,
+		tr:  tr,
+		now: time.Now,
+	}
+}
+
+// CreateRoom - создаёт комнату в доме, доступно участникам дома с ролью не ниже member
+func (r *Room) CreateRoom(ctx context.Context, room *domain.Room) (*domain.Room, error) {
+	if room == nil {
+		return nil, errors.New("nil room")
+	}
+	if room.Name == "" {
+		return nil, ErrInvalidRoomName
+	}
+	if _, err := r.hr.GetHomeByID(ctx, room.HomeID); err != nil {
+		return nil, err
+	}
+	if err := checkHomeAccess(ctx, r.hr, room.HomeID, domain.SensorRoleMember); err != nil {
+		return nil, err
+	}
+	room.CreatedAt = r.now()
+	if err := r.rr.SaveRoom(ctx, room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+func (r *Room) GetRooms(ctx context.Context, homeID int64) ([]domain.Room, error) {
+	if _, err := r.hr.GetHomeByID(ctx, homeID); err != nil {
+		return nil, err
+	}
+	if err := checkHomeAccess(ctx, r.hr, homeID, domain.SensorRoleViewer); err != nil {
+		return nil, err
+	}
+	return r.rr.GetRoomsByHomeID(ctx, homeID)
+}
+
+func (r *Room) GetRoomByID(ctx context.Context, id int64) (*domain.Room, error) {
+	return r.getRoom(ctx, id, domain.SensorRoleViewer)
+}
+
+// RenameRoom - меняет название комнаты
+func (r *Room) RenameRoom(ctx context.Context, id int64, name string) (*domain.Room, error) {
+	if name == "" {
+		return nil, ErrInvalidRoomName
+	}
+	room, err := r.getRoom(ctx, id, domain.SensorRoleMember)
+	if err != nil {
+		return nil, err
+	}
+	room.Name = name
+	if err := r.rr.SaveRoom(ctx, room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// DeleteRoom - удаляет комнату, датчики комнаты остаются в доме без комнаты.
+// Датчики убираются из комнаты и комната удаляется в одной транзакции
+func (r *Room) DeleteRoom(ctx context.Context, id int64) error {
+	if _, err := r.getRoom(ctx, id, domain.SensorRoleMember); err != nil {
+		return err
+	}
+	return r.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.sr.ClearRoomSensors(ctx, id); err != nil {
+			return err
+		}
+		return r.rr.DeleteRoom(ctx, id)
+	})
+}
+
+func (r *Room) GetRoomSensors(ctx context.Context, id int64) ([]domain.Sensor, error) {
+	if _, err := r.getRoom(ctx, id, domain.SensorRoleViewer); err != nil {
+		return nil, err
+	}
+	return r.sr.GetSensorsByRoomID(ctx, id)
+}
+
+// GetRoomSummary - возвращает текущее состояние всех датчиков комнаты
+func (r *Room) GetRoomSummary(ctx context.Context, id int64) (*domain.RoomSummary, error) {
+	room, err := r.getRoom(ctx, id, domain.SensorRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	sensors, err := r.sr.GetSensorsByRoomID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	summary := &domain.RoomSummary{
+		Room:    *room,
+		Sensors: sensors,
+	}
+	for _, sensor := range sensors {
+		if sensor.LastActivity.After(summary.LastActivity) {
+			summary.LastActivity = sensor.LastActivity
+		}
+	}
+	return summary, nil
+}
+
+// AssignSensorToRoom - переносит датчик в комнату. Комната должна быть в том же доме, что и датчик.
+// Сохраняется только комната датчика, чтобы не затереть состояние, записанное событиями.
+func (r *Room) AssignSensorToRoom(ctx context.Context, sensorID, roomID int64) (*domain.Sensor, error) {
+	sensor, err := r.sr.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSensorAccess(ctx, r.sor, r.hr, sensor, domain.SensorRoleMember); err != nil {
+		return nil, err
+	}
+	room, err := r.getRoom(ctx, roomID, domain.SensorRoleMember)
+	if err != nil {
+		return nil, err
+	}
+	if sensor.HomeID != room.HomeID {
+		return nil, ErrSensorNotInRoomHome
+	}
+	if err := r.sr.SaveSensorRoom(ctx, sensor.ID, room.ID); err != nil {
+		return nil, err
+	}
+	sensor.RoomID = room.ID
+	return sensor, nil
+}
+
+// UnassignSensorFromRoom - убирает датчик из комнаты, датчик остаётся в доме
+func (r *Room) UnassignSensorFromRoom(ctx context.Context, sensorID int64) error {
+	sensor, err := r.sr.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+	if err := checkSensorAccess(ctx, r.sor, r.hr, sensor, domain.SensorRoleMember); err != nil {
+		return err
+	}
+	if sensor.RoomID == 0 {
+		return nil
+	}
+	return r.sr.SaveSensorRoom(ctx, sensor.ID, 0)
+}
+
+// getRoom - возвращает комнату, если вызывающий состоит в её доме с ролью не ниже required.
+// Комнаты чужих домов неотличимы от несуществующих.
+func (r *Room) getRoom(ctx context.Context, id int64, required domain.SensorRole) (*domain.Room, error) {
+	room, err := r.rr.GetRoomByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = checkHomeAccess(ctx, r.hr, room.HomeID, required)
+	if errors.Is(err, ErrHomeNotFound) {
+		return nil, ErrRoomNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return room, nil
+}
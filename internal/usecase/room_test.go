@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"homework/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_room_CreateRoom(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, empty name", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		r := NewRoom(nil, nil, nil, nil, newTransactor(ctrl))
+
+		_, err := r.CreateRoom(ctx, &domain.Room{HomeID: 1})
+		assert.ErrorIs(t, err, ErrInvalidRoomName)
+	})
+
+	t.Run("fail, viewer cannot create", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).Times(1).Return(&domain.Home{ID: 1}, nil)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 1, UserID: 7, Role: domain.SensorRoleViewer},
+		}, nil)
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().SaveRoom(ctx, gomock.Any()).Times(0)
+
+		r := NewRoom(rr, hr, nil, nil, newTransactor(ctrl))
+
+		_, err := r.CreateRoom(ctx, &domain.Room{HomeID: 1, Name: "Кухня"})
+		assert.ErrorIs(t, err, ErrSensorAccessDenied)
+	})
+
+	t.Run("ok, member creates room", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(1)).Times(1).Return(&domain.Home{ID: 1}, nil)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 1, UserID: 7, Role: domain.SensorRoleMember},
+		}, nil)
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().SaveRoom(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, room *domain.Room) error {
+			room.ID = 3
+			return nil
+		})
+
+		r := NewRoom(rr, hr, nil, nil, newTransactor(ctrl))
+
+		room, err := r.CreateRoom(ctx, &domain.Room{HomeID: 1, Name: "Кухня"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), room.ID)
+		assert.False(t, room.CreatedAt.IsZero())
+	})
+}
+
+func Test_room_GetRoomByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, room of another home", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().GetRoomByID(ctx, int64(3)).Times(1).Return(&domain.Room{ID: 3, HomeID: 1}, nil)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return(nil, nil)
+
+		r := NewRoom(rr, hr, nil, nil, newTransactor(ctrl))
+
+		_, err := r.GetRoomByID(ctx, 3)
+		assert.ErrorIs(t, err, ErrRoomNotFound)
+	})
+}
+
+func Test_room_DeleteRoom(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, sensors left without room", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().GetRoomByID(ctx, int64(3)).Times(1).Return(&domain.Room{ID: 3, HomeID: 1}, nil)
+		rr.EXPECT().DeleteRoom(ctx, int64(3)).Times(1).Return(nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().ClearRoomSensors(ctx, int64(3)).Times(1).Return(nil)
+
+		r := NewRoom(rr, nil, sr, nil, newTransactor(ctrl))
+
+		assert.NoError(t, r.DeleteRoom(ctx, 3))
+	})
+
+	t.Run("fail, room kept when sensors not cleared", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		txCtx := context.WithValue(ctx, struct{}{}, "tx")
+		someErr := errors.New("some error")
+
+		tr := NewMockTransactor(ctrl)
+		tr.EXPECT().WithinTransaction(ctx, gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, fn func(ctx context.Context) error) error {
+				return fn(txCtx)
+			})
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().GetRoomByID(ctx, int64(3)).Times(1).Return(&domain.Room{ID: 3, HomeID: 1}, nil)
+		rr.EXPECT().DeleteRoom(gomock.Any(), gomock.Any()).Times(0)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().ClearRoomSensors(txCtx, int64(3)).Times(1).Return(someErr)
+
+		r := NewRoom(rr, nil, sr, nil, tr)
+
+		assert.ErrorIs(t, r.DeleteRoom(ctx, 3), someErr)
+	})
+}
+
+func Test_room_GetRoomSummary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, last activity of room", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		now := time.Now()
+		sensors := []domain.Sensor{
+			{ID: 5, RoomID: 3, CurrentState: 1, LastActivity: now.Add(-time.Hour)},
+			{ID: 6, RoomID: 3, CurrentState: 0, LastActivity: now},
+		}
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().GetRoomByID(ctx, int64(3)).Times(1).Return(&domain.Room{ID: 3, HomeID: 1, Name: "Кухня"}, nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorsByRoomID(ctx, int64(3)).Times(1).Return(sensors, nil)
+
+		r := NewRoom(rr, nil, sr, nil, newTransactor(ctrl))
+
+		summary, err := r.GetRoomSummary(ctx, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, "Кухня", summary.Room.Name)
+		assert.Equal(t, sensors, summary.Sensors)
+		assert.Equal(t, now, summary.LastActivity)
+	})
+}
+
+func Test_room_AssignSensorToRoom(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, room in another home", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(5)).Times(1).Return(&domain.Sensor{ID: 5, HomeID: 2}, nil)
+		sr.EXPECT().SaveSensorRoom(ctx, gomock.Any(), gomock.Any()).Times(0)
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().GetRoomByID(ctx, int64(3)).Times(1).Return(&domain.Room{ID: 3, HomeID: 1}, nil)
+
+		r := NewRoom(rr, nil, sr, nil, newTransactor(ctrl))
+
+		_, err := r.AssignSensorToRoom(ctx, 5, 3)
+		assert.ErrorIs(t, err, ErrSensorNotInRoomHome)
+	})
+
+	t.Run("ok, sensor moved", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(5)).Times(1).Return(&domain.Sensor{ID: 5, HomeID: 1}, nil)
+		sr.EXPECT().SaveSensorRoom(ctx, int64(5), int64(3)).Times(1).Return(nil)
+
+		rr := NewMockRoomRepository(ctrl)
+		rr.EXPECT().GetRoomByID(ctx, int64(3)).Times(1).Return(&domain.Room{ID: 3, HomeID: 1}, nil)
+
+		r := NewRoom(rr, nil, sr, nil, newTransactor(ctrl))
+
+		sensor, err := r.AssignSensorToRoom(ctx, 5, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), sensor.RoomID)
+	})
+}
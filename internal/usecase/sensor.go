@@ -4,47 +4,156 @@ import (
 	"context"
 	"errors"
 	"homework/internal/domain"
+	"time"
 )
 
 type Sensor struct {
-	sr SensorRepository
+	sr  SensorRepository
+	sor SensorOwnerRepository
+	skr SensorKeyRepository
+	hr  HomeRepository
+	str SensorTypeRepository
+	tr  Transactor
+	now func() time.Time
+	// keySecret - секрет, из которого выводятся ключи датчиков; без него подписи не принимаются
+	keySecret []byte
 }
 
-func NewSensor(sr SensorRepository) *Sensor {
-	return &Sensor{
-		sr: sr,
+func NewSensor(sr SensorRepository, sor SensorOwnerRepository, skr SensorKeyRepository, hr HomeRepository, str SensorTypeRepository, tr Transactor, opts ...func(*Sensor)) *Sensor {
+	s := &Sensor{
+		sr:  sr,
+		sor: sor,
+		skr: skr,
+		hr:  hr,
+		str: str,
+		tr:  tr,
+		now: time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithSensorKeySecret - задаёт секрет сервера, из которого выводятся ключи датчиков.
+// Без секрета ключи случайны, а запросы с подписью отклоняются
+func WithSensorKeySecret(secret []byte) func(*Sensor) {
+	return func(s *Sensor) {
+		s.keySecret = secret
 	}
 }
 
-func (s *Sensor) RegisterSensor(ctx context.Context, sensor *domain.Sensor) (*domain.Sensor, error) {
+// RegisterSensor - регистрирует датчик и выдаёт ему ключ. Тип датчика должен быть в реестре типов.
+// Датчик с HomeID регистрируется в доме, и доступ к нему получают участники дома,
+// иначе вызывающий пользователь становится владельцем датчика.
+// Для уже зарегистрированного датчика ключ повторно не выдаётся.
+// Датчик, привязка к владельцу и ключ сохраняются в одной транзакции.
+func (s *Sensor) RegisterSensor(ctx context.Context, sensor *domain.Sensor) (*domain.RegisteredSensor, error) {
 	if sensor == nil {
 		return nil, errors.New("nil sensor")
 	}
-	if sensor.Type != domain.SensorTypeContactClosure && sensor.Type != domain.SensorTypeADC {
-		return nil, ErrWrongSensorType
-	}
 	if len(sensor.SerialNumber) != 10 {
 		return nil, ErrWrongSensorSerialNumber
 	}
-	existingSensor, err := s.sr.GetSensorBySerialNumber(ctx, sensor.SerialNumber)
-	if err != nil && !errors.Is(err, ErrSensorNotFound) {
+	if _, err := s.str.GetSensorType(ctx, sensor.Type); errors.Is(err, ErrSensorTypeNotFound) {
+		return nil, ErrWrongSensorType
+	} else if err != nil {
 		return nil, err
 	}
-	if existingSensor != nil {
-		return existingSensor, nil
+	if sensor.HomeID != 0 {
+		if _, err := s.hr.GetHomeByID(ctx, sensor.HomeID); err != nil {
+			return nil, err
+		}
+		if err := checkHomeAccess(ctx, s.hr, sensor.HomeID, domain.SensorRoleMember); err != nil {
+			return nil, err
+		}
 	}
+	var registered *domain.RegisteredSensor
+	err := s.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		existingSensor, err := s.sr.GetSensorBySerialNumber(ctx, sensor.SerialNumber)
+		if err != nil && !errors.Is(err, ErrSensorNotFound) {
+			return err
+		}
+		if existingSensor != nil {
+			if err := checkSensorAccess(ctx, s.sor, s.hr, existingSensor, domain.SensorRoleViewer); errors.Is(err, ErrSensorNotFound) {
+				return ErrSensorAlreadyExists
+			} else if err != nil {
+				return err
+			}
+			registered = &domain.RegisteredSensor{Sensor: *existingSensor}
+			return nil
+		}
 
-	if err := s.sr.SaveSensor(ctx, sensor); err != nil {
+		if err := s.sr.SaveSensor(ctx, sensor); err != nil {
+			return err
+		}
+		if userID, ok := CallerFromContext(ctx); ok && sensor.HomeID == 0 {
+			if err := s.sor.SaveSensorOwner(ctx, domain.SensorOwner{
+				UserID:   userID,
+				SensorID: sensor.ID,
+				Role:     domain.SensorRoleOwner,
+			}); err != nil {
+				return err
+			}
+		}
+		apiKey, err := s.issueSensorKey(ctx, sensor.ID)
+		if err != nil {
+			return err
+		}
+		registered = &domain.RegisteredSensor{Sensor: *sensor, APIKey: apiKey}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	return sensor, nil
+	return registered, nil
 }
 
+// GetSensors - возвращает датчики, доступные вызывающему: привязанные к нему и датчики его домов.
+// Администратор и внутренние вызовы получают все датчики.
 func (s *Sensor) GetSensors(ctx context.Context) ([]domain.Sensor, error) {
-	sensors, err := s.sr.GetSensors(ctx)
+	userID, ok := restrictedCaller(ctx)
+	if !ok {
+		sensors, err := s.sr.GetSensors(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return sensors, nil
+	}
+	sensorOwners, err := s.sor.GetSensorsByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	seen := make(map[int64]struct{}, len(sensorOwners))
+	sensors := make([]domain.Sensor, 0, len(sensorOwners))
+	for _, sensorOwner := range sensorOwners {
+		sensor, err := s.sr.GetSensorByID(ctx, sensorOwner.SensorID)
+		if err != nil {
+			return nil, err
+		}
+		if !sensor.DeletedAt.IsZero() {
+			continue
+		}
+		seen[sensor.ID] = struct{}{}
+		sensors = append(sensors, *sensor)
+	}
+	homeMembers, err := s.hr.GetHomesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, homeMember := range homeMembers {
+		homeSensors, err := s.sr.GetSensorsByHomeID(ctx, homeMember.HomeID)
+		if err != nil {
+			return nil, err
+		}
+		for _, sensor := range homeSensors {
+			if _, ok := seen[sensor.ID]; ok {
+				continue
+			}
+			seen[sensor.ID] = struct{}{}
+			sensors = append(sensors, sensor)
+		}
+	}
 	return sensors, nil
 }
 
@@ -53,6 +162,9 @@ func (s *Sensor) GetSensorByID(ctx context.Context, id int64) (*domain.Sensor, e
 	if err != nil {
 		return nil, err
 	}
+	if err := checkSensorAccess(ctx, s.sor, s.hr, sensor, domain.SensorRoleViewer); err != nil {
+		return nil, err
+	}
 	return sensor, nil
 }
 
@@ -61,5 +173,91 @@ func (s *Sensor) GetSensorBySerialNumber(ctx context.Context, serialNumber strin
 	if err != nil {
 		return nil, err
 	}
+	if err := checkSensorAccess(ctx, s.sor, s.hr, sensor, domain.SensorRoleViewer); err != nil {
+		return nil, err
+	}
+	return sensor, nil
+}
+
+// UpdateSensor - меняет описание, флаг активности, единицу измерения и калибровку датчика.
+// При смене калибровки текущее состояние пересчитывается из сырого значения,
+// уже сохранённые события не меняются. Датчик читается с блокировкой в транзакции,
+// чтобы не вернуть назад состояние, сохранённое параллельно полученным событием.
+func (s *Sensor) UpdateSensor(ctx context.Context, id int64, update domain.SensorUpdate) (*domain.Sensor, error) {
+	var sensor *domain.Sensor
+	err := s.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		sensor, err = s.updateSensor(ctx, id, update)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sensor, nil
+}
+
+// updateSensor - применяет изменения к датчику, прочитанному с блокировкой
+func (s *Sensor) updateSensor(ctx context.Context, id int64, update domain.SensorUpdate) (*domain.Sensor, error) {
+	sensor, err := s.sr.GetSensorByIDForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSensorAccess(ctx, s.sor, s.hr, sensor, domain.SensorRoleMember); err != nil {
+		return nil, err
+	}
+	if update.Calibration != nil && update.Calibration.Kind != domain.CalibrationNone {
+		definition, err := s.str.GetSensorType(ctx, sensor.Type)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkCalibration(definition.ValueKind, update.Calibration); err != nil {
+			return nil, err
+		}
+	}
+	if update.Description != nil {
+		sensor.Description = *update.Description
+	}
+	if update.IsActive != nil {
+		sensor.IsActive = *update.IsActive
+	}
+	if update.Unit != nil {
+		sensor.Unit = *update.Unit
+	}
+	if update.Calibration != nil {
+		sensor.Calibration = update.Calibration
+		if update.Calibration.Kind == domain.CalibrationNone {
+			sensor.Calibration = nil
+		}
+		sensor.CalibratedState = nil
+		if !sensor.LastActivity.IsZero() {
+			sensor.CalibratedState = calibrate(sensor.Calibration, sensor.CurrentState, sensor.CurrentValue)
+		}
+	}
+	if err := s.sr.SaveSensor(ctx, sensor); err != nil {
+		return nil, err
+	}
 	return sensor, nil
 }
+
+// DeleteSensor - помечает датчик удалённым и отзывает его ключ, доступно только владельцу.
+// События датчика сохраняются, а серийный номер остаётся занятым, чтобы история
+// удалённого датчика не смешалась с историей нового устройства.
+// Пометка и отзыв ключа выполняются в одной транзакции над заблокированным датчиком.
+func (s *Sensor) DeleteSensor(ctx context.Context, id int64) error {
+	return s.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		sensor, err := s.sr.GetSensorByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkSensorAccess(ctx, s.sor, s.hr, sensor, domain.SensorRoleOwner); err != nil {
+			return err
+		}
+		sensor.IsActive = false
+		sensor.RoomID = 0
+		sensor.DeletedAt = s.now()
+		if err := s.sr.SaveSensor(ctx, sensor); err != nil {
+			return err
+		}
+		return s.skr.DeleteSensorKey(ctx, id)
+	})
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+)
+
+// GetSensorAccess - возвращает список пользователей с доступом к датчику, доступен только владельцу
+func (u *User) GetSensorAccess(ctx context.Context, sensorID int64) ([]domain.SensorOwner, error) {
+	sensor, err := u.sr.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSensorAccess(ctx, u.sor, u.hr, sensor, domain.SensorRoleOwner); err != nil {
+		return nil, err
+	}
+	return u.sor.GetSensorOwners(ctx, sensorID)
+}
+
+// SetSensorAccess - выдаёт пользователю доступ к датчику или меняет его роль.
+// Последнего владельца понизить нельзя, иначе доступом к датчику станет некому управлять.
+func (u *User) SetSensorAccess(ctx context.Context, sensorID, userID int64, role domain.SensorRole) error {
+	if !isValidSensorRole(role) {
+		return ErrWrongSensorRole
+	}
+	sensor, err := u.sr.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+	if err := checkSensorAccess(ctx, u.sor, u.hr, sensor, domain.SensorRoleOwner); err != nil {
+		return err
+	}
+	if _, err := u.ur.GetUserByID(ctx, userID); err != nil {
+		return err
+	}
+	return u.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		if role != domain.SensorRoleOwner {
+			if err := u.checkOtherOwnerExists(ctx, sensorID, userID); err != nil {
+				return err
+			}
+		}
+		_, bound, err := getSensorRole(ctx, u.sor, userID, sensorID)
+		if err != nil {
+			return err
+		}
+		sensorOwner := domain.SensorOwner{
+			UserID:   userID,
+			SensorID: sensorID,
+			Role:     role,
+		}
+		if bound {
+			return u.sor.UpdateSensorOwner(ctx, sensorOwner)
+		}
+		return u.sor.SaveSensorOwner(ctx, sensorOwner)
+	})
+}
+
+// RevokeSensorAccess - отвязывает датчик от пользователя.
+// Отвязать других может только владелец, отвязаться самому может любой пользователь, кроме последнего владельца.
+func (u *User) RevokeSensorAccess(ctx context.Context, sensorID, userID int64) error {
+	sensor, err := u.sr.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+	if err := u.checkBindingAccess(ctx, userID, sensor); err != nil {
+		return err
+	}
+	return u.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, bound, err := getSensorRole(ctx, u.sor, userID, sensorID)
+		if err != nil {
+			return err
+		}
+		if !bound {
+			return ErrUserNotFound
+		}
+		if err := u.checkOtherOwnerExists(ctx, sensorID, userID); err != nil {
+			return err
+		}
+		return u.sor.DeleteSensorOwner(ctx, userID, sensorID)
+	})
+}
+
+// checkOtherOwnerExists - проверяет, что после лишения userID прав владельца у датчика останется владелец
+func (u *User) checkOtherOwnerExists(ctx context.Context, sensorID, userID int64) error {
+	sensorOwners, err := u.sor.GetSensorOwners(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+	isOwner := false
+	for _, sensorOwner := range sensorOwners {
+		if sensorOwner.Role != domain.SensorRoleOwner {
+			continue
+		}
+		if sensorOwner.UserID != userID {
+			return nil
+		}
+		isOwner = true
+	}
+	if isOwner {
+		return ErrLastSensorOwner
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_user_GetSensorAccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, owner gets list", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 1))
+		defer cancel()
+
+		sensorOwners := []domain.SensorOwner{
+			{UserID: 1, SensorID: 3, Role: domain.SensorRoleOwner},
+			{UserID: 2, SensorID: 3, Role: domain.SensorRoleViewer},
+		}
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Return(&domain.Sensor{ID: 3}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Return(sensorOwners[:1], nil)
+		sor.EXPECT().GetSensorOwners(ctx, int64(3)).Return(sensorOwners, nil)
+
+		u := NewUser(nil, sor, sr, nil, newTransactor(ctrl))
+
+		list, err := u.GetSensorAccess(ctx, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, sensorOwners, list)
+	})
+
+	t.Run("fail, viewer cannot list", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 2))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Return(&domain.Sensor{ID: 3}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Return([]domain.SensorOwner{
+			{UserID: 2, SensorID: 3, Role: domain.SensorRoleViewer},
+		}, nil)
+		sor.EXPECT().GetSensorOwners(ctx, gomock.Any()).Times(0)
+
+		u := NewUser(nil, sor, sr, nil, newTransactor(ctrl))
+
+		_, err := u.GetSensorAccess(ctx, 3)
+		assert.ErrorIs(t, err, ErrSensorAccessDenied)
+	})
+}
+
+func Test_user_SetSensorAccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, change role", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 1))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Return(&domain.Sensor{ID: 3}, nil)
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(2)).Return(&domain.User{ID: 2}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Return([]domain.SensorOwner{
+			{UserID: 1, SensorID: 3, Role: domain.SensorRoleOwner},
+		}, nil)
+		sor.EXPECT().GetSensorOwners(ctx, int64(3)).Return([]domain.SensorOwner{
+			{UserID: 1, SensorID: 3, Role: domain.SensorRoleOwner},
+			{UserID: 2, SensorID: 3, Role: domain.SensorRoleViewer},
+		}, nil)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Return([]domain.SensorOwner{
+			{UserID: 2, SensorID: 3, Role: domain.SensorRoleViewer},
+		}, nil)
+		sor.EXPECT().UpdateSensorOwner(ctx, domain.SensorOwner{UserID: 2, SensorID: 3, Role: domain.SensorRoleMember}).Return(nil)
+		sor.EXPECT().SaveSensorOwner(ctx, gomock.Any()).Times(0)
+
+		u := NewUser(ur, sor, sr, nil, newTransactor(ctrl))
+
+		assert.NoError(t, u.SetSensorAccess(ctx, 3, 2, domain.SensorRoleMember))
+	})
+
+	t.Run("ok, grant new user", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 1))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Return(&domain.Sensor{ID: 3}, nil)
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(2)).Return(&domain.User{ID: 2}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Return([]domain.SensorOwner{
+			{UserID: 1, SensorID: 3, Role: domain.SensorRoleOwner},
+		}, nil)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Return(nil, nil)
+		sor.EXPECT().SaveSensorOwner(ctx, domain.SensorOwner{UserID: 2, SensorID: 3, Role: domain.SensorRoleOwner}).Return(nil)
+
+		u := NewUser(ur, sor, sr, nil, newTransactor(ctrl))
+
+		assert.NoError(t, u.SetSensorAccess(ctx, 3, 2, domain.SensorRoleOwner))
+	})
+
+	t.Run("fail, last owner downgrade", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 1))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Return(&domain.Sensor{ID: 3}, nil)
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(1)).Return(&domain.User{ID: 1}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Return([]domain.SensorOwner{
+			{UserID: 1, SensorID: 3, Role: domain.SensorRoleOwner},
+		}, nil)
+		sor.EXPECT().GetSensorOwners(ctx, int64(3)).Return([]domain.SensorOwner{
+			{UserID: 1, SensorID: 3, Role: domain.SensorRoleOwner},
+			{UserID: 2, SensorID: 3, Role: domain.SensorRoleMember},
+		}, nil)
+		sor.EXPECT().UpdateSensorOwner(ctx, gomock.Any()).Times(0)
+
+		u := NewUser(ur, sor, sr, nil, newTransactor(ctrl))
+
+		assert.ErrorIs(t, u.SetSensorAccess(ctx, 3, 1, domain.SensorRoleViewer), ErrLastSensorOwner)
+	})
+
+	t.Run("fail, member cannot change roles", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 2))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Return(&domain.Sensor{ID: 3}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Return([]domain.SensorOwner{
+			{UserID: 2, SensorID: 3, Role: domain.SensorRoleMember},
+		}, nil)
+
+		u := NewUser(nil, sor, sr, nil, newTransactor(ctrl))
+
+		assert.ErrorIs(t, u.SetSensorAccess(ctx, 3, 2, domain.SensorRoleOwner), ErrSensorAccessDenied)
+	})
+
+	t.Run("fail, wrong role", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		u := NewUser(nil, nil, nil, nil, newTransactor(ctrl))
+
+		assert.ErrorIs(t, u.SetSensorAccess(ctx, 3, 2, ""), ErrWrongSensorRole)
+	})
+}
+
+func Test_user_RevokeSensorAccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, viewer leaves", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 2))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Return(&domain.Sensor{ID: 3}, nil)
+
+		bindings := []domain.SensorOwner{{UserID: 2, SensorID: 3, Role: domain.SensorRoleViewer}}
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Times(2).Return(bindings, nil)
+		sor.EXPECT().GetSensorOwners(ctx, int64(3)).Return([]domain.SensorOwner{
+			{UserID: 1, SensorID: 3, Role: domain.SensorRoleOwner},
+			bindings[0],
+		}, nil)
+		sor.EXPECT().DeleteSensorOwner(ctx, int64(2), int64(3)).Return(nil)
+
+		u := NewUser(nil, sor, sr, nil, newTransactor(ctrl))
+
+		assert.NoError(t, u.RevokeSensorAccess(ctx, 3, 2))
+	})
+
+	t.Run("fail, viewer cannot unbind others", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 2))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Return(&domain.Sensor{ID: 3}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Return([]domain.SensorOwner{
+			{UserID: 2, SensorID: 3, Role: domain.SensorRoleViewer},
+		}, nil)
+		sor.EXPECT().DeleteSensorOwner(ctx, gomock.Any(), gomock.Any()).Times(0)
+
+		u := NewUser(nil, sor, sr, nil, newTransactor(ctrl))
+
+		assert.ErrorIs(t, u.RevokeSensorAccess(ctx, 3, 1), ErrSensorAccessDenied)
+	})
+
+	t.Run("fail, last owner leaves", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 1))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Return(&domain.Sensor{ID: 3}, nil)
+
+		bindings := []domain.SensorOwner{{UserID: 1, SensorID: 3, Role: domain.SensorRoleOwner}}
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(2).Return(bindings, nil)
+		sor.EXPECT().GetSensorOwners(ctx, int64(3)).Return(bindings, nil)
+		sor.EXPECT().DeleteSensorOwner(ctx, gomock.Any(), gomock.Any()).Times(0)
+
+		u := NewUser(nil, sor, sr, nil, newTransactor(ctrl))
+
+		assert.ErrorIs(t, u.RevokeSensorAccess(ctx, 3, 1), ErrLastSensorOwner)
+	})
+
+	t.Run("fail, user not bound", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 1))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Return(&domain.Sensor{ID: 3}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Return([]domain.SensorOwner{
+			{UserID: 1, SensorID: 3, Role: domain.SensorRoleOwner},
+		}, nil)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(5)).Return(nil, nil)
+
+		u := NewUser(nil, sor, sr, nil, newTransactor(ctrl))
+
+		assert.ErrorIs(t, u.RevokeSensorAccess(ctx, 3, 5), ErrUserNotFound)
+	})
+}
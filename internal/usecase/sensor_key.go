@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"homework/internal/domain"
+	"strconv"
+	"time"
+)
+
+// sensorKeySize - длина ключа датчика в байтах до кодирования
+const sensorKeySize = 32
+
+// MaxSensorSignatureSkew - насколько время подписи может расходиться с часами сервера.
+// Перехваченный подписанный запрос можно повторить только в этом окне
+const MaxSensorSignatureSkew = 5 * time.Minute
+
+// RotateSensorKey - выдаёт датчику новый ключ, прежний ключ перестаёт действовать
+func (s *Sensor) RotateSensorKey(ctx context.Context, sensorID int64) (string, error) {
+	if err := s.checkSensorKeyAccess(ctx, sensorID); err != nil {
+		return "", err
+	}
+	return s.issueSensorKey(ctx, sensorID)
+}
+
+// RevokeSensorKey - отзывает ключ датчика, после чего его события не принимаются
+func (s *Sensor) RevokeSensorKey(ctx context.Context, sensorID int64) error {
+	if err := s.checkSensorKeyAccess(ctx, sensorID); err != nil {
+		return err
+	}
+	return s.skr.DeleteSensorKey(ctx, sensorID)
+}
+
+// checkSensorKeyAccess - управлять ключом могут пользователи, которым разрешено настраивать датчик
+func (s *Sensor) checkSensorKeyAccess(ctx context.Context, sensorID int64) error {
+	sensor, err := s.sr.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+	return checkSensorAccess(ctx, s.sor, s.hr, sensor, domain.SensorRoleMember)
+}
+
+// AuthenticateSensorByKey - проверяет ключ, переданный датчиком в открытом виде
+func (s *Sensor) AuthenticateSensorByKey(ctx context.Context, serialNumber, apiKey string) (*domain.Sensor, error) {
+	sensor, key, err := s.getSensorKey(ctx, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(hashSensorKey(apiKey), key.KeyHash) {
+		return nil, ErrSensorUnauthorized
+	}
+	return sensor, nil
+}
+
+// AuthenticateSensorBySignature - проверяет подпись HMAC-SHA256 строки "<timestamp>.<тело запроса>",
+// где timestamp - время подписи в секундах Unix, а ключ подписи - сам ключ датчика.
+// Подпись старше MaxSensorSignatureSkew или из будущего отклоняется, чтобы перехваченный запрос нельзя было повторять.
+// Сервер не хранит ключ: он выводится из секрета сервера и соли ключа, поэтому утечка одной базы
+// не позволяет подделывать подписи. Ключи, выданные без секрета, подписывать запросы не могут.
+func (s *Sensor) AuthenticateSensorBySignature(ctx context.Context, serialNumber string, timestamp int64, body, signature []byte) (*domain.Sensor, error) {
+	if len(s.keySecret) == 0 {
+		return nil, ErrSensorUnauthorized
+	}
+	if skew := s.now().Sub(time.Unix(timestamp, 0)); skew > MaxSensorSignatureSkew || skew < -MaxSensorSignatureSkew {
+		return nil, ErrSensorUnauthorized
+	}
+	sensor, key, err := s.getSensorKey(ctx, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(key.Salt) == 0 {
+		return nil, ErrSensorUnauthorized
+	}
+	// после смены секрета выведенный ключ не совпадёт с выданным
+	apiKey := deriveSensorKey(s.keySecret, key.Salt)
+	if !hmac.Equal(hashSensorKey(apiKey), key.KeyHash) {
+		return nil, ErrSensorUnauthorized
+	}
+	mac := hmac.New(sha256.New, []byte(apiKey))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), signature) {
+		return nil, ErrSensorUnauthorized
+	}
+	return sensor, nil
+}
+
+// getSensorKey - возвращает датчик и его ключ. Неизвестный датчик и датчик без ключа
+// неотличимы для вызывающего, чтобы по ответу нельзя было подобрать серийные номера.
+func (s *Sensor) getSensorKey(ctx context.Context, serialNumber string) (*domain.Sensor, *domain.SensorKey, error) {
+	sensor, err := s.sr.GetSensorBySerialNumber(ctx, serialNumber)
+	if errors.Is(err, ErrSensorNotFound) {
+		return nil, nil, ErrSensorUnauthorized
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := s.skr.GetSensorKey(ctx, sensor.ID)
+	if errors.Is(err, ErrSensorKeyNotFound) {
+		return nil, nil, ErrSensorUnauthorized
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return sensor, key, nil
+}
+
+// issueSensorKey - выдаёт датчику ключ. С секретом сервера ключ выводится из случайной соли,
+// которая сохраняется рядом с хэшем, без секрета ключ случаен
+func (s *Sensor) issueSensorKey(ctx context.Context, sensorID int64) (string, error) {
+	raw := make([]byte, sensorKeySize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	key := domain.SensorKey{
+		SensorID:  sensorID,
+		CreatedAt: s.now(),
+	}
+	apiKey := hex.EncodeToString(raw)
+	if len(s.keySecret) != 0 {
+		key.Salt = raw
+		apiKey = deriveSensorKey(s.keySecret, raw)
+	}
+	key.KeyHash = hashSensorKey(apiKey)
+	if err := s.skr.SaveSensorKey(ctx, key); err != nil {
+		return "", err
+	}
+	return apiKey, nil
+}
+
+// deriveSensorKey - ключ датчика: HMAC-SHA256 соли на секрете сервера в hex
+func deriveSensorKey(secret, salt []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(salt)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func hashSensorKey(apiKey string) []byte {
+	hash := sha256.Sum256([]byte(apiKey))
+	return hash[:]
+}
@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"homework/internal/domain"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sensor_RotateSensorKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, new key replaces old one", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Return(&domain.Sensor{ID: 1}, nil)
+
+		var saved domain.SensorKey
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().SaveSensorKey(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, key domain.SensorKey) error {
+			saved = key
+			return nil
+		})
+
+		s := NewSensor(sr, nil, skr, nil, nil, newTransactor(ctrl))
+
+		apiKey, err := s.RotateSensorKey(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, apiKey, 2*sensorKeySize)
+		assert.Equal(t, int64(1), saved.SensorID)
+		assert.Equal(t, hashSensorKey(apiKey), saved.KeyHash)
+		assert.NotEqual(t, []byte(apiKey), saved.KeyHash)
+	})
+
+	t.Run("ok, key derived from server secret", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Return(&domain.Sensor{ID: 1}, nil)
+
+		var saved domain.SensorKey
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().SaveSensorKey(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, key domain.SensorKey) error {
+			saved = key
+			return nil
+		})
+
+		secret := []byte("server secret")
+		s := NewSensor(sr, nil, skr, nil, nil, newTransactor(ctrl), WithSensorKeySecret(secret))
+
+		apiKey, err := s.RotateSensorKey(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, saved.Salt, sensorKeySize)
+		assert.Equal(t, deriveSensorKey(secret, saved.Salt), apiKey)
+		assert.Equal(t, hashSensorKey(apiKey), saved.KeyHash)
+	})
+
+	t.Run("fail, sensor of another user", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Return(&domain.Sensor{ID: 1}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Return(nil, nil)
+
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().SaveSensorKey(ctx, gomock.Any()).Times(0)
+
+		s := NewSensor(sr, sor, skr, nil, nil, newTransactor(ctrl))
+
+		_, err := s.RotateSensorKey(ctx, 1)
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+
+	t.Run("fail, viewer cannot rotate", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Return(&domain.Sensor{ID: 1}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Return([]domain.SensorOwner{
+			{UserID: 7, SensorID: 1, Role: domain.SensorRoleViewer},
+		}, nil)
+
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().SaveSensorKey(ctx, gomock.Any()).Times(0)
+
+		s := NewSensor(sr, sor, skr, nil, nil, newTransactor(ctrl))
+
+		_, err := s.RotateSensorKey(ctx, 1)
+		assert.ErrorIs(t, err, ErrSensorAccessDenied)
+	})
+}
+
+func Test_sensor_RevokeSensorKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, key deleted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Return(&domain.Sensor{ID: 1}, nil)
+
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().DeleteSensorKey(ctx, int64(1)).Times(1).Return(nil)
+
+		s := NewSensor(sr, nil, skr, nil, nil, newTransactor(ctrl))
+
+		assert.NoError(t, s.RevokeSensorKey(ctx, 1))
+	})
+
+	t.Run("fail, sensor not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Return(nil, ErrSensorNotFound)
+
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().DeleteSensorKey(ctx, gomock.Any()).Times(0)
+
+		s := NewSensor(sr, nil, skr, nil, nil, newTransactor(ctrl))
+
+		assert.ErrorIs(t, s.RevokeSensorKey(ctx, 1), ErrSensorNotFound)
+	})
+}
+
+func Test_sensor_AuthenticateSensor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const (
+		serialNumber = "1234567890"
+		apiKey       = "secret key"
+	)
+	sensor := &domain.Sensor{ID: 1, SerialNumber: serialNumber}
+	key := &domain.SensorKey{SensorID: 1, KeyHash: hashSensorKey(apiKey)}
+	body := []byte(`{"sensor_serial_number":"1234567890","payload":10}`)
+	secret := []byte("server secret")
+	signingKey := deriveSensorKey(secret, []byte("salt"))
+	signedKey := &domain.SensorKey{SensorID: 1, KeyHash: hashSensorKey(signingKey), Salt: []byte("salt")}
+	now := time.Now()
+	sign := func(apiKey string, timestamp int64, body []byte) []byte {
+		mac := hmac.New(sha256.New, []byte(apiKey))
+		mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+		mac.Write(body)
+		return mac.Sum(nil)
+	}
+	signature := sign(signingKey, now.Unix(), body)
+
+	t.Run("ok, valid key", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, serialNumber).Return(sensor, nil)
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().GetSensorKey(ctx, int64(1)).Return(key, nil)
+
+		s := NewSensor(sr, nil, skr, nil, nil, newTransactor(ctrl))
+
+		got, err := s.AuthenticateSensorByKey(ctx, serialNumber, apiKey)
+		assert.NoError(t, err)
+		assert.Equal(t, sensor, got)
+	})
+
+	t.Run("fail, wrong key", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, serialNumber).Return(sensor, nil)
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().GetSensorKey(ctx, int64(1)).Return(key, nil)
+
+		s := NewSensor(sr, nil, skr, nil, nil, newTransactor(ctrl))
+
+		_, err := s.AuthenticateSensorByKey(ctx, serialNumber, "wrong key")
+		assert.ErrorIs(t, err, ErrSensorUnauthorized)
+	})
+
+	t.Run("fail, unknown sensor", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, serialNumber).Return(nil, ErrSensorNotFound)
+
+		s := NewSensor(sr, nil, nil, nil, nil, newTransactor(ctrl))
+
+		_, err := s.AuthenticateSensorByKey(ctx, serialNumber, apiKey)
+		assert.ErrorIs(t, err, ErrSensorUnauthorized)
+	})
+
+	t.Run("fail, key revoked", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, serialNumber).Return(sensor, nil)
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().GetSensorKey(ctx, int64(1)).Return(nil, ErrSensorKeyNotFound)
+
+		s := NewSensor(sr, nil, skr, nil, nil, newTransactor(ctrl))
+
+		_, err := s.AuthenticateSensorByKey(ctx, serialNumber, apiKey)
+		assert.ErrorIs(t, err, ErrSensorUnauthorized)
+	})
+
+	t.Run("fail, repository return an error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		expectedError := errors.New("some error")
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, serialNumber).Return(sensor, nil)
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().GetSensorKey(ctx, int64(1)).Return(nil, expectedError)
+
+		s := NewSensor(sr, nil, skr, nil, nil, newTransactor(ctrl))
+
+		_, err := s.AuthenticateSensorByKey(ctx, serialNumber, apiKey)
+		assert.ErrorIs(t, err, expectedError)
+	})
+
+	t.Run("ok, valid signature", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, serialNumber).Return(sensor, nil)
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().GetSensorKey(ctx, int64(1)).Return(signedKey, nil)
+
+		s := NewSensor(sr, nil, skr, nil, nil, newTransactor(ctrl), WithSensorKeySecret(secret))
+
+		got, err := s.AuthenticateSensorBySignature(ctx, serialNumber, now.Unix(), body, signature)
+		assert.NoError(t, err)
+		assert.Equal(t, sensor, got)
+	})
+
+	t.Run("fail, body tampered", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, serialNumber).Return(sensor, nil)
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().GetSensorKey(ctx, int64(1)).Return(signedKey, nil)
+
+		s := NewSensor(sr, nil, skr, nil, nil, newTransactor(ctrl), WithSensorKeySecret(secret))
+
+		tampered := []byte(`{"sensor_serial_number":"1234567890","payload":11}`)
+		_, err := s.AuthenticateSensorBySignature(ctx, serialNumber, now.Unix(), tampered, signature)
+		assert.ErrorIs(t, err, ErrSensorUnauthorized)
+	})
+
+	t.Run("fail, signature replayed after skew window", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		s := NewSensor(nil, nil, nil, nil, nil, newTransactor(ctrl), WithSensorKeySecret(secret))
+
+		signedAt := now.Add(-MaxSensorSignatureSkew - time.Minute).Unix()
+		_, err := s.AuthenticateSensorBySignature(ctx, serialNumber, signedAt, body, sign(signingKey, signedAt, body))
+		assert.ErrorIs(t, err, ErrSensorUnauthorized)
+	})
+
+	t.Run("fail, stored hash is not a signing key", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, serialNumber).Return(sensor, nil)
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().GetSensorKey(ctx, int64(1)).Return(signedKey, nil)
+
+		s := NewSensor(sr, nil, skr, nil, nil, newTransactor(ctrl), WithSensorKeySecret(secret))
+
+		_, err := s.AuthenticateSensorBySignature(ctx, serialNumber, now.Unix(), body, sign(string(signedKey.KeyHash), now.Unix(), body))
+		assert.ErrorIs(t, err, ErrSensorUnauthorized)
+	})
+
+	t.Run("fail, signatures rejected without server secret", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		s := NewSensor(nil, nil, nil, nil, nil, newTransactor(ctrl))
+
+		_, err := s.AuthenticateSensorBySignature(ctx, serialNumber, now.Unix(), body, signature)
+		assert.ErrorIs(t, err, ErrSensorUnauthorized)
+	})
+}
@@ -22,7 +22,10 @@ func Test_sensor_RegisterSensor(t *testing.T) {
 		sr := NewMockSensorRepository(ctrl)
 		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(0)
 
-		s := NewSensor(sr)
+		str := NewMockSensorTypeRepository(ctrl)
+		str.EXPECT().GetSensorType(ctx, domain.SensorType("some")).Return(nil, ErrSensorTypeNotFound)
+
+		s := NewSensor(sr, nil, nil, nil, str, newTransactor(ctrl))
 
 		_, err := s.RegisterSensor(ctx, &domain.Sensor{
 			SerialNumber: "1234567890",
@@ -51,7 +54,7 @@ func Test_sensor_RegisterSensor(t *testing.T) {
 		expectedError := errors.New("some error")
 		sr.EXPECT().GetSensorBySerialNumber(ctx, gomock.Any()).Return(nil, expectedError)
 
-		s := NewSensor(sr)
+		s := NewSensor(sr, nil, nil, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
 
 		_, err := s.RegisterSensor(ctx, &domain.Sensor{
 			Type:         domain.SensorTypeADC,
@@ -70,7 +73,10 @@ func Test_sensor_RegisterSensor(t *testing.T) {
 		sr.EXPECT().GetSensorBySerialNumber(ctx, gomock.Any()).Return(nil, ErrSensorNotFound)
 		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Return(expectedError)
 
-		a := NewSensor(sr)
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().SaveSensorKey(ctx, gomock.Any()).Times(0)
+
+		a := NewSensor(sr, nil, skr, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
 
 		_, err := a.RegisterSensor(ctx, &domain.Sensor{
 			Type:         domain.SensorTypeADC,
@@ -105,13 +111,21 @@ func Test_sensor_RegisterSensor(t *testing.T) {
 		})
 		sr.EXPECT().GetSensorBySerialNumber(ctx, sensor.SerialNumber).Return(nil, ErrSensorNotFound)
 
-		s := NewSensor(sr)
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().SaveSensorKey(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, key domain.SensorKey) error {
+			assert.Equal(t, int64(1), key.SensorID)
+			assert.NotEmpty(t, key.KeyHash)
+			return nil
+		})
 
-		sensor, err := s.RegisterSensor(ctx, sensor)
+		s := NewSensor(sr, nil, skr, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+
+		registered, err := s.RegisterSensor(ctx, sensor)
 		assert.NoError(t, err)
 
-		assert.NotEmpty(t, sensor.RegisteredAt)
-		assert.Equal(t, int64(1), sensor.ID)
+		assert.NotEmpty(t, registered.RegisteredAt)
+		assert.Equal(t, int64(1), registered.ID)
+		assert.NotEmpty(t, registered.APIKey)
 	})
 
 	t.Run("ok, register idempotency", func(t *testing.T) {
@@ -139,7 +153,10 @@ func Test_sensor_RegisterSensor(t *testing.T) {
 		})
 		sr.EXPECT().GetSensorBySerialNumber(ctx, sensor.SerialNumber).Return(nil, ErrSensorNotFound)
 
-		s := NewSensor(sr)
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().SaveSensorKey(ctx, gomock.Any()).Times(1).Return(nil)
+
+		s := NewSensor(sr, nil, skr, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
 
 		_, err := s.RegisterSensor(ctx, sensor)
 		assert.NoError(t, err)
@@ -161,6 +178,142 @@ func Test_sensor_RegisterSensor(t *testing.T) {
 		assert.Equal(t, sensor.Description, sensor2.Description)
 		assert.Equal(t, sensor.Type, sensor2.Type)
 		assert.Equal(t, sensor.SerialNumber, sensor2.SerialNumber)
+		assert.Empty(t, sensor2.APIKey)
+	})
+
+	t.Run("ok, caller becomes owner", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "1234567890").Return(nil, ErrSensorNotFound)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, ss *domain.Sensor) error {
+			ss.ID = 3
+			return nil
+		})
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().SaveSensorOwner(ctx, domain.SensorOwner{UserID: 7, SensorID: 3, Role: domain.SensorRoleOwner}).Times(1).Return(nil)
+
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().SaveSensorKey(ctx, gomock.Any()).Times(1).Return(nil)
+
+		s := NewSensor(sr, sor, skr, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+
+		_, err := s.RegisterSensor(ctx, &domain.Sensor{
+			Type:         domain.SensorTypeADC,
+			SerialNumber: "1234567890",
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("fail, sensor, owner and key saved in one transaction", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+		txCtx := context.WithValue(ctx, struct{}{}, "tx")
+		someErr := errors.New("some error")
+
+		tr := NewMockTransactor(ctrl)
+		tr.EXPECT().WithinTransaction(ctx, gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, fn func(ctx context.Context) error) error {
+				return fn(txCtx)
+			})
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(txCtx, "1234567890").Return(nil, ErrSensorNotFound)
+		sr.EXPECT().SaveSensor(txCtx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, ss *domain.Sensor) error {
+			ss.ID = 3
+			return nil
+		})
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().SaveSensorOwner(txCtx, gomock.Any()).Times(1).Return(nil)
+
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().SaveSensorKey(txCtx, gomock.Any()).Times(1).Return(someErr)
+
+		s := NewSensor(sr, sor, skr, nil, newUnboundedSensorTypeRepository(ctrl), tr)
+
+		registered, err := s.RegisterSensor(ctx, &domain.Sensor{
+			Type:         domain.SensorTypeADC,
+			SerialNumber: "1234567890",
+		})
+		assert.ErrorIs(t, err, someErr)
+		assert.Nil(t, registered)
+	})
+
+	t.Run("fail, registered by another user", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "1234567890").Return(&domain.Sensor{ID: 3}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(0)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Times(1).Return(nil, nil)
+
+		s := NewSensor(sr, sor, nil, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+
+		_, err := s.RegisterSensor(ctx, &domain.Sensor{
+			Type:         domain.SensorTypeADC,
+			SerialNumber: "1234567890",
+		})
+		assert.ErrorIs(t, err, ErrSensorAlreadyExists)
+	})
+
+	t.Run("ok, registered in caller home", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorBySerialNumber(ctx, "1234567890").Return(nil, ErrSensorNotFound)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Return(nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().SaveSensorOwner(ctx, gomock.Any()).Times(0)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(2)).Times(1).Return(&domain.Home{ID: 2}, nil)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 2, UserID: 7, Role: domain.SensorRoleMember},
+		}, nil)
+
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().SaveSensorKey(ctx, gomock.Any()).Times(1).Return(nil)
+
+		s := NewSensor(sr, sor, skr, hr, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+
+		registered, err := s.RegisterSensor(ctx, &domain.Sensor{
+			Type:         domain.SensorTypeADC,
+			SerialNumber: "1234567890",
+			HomeID:       2,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), registered.HomeID)
+	})
+
+	t.Run("fail, viewer cannot register in home", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(0)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomeByID(ctx, int64(2)).Times(1).Return(&domain.Home{ID: 2}, nil)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 2, UserID: 7, Role: domain.SensorRoleViewer},
+		}, nil)
+
+		s := NewSensor(sr, nil, nil, hr, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+
+		_, err := s.RegisterSensor(ctx, &domain.Sensor{
+			Type:         domain.SensorTypeADC,
+			SerialNumber: "1234567890",
+			HomeID:       2,
+		})
+		assert.ErrorIs(t, err, ErrSensorAccessDenied)
 	})
 }
 
@@ -176,7 +329,7 @@ func Test_sensor_GetSensors(t *testing.T) {
 		expectedError := errors.New("some error")
 		sr.EXPECT().GetSensors(ctx).Times(1).Return(nil, expectedError)
 
-		s := NewSensor(sr)
+		s := NewSensor(sr, nil, nil, nil, nil, newTransactor(ctrl))
 
 		_, err := s.GetSensors(ctx)
 		assert.ErrorIs(t, err, expectedError)
@@ -192,7 +345,69 @@ func Test_sensor_GetSensors(t *testing.T) {
 			{},
 		}, nil)
 
-		s := NewSensor(sr)
+		s := NewSensor(sr, nil, nil, nil, nil, newTransactor(ctrl))
+
+		list, err := s.GetSensors(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, list, 2)
+	})
+
+	t.Run("ok, only caller sensors", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensors(ctx).Times(0)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Times(1).Return(&domain.Sensor{ID: 3}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Times(1).Return([]domain.SensorOwner{{UserID: 7, SensorID: 3}}, nil)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return(nil, nil)
+
+		s := NewSensor(sr, sor, nil, hr, nil, newTransactor(ctrl))
+
+		list, err := s.GetSensors(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Sensor{{ID: 3}}, list)
+	})
+
+	t.Run("ok, sensors of caller homes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensors(ctx).Times(0)
+		sr.EXPECT().GetSensorByID(ctx, int64(3)).Times(1).Return(&domain.Sensor{ID: 3, HomeID: 2}, nil)
+		sr.EXPECT().GetSensorsByHomeID(ctx, int64(2)).Times(1).Return([]domain.Sensor{
+			{ID: 3, HomeID: 2},
+			{ID: 4, HomeID: 2},
+		}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Times(1).Return([]domain.SensorOwner{{UserID: 7, SensorID: 3}}, nil)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 2, UserID: 7, Role: domain.SensorRoleViewer},
+		}, nil)
+
+		s := NewSensor(sr, sor, nil, hr, nil, newTransactor(ctrl))
+
+		list, err := s.GetSensors(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Sensor{{ID: 3, HomeID: 2}, {ID: 4, HomeID: 2}}, list)
+	})
+
+	t.Run("ok, admin gets all sensors", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithAdmin(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensors(ctx).Times(1).Return([]domain.Sensor{{ID: 3}, {ID: 4}}, nil)
+
+		s := NewSensor(sr, nil, nil, nil, nil, newTransactor(ctrl))
 
 		list, err := s.GetSensors(ctx)
 		assert.NoError(t, err)
@@ -212,7 +427,7 @@ func Test_sensor_GetSensorByID(t *testing.T) {
 		expectedError := errors.New("some error")
 		sr.EXPECT().GetSensorByID(ctx, gomock.Any()).Times(1).Return(nil, expectedError)
 
-		s := NewSensor(sr)
+		s := NewSensor(sr, nil, nil, nil, nil, newTransactor(ctrl))
 
 		_, err := s.GetSensorByID(ctx, 1)
 		assert.ErrorIs(t, err, expectedError)
@@ -225,7 +440,7 @@ func Test_sensor_GetSensorByID(t *testing.T) {
 		sr := NewMockSensorRepository(ctrl)
 		sr.EXPECT().GetSensorByID(ctx, gomock.Any()).Times(1).Return(nil, ErrSensorNotFound)
 
-		s := NewSensor(sr)
+		s := NewSensor(sr, nil, nil, nil, nil, newTransactor(ctrl))
 
 		_, err := s.GetSensorByID(ctx, 1)
 		assert.ErrorIs(t, err, ErrSensorNotFound)
@@ -246,10 +461,207 @@ func Test_sensor_GetSensorByID(t *testing.T) {
 			RegisteredAt: time.Now(),
 		}, nil)
 
-		s := NewSensor(sr)
+		s := NewSensor(sr, nil, nil, nil, nil, newTransactor(ctrl))
 
 		sensor, err := s.GetSensorByID(ctx, 1)
 		assert.NoError(t, err)
 		assert.NotNil(t, sensor)
 	})
+
+	t.Run("err, sensor of another user", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Times(1).Return([]domain.SensorOwner{{UserID: 7, SensorID: 3}}, nil)
+
+		s := NewSensor(sr, sor, nil, nil, nil, newTransactor(ctrl))
+
+		_, err := s.GetSensorByID(ctx, 1)
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+}
+
+func Test_sensor_UpdateSensor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, only passed fields changed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByIDForUpdate(ctx, int64(1)).Times(1).Return(&domain.Sensor{
+			ID:          1,
+			Description: "old desc",
+			IsActive:    true,
+		}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Return(nil)
+
+		s := NewSensor(sr, nil, nil, nil, nil, newTransactor(ctrl))
+
+		isActive := false
+		sensor, err := s.UpdateSensor(ctx, 1, domain.SensorUpdate{IsActive: &isActive})
+		assert.NoError(t, err)
+		assert.Equal(t, "old desc", sensor.Description)
+		assert.False(t, sensor.IsActive)
+	})
+
+	t.Run("ok, recalibration keeps raw state", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByIDForUpdate(ctx, int64(1)).Times(1).Return(&domain.Sensor{
+			ID:           1,
+			Type:         domain.SensorTypeADC,
+			CurrentState: 2048,
+			LastActivity: time.Now(),
+			Calibration:  &domain.Calibration{Kind: domain.CalibrationLinear, Scale: 1},
+		}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Return(nil)
+
+		s := NewSensor(sr, nil, nil, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+
+		unit := "V"
+		sensor, err := s.UpdateSensor(ctx, 1, domain.SensorUpdate{
+			Unit: &unit,
+			Calibration: &domain.Calibration{
+				Kind:   domain.CalibrationTable,
+				Points: []domain.CalibrationPoint{{Raw: 0, Value: 0}, {Raw: 4096, Value: 3.3}},
+			},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2048), sensor.CurrentState)
+		assert.Equal(t, "V", sensor.Unit)
+		if assert.NotNil(t, sensor.CalibratedState) {
+			assert.InDelta(t, 1.65, *sensor.CalibratedState, 1e-9)
+		}
+	})
+
+	t.Run("ok, calibration removed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		calibratedState := 1.0
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByIDForUpdate(ctx, int64(1)).Times(1).Return(&domain.Sensor{
+			ID:              1,
+			Type:            domain.SensorTypeADC,
+			LastActivity:    time.Now(),
+			CalibratedState: &calibratedState,
+			Calibration:     &domain.Calibration{Kind: domain.CalibrationLinear, Scale: 1},
+		}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).Return(nil)
+
+		s := NewSensor(sr, nil, nil, nil, nil, newTransactor(ctrl))
+
+		sensor, err := s.UpdateSensor(ctx, 1, domain.SensorUpdate{
+			Calibration: &domain.Calibration{Kind: domain.CalibrationNone},
+		})
+		assert.NoError(t, err)
+		assert.Nil(t, sensor.Calibration)
+		assert.Nil(t, sensor.CalibratedState)
+	})
+
+	t.Run("err, invalid calibration", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByIDForUpdate(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, Type: domain.SensorTypeADC}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(0)
+
+		s := NewSensor(sr, nil, nil, nil, newUnboundedSensorTypeRepository(ctrl), newTransactor(ctrl))
+
+		_, err := s.UpdateSensor(ctx, 1, domain.SensorUpdate{
+			Calibration: &domain.Calibration{Kind: domain.CalibrationLinear},
+		})
+		assert.ErrorIs(t, err, ErrInvalidCalibration)
+	})
+
+	t.Run("err, viewer cannot update", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByIDForUpdate(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(0)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Times(1).Return([]domain.SensorOwner{
+			{UserID: 7, SensorID: 1, Role: domain.SensorRoleViewer},
+		}, nil)
+
+		s := NewSensor(sr, sor, nil, nil, nil, newTransactor(ctrl))
+
+		description := "new desc"
+		_, err := s.UpdateSensor(ctx, 1, domain.SensorUpdate{Description: &description})
+		assert.ErrorIs(t, err, ErrSensorAccessDenied)
+	})
+
+	t.Run("err, sensor is deleted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByIDForUpdate(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1, DeletedAt: time.Now()}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(0)
+
+		s := NewSensor(sr, nil, nil, nil, nil, newTransactor(ctrl))
+
+		_, err := s.UpdateSensor(ctx, 1, domain.SensorUpdate{})
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+}
+
+func Test_sensor_DeleteSensor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, sensor marked deleted and key revoked", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByIDForUpdate(ctx, int64(1)).Times(1).Return(&domain.Sensor{
+			ID:       1,
+			IsActive: true,
+			RoomID:   2,
+		}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, sensor *domain.Sensor) error {
+			assert.False(t, sensor.IsActive)
+			assert.Zero(t, sensor.RoomID)
+			assert.False(t, sensor.DeletedAt.IsZero())
+			return nil
+		})
+
+		skr := NewMockSensorKeyRepository(ctrl)
+		skr.EXPECT().DeleteSensorKey(ctx, int64(1)).Times(1).Return(nil)
+
+		s := NewSensor(sr, nil, skr, nil, nil, newTransactor(ctrl))
+
+		assert.NoError(t, s.DeleteSensor(ctx, 1))
+	})
+
+	t.Run("err, member cannot delete", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByIDForUpdate(ctx, int64(1)).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+		sr.EXPECT().SaveSensor(ctx, gomock.Any()).Times(0)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Times(1).Return([]domain.SensorOwner{
+			{UserID: 7, SensorID: 1, Role: domain.SensorRoleMember},
+		}, nil)
+
+		s := NewSensor(sr, sor, nil, nil, nil, newTransactor(ctrl))
+
+		assert.ErrorIs(t, s.DeleteSensor(ctx, 1), ErrSensorAccessDenied)
+	})
 }
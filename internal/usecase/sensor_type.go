@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"math"
+	"regexp"
+	"slices"
+)
+
+// sensorTypeCodePattern - код типа и имя канала: латиница в нижнем регистре, цифры и подчёркивание
+var sensorTypeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+type SensorType struct {
+	str SensorTypeRepository
+}
+
+func NewSensorType(str SensorTypeRepository) *SensorType {
+	return &SensorType{
+		str: str,
+	}
+}
+
+// CreateSensorType - добавляет тип датчика в реестр, доступно только администратору.
+// Для видов значения без диапазона диапазон можно не указывать, у binary он всегда от 0 до 1.
+func (s *SensorType) CreateSensorType(ctx context.Context, definition domain.SensorTypeDefinition) (*domain.SensorTypeDefinition, error) {
+	if _, ok := restrictedCaller(ctx); ok {
+		return nil, ErrAdminRequired
+	}
+	if !sensorTypeCodePattern.MatchString(string(definition.Type)) || definition.Name == "" {
+		return nil, ErrInvalidSensorType
+	}
+	if definition.ValueKind == domain.SensorValueKindChannels {
+		if len(definition.Channels) == 0 || definition.MinValue != 0 || definition.MaxValue != 0 {
+			return nil, ErrInvalidSensorType
+		}
+		names := make(map[string]bool, len(definition.Channels))
+		for i, channel := range definition.Channels {
+			if !sensorTypeCodePattern.MatchString(channel.Name) || names[channel.Name] || channel.ValueKind == domain.SensorValueKindChannels {
+				return nil, ErrInvalidSensorType
+			}
+			names[channel.Name] = true
+			minValue, maxValue, err := sensorValueRange(channel.ValueKind, channel.MinValue, channel.MaxValue)
+			if err != nil {
+				return nil, err
+			}
+			definition.Channels[i].MinValue, definition.Channels[i].MaxValue = minValue, maxValue
+		}
+	} else {
+		if len(definition.Channels) != 0 {
+			return nil, ErrInvalidSensorType
+		}
+		minValue, maxValue, err := sensorValueRange(definition.ValueKind, definition.MinValue, definition.MaxValue)
+		if err != nil {
+			return nil, err
+		}
+		definition.MinValue, definition.MaxValue = minValue, maxValue
+	}
+	if err := s.str.SaveSensorType(ctx, definition); err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// GetSensorTypes - возвращает все типы датчиков из реестра
+func (s *SensorType) GetSensorTypes(ctx context.Context) ([]domain.SensorTypeDefinition, error) {
+	return s.str.GetSensorTypes(ctx)
+}
+
+// GetSensorType - возвращает описание типа датчика по коду
+func (s *SensorType) GetSensorType(ctx context.Context, sensorType domain.SensorType) (*domain.SensorTypeDefinition, error) {
+	return s.str.GetSensorType(ctx, sensorType)
+}
+
+// sensorValueRange - проверяет диапазон для вида значения и возвращает его в сохраняемом виде
+func sensorValueRange(kind domain.SensorValueKind, minValue, maxValue float64) (float64, float64, error) {
+	switch kind {
+	case domain.SensorValueKindInteger, domain.SensorValueKindFloat:
+		if !isFinite(minValue) || !isFinite(maxValue) || minValue > maxValue {
+			return 0, 0, ErrInvalidSensorType
+		}
+		return minValue, maxValue, nil
+	case domain.SensorValueKindBinary:
+		if minValue != 0 || maxValue != 0 && maxValue != 1 {
+			return 0, 0, ErrInvalidSensorType
+		}
+		return 0, 1, nil
+	case domain.SensorValueKindBoolean, domain.SensorValueKindString:
+		if minValue != 0 || maxValue != 0 {
+			return 0, 0, ErrInvalidSensorType
+		}
+		return 0, 0, nil
+	}
+	return 0, 0, ErrInvalidSensorType
+}
+
+// checkSensorValue - проверяет, что значение события соответствует типу датчика.
+// У откалиброванного датчика диапазон типа задан в единицах калибровки, поэтому с ним сравнивается
+// откалиброванное значение, а сырое проверяется только по виду
+func checkSensorValue(ctx context.Context, str SensorTypeRepository, sensorType domain.SensorType, calibration *domain.Calibration, event *domain.Event) error {
+	definition, err := str.GetSensorType(ctx, sensorType)
+	if err != nil {
+		return err
+	}
+	if definition.ValueKind != domain.SensorValueKindChannels {
+		value := event.Value
+		if value == nil {
+			value = event.Payload
+		}
+		if calibration == nil {
+			return checkValue(definition.ValueKind, definition.MinValue, definition.MaxValue, value)
+		}
+		if err := checkValue(definition.ValueKind, math.Inf(-1), math.Inf(1), value); err != nil {
+			return err
+		}
+		calibrated := calibrate(calibration, event.Payload, event.Value)
+		if calibrated == nil || *calibrated < definition.MinValue || *calibrated > definition.MaxValue {
+			return ErrSensorValueOutOfRange
+		}
+		return nil
+	}
+	channels, ok := event.Value.(map[string]any)
+	if !ok || len(channels) == 0 {
+		return ErrInvalidSensorValue
+	}
+	for name, value := range channels {
+		i := slices.IndexFunc(definition.Channels, func(channel domain.SensorChannelDefinition) bool {
+			return channel.Name == name
+		})
+		if i < 0 {
+			return ErrInvalidSensorValue
+		}
+		channel := definition.Channels[i]
+		if err := checkValue(channel.ValueKind, channel.MinValue, channel.MaxValue, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkValue - проверяет одно значение: целые числа приходят как int64, дробные - как float64
+func checkValue(kind domain.SensorValueKind, minValue, maxValue float64, value any) error {
+	switch kind {
+	case domain.SensorValueKindBinary:
+		if v, ok := value.(int64); ok && (v == 0 || v == 1) {
+			return nil
+		}
+		if _, ok := value.(bool); ok {
+			return nil
+		}
+	case domain.SensorValueKindInteger:
+		if v, ok := value.(int64); ok {
+			if float64(v) < minValue || float64(v) > maxValue {
+				return ErrSensorValueOutOfRange
+			}
+			return nil
+		}
+	case domain.SensorValueKindFloat:
+		var v float64
+		switch number := value.(type) {
+		case int64:
+			v = float64(number)
+		case float64:
+			v = number
+		default:
+			return ErrInvalidSensorValue
+		}
+		if v < minValue || v > maxValue {
+			return ErrSensorValueOutOfRange
+		}
+		return nil
+	case domain.SensorValueKindBoolean:
+		if _, ok := value.(bool); ok {
+			return nil
+		}
+	case domain.SensorValueKindString:
+		if _, ok := value.(string); ok {
+			return nil
+		}
+	}
+	return ErrInvalidSensorValue
+}
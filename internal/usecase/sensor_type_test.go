@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"homework/internal/domain"
+	"math"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sensorType_CreateSensorType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pressure := domain.SensorTypeDefinition{
+		Type:      "pressure",
+		Name:      "Давление",
+		ValueKind: domain.SensorValueKindInteger,
+		MinValue:  300,
+		MaxValue:  1100,
+		Unit:      "гПа",
+	}
+
+	t.Run("ok, admin creates type", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithAdmin(context.Background(), 1))
+		defer cancel()
+
+		str := NewMockSensorTypeRepository(ctrl)
+		str.EXPECT().SaveSensorType(ctx, pressure).Return(nil)
+
+		s := NewSensorType(str)
+
+		created, err := s.CreateSensorType(ctx, pressure)
+		assert.NoError(t, err)
+		assert.Equal(t, pressure, *created)
+	})
+
+	t.Run("fail, user is not admin", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 1))
+		defer cancel()
+
+		str := NewMockSensorTypeRepository(ctrl)
+		str.EXPECT().SaveSensorType(ctx, gomock.Any()).Times(0)
+
+		s := NewSensorType(str)
+
+		_, err := s.CreateSensorType(ctx, pressure)
+		assert.ErrorIs(t, err, ErrAdminRequired)
+	})
+
+	t.Run("fail, type already exists", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		str := NewMockSensorTypeRepository(ctrl)
+		str.EXPECT().SaveSensorType(ctx, gomock.Any()).Return(ErrSensorTypeAlreadyExists)
+
+		s := NewSensorType(str)
+
+		_, err := s.CreateSensorType(ctx, pressure)
+		assert.ErrorIs(t, err, ErrSensorTypeAlreadyExists)
+	})
+
+	t.Run("fail, invalid definition", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		str := NewMockSensorTypeRepository(ctrl)
+		str.EXPECT().SaveSensorType(ctx, gomock.Any()).Times(0)
+
+		s := NewSensorType(str)
+
+		invalid := []domain.SensorTypeDefinition{
+			{Type: "Pressure", Name: "Давление", ValueKind: domain.SensorValueKindInteger},
+			{Type: "pressure", ValueKind: domain.SensorValueKindInteger},
+			{Type: "pressure", Name: "Давление", ValueKind: "decimal"},
+			{Type: "pressure", Name: "Давление", ValueKind: domain.SensorValueKindInteger, MinValue: 10, MaxValue: 1},
+			{Type: "pressure", Name: "Давление", ValueKind: domain.SensorValueKindFloat, MinValue: math.NaN(), MaxValue: 1},
+			{Type: "window", Name: "Окно", ValueKind: domain.SensorValueKindBinary, MinValue: 0, MaxValue: 2},
+			{Type: "label", Name: "Метка", ValueKind: domain.SensorValueKindString, MinValue: 1, MaxValue: 10},
+			{Type: "weather", Name: "Погода", ValueKind: domain.SensorValueKindChannels},
+			{Type: "weather", Name: "Погода", ValueKind: domain.SensorValueKindChannels, Channels: []domain.SensorChannelDefinition{
+				{Name: "wind", ValueKind: domain.SensorValueKindFloat},
+				{Name: "wind", ValueKind: domain.SensorValueKindInteger},
+			}},
+			{Type: "pressure", Name: "Давление", ValueKind: domain.SensorValueKindFloat, Channels: []domain.SensorChannelDefinition{
+				{Name: "hpa", ValueKind: domain.SensorValueKindFloat},
+			}},
+		}
+		for _, definition := range invalid {
+			_, err := s.CreateSensorType(ctx, definition)
+			assert.ErrorIs(t, err, ErrInvalidSensorType, definition.Type)
+		}
+	})
+}
+
+func Test_sensorType_CreateSensorType_normalizeRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	str := NewMockSensorTypeRepository(ctrl)
+	str.EXPECT().SaveSensorType(ctx, gomock.Any()).Return(nil)
+
+	s := NewSensorType(str)
+
+	created, err := s.CreateSensorType(ctx, domain.SensorTypeDefinition{
+		Type:      "weather",
+		Name:      "Погода",
+		ValueKind: domain.SensorValueKindChannels,
+		Channels: []domain.SensorChannelDefinition{
+			{Name: "wind", ValueKind: domain.SensorValueKindFloat, MinValue: 0, MaxValue: 60, Unit: "м/с"},
+			{Name: "rain", ValueKind: domain.SensorValueKindBinary},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), created.Channels[1].MaxValue, "Диапазон binary не дополнен до 0..1")
+}
+
+func Test_sensorType_GetSensorType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("fail, unknown type", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 1))
+		defer cancel()
+
+		str := NewMockSensorTypeRepository(ctrl)
+		str.EXPECT().GetSensorType(ctx, domain.SensorType("pressure")).Return(nil, ErrSensorTypeNotFound)
+
+		s := NewSensorType(str)
+
+		_, err := s.GetSensorType(ctx, "pressure")
+		assert.ErrorIs(t, err, ErrSensorTypeNotFound)
+	})
+}
+
+func Test_checkValue(t *testing.T) {
+	tests := []struct {
+		kind  domain.SensorValueKind
+		value any
+		err   error
+	}{
+		{kind: domain.SensorValueKindBinary, value: int64(1)},
+		{kind: domain.SensorValueKindBinary, value: true},
+		{kind: domain.SensorValueKindBinary, value: int64(2), err: ErrInvalidSensorValue},
+		{kind: domain.SensorValueKindInteger, value: int64(10)},
+		{kind: domain.SensorValueKindInteger, value: int64(101), err: ErrSensorValueOutOfRange},
+		{kind: domain.SensorValueKindInteger, value: 1.5, err: ErrInvalidSensorValue},
+		{kind: domain.SensorValueKindFloat, value: 99.5},
+		{kind: domain.SensorValueKindFloat, value: int64(100)},
+		{kind: domain.SensorValueKindFloat, value: 100.5, err: ErrSensorValueOutOfRange},
+		{kind: domain.SensorValueKindFloat, value: "99", err: ErrInvalidSensorValue},
+		{kind: domain.SensorValueKindBoolean, value: false},
+		{kind: domain.SensorValueKindBoolean, value: int64(0), err: ErrInvalidSensorValue},
+		{kind: domain.SensorValueKindString, value: "open"},
+		{kind: domain.SensorValueKindString, value: map[string]any{}, err: ErrInvalidSensorValue},
+	}
+	for _, tt := range tests {
+		err := checkValue(tt.kind, 0, 100, tt.value)
+		if tt.err == nil {
+			assert.NoError(t, err, "%s %v", tt.kind, tt.value)
+		} else {
+			assert.ErrorIs(t, err, tt.err, "%s %v", tt.kind, tt.value)
+		}
+	}
+
+	assert.NoError(t, checkValue(domain.SensorValueKindFloat, 0, 3.3, 3.25), "Дробная граница диапазона округлена")
+	assert.ErrorIs(t, checkValue(domain.SensorValueKindFloat, 0, 3.3, 3.35), ErrSensorValueOutOfRange,
+		"Дробная граница диапазона округлена")
+}
@@ -11,42 +11,201 @@ var (
 	ErrWrongSensorSerialNumber = errors.New("wrong sensor serial number")
 	ErrWrongSensorType         = errors.New("wrong sensor type")
 	ErrInvalidEventTimestamp   = errors.New("invalid event timestamp")
+	ErrEventFromFuture         = errors.New("event timestamp is too far in the future")
+	ErrEventBatchTooLarge      = errors.New("event batch is too large")
+	ErrEventDuplicate          = errors.New("event with this id is already saved")
+	ErrInvalidHistoryQuery     = errors.New("invalid sensor history query")
 	ErrInvalidUserName         = errors.New("invalid user name")
 	ErrSensorNotFound          = errors.New("sensor not found")
 	ErrUserNotFound            = errors.New("user not found")
 	ErrEventNotFound           = errors.New("event not found")
+	ErrInvalidPassword         = errors.New("invalid password")
+	ErrUserAlreadyExists       = errors.New("user already exists")
+	ErrInvalidCredentials      = errors.New("invalid credentials")
+	ErrInvalidToken            = errors.New("invalid token")
+	ErrSensorAlreadyExists     = errors.New("sensor already registered")
+	ErrSensorKeyNotFound       = errors.New("sensor key not found")
+	ErrSensorUnauthorized      = errors.New("sensor unauthorized")
+	ErrWrongSensorRole         = errors.New("wrong sensor role")
+	ErrSensorAccessDenied      = errors.New("sensor access denied")
+	ErrLastSensorOwner         = errors.New("sensor must have at least one owner")
+	ErrHomeNotFound            = errors.New("home not found")
+	ErrInvalidHomeName         = errors.New("invalid home name")
+	ErrLastHomeOwner           = errors.New("home must have at least one owner")
+	ErrRoomNotFound            = errors.New("room not found")
+	ErrInvalidRoomName         = errors.New("invalid room name")
+	ErrSensorNotInRoomHome     = errors.New("sensor and room belong to different homes")
+	ErrSensorInactive          = errors.New("sensor is inactive")
+	ErrSensorTypeNotFound      = errors.New("sensor type not found")
+	ErrSensorTypeAlreadyExists = errors.New("sensor type already exists")
+	ErrInvalidSensorType       = errors.New("invalid sensor type definition")
+	ErrSensorValueOutOfRange   = errors.New("sensor value out of range")
+	ErrInvalidSensorValue      = errors.New("sensor value does not match sensor type")
+	ErrAdminRequired           = errors.New("admin required")
+	ErrInvalidCalibration      = errors.New("invalid sensor calibration")
+	ErrRetentionPolicyNotFound = errors.New("retention policy not found")
+	ErrInvalidRetentionPolicy  = errors.New("invalid retention policy")
+	ErrInvalidImportRecord     = errors.New("invalid import record")
 )
 
 //go:generate mockgen -source usecase.go -package usecase -destination usecase_mock.go
 type SensorRepository interface {
-	// SaveSensor - функция сохранения датчика
+	// SaveSensor - функция сохранения датчика, удалённый датчик сохраняется с заполненным DeletedAt
 	SaveSensor(ctx context.Context, sensor *domain.Sensor) error
-	// GetSensors - функция получения списка датчиков
+	// SaveSensorState - функция сохранения состояния датчика по событию: CurrentState, CurrentValue, CalibratedState
+	// и LastActivity меняются, только если датчик не удалён и сохранённое состояние не новее
+	SaveSensorState(ctx context.Context, sensor *domain.Sensor) error
+	// SaveSensorRoom - функция переноса датчика в комнату, меняет только RoomID, 0 - без комнаты.
+	// Удалённый датчик не меняется
+	SaveSensorRoom(ctx context.Context, sensorID, roomID int64) error
+	// ClearRoomSensors - функция, убирающая из комнаты все её датчики
+	ClearRoomSensors(ctx context.Context, roomID int64) error
+	// GetSensors - функция получения списка датчиков, удалённые датчики в списки не попадают
 	GetSensors(ctx context.Context) ([]domain.Sensor, error)
+	// GetAllSensors - функция получения списка всех датчиков, включая удалённые
+	GetAllSensors(ctx context.Context) ([]domain.Sensor, error)
 	// GetSensorByID - функция получения датчика по ID
 	GetSensorByID(ctx context.Context, id int64) (*domain.Sensor, error)
+	// GetSensorByIDForUpdate - функция получения датчика по ID с блокировкой до конца транзакции,
+	// чтобы между чтением и сохранением датчик не изменили другие транзакции
+	GetSensorByIDForUpdate(ctx context.Context, id int64) (*domain.Sensor, error)
 	// GetSensorBySerialNumber - функция получения датчика по серийному номеру
 	GetSensorBySerialNumber(ctx context.Context, sn string) (*domain.Sensor, error)
+	// GetSensorsByHomeID - функция получения списка датчиков дома
+	GetSensorsByHomeID(ctx context.Context, homeID int64) ([]domain.Sensor, error)
+	// GetSensorsByRoomID - функция получения списка датчиков комнаты
+	GetSensorsByRoomID(ctx context.Context, roomID int64) ([]domain.Sensor, error)
+}
+
+type SensorTypeRepository interface {
+	// SaveSensorType - функция сохранения типа датчика, существующий тип не перезаписывается
+	SaveSensorType(ctx context.Context, definition domain.SensorTypeDefinition) error
+	// GetSensorType - функция получения описания типа датчика по коду
+	GetSensorType(ctx context.Context, sensorType domain.SensorType) (*domain.SensorTypeDefinition, error)
+	// GetSensorTypes - функция получения списка всех типов датчиков
+	GetSensorTypes(ctx context.Context) ([]domain.SensorTypeDefinition, error)
 }
 
 type EventRepository interface {
-	// SaveEvent - функция сохранения события по датчику
+	// SaveEvent - функция сохранения события по датчику. Если у датчика уже есть событие с тем же EventID,
+	// полученное в пределах окна дедупликации, событие не сохраняется и возвращается ErrEventDuplicate.
+	// Вместе с событием обновляются накопленные агрегаты датчика. Событию проставляются ID и Sequence
 	SaveEvent(ctx context.Context, event *domain.Event) error
+	// SaveEvents - функция сохранения пакета событий, пакет сохраняется целиком или не сохраняется вовсе.
+	// Повторы отсеиваются как в SaveEvent, для каждого события возвращается nil или ErrEventDuplicate
+	SaveEvents(ctx context.Context, events []domain.Event) ([]error, error)
 	// GetLastEventBySensorID - функция получения последнего события по ID датчика
 	GetLastEventBySensorID(ctx context.Context, id int64) (*domain.Event, error)
-	GetSensorHistory(ctx context.Context, id int64, start, end time.Time) ([]domain.Event, error)
+	// GetSensorHistory - функция получения событий датчика по параметрам выборки, упорядоченных по времени и ID
+	GetSensorHistory(ctx context.Context, id int64, query domain.HistoryQuery) ([]domain.Event, error)
+	// StreamSensorHistory - функция выгрузки событий датчика по параметрам выборки без After: события по порядку
+	// передаются в fn, ошибка fn прекращает выгрузку
+	StreamSensorHistory(ctx context.Context, id int64, query domain.HistoryQuery, fn func(domain.Event) error) error
+	// ReplaySensorEvents - функция получения событий датчика по параметрам дозагрузки, упорядоченных по Sequence
+	ReplaySensorEvents(ctx context.Context, id int64, query domain.ReplayQuery) ([]domain.Event, error)
+	// GetLastSequence - функция получения последовательности, до которой включительно все события уже видны
+	GetLastSequence(ctx context.Context) (int64, error)
+	// GetSensorHistoryAggregates - функция получения агрегатов числовых значений событий датчика
+	// по интервалам query.Bucket. Возвращаются только интервалы с событиями, упорядоченные по времени
+	GetSensorHistoryAggregates(ctx context.Context, id int64, query domain.HistoryAggregateQuery) ([]domain.HistoryBucket, error)
+	// GetSensorRollups - функция получения накопленных агрегатов датчика длительности query.Bucket, начинающихся
+	// в [query.Start, query.End). Агрегаты разных домов возвращаются отдельно, упорядоченные по началу интервала
+	GetSensorRollups(ctx context.Context, id int64, query domain.HistoryAggregateQuery) ([]domain.HistoryRollup, error)
+	// DeleteEventsBySensorID - функция удаления всех событий и накопленных агрегатов датчика
+	DeleteEventsBySensorID(ctx context.Context, id int64) error
+	// CountEventsBefore - функция подсчёта событий датчика, время которых раньше before
+	CountEventsBefore(ctx context.Context, id int64, before time.Time) (int64, error)
+	// DeleteEventsBefore - функция удаления не больше limit самых старых событий датчика, время которых раньше before.
+	// Накопленные агрегаты не меняются, возвращаются удалённые события
+	DeleteEventsBefore(ctx context.Context, id int64, before time.Time, limit int) ([]domain.Event, error)
+	// DeleteRollupsBefore - функция удаления накопленных агрегатов датчика за интервалы, закончившиеся не позже before
+	DeleteRollupsBefore(ctx context.Context, id int64, before time.Time) error
+}
+
+type RetentionRepository interface {
+	// SaveRetentionPolicy - функция сохранения политики хранения типа датчика или датчика, прежняя политика заменяется
+	SaveRetentionPolicy(ctx context.Context, policy domain.RetentionPolicy) error
+	// DeleteRetentionPolicy - функция удаления политики хранения типа датчика или датчика
+	DeleteRetentionPolicy(ctx context.Context, policy domain.RetentionPolicy) error
+	// GetRetentionPolicies - функция получения всех политик хранения
+	GetRetentionPolicies(ctx context.Context) ([]domain.RetentionPolicy, error)
+}
+
+type EventArchive interface {
+	// ArchiveEvents - функция сохранения событий датчика в архив перед их удалением
+	ArchiveEvents(ctx context.Context, id int64, events []domain.Event) error
 }
 
 type UserRepository interface {
-	// SaveUser - функция сохранения пользователя
+	// SaveUser - функция сохранения пользователя, для существующего пользователя меняет имя
 	SaveUser(ctx context.Context, user *domain.User) error
 	// GetUserByID - функция получения пользователя по id
 	GetUserByID(ctx context.Context, id int64) (*domain.User, error)
+	// GetUsers - функция получения списка всех пользователей
+	GetUsers(ctx context.Context) ([]domain.User, error)
+	// DeleteUser - функция удаления пользователя вместе с его учётными данными
+	DeleteUser(ctx context.Context, id int64) error
+	// SaveCredentials - функция сохранения учётных данных пользователя
+	SaveCredentials(ctx context.Context, credentials domain.Credentials) error
+	// GetCredentialsByLogin - функция получения учётных данных по логину
+	GetCredentialsByLogin(ctx context.Context, login string) (*domain.Credentials, error)
 }
 
 type SensorOwnerRepository interface {
 	// SaveSensorOwner - функция привязки датчика к пользователю
 	SaveSensorOwner(ctx context.Context, sensorOwner domain.SensorOwner) error
+	// UpdateSensorOwner - функция изменения роли в существующей привязке
+	UpdateSensorOwner(ctx context.Context, sensorOwner domain.SensorOwner) error
 	// GetSensorsByUserID -функция, возвращающая список привязок для пользователя
 	GetSensorsByUserID(ctx context.Context, userID int64) ([]domain.SensorOwner, error)
+	// GetSensorOwners - функция, возвращающая список привязок для датчика
+	GetSensorOwners(ctx context.Context, sensorID int64) ([]domain.SensorOwner, error)
+	// DeleteSensorOwner - функция отвязки датчика от пользователя
+	DeleteSensorOwner(ctx context.Context, userID, sensorID int64) error
+}
+
+type SensorKeyRepository interface {
+	// SaveSensorKey - функция сохранения ключа датчика, заменяет ранее выданный ключ
+	SaveSensorKey(ctx context.Context, key domain.SensorKey) error
+	// GetSensorKey - функция получения ключа по ID датчика
+	GetSensorKey(ctx context.Context, sensorID int64) (*domain.SensorKey, error)
+	// DeleteSensorKey - функция отзыва ключа датчика
+	DeleteSensorKey(ctx context.Context, sensorID int64) error
+}
+
+type HomeRepository interface {
+	// SaveHome - функция сохранения дома
+	SaveHome(ctx context.Context, home *domain.Home) error
+	// GetHomeByID - функция получения дома по id
+	GetHomeByID(ctx context.Context, id int64) (*domain.Home, error)
+	// GetHomeByIDForUpdate - функция получения дома по id с блокировкой до конца транзакции,
+	// чтобы изменения состава участников дома выполнялись по одному
+	GetHomeByIDForUpdate(ctx context.Context, id int64) (*domain.Home, error)
+	// GetHomes - функция получения списка всех домов
+	GetHomes(ctx context.Context) ([]domain.Home, error)
+	// SaveHomeMember - функция добавления пользователя в дом, для существующего участника меняет роль
+	SaveHomeMember(ctx context.Context, member domain.HomeMember) error
+	// GetHomeMembers - функция получения списка участников дома
+	GetHomeMembers(ctx context.Context, homeID int64) ([]domain.HomeMember, error)
+	// GetHomesByUserID - функция получения списка домов, в которых состоит пользователь
+	GetHomesByUserID(ctx context.Context, userID int64) ([]domain.HomeMember, error)
+	// DeleteHomeMember - функция исключения пользователя из дома
+	DeleteHomeMember(ctx context.Context, homeID, userID int64) error
+}
+
+type RoomRepository interface {
+	// SaveRoom - функция сохранения комнаты, для существующей комнаты меняет название
+	SaveRoom(ctx context.Context, room *domain.Room) error
+	// GetRoomByID - функция получения комнаты по id
+	GetRoomByID(ctx context.Context, id int64) (*domain.Room, error)
+	// GetRoomsByHomeID - функция получения списка комнат дома
+	GetRoomsByHomeID(ctx context.Context, homeID int64) ([]domain.Room, error)
+	// DeleteRoom - функция удаления комнаты
+	DeleteRoom(ctx context.Context, id int64) error
+}
+
+type Transactor interface {
+	// WithinTransaction - функция выполнения fn в одной транзакции хранилища: изменения, сделанные репозиториями
+	// с переданным в fn контекстом, сохраняются вместе, если fn вернула nil, и отменяются, если вернула ошибку
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
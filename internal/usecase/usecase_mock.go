@@ -8,7 +8,7 @@ import (
 	context "context"
 	domain "homework/internal/domain"
 	reflect "reflect"
-	"time"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -36,6 +36,35 @@ func (m *MockSensorRepository) EXPECT() *MockSensorRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ClearRoomSensors mocks base method.
+func (m *MockSensorRepository) ClearRoomSensors(ctx context.Context, roomID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearRoomSensors", ctx, roomID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearRoomSensors indicates an expected call of ClearRoomSensors.
+func (mr *MockSensorRepositoryMockRecorder) ClearRoomSensors(ctx, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearRoomSensors", reflect.TypeOf((*MockSensorRepository)(nil).ClearRoomSensors), ctx, roomID)
+}
+
+// GetAllSensors mocks base method.
+func (m *MockSensorRepository) GetAllSensors(ctx context.Context) ([]domain.Sensor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllSensors", ctx)
+	ret0, _ := ret[0].([]domain.Sensor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllSensors indicates an expected call of GetAllSensors.
+func (mr *MockSensorRepositoryMockRecorder) GetAllSensors(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllSensors", reflect.TypeOf((*MockSensorRepository)(nil).GetAllSensors), ctx)
+}
+
 // GetSensorByID mocks base method.
 func (m *MockSensorRepository) GetSensorByID(ctx context.Context, id int64) (*domain.Sensor, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +80,21 @@ func (mr *MockSensorRepositoryMockRecorder) GetSensorByID(ctx, id interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorByID", reflect.TypeOf((*MockSensorRepository)(nil).GetSensorByID), ctx, id)
 }
 
+// GetSensorByIDForUpdate mocks base method.
+func (m *MockSensorRepository) GetSensorByIDForUpdate(ctx context.Context, id int64) (*domain.Sensor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorByIDForUpdate", ctx, id)
+	ret0, _ := ret[0].(*domain.Sensor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorByIDForUpdate indicates an expected call of GetSensorByIDForUpdate.
+func (mr *MockSensorRepositoryMockRecorder) GetSensorByIDForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorByIDForUpdate", reflect.TypeOf((*MockSensorRepository)(nil).GetSensorByIDForUpdate), ctx, id)
+}
+
 // GetSensorBySerialNumber mocks base method.
 func (m *MockSensorRepository) GetSensorBySerialNumber(ctx context.Context, sn string) (*domain.Sensor, error) {
 	m.ctrl.T.Helper()
@@ -81,6 +125,36 @@ func (mr *MockSensorRepositoryMockRecorder) GetSensors(ctx interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensors", reflect.TypeOf((*MockSensorRepository)(nil).GetSensors), ctx)
 }
 
+// GetSensorsByHomeID mocks base method.
+func (m *MockSensorRepository) GetSensorsByHomeID(ctx context.Context, homeID int64) ([]domain.Sensor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorsByHomeID", ctx, homeID)
+	ret0, _ := ret[0].([]domain.Sensor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorsByHomeID indicates an expected call of GetSensorsByHomeID.
+func (mr *MockSensorRepositoryMockRecorder) GetSensorsByHomeID(ctx, homeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorsByHomeID", reflect.TypeOf((*MockSensorRepository)(nil).GetSensorsByHomeID), ctx, homeID)
+}
+
+// GetSensorsByRoomID mocks base method.
+func (m *MockSensorRepository) GetSensorsByRoomID(ctx context.Context, roomID int64) ([]domain.Sensor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorsByRoomID", ctx, roomID)
+	ret0, _ := ret[0].([]domain.Sensor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorsByRoomID indicates an expected call of GetSensorsByRoomID.
+func (mr *MockSensorRepositoryMockRecorder) GetSensorsByRoomID(ctx, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorsByRoomID", reflect.TypeOf((*MockSensorRepository)(nil).GetSensorsByRoomID), ctx, roomID)
+}
+
 // SaveSensor mocks base method.
 func (m *MockSensorRepository) SaveSensor(ctx context.Context, sensor *domain.Sensor) error {
 	m.ctrl.T.Helper()
@@ -95,6 +169,101 @@ func (mr *MockSensorRepositoryMockRecorder) SaveSensor(ctx, sensor interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSensor", reflect.TypeOf((*MockSensorRepository)(nil).SaveSensor), ctx, sensor)
 }
 
+// SaveSensorRoom mocks base method.
+func (m *MockSensorRepository) SaveSensorRoom(ctx context.Context, sensorID, roomID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSensorRoom", ctx, sensorID, roomID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSensorRoom indicates an expected call of SaveSensorRoom.
+func (mr *MockSensorRepositoryMockRecorder) SaveSensorRoom(ctx, sensorID, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSensorRoom", reflect.TypeOf((*MockSensorRepository)(nil).SaveSensorRoom), ctx, sensorID, roomID)
+}
+
+// SaveSensorState mocks base method.
+func (m *MockSensorRepository) SaveSensorState(ctx context.Context, sensor *domain.Sensor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSensorState", ctx, sensor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSensorState indicates an expected call of SaveSensorState.
+func (mr *MockSensorRepositoryMockRecorder) SaveSensorState(ctx, sensor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSensorState", reflect.TypeOf((*MockSensorRepository)(nil).SaveSensorState), ctx, sensor)
+}
+
+// MockSensorTypeRepository is a mock of SensorTypeRepository interface.
+type MockSensorTypeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSensorTypeRepositoryMockRecorder
+}
+
+// MockSensorTypeRepositoryMockRecorder is the mock recorder for MockSensorTypeRepository.
+type MockSensorTypeRepositoryMockRecorder struct {
+	mock *MockSensorTypeRepository
+}
+
+// NewMockSensorTypeRepository creates a new mock instance.
+func NewMockSensorTypeRepository(ctrl *gomock.Controller) *MockSensorTypeRepository {
+	mock := &MockSensorTypeRepository{ctrl: ctrl}
+	mock.recorder = &MockSensorTypeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSensorTypeRepository) EXPECT() *MockSensorTypeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetSensorType mocks base method.
+func (m *MockSensorTypeRepository) GetSensorType(ctx context.Context, sensorType domain.SensorType) (*domain.SensorTypeDefinition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorType", ctx, sensorType)
+	ret0, _ := ret[0].(*domain.SensorTypeDefinition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorType indicates an expected call of GetSensorType.
+func (mr *MockSensorTypeRepositoryMockRecorder) GetSensorType(ctx, sensorType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorType", reflect.TypeOf((*MockSensorTypeRepository)(nil).GetSensorType), ctx, sensorType)
+}
+
+// GetSensorTypes mocks base method.
+func (m *MockSensorTypeRepository) GetSensorTypes(ctx context.Context) ([]domain.SensorTypeDefinition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorTypes", ctx)
+	ret0, _ := ret[0].([]domain.SensorTypeDefinition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorTypes indicates an expected call of GetSensorTypes.
+func (mr *MockSensorTypeRepositoryMockRecorder) GetSensorTypes(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorTypes", reflect.TypeOf((*MockSensorTypeRepository)(nil).GetSensorTypes), ctx)
+}
+
+// SaveSensorType mocks base method.
+func (m *MockSensorTypeRepository) SaveSensorType(ctx context.Context, definition domain.SensorTypeDefinition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSensorType", ctx, definition)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSensorType indicates an expected call of SaveSensorType.
+func (mr *MockSensorTypeRepositoryMockRecorder) SaveSensorType(ctx, definition interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSensorType", reflect.TypeOf((*MockSensorTypeRepository)(nil).SaveSensorType), ctx, definition)
+}
+
 // MockEventRepository is a mock of EventRepository interface.
 type MockEventRepository struct {
 	ctrl     *gomock.Controller
@@ -113,23 +282,67 @@ func NewMockEventRepository(ctrl *gomock.Controller) *MockEventRepository {
 	return mock
 }
 
-func (mr *MockEventRepositoryMockRecorder) GetSensorHistory(ctx context.Context, id int64, start gomock.Matcher, emd gomock.Matcher) *gomock.Call {
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventRepository) EXPECT() *MockEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountEventsBefore mocks base method.
+func (m *MockEventRepository) CountEventsBefore(ctx context.Context, id int64, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountEventsBefore", ctx, id, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountEventsBefore indicates an expected call of CountEventsBefore.
+func (mr *MockEventRepositoryMockRecorder) CountEventsBefore(ctx, id, before interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorHistory", reflect.TypeOf((*MockEventRepository)(nil).GetSensorHistory), ctx, id, start, emd)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountEventsBefore", reflect.TypeOf((*MockEventRepository)(nil).CountEventsBefore), ctx, id, before)
+}
+
+// DeleteEventsBefore mocks base method.
+func (m *MockEventRepository) DeleteEventsBefore(ctx context.Context, id int64, before time.Time, limit int) ([]domain.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEventsBefore", ctx, id, before, limit)
+	ret0, _ := ret[0].([]domain.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
 
+// DeleteEventsBefore indicates an expected call of DeleteEventsBefore.
+func (mr *MockEventRepositoryMockRecorder) DeleteEventsBefore(ctx, id, before, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEventsBefore", reflect.TypeOf((*MockEventRepository)(nil).DeleteEventsBefore), ctx, id, before, limit)
 }
 
-func (m *MockEventRepository) GetSensorHistory(ctx context.Context, id int64, start, end time.Time) ([]domain.Event, error) {
+// DeleteEventsBySensorID mocks base method.
+func (m *MockEventRepository) DeleteEventsBySensorID(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
-	tmp := m.ctrl.Call(m, "GetSensorHistory", ctx, id, start, end)
-	tmp1, _ := tmp[0].([]domain.Event)
-	tmp2, _ := tmp[1].(error)
-	return tmp1, tmp2
+	ret := m.ctrl.Call(m, "DeleteEventsBySensorID", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockEventRepository) EXPECT() *MockEventRepositoryMockRecorder {
-	return m.recorder
+// DeleteEventsBySensorID indicates an expected call of DeleteEventsBySensorID.
+func (mr *MockEventRepositoryMockRecorder) DeleteEventsBySensorID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEventsBySensorID", reflect.TypeOf((*MockEventRepository)(nil).DeleteEventsBySensorID), ctx, id)
+}
+
+// DeleteRollupsBefore mocks base method.
+func (m *MockEventRepository) DeleteRollupsBefore(ctx context.Context, id int64, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRollupsBefore", ctx, id, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRollupsBefore indicates an expected call of DeleteRollupsBefore.
+func (mr *MockEventRepositoryMockRecorder) DeleteRollupsBefore(ctx, id, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRollupsBefore", reflect.TypeOf((*MockEventRepository)(nil).DeleteRollupsBefore), ctx, id, before)
 }
 
 // GetLastEventBySensorID mocks base method.
@@ -147,6 +360,81 @@ func (mr *MockEventRepositoryMockRecorder) GetLastEventBySensorID(ctx, id interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastEventBySensorID", reflect.TypeOf((*MockEventRepository)(nil).GetLastEventBySensorID), ctx, id)
 }
 
+// GetLastSequence mocks base method.
+func (m *MockEventRepository) GetLastSequence(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastSequence", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastSequence indicates an expected call of GetLastSequence.
+func (mr *MockEventRepositoryMockRecorder) GetLastSequence(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastSequence", reflect.TypeOf((*MockEventRepository)(nil).GetLastSequence), ctx)
+}
+
+// GetSensorHistory mocks base method.
+func (m *MockEventRepository) GetSensorHistory(ctx context.Context, id int64, query domain.HistoryQuery) ([]domain.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorHistory", ctx, id, query)
+	ret0, _ := ret[0].([]domain.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorHistory indicates an expected call of GetSensorHistory.
+func (mr *MockEventRepositoryMockRecorder) GetSensorHistory(ctx, id, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorHistory", reflect.TypeOf((*MockEventRepository)(nil).GetSensorHistory), ctx, id, query)
+}
+
+// GetSensorHistoryAggregates mocks base method.
+func (m *MockEventRepository) GetSensorHistoryAggregates(ctx context.Context, id int64, query domain.HistoryAggregateQuery) ([]domain.HistoryBucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorHistoryAggregates", ctx, id, query)
+	ret0, _ := ret[0].([]domain.HistoryBucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorHistoryAggregates indicates an expected call of GetSensorHistoryAggregates.
+func (mr *MockEventRepositoryMockRecorder) GetSensorHistoryAggregates(ctx, id, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorHistoryAggregates", reflect.TypeOf((*MockEventRepository)(nil).GetSensorHistoryAggregates), ctx, id, query)
+}
+
+// GetSensorRollups mocks base method.
+func (m *MockEventRepository) GetSensorRollups(ctx context.Context, id int64, query domain.HistoryAggregateQuery) ([]domain.HistoryRollup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorRollups", ctx, id, query)
+	ret0, _ := ret[0].([]domain.HistoryRollup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorRollups indicates an expected call of GetSensorRollups.
+func (mr *MockEventRepositoryMockRecorder) GetSensorRollups(ctx, id, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorRollups", reflect.TypeOf((*MockEventRepository)(nil).GetSensorRollups), ctx, id, query)
+}
+
+// ReplaySensorEvents mocks base method.
+func (m *MockEventRepository) ReplaySensorEvents(ctx context.Context, id int64, query domain.ReplayQuery) ([]domain.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaySensorEvents", ctx, id, query)
+	ret0, _ := ret[0].([]domain.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaySensorEvents indicates an expected call of ReplaySensorEvents.
+func (mr *MockEventRepositoryMockRecorder) ReplaySensorEvents(ctx, id, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaySensorEvents", reflect.TypeOf((*MockEventRepository)(nil).ReplaySensorEvents), ctx, id, query)
+}
+
 // SaveEvent mocks base method.
 func (m *MockEventRepository) SaveEvent(ctx context.Context, event *domain.Event) error {
 	m.ctrl.T.Helper()
@@ -161,6 +449,138 @@ func (mr *MockEventRepositoryMockRecorder) SaveEvent(ctx, event interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEvent", reflect.TypeOf((*MockEventRepository)(nil).SaveEvent), ctx, event)
 }
 
+// SaveEvents mocks base method.
+func (m *MockEventRepository) SaveEvents(ctx context.Context, events []domain.Event) ([]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEvents", ctx, events)
+	ret0, _ := ret[0].([]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveEvents indicates an expected call of SaveEvents.
+func (mr *MockEventRepositoryMockRecorder) SaveEvents(ctx, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEvents", reflect.TypeOf((*MockEventRepository)(nil).SaveEvents), ctx, events)
+}
+
+// StreamSensorHistory mocks base method.
+func (m *MockEventRepository) StreamSensorHistory(ctx context.Context, id int64, query domain.HistoryQuery, fn func(domain.Event) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamSensorHistory", ctx, id, query, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamSensorHistory indicates an expected call of StreamSensorHistory.
+func (mr *MockEventRepositoryMockRecorder) StreamSensorHistory(ctx, id, query, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamSensorHistory", reflect.TypeOf((*MockEventRepository)(nil).StreamSensorHistory), ctx, id, query, fn)
+}
+
+// MockRetentionRepository is a mock of RetentionRepository interface.
+type MockRetentionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRetentionRepositoryMockRecorder
+}
+
+// MockRetentionRepositoryMockRecorder is the mock recorder for MockRetentionRepository.
+type MockRetentionRepositoryMockRecorder struct {
+	mock *MockRetentionRepository
+}
+
+// NewMockRetentionRepository creates a new mock instance.
+func NewMockRetentionRepository(ctrl *gomock.Controller) *MockRetentionRepository {
+	mock := &MockRetentionRepository{ctrl: ctrl}
+	mock.recorder = &MockRetentionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRetentionRepository) EXPECT() *MockRetentionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteRetentionPolicy mocks base method.
+func (m *MockRetentionRepository) DeleteRetentionPolicy(ctx context.Context, policy domain.RetentionPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRetentionPolicy", ctx, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRetentionPolicy indicates an expected call of DeleteRetentionPolicy.
+func (mr *MockRetentionRepositoryMockRecorder) DeleteRetentionPolicy(ctx, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRetentionPolicy", reflect.TypeOf((*MockRetentionRepository)(nil).DeleteRetentionPolicy), ctx, policy)
+}
+
+// GetRetentionPolicies mocks base method.
+func (m *MockRetentionRepository) GetRetentionPolicies(ctx context.Context) ([]domain.RetentionPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRetentionPolicies", ctx)
+	ret0, _ := ret[0].([]domain.RetentionPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRetentionPolicies indicates an expected call of GetRetentionPolicies.
+func (mr *MockRetentionRepositoryMockRecorder) GetRetentionPolicies(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRetentionPolicies", reflect.TypeOf((*MockRetentionRepository)(nil).GetRetentionPolicies), ctx)
+}
+
+// SaveRetentionPolicy mocks base method.
+func (m *MockRetentionRepository) SaveRetentionPolicy(ctx context.Context, policy domain.RetentionPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRetentionPolicy", ctx, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRetentionPolicy indicates an expected call of SaveRetentionPolicy.
+func (mr *MockRetentionRepositoryMockRecorder) SaveRetentionPolicy(ctx, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRetentionPolicy", reflect.TypeOf((*MockRetentionRepository)(nil).SaveRetentionPolicy), ctx, policy)
+}
+
+// MockEventArchive is a mock of EventArchive interface.
+type MockEventArchive struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventArchiveMockRecorder
+}
+
+// MockEventArchiveMockRecorder is the mock recorder for MockEventArchive.
+type MockEventArchiveMockRecorder struct {
+	mock *MockEventArchive
+}
+
+// NewMockEventArchive creates a new mock instance.
+func NewMockEventArchive(ctrl *gomock.Controller) *MockEventArchive {
+	mock := &MockEventArchive{ctrl: ctrl}
+	mock.recorder = &MockEventArchiveMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventArchive) EXPECT() *MockEventArchiveMockRecorder {
+	return m.recorder
+}
+
+// ArchiveEvents mocks base method.
+func (m *MockEventArchive) ArchiveEvents(ctx context.Context, id int64, events []domain.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveEvents", ctx, id, events)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveEvents indicates an expected call of ArchiveEvents.
+func (mr *MockEventArchiveMockRecorder) ArchiveEvents(ctx, id, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveEvents", reflect.TypeOf((*MockEventArchive)(nil).ArchiveEvents), ctx, id, events)
+}
+
 // MockUserRepository is a mock of UserRepository interface.
 type MockUserRepository struct {
 	ctrl     *gomock.Controller
@@ -184,6 +604,35 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// DeleteUser mocks base method.
+func (m *MockUserRepository) DeleteUser(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUserRepositoryMockRecorder) DeleteUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserRepository)(nil).DeleteUser), ctx, id)
+}
+
+// GetCredentialsByLogin mocks base method.
+func (m *MockUserRepository) GetCredentialsByLogin(ctx context.Context, login string) (*domain.Credentials, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCredentialsByLogin", ctx, login)
+	ret0, _ := ret[0].(*domain.Credentials)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCredentialsByLogin indicates an expected call of GetCredentialsByLogin.
+func (mr *MockUserRepositoryMockRecorder) GetCredentialsByLogin(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCredentialsByLogin", reflect.TypeOf((*MockUserRepository)(nil).GetCredentialsByLogin), ctx, login)
+}
+
 // GetUserByID mocks base method.
 func (m *MockUserRepository) GetUserByID(ctx context.Context, id int64) (*domain.User, error) {
 	m.ctrl.T.Helper()
@@ -199,6 +648,35 @@ func (mr *MockUserRepositoryMockRecorder) GetUserByID(ctx, id interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepository)(nil).GetUserByID), ctx, id)
 }
 
+// GetUsers mocks base method.
+func (m *MockUserRepository) GetUsers(ctx context.Context) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", ctx)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockUserRepositoryMockRecorder) GetUsers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserRepository)(nil).GetUsers), ctx)
+}
+
+// SaveCredentials mocks base method.
+func (m *MockUserRepository) SaveCredentials(ctx context.Context, credentials domain.Credentials) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCredentials", ctx, credentials)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCredentials indicates an expected call of SaveCredentials.
+func (mr *MockUserRepositoryMockRecorder) SaveCredentials(ctx, credentials interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCredentials", reflect.TypeOf((*MockUserRepository)(nil).SaveCredentials), ctx, credentials)
+}
+
 // SaveUser mocks base method.
 func (m *MockUserRepository) SaveUser(ctx context.Context, user *domain.User) error {
 	m.ctrl.T.Helper()
@@ -236,6 +714,35 @@ func (m *MockSensorOwnerRepository) EXPECT() *MockSensorOwnerRepositoryMockRecor
 	return m.recorder
 }
 
+// DeleteSensorOwner mocks base method.
+func (m *MockSensorOwnerRepository) DeleteSensorOwner(ctx context.Context, userID, sensorID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSensorOwner", ctx, userID, sensorID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSensorOwner indicates an expected call of DeleteSensorOwner.
+func (mr *MockSensorOwnerRepositoryMockRecorder) DeleteSensorOwner(ctx, userID, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSensorOwner", reflect.TypeOf((*MockSensorOwnerRepository)(nil).DeleteSensorOwner), ctx, userID, sensorID)
+}
+
+// GetSensorOwners mocks base method.
+func (m *MockSensorOwnerRepository) GetSensorOwners(ctx context.Context, sensorID int64) ([]domain.SensorOwner, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorOwners", ctx, sensorID)
+	ret0, _ := ret[0].([]domain.SensorOwner)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorOwners indicates an expected call of GetSensorOwners.
+func (mr *MockSensorOwnerRepositoryMockRecorder) GetSensorOwners(ctx, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorOwners", reflect.TypeOf((*MockSensorOwnerRepository)(nil).GetSensorOwners), ctx, sensorID)
+}
+
 // GetSensorsByUserID mocks base method.
 func (m *MockSensorOwnerRepository) GetSensorsByUserID(ctx context.Context, userID int64) ([]domain.SensorOwner, error) {
 	m.ctrl.T.Helper()
@@ -264,3 +771,341 @@ func (mr *MockSensorOwnerRepositoryMockRecorder) SaveSensorOwner(ctx, sensorOwne
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSensorOwner", reflect.TypeOf((*MockSensorOwnerRepository)(nil).SaveSensorOwner), ctx, sensorOwner)
 }
+
+// UpdateSensorOwner mocks base method.
+func (m *MockSensorOwnerRepository) UpdateSensorOwner(ctx context.Context, sensorOwner domain.SensorOwner) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSensorOwner", ctx, sensorOwner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSensorOwner indicates an expected call of UpdateSensorOwner.
+func (mr *MockSensorOwnerRepositoryMockRecorder) UpdateSensorOwner(ctx, sensorOwner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSensorOwner", reflect.TypeOf((*MockSensorOwnerRepository)(nil).UpdateSensorOwner), ctx, sensorOwner)
+}
+
+// MockSensorKeyRepository is a mock of SensorKeyRepository interface.
+type MockSensorKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSensorKeyRepositoryMockRecorder
+}
+
+// MockSensorKeyRepositoryMockRecorder is the mock recorder for MockSensorKeyRepository.
+type MockSensorKeyRepositoryMockRecorder struct {
+	mock *MockSensorKeyRepository
+}
+
+// NewMockSensorKeyRepository creates a new mock instance.
+func NewMockSensorKeyRepository(ctrl *gomock.Controller) *MockSensorKeyRepository {
+	mock := &MockSensorKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockSensorKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSensorKeyRepository) EXPECT() *MockSensorKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteSensorKey mocks base method.
+func (m *MockSensorKeyRepository) DeleteSensorKey(ctx context.Context, sensorID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSensorKey", ctx, sensorID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSensorKey indicates an expected call of DeleteSensorKey.
+func (mr *MockSensorKeyRepositoryMockRecorder) DeleteSensorKey(ctx, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSensorKey", reflect.TypeOf((*MockSensorKeyRepository)(nil).DeleteSensorKey), ctx, sensorID)
+}
+
+// GetSensorKey mocks base method.
+func (m *MockSensorKeyRepository) GetSensorKey(ctx context.Context, sensorID int64) (*domain.SensorKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSensorKey", ctx, sensorID)
+	ret0, _ := ret[0].(*domain.SensorKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSensorKey indicates an expected call of GetSensorKey.
+func (mr *MockSensorKeyRepositoryMockRecorder) GetSensorKey(ctx, sensorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSensorKey", reflect.TypeOf((*MockSensorKeyRepository)(nil).GetSensorKey), ctx, sensorID)
+}
+
+// SaveSensorKey mocks base method.
+func (m *MockSensorKeyRepository) SaveSensorKey(ctx context.Context, key domain.SensorKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSensorKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSensorKey indicates an expected call of SaveSensorKey.
+func (mr *MockSensorKeyRepositoryMockRecorder) SaveSensorKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSensorKey", reflect.TypeOf((*MockSensorKeyRepository)(nil).SaveSensorKey), ctx, key)
+}
+
+// MockHomeRepository is a mock of HomeRepository interface.
+type MockHomeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHomeRepositoryMockRecorder
+}
+
+// MockHomeRepositoryMockRecorder is the mock recorder for MockHomeRepository.
+type MockHomeRepositoryMockRecorder struct {
+	mock *MockHomeRepository
+}
+
+// NewMockHomeRepository creates a new mock instance.
+func NewMockHomeRepository(ctrl *gomock.Controller) *MockHomeRepository {
+	mock := &MockHomeRepository{ctrl: ctrl}
+	mock.recorder = &MockHomeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHomeRepository) EXPECT() *MockHomeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteHomeMember mocks base method.
+func (m *MockHomeRepository) DeleteHomeMember(ctx context.Context, homeID, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHomeMember", ctx, homeID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteHomeMember indicates an expected call of DeleteHomeMember.
+func (mr *MockHomeRepositoryMockRecorder) DeleteHomeMember(ctx, homeID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHomeMember", reflect.TypeOf((*MockHomeRepository)(nil).DeleteHomeMember), ctx, homeID, userID)
+}
+
+// GetHomeByID mocks base method.
+func (m *MockHomeRepository) GetHomeByID(ctx context.Context, id int64) (*domain.Home, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHomeByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Home)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHomeByID indicates an expected call of GetHomeByID.
+func (mr *MockHomeRepositoryMockRecorder) GetHomeByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHomeByID", reflect.TypeOf((*MockHomeRepository)(nil).GetHomeByID), ctx, id)
+}
+
+// GetHomeByIDForUpdate mocks base method.
+func (m *MockHomeRepository) GetHomeByIDForUpdate(ctx context.Context, id int64) (*domain.Home, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHomeByIDForUpdate", ctx, id)
+	ret0, _ := ret[0].(*domain.Home)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHomeByIDForUpdate indicates an expected call of GetHomeByIDForUpdate.
+func (mr *MockHomeRepositoryMockRecorder) GetHomeByIDForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHomeByIDForUpdate", reflect.TypeOf((*MockHomeRepository)(nil).GetHomeByIDForUpdate), ctx, id)
+}
+
+// GetHomeMembers mocks base method.
+func (m *MockHomeRepository) GetHomeMembers(ctx context.Context, homeID int64) ([]domain.HomeMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHomeMembers", ctx, homeID)
+	ret0, _ := ret[0].([]domain.HomeMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHomeMembers indicates an expected call of GetHomeMembers.
+func (mr *MockHomeRepositoryMockRecorder) GetHomeMembers(ctx, homeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHomeMembers", reflect.TypeOf((*MockHomeRepository)(nil).GetHomeMembers), ctx, homeID)
+}
+
+// GetHomes mocks base method.
+func (m *MockHomeRepository) GetHomes(ctx context.Context) ([]domain.Home, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHomes", ctx)
+	ret0, _ := ret[0].([]domain.Home)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHomes indicates an expected call of GetHomes.
+func (mr *MockHomeRepositoryMockRecorder) GetHomes(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHomes", reflect.TypeOf((*MockHomeRepository)(nil).GetHomes), ctx)
+}
+
+// GetHomesByUserID mocks base method.
+func (m *MockHomeRepository) GetHomesByUserID(ctx context.Context, userID int64) ([]domain.HomeMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHomesByUserID", ctx, userID)
+	ret0, _ := ret[0].([]domain.HomeMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHomesByUserID indicates an expected call of GetHomesByUserID.
+func (mr *MockHomeRepositoryMockRecorder) GetHomesByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHomesByUserID", reflect.TypeOf((*MockHomeRepository)(nil).GetHomesByUserID), ctx, userID)
+}
+
+// SaveHome mocks base method.
+func (m *MockHomeRepository) SaveHome(ctx context.Context, home *domain.Home) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveHome", ctx, home)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveHome indicates an expected call of SaveHome.
+func (mr *MockHomeRepositoryMockRecorder) SaveHome(ctx, home interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveHome", reflect.TypeOf((*MockHomeRepository)(nil).SaveHome), ctx, home)
+}
+
+// SaveHomeMember mocks base method.
+func (m *MockHomeRepository) SaveHomeMember(ctx context.Context, member domain.HomeMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveHomeMember", ctx, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveHomeMember indicates an expected call of SaveHomeMember.
+func (mr *MockHomeRepositoryMockRecorder) SaveHomeMember(ctx, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveHomeMember", reflect.TypeOf((*MockHomeRepository)(nil).SaveHomeMember), ctx, member)
+}
+
+// MockRoomRepository is a mock of RoomRepository interface.
+type MockRoomRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoomRepositoryMockRecorder
+}
+
+// MockRoomRepositoryMockRecorder is the mock recorder for MockRoomRepository.
+type MockRoomRepositoryMockRecorder struct {
+	mock *MockRoomRepository
+}
+
+// NewMockRoomRepository creates a new mock instance.
+func NewMockRoomRepository(ctrl *gomock.Controller) *MockRoomRepository {
+	mock := &MockRoomRepository{ctrl: ctrl}
+	mock.recorder = &MockRoomRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRoomRepository) EXPECT() *MockRoomRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteRoom mocks base method.
+func (m *MockRoomRepository) DeleteRoom(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRoom", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRoom indicates an expected call of DeleteRoom.
+func (mr *MockRoomRepositoryMockRecorder) DeleteRoom(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoom", reflect.TypeOf((*MockRoomRepository)(nil).DeleteRoom), ctx, id)
+}
+
+// GetRoomByID mocks base method.
+func (m *MockRoomRepository) GetRoomByID(ctx context.Context, id int64) (*domain.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoomByID indicates an expected call of GetRoomByID.
+func (mr *MockRoomRepositoryMockRecorder) GetRoomByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomByID", reflect.TypeOf((*MockRoomRepository)(nil).GetRoomByID), ctx, id)
+}
+
+// GetRoomsByHomeID mocks base method.
+func (m *MockRoomRepository) GetRoomsByHomeID(ctx context.Context, homeID int64) ([]domain.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomsByHomeID", ctx, homeID)
+	ret0, _ := ret[0].([]domain.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoomsByHomeID indicates an expected call of GetRoomsByHomeID.
+func (mr *MockRoomRepositoryMockRecorder) GetRoomsByHomeID(ctx, homeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomsByHomeID", reflect.TypeOf((*MockRoomRepository)(nil).GetRoomsByHomeID), ctx, homeID)
+}
+
+// SaveRoom mocks base method.
+func (m *MockRoomRepository) SaveRoom(ctx context.Context, room *domain.Room) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRoom", ctx, room)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRoom indicates an expected call of SaveRoom.
+func (mr *MockRoomRepositoryMockRecorder) SaveRoom(ctx, room interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRoom", reflect.TypeOf((*MockRoomRepository)(nil).SaveRoom), ctx, room)
+}
+
+// MockTransactor is a mock of Transactor interface.
+type MockTransactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactorMockRecorder
+}
+
+// MockTransactorMockRecorder is the mock recorder for MockTransactor.
+type MockTransactorMockRecorder struct {
+	mock *MockTransactor
+}
+
+// NewMockTransactor creates a new mock instance.
+func NewMockTransactor(ctrl *gomock.Controller) *MockTransactor {
+	mock := &MockTransactor{ctrl: ctrl}
+	mock.recorder = &MockTransactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactor) EXPECT() *MockTransactorMockRecorder {
+	return m.recorder
+}
+
+// WithinTransaction mocks base method.
+func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTransaction indicates an expected call of WithinTransaction.
+func (mr *MockTransactorMockRecorder) WithinTransaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTransaction", reflect.TypeOf((*MockTransactor)(nil).WithinTransaction), ctx, fn)
+}
@@ -6,47 +6,112 @@ import (
 	"homework/internal/domain"
 )
 
+const minPasswordLength = 8
+
 type User struct {
 	ur  UserRepository
 	sor SensorOwnerRepository
 	sr  SensorRepository
+	hr  HomeRepository
+	tr  Transactor
 }
 
-func NewUser(ur UserRepository, sor SensorOwnerRepository, sr SensorRepository) *User {
+func NewUser(ur UserRepository, sor SensorOwnerRepository, sr SensorRepository, hr HomeRepository, tr Transactor) *User {
 	return &User{
 		ur:  ur,
 		sor: sor,
 		sr:  sr,
+		hr:  hr,
+		tr:  tr,
 	}
 }
 
-func (u *User) RegisterUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+// RegisterUser - регистрирует пользователя. Проверка логина, пользователь и его учётные данные
+// сохраняются в одной транзакции, чтобы не остался пользователь без пароля.
+func (u *User) RegisterUser(ctx context.Context, user *domain.User, password string) (*domain.User, error) {
 	if user == nil {
 		return nil, errors.New("nil user")
 	}
 	if user.Name == "" {
 		return nil, ErrInvalidUserName
 	}
-	if err := u.ur.SaveUser(ctx, user); err != nil {
+	if len(password) < minPasswordLength {
+		return nil, ErrInvalidPassword
+	}
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	err = u.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := u.ur.GetCredentialsByLogin(ctx, user.Name)
+		if err == nil {
+			return ErrUserAlreadyExists
+		}
+		if !errors.Is(err, ErrUserNotFound) {
+			return err
+		}
+		if err := u.ur.SaveUser(ctx, user); err != nil {
+			return err
+		}
+		return u.ur.SaveCredentials(ctx, domain.Credentials{
+			UserID:       user.ID,
+			Login:        user.Name,
+			PasswordHash: passwordHash,
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 	return user, nil
 }
 
-func (u *User) AttachSensorToUser(ctx context.Context, userID, sensorID int64) error {
+// AttachSensorToUser - выдаёт пользователю доступ к датчику с указанной ролью.
+// Выдавать доступ может только владелец датчика. Существующая привязка не меняется,
+// роль меняется через SetSensorAccess. Проверка существующей привязки и сохранение новой выполняются в одной транзакции.
+func (u *User) AttachSensorToUser(ctx context.Context, userID, sensorID int64, role domain.SensorRole) error {
+	if !isValidSensorRole(role) {
+		return ErrWrongSensorRole
+	}
 	if _, err := u.ur.GetUserByID(ctx, userID); err != nil {
 		return err
 	}
-	if _, err := u.sr.GetSensorByID(ctx, sensorID); err != nil {
+	sensor, err := u.sr.GetSensorByID(ctx, sensorID)
+	if err != nil {
+		return err
+	}
+	if err := u.checkBindingAccess(ctx, userID, sensor); err != nil {
 		return err
 	}
-	return u.sor.SaveSensorOwner(ctx, domain.SensorOwner{
-		UserID:   userID,
-		SensorID: sensorID,
+	return u.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, bound, err := getSensorRole(ctx, u.sor, userID, sensorID)
+		if err != nil {
+			return err
+		}
+		if bound {
+			return nil
+		}
+		return u.sor.SaveSensorOwner(ctx, domain.SensorOwner{
+			UserID:   userID,
+			SensorID: sensorID,
+			Role:     role,
+		})
 	})
 }
 
+// checkBindingAccess - со своей привязкой пользователь может работать с любой ролью,
+// а с привязками других пользователей - только владелец датчика
+func (u *User) checkBindingAccess(ctx context.Context, userID int64, sensor *domain.Sensor) error {
+	required := domain.SensorRoleOwner
+	if callerID, ok := CallerFromContext(ctx); ok && callerID == userID {
+		required = domain.SensorRoleViewer
+	}
+	return checkSensorAccess(ctx, u.sor, u.hr, sensor, required)
+}
+
 func (u *User) GetUserSensors(ctx context.Context, userID int64) ([]domain.Sensor, error) {
+	if err := checkUserAccess(ctx, userID); err != nil {
+		return nil, err
+	}
 	if _, err := u.ur.GetUserByID(ctx, userID); err != nil {
 		return nil, err
 	}
@@ -64,3 +129,123 @@ func (u *User) GetUserSensors(ctx context.Context, userID int64) ([]domain.Senso
 	}
 	return sensors, err
 }
+
+// GetUsers - возвращает список пользователей. Обычному пользователю виден только он сам,
+// администратор и внутренние вызовы получают всех пользователей.
+func (u *User) GetUsers(ctx context.Context) ([]domain.User, error) {
+	userID, ok := restrictedCaller(ctx)
+	if !ok {
+		return u.ur.GetUsers(ctx)
+	}
+	user, err := u.ur.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return []domain.User{*user}, nil
+}
+
+func (u *User) GetUserByID(ctx context.Context, userID int64) (*domain.User, error) {
+	if err := checkUserAccess(ctx, userID); err != nil {
+		return nil, err
+	}
+	return u.ur.GetUserByID(ctx, userID)
+}
+
+// UpdateUser - меняет имя пользователя. Логин остаётся прежним, чтобы не ломать вход.
+func (u *User) UpdateUser(ctx context.Context, userID int64, update domain.UserUpdate) (*domain.User, error) {
+	if update.Name != nil && *update.Name == "" {
+		return nil, ErrInvalidUserName
+	}
+	user, err := u.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if update.Name != nil {
+		user.Name = *update.Name
+	}
+	if err := u.ur.SaveUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteUser - удаляет пользователя вместе с его привязками к датчикам и участием в домах.
+// Если пользователь - последний владелец датчика или дома, к которому есть доступ у других,
+// удаление отклоняется: сначала надо передать права владельца.
+// Проверки и удаление выполняются в одной транзакции, чтобы не остался пользователь с частью привязок.
+func (u *User) DeleteUser(ctx context.Context, userID int64) error {
+	if _, err := u.GetUserByID(ctx, userID); err != nil {
+		return err
+	}
+	return u.tr.WithinTransaction(ctx, func(ctx context.Context) error {
+		return u.deleteUser(ctx, userID)
+	})
+}
+
+// deleteUser - удаляет пользователя, его привязки к датчикам и участие в домах
+func (u *User) deleteUser(ctx context.Context, userID int64) error {
+	sensorOwners, err := u.sor.GetSensorsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, sensorOwner := range sensorOwners {
+		if sensorOwner.Role != domain.SensorRoleOwner {
+			continue
+		}
+		bindings, err := u.sor.GetSensorOwners(ctx, sensorOwner.SensorID)
+		if err != nil {
+			return err
+		}
+		roles := make(map[int64]domain.SensorRole, len(bindings))
+		for _, binding := range bindings {
+			roles[binding.UserID] = binding.Role
+		}
+		if isLastSharedOwner(userID, roles) {
+			return ErrLastSensorOwner
+		}
+	}
+	homeMembers, err := u.hr.GetHomesByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, homeMember := range homeMembers {
+		if homeMember.Role != domain.SensorRoleOwner {
+			continue
+		}
+		members, err := u.hr.GetHomeMembers(ctx, homeMember.HomeID)
+		if err != nil {
+			return err
+		}
+		roles := make(map[int64]domain.SensorRole, len(members))
+		for _, member := range members {
+			roles[member.UserID] = member.Role
+		}
+		if isLastSharedOwner(userID, roles) {
+			return ErrLastHomeOwner
+		}
+	}
+	for _, sensorOwner := range sensorOwners {
+		if err := u.sor.DeleteSensorOwner(ctx, userID, sensorOwner.SensorID); err != nil {
+			return err
+		}
+	}
+	for _, homeMember := range homeMembers {
+		if err := u.hr.DeleteHomeMember(ctx, homeMember.HomeID, userID); err != nil {
+			return err
+		}
+	}
+	return u.ur.DeleteUser(ctx, userID)
+}
+
+// isLastSharedOwner - проверяет, что userID единственный владелец, а кроме него доступ есть у других
+func isLastSharedOwner(userID int64, roles map[int64]domain.SensorRole) bool {
+	if len(roles) < 2 {
+		return false
+	}
+	for id, role := range roles {
+		if id != userID && role == domain.SensorRoleOwner {
+			return false
+		}
+	}
+	return true
+}
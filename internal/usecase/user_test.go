@@ -18,25 +18,54 @@ func Test_user_RegisterUser(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
-		u := NewUser(nil, nil, nil)
+		u := NewUser(nil, nil, nil, nil, newTransactor(ctrl))
 
-		_, err := u.RegisterUser(ctx, &domain.User{})
+		_, err := u.RegisterUser(ctx, &domain.User{}, "password")
 		assert.ErrorIs(t, err, ErrInvalidUserName)
 	})
 
+	t.Run("fail, password too short", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		u := NewUser(nil, nil, nil, nil, newTransactor(ctrl))
+
+		_, err := u.RegisterUser(ctx, &domain.User{
+			Name: "Homer Simpson",
+		}, "donut")
+		assert.ErrorIs(t, err, ErrInvalidPassword)
+	})
+
+	t.Run("fail, login already taken", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetCredentialsByLogin(ctx, "Homer Simpson").Times(1).Return(&domain.Credentials{UserID: 1}, nil)
+		ur.EXPECT().SaveUser(ctx, gomock.Any()).Times(0)
+
+		u := NewUser(ur, nil, nil, nil, newTransactor(ctrl))
+
+		_, err := u.RegisterUser(ctx, &domain.User{
+			Name: "Homer Simpson",
+		}, "password")
+		assert.ErrorIs(t, err, ErrUserAlreadyExists)
+	})
+
 	t.Run("fail, repo fail", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
 		ur := NewMockUserRepository(ctrl)
 		expectedError := errors.New("doh")
+		ur.EXPECT().GetCredentialsByLogin(ctx, gomock.Any()).Times(1).Return(nil, ErrUserNotFound)
 		ur.EXPECT().SaveUser(ctx, gomock.Any()).Times(1).Return(expectedError)
 
-		u := NewUser(ur, nil, nil)
+		u := NewUser(ur, nil, nil, nil, newTransactor(ctrl))
 
 		_, err := u.RegisterUser(ctx, &domain.User{
 			Name: "Homer Simpson",
-		})
+		}, "password")
 		assert.ErrorIs(t, err, expectedError)
 	})
 
@@ -45,19 +74,51 @@ func Test_user_RegisterUser(t *testing.T) {
 		defer cancel()
 
 		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetCredentialsByLogin(ctx, "Homer Simpson").Times(1).Return(nil, ErrUserNotFound)
 		ur.EXPECT().SaveUser(ctx, gomock.Any()).Times(1).Do(func(_ context.Context, u *domain.User) {
 			assert.Equal(t, "Homer Simpson", u.Name)
 			u.ID = 1
 		})
+		ur.EXPECT().SaveCredentials(ctx, gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, c domain.Credentials) error {
+			assert.Equal(t, int64(1), c.UserID)
+			assert.Equal(t, "Homer Simpson", c.Login)
+			assert.NotEqual(t, []byte("password"), c.PasswordHash)
+			return nil
+		})
 
-		u := NewUser(ur, nil, nil)
+		u := NewUser(ur, nil, nil, nil, newTransactor(ctrl))
 
 		user, err := u.RegisterUser(ctx, &domain.User{
 			Name: "Homer Simpson",
-		})
+		}, "password")
 		assert.NoError(t, err)
 		assert.Equal(t, int64(1), user.ID)
 	})
+	t.Run("fail, user and credentials saved in one transaction", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		txCtx := context.WithValue(ctx, struct{}{}, "tx")
+		someErr := errors.New("some error")
+
+		tr := NewMockTransactor(ctrl)
+		tr.EXPECT().WithinTransaction(ctx, gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, fn func(ctx context.Context) error) error {
+				return fn(txCtx)
+			})
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetCredentialsByLogin(txCtx, "Homer Simpson").Times(1).Return(nil, ErrUserNotFound)
+		ur.EXPECT().SaveUser(txCtx, gomock.Any()).Times(1).Return(nil)
+		ur.EXPECT().SaveCredentials(txCtx, gomock.Any()).Times(1).Return(someErr)
+
+		u := NewUser(ur, nil, nil, nil, tr)
+
+		user, err := u.RegisterUser(ctx, &domain.User{
+			Name: "Homer Simpson",
+		}, "password")
+		assert.ErrorIs(t, err, someErr)
+		assert.Nil(t, user)
+	})
 }
 
 func Test_user_AttachSensorToUser(t *testing.T) {
@@ -71,9 +132,9 @@ func Test_user_AttachSensorToUser(t *testing.T) {
 		ur := NewMockUserRepository(ctrl)
 		ur.EXPECT().GetUserByID(ctx, gomock.Any()).Times(1).Return(nil, ErrUserNotFound)
 
-		u := NewUser(ur, nil, nil)
+		u := NewUser(ur, nil, nil, nil, newTransactor(ctrl))
 
-		err := u.AttachSensorToUser(ctx, 1, 1)
+		err := u.AttachSensorToUser(ctx, 1, 1, domain.SensorRoleViewer)
 		assert.ErrorIs(t, err, ErrUserNotFound)
 	})
 
@@ -87,9 +148,9 @@ func Test_user_AttachSensorToUser(t *testing.T) {
 		sr := NewMockSensorRepository(ctrl)
 		sr.EXPECT().GetSensorByID(ctx, gomock.Any()).Times(1).Return(nil, ErrSensorNotFound)
 
-		u := NewUser(ur, nil, sr)
+		u := NewUser(ur, nil, sr, nil, newTransactor(ctrl))
 
-		err := u.AttachSensorToUser(ctx, 1, 1)
+		err := u.AttachSensorToUser(ctx, 1, 1, domain.SensorRoleViewer)
 		assert.ErrorIs(t, err, ErrSensorNotFound)
 	})
 
@@ -105,11 +166,12 @@ func Test_user_AttachSensorToUser(t *testing.T) {
 
 		sor := NewMockSensorOwnerRepository(ctrl)
 		expectedError := errors.New("some error")
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(1).Return(nil, nil)
 		sor.EXPECT().SaveSensorOwner(ctx, gomock.Any()).Times(1).Return(expectedError)
 
-		u := NewUser(ur, sor, sr)
+		u := NewUser(ur, sor, sr, nil, newTransactor(ctrl))
 
-		err := u.AttachSensorToUser(ctx, 1, 1)
+		err := u.AttachSensorToUser(ctx, 1, 1, domain.SensorRoleViewer)
 		assert.ErrorIs(t, err, expectedError)
 	})
 
@@ -124,14 +186,111 @@ func Test_user_AttachSensorToUser(t *testing.T) {
 		sr.EXPECT().GetSensorByID(ctx, gomock.Any()).Times(1).Return(&domain.Sensor{ID: 1}, nil)
 
 		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(1).Return(nil, nil)
 		sor.EXPECT().SaveSensorOwner(ctx, gomock.Any()).Times(1).Return(nil).Do(func(_ context.Context, o domain.SensorOwner) {
 			assert.Equal(t, int64(1), o.UserID)
 			assert.Equal(t, int64(1), o.SensorID)
+			assert.Equal(t, domain.SensorRoleViewer, o.Role)
 		})
 
-		u := NewUser(ur, sor, sr)
+		u := NewUser(ur, sor, sr, nil, newTransactor(ctrl))
 
-		err := u.AttachSensorToUser(ctx, 1, 1)
+		err := u.AttachSensorToUser(ctx, 1, 1, domain.SensorRoleViewer)
+		assert.NoError(t, err)
+	})
+
+	t.Run("ok, already attached", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, gomock.Any()).Times(1).Return(&domain.User{ID: 1}, nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, gomock.Any()).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(1).Return([]domain.SensorOwner{{UserID: 1, SensorID: 1}}, nil)
+		sor.EXPECT().SaveSensorOwner(ctx, gomock.Any()).Times(0)
+
+		u := NewUser(ur, sor, sr, nil, newTransactor(ctrl))
+
+		err := u.AttachSensorToUser(ctx, 1, 1, domain.SensorRoleViewer)
+		assert.NoError(t, err)
+	})
+
+	t.Run("fail, caller has no access to sensor", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 2))
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, gomock.Any()).Times(1).Return(&domain.User{ID: 1}, nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, gomock.Any()).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Times(1).Return([]domain.SensorOwner{{UserID: 2, SensorID: 5}}, nil)
+		sor.EXPECT().SaveSensorOwner(ctx, gomock.Any()).Times(0)
+
+		u := NewUser(ur, sor, sr, nil, newTransactor(ctrl))
+
+		err := u.AttachSensorToUser(ctx, 1, 1, domain.SensorRoleViewer)
+		assert.ErrorIs(t, err, ErrSensorNotFound)
+	})
+
+	t.Run("fail, wrong role", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		u := NewUser(nil, nil, nil, nil, newTransactor(ctrl))
+
+		err := u.AttachSensorToUser(ctx, 1, 1, "admin")
+		assert.ErrorIs(t, err, ErrWrongSensorRole)
+	})
+
+	t.Run("fail, member cannot share", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 2))
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, gomock.Any()).Times(1).Return(&domain.User{ID: 1}, nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, gomock.Any()).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Times(1).Return([]domain.SensorOwner{
+			{UserID: 2, SensorID: 1, Role: domain.SensorRoleMember},
+		}, nil)
+		sor.EXPECT().SaveSensorOwner(ctx, gomock.Any()).Times(0)
+
+		u := NewUser(ur, sor, sr, nil, newTransactor(ctrl))
+
+		err := u.AttachSensorToUser(ctx, 1, 1, domain.SensorRoleViewer)
+		assert.ErrorIs(t, err, ErrSensorAccessDenied)
+	})
+
+	t.Run("ok, owner shares", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 2))
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, gomock.Any()).Times(1).Return(&domain.User{ID: 1}, nil)
+
+		sr := NewMockSensorRepository(ctrl)
+		sr.EXPECT().GetSensorByID(ctx, gomock.Any()).Times(1).Return(&domain.Sensor{ID: 1}, nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(2)).Times(1).Return([]domain.SensorOwner{
+			{UserID: 2, SensorID: 1, Role: domain.SensorRoleOwner},
+		}, nil)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(1)).Times(1).Return(nil, nil)
+		sor.EXPECT().SaveSensorOwner(ctx, domain.SensorOwner{UserID: 1, SensorID: 1, Role: domain.SensorRoleMember}).Times(1).Return(nil)
+
+		u := NewUser(ur, sor, sr, nil, newTransactor(ctrl))
+
+		err := u.AttachSensorToUser(ctx, 1, 1, domain.SensorRoleMember)
 		assert.NoError(t, err)
 	})
 }
@@ -140,6 +299,19 @@ func Test_user_GetUserSensors(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	t.Run("fail, another user", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 2))
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, gomock.Any()).Times(0)
+
+		u := NewUser(ur, nil, nil, nil, newTransactor(ctrl))
+
+		_, err := u.GetUserSensors(ctx, 1)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
 	t.Run("fail, user not found", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -147,7 +319,7 @@ func Test_user_GetUserSensors(t *testing.T) {
 		ur := NewMockUserRepository(ctrl)
 		ur.EXPECT().GetUserByID(ctx, gomock.Any()).Times(1).Return(nil, ErrUserNotFound)
 
-		u := NewUser(ur, nil, nil)
+		u := NewUser(ur, nil, nil, nil, newTransactor(ctrl))
 
 		_, err := u.GetUserSensors(ctx, 1)
 		assert.ErrorIs(t, err, ErrUserNotFound)
@@ -164,7 +336,7 @@ func Test_user_GetUserSensors(t *testing.T) {
 		expectedError := errors.New("some error")
 		sor.EXPECT().GetSensorsByUserID(ctx, gomock.Any()).Times(1).Return(nil, expectedError)
 
-		u := NewUser(ur, sor, nil)
+		u := NewUser(ur, sor, nil, nil, newTransactor(ctrl))
 
 		_, err := u.GetUserSensors(ctx, 1)
 		assert.ErrorIs(t, err, expectedError)
@@ -189,7 +361,7 @@ func Test_user_GetUserSensors(t *testing.T) {
 		expectedError := errors.New("some error")
 		sr.EXPECT().GetSensorByID(ctx, gomock.Any()).Times(1).Return(nil, expectedError)
 
-		u := NewUser(ur, sor, sr)
+		u := NewUser(ur, sor, sr, nil, newTransactor(ctrl))
 
 		_, err := u.GetUserSensors(ctx, 1)
 		assert.ErrorIs(t, err, expectedError)
@@ -223,10 +395,191 @@ func Test_user_GetUserSensors(t *testing.T) {
 		sr.EXPECT().GetSensorByID(ctx, int64(2)).Times(1).Return(&domain.Sensor{ID: 2, Type: domain.SensorTypeContactClosure}, nil)
 		sr.EXPECT().GetSensorByID(ctx, int64(3)).Times(1).Return(&domain.Sensor{ID: 3, Type: domain.SensorTypeContactClosure}, nil)
 
-		u := NewUser(ur, sor, sr)
+		u := NewUser(ur, sor, sr, nil, newTransactor(ctrl))
 
 		sensors, err := u.GetUserSensors(ctx, 1)
 		assert.NoError(t, err)
 		assert.Len(t, sensors, 3)
 	})
 }
+
+func Test_user_GetUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, user sees only himself", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(7)).Times(1).Return(&domain.User{ID: 7, Name: "Иван"}, nil)
+		ur.EXPECT().GetUsers(ctx).Times(0)
+
+		u := NewUser(ur, nil, nil, nil, newTransactor(ctrl))
+
+		users, err := u.GetUsers(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.User{{ID: 7, Name: "Иван"}}, users)
+	})
+
+	t.Run("ok, admin sees everyone", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithAdmin(context.Background(), 7))
+		defer cancel()
+
+		expected := []domain.User{{ID: 1}, {ID: 7, IsAdmin: true}}
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUsers(ctx).Times(1).Return(expected, nil)
+
+		u := NewUser(ur, nil, nil, nil, newTransactor(ctrl))
+
+		users, err := u.GetUsers(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, users)
+	})
+}
+
+func Test_user_UpdateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, rename", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(7)).Times(1).Return(&domain.User{ID: 7, Name: "Иван"}, nil)
+		ur.EXPECT().SaveUser(ctx, &domain.User{ID: 7, Name: "Пётр"}).Times(1).Return(nil)
+
+		u := NewUser(ur, nil, nil, nil, newTransactor(ctrl))
+
+		name := "Пётр"
+		user, err := u.UpdateUser(ctx, 7, domain.UserUpdate{Name: &name})
+		assert.NoError(t, err)
+		assert.Equal(t, "Пётр", user.Name)
+	})
+
+	t.Run("err, empty name", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		u := NewUser(nil, nil, nil, nil, newTransactor(ctrl))
+
+		name := ""
+		_, err := u.UpdateUser(ctx, 7, domain.UserUpdate{Name: &name})
+		assert.ErrorIs(t, err, ErrInvalidUserName)
+	})
+
+	t.Run("err, another user", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().SaveUser(ctx, gomock.Any()).Times(0)
+
+		u := NewUser(ur, nil, nil, nil, newTransactor(ctrl))
+
+		name := "Пётр"
+		_, err := u.UpdateUser(ctx, 8, domain.UserUpdate{Name: &name})
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func Test_user_DeleteUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("ok, bindings and memberships removed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(7)).Times(1).Return(&domain.User{ID: 7}, nil)
+		ur.EXPECT().DeleteUser(ctx, int64(7)).Times(1).Return(nil)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Times(1).Return([]domain.SensorOwner{
+			{UserID: 7, SensorID: 1, Role: domain.SensorRoleOwner},
+			{UserID: 7, SensorID: 2, Role: domain.SensorRoleViewer},
+		}, nil)
+		sor.EXPECT().GetSensorOwners(ctx, int64(1)).Times(1).Return([]domain.SensorOwner{
+			{UserID: 7, SensorID: 1, Role: domain.SensorRoleOwner},
+		}, nil)
+		sor.EXPECT().DeleteSensorOwner(ctx, int64(7), int64(1)).Times(1).Return(nil)
+		sor.EXPECT().DeleteSensorOwner(ctx, int64(7), int64(2)).Times(1).Return(nil)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomesByUserID(ctx, int64(7)).Times(1).Return([]domain.HomeMember{
+			{HomeID: 3, UserID: 7, Role: domain.SensorRoleMember},
+		}, nil)
+		hr.EXPECT().DeleteHomeMember(ctx, int64(3), int64(7)).Times(1).Return(nil)
+
+		u := NewUser(ur, sor, nil, hr, newTransactor(ctrl))
+
+		assert.NoError(t, u.DeleteUser(ctx, 7))
+	})
+
+	t.Run("fail, bindings and user deleted in one transaction", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+		txCtx := context.WithValue(ctx, struct{}{}, "tx")
+		someErr := errors.New("some error")
+
+		tr := NewMockTransactor(ctrl)
+		tr.EXPECT().WithinTransaction(ctx, gomock.Any()).Times(1).DoAndReturn(
+			func(_ context.Context, fn func(ctx context.Context) error) error {
+				return fn(txCtx)
+			})
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(7)).Times(1).Return(&domain.User{ID: 7}, nil)
+		ur.EXPECT().DeleteUser(txCtx, int64(7)).Times(1).Return(someErr)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(txCtx, int64(7)).Times(1).Return([]domain.SensorOwner{
+			{UserID: 7, SensorID: 2, Role: domain.SensorRoleViewer},
+		}, nil)
+		sor.EXPECT().DeleteSensorOwner(txCtx, int64(7), int64(2)).Times(1).Return(nil)
+
+		hr := NewMockHomeRepository(ctrl)
+		hr.EXPECT().GetHomesByUserID(txCtx, int64(7)).Times(1).Return(nil, nil)
+
+		u := NewUser(ur, sor, nil, hr, tr)
+
+		assert.ErrorIs(t, u.DeleteUser(ctx, 7), someErr)
+	})
+
+	t.Run("err, last owner of shared sensor", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(WithCaller(context.Background(), 7))
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(7)).Times(1).Return(&domain.User{ID: 7}, nil)
+		ur.EXPECT().DeleteUser(ctx, gomock.Any()).Times(0)
+
+		sor := NewMockSensorOwnerRepository(ctrl)
+		sor.EXPECT().GetSensorsByUserID(ctx, int64(7)).Times(1).Return([]domain.SensorOwner{
+			{UserID: 7, SensorID: 1, Role: domain.SensorRoleOwner},
+		}, nil)
+		sor.EXPECT().GetSensorOwners(ctx, int64(1)).Times(1).Return([]domain.SensorOwner{
+			{UserID: 7, SensorID: 1, Role: domain.SensorRoleOwner},
+			{UserID: 8, SensorID: 1, Role: domain.SensorRoleViewer},
+		}, nil)
+		sor.EXPECT().DeleteSensorOwner(ctx, gomock.Any(), gomock.Any()).Times(0)
+
+		u := NewUser(ur, sor, nil, nil, newTransactor(ctrl))
+
+		assert.ErrorIs(t, u.DeleteUser(ctx, 7), ErrLastSensorOwner)
+	})
+
+	t.Run("err, user not found", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ur := NewMockUserRepository(ctrl)
+		ur.EXPECT().GetUserByID(ctx, int64(7)).Times(1).Return(nil, ErrUserNotFound)
+
+		u := NewUser(ur, nil, nil, nil, newTransactor(ctrl))
+
+		assert.ErrorIs(t, u.DeleteUser(ctx, 7), ErrUserNotFound)
+	})
+}
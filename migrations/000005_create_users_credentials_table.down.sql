@@ -0,0 +1 @@
+drop table users_credentials;
\ No newline at end of file
@@ -0,0 +1,6 @@
+create table users_credentials
+(
+    user_id       bigint  not null,
+    login         text    not null unique,
+    password_hash bytea   not null
+);
@@ -0,0 +1 @@
+drop table sensors_keys;
\ No newline at end of file
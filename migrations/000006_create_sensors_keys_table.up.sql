@@ -0,0 +1,6 @@
+create table sensors_keys
+(
+    sensor_id   bigint      not null primary key,
+    key_hash    bytea       not null,
+    created_at  timestamp   not null
+);
@@ -0,0 +1,3 @@
+alter table sensors_users drop column role;
+
+drop type sensor_role;
\ No newline at end of file
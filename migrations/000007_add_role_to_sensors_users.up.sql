@@ -0,0 +1,4 @@
+create type sensor_role as enum ('owner', 'member', 'viewer');
+
+alter table sensors_users
+    add column role sensor_role not null default 'owner';
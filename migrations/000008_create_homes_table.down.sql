@@ -0,0 +1 @@
+drop table homes;
\ No newline at end of file
@@ -0,0 +1,6 @@
+create table homes
+(
+    id          bigserial   not null,
+    name        text        not null,
+    created_at  timestamp   not null
+);
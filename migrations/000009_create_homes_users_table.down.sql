@@ -0,0 +1 @@
+drop table homes_users;
\ No newline at end of file
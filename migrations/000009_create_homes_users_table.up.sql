@@ -0,0 +1,7 @@
+create table homes_users
+(
+    home_id bigint      not null,
+    user_id bigint      not null,
+    role    sensor_role not null,
+    primary key (home_id, user_id)
+);
@@ -0,0 +1,3 @@
+alter table events drop column home_id;
+
+alter table sensors drop column home_id;
\ No newline at end of file
@@ -0,0 +1,5 @@
+alter table sensors
+    add column home_id bigint;
+
+alter table events
+    add column home_id bigint;
@@ -0,0 +1,2 @@
+alter table users
+    add column is_admin boolean not null default false;
@@ -0,0 +1,3 @@
+alter table sensors drop column room_id;
+
+drop table rooms;
\ No newline at end of file
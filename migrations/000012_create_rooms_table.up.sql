@@ -0,0 +1,10 @@
+create table rooms
+(
+    id          bigserial   not null,
+    home_id     bigint      not null,
+    name        text        not null,
+    created_at  timestamp   not null
+);
+
+alter table sensors
+    add column room_id bigint;
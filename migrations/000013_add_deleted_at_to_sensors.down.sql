@@ -0,0 +1 @@
+alter table sensors drop column deleted_at;
\ No newline at end of file
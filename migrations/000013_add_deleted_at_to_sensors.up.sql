@@ -0,0 +1,2 @@
+alter table sensors
+    add column deleted_at timestamp;
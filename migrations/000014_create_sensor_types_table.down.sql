@@ -0,0 +1,8 @@
+create type sensor_type as enum ('cc', 'adc');
+
+delete from sensors where type not in ('cc', 'adc');
+
+alter table sensors
+    alter column type type sensor_type using type::sensor_type;
+
+drop table sensor_types;
\ No newline at end of file
@@ -0,0 +1,24 @@
+create table sensor_types
+(
+    type        text        not null primary key,
+    name        text        not null,
+    value_kind  text        not null,
+    min_value   double precision not null,
+    max_value   double precision not null,
+    unit        text        not null default ''
+);
+
+insert into sensor_types (type, name, value_kind, min_value, max_value, unit)
+values ('cc', 'Сухой контакт', 'integer', -9223372036854775808, 9223372036854775807, ''),
+       ('adc', 'АЦП', 'integer', -9223372036854775808, 9223372036854775807, ''),
+       ('temperature', 'Температура', 'integer', -60, 150, '°C'),
+       ('humidity', 'Влажность', 'integer', 0, 100, '%'),
+       ('motion', 'Движение', 'binary', 0, 1, ''),
+       ('co2', 'Углекислый газ', 'integer', 0, 10000, 'ppm'),
+       ('power_meter', 'Счётчик электроэнергии', 'integer', 0, 9223372036854775807, 'Вт·ч'),
+       ('door_lock', 'Дверной замок', 'binary', 0, 1, '');
+
+alter table sensors
+    alter column type type text using type::text;
+
+drop type sensor_type;
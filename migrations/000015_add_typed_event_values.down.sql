@@ -0,0 +1,11 @@
+delete from sensor_types where value_kind not in ('binary', 'integer', 'float');
+
+update sensor_types
+set value_kind = 'integer'
+where value_kind = 'float';
+
+alter table sensor_types drop column channels;
+
+alter table sensors drop column current_value;
+
+alter table events drop column value;
\ No newline at end of file
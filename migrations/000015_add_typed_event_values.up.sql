@@ -0,0 +1,17 @@
+alter table events
+    add column value jsonb;
+
+alter table sensors
+    add column current_value jsonb;
+
+alter table sensor_types
+    add column channels jsonb;
+
+update sensor_types
+set value_kind = 'float'
+where type in ('adc', 'temperature', 'humidity');
+
+insert into sensor_types (type, name, value_kind, min_value, max_value, unit, channels)
+values ('climate', 'Климат', 'channels', 0, 0, '',
+        '[{"Name": "temp", "ValueKind": "float", "MinValue": -60, "MaxValue": 150, "Unit": "°C"},
+          {"Name": "rh", "ValueKind": "float", "MinValue": 0, "MaxValue": 100, "Unit": "%"}]');
@@ -0,0 +1,8 @@
+alter table events
+    drop column unit,
+    drop column calibrated_value;
+
+alter table sensors
+    drop column calibrated_state,
+    drop column calibration,
+    drop column unit;
\ No newline at end of file
@@ -0,0 +1,8 @@
+alter table sensors
+    add column unit text not null default '',
+    add column calibration jsonb,
+    add column calibrated_state double precision;
+
+alter table events
+    add column calibrated_value double precision,
+    add column unit text not null default '';
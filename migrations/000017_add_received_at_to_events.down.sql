@@ -0,0 +1,2 @@
+alter table events
+    drop column received_at;
\ No newline at end of file
@@ -0,0 +1,8 @@
+alter table events
+    add column received_at timestamp;
+
+update events
+set received_at = timestamp;
+
+alter table events
+    alter column received_at set not null;
@@ -0,0 +1,4 @@
+drop index events_sensor_id_event_id_idx;
+
+alter table events
+    drop column event_id;
\ No newline at end of file
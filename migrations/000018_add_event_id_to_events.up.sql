@@ -0,0 +1,6 @@
+alter table events
+    add column event_id text;
+
+create index events_sensor_id_event_id_idx
+    on events (sensor_id, event_id)
+    where event_id is not null;
@@ -0,0 +1,4 @@
+drop index events_sensor_id_timestamp_id_idx;
+
+alter table events
+    drop column id;
\ No newline at end of file
@@ -0,0 +1,5 @@
+alter table events
+    add column id bigserial primary key;
+
+create index events_sensor_id_timestamp_id_idx
+    on events (sensor_id, timestamp, id);
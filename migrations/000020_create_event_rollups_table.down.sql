@@ -0,0 +1 @@
+drop table event_rollups;
\ No newline at end of file
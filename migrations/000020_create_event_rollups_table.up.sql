@@ -0,0 +1,43 @@
+create table event_rollups
+(
+    sensor_id       bigint              not null,
+    resolution      bigint              not null,
+    bucket          timestamp           not null,
+    home_id         bigint              not null,
+    count           bigint              not null,
+    sum             double precision    not null,
+    min             double precision    not null,
+    max             double precision    not null,
+    first_timestamp timestamp           not null,
+    first_value     double precision    not null,
+    last_timestamp  timestamp           not null,
+    last_value      double precision    not null,
+    primary key (sensor_id, resolution, bucket, home_id)
+);
+
+insert into event_rollups (sensor_id, resolution, bucket, home_id, count, sum, min, max,
+                           first_timestamp, first_value, last_timestamp, last_value)
+select sensor_id,
+       r.resolution,
+       date_bin(make_interval(secs => r.resolution), timestamp, timestamp '2000-01-01'),
+       home_id,
+       count(*),
+       sum(value),
+       min(value),
+       max(value),
+       min(timestamp),
+       (array_agg(value order by timestamp, id))[1],
+       max(timestamp),
+       (array_agg(value order by timestamp desc, id desc))[1]
+from (select sensor_id,
+             timestamp,
+             id,
+             coalesce(home_id, 0) as home_id,
+             coalesce(calibrated_value, case
+                 when value is null or jsonb_typeof(value) in ('null', 'boolean') then payload
+                 when jsonb_typeof(value) = 'number' then (value #>> '{}')::double precision
+             end) as value
+      from events) e
+         cross join (values (3600), (86400)) as r(resolution)
+where value is not null
+group by sensor_id, r.resolution, 3, home_id;
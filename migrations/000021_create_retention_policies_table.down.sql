@@ -0,0 +1 @@
+drop table retention_policies;
\ No newline at end of file
@@ -0,0 +1,8 @@
+create table retention_policies
+(
+    sensor_type text   not null default '',
+    sensor_id   bigint not null default 0,
+    retention   bigint not null,
+    primary key (sensor_type, sensor_id),
+    check ((sensor_type = '') <> (sensor_id = 0))
+);
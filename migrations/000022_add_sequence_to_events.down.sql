@@ -0,0 +1,8 @@
+drop index events_sensor_id_sequence_idx;
+
+drop index events_sequence_idx;
+
+alter table events
+    drop column sequence;
+
+drop table event_sequence;
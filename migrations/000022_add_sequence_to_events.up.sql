@@ -0,0 +1,23 @@
+create table event_sequence
+(
+    value bigint not null
+);
+
+insert into event_sequence (value)
+select coalesce(max(id), 0)
+from events;
+
+alter table events
+    add column sequence bigint;
+
+update events
+set sequence = id;
+
+alter table events
+    alter column sequence set not null;
+
+create unique index events_sequence_idx
+    on events (sequence);
+
+create index events_sensor_id_sequence_idx
+    on events (sensor_id, sequence);
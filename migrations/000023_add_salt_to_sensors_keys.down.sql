@@ -0,0 +1,2 @@
+alter table sensors_keys
+    drop column salt;
@@ -0,0 +1,2 @@
+alter table sensors_keys
+    add column salt bytea;
@@ -0,0 +1,10 @@
+create table event_sequence
+(
+    value bigint not null
+);
+
+insert into event_sequence (value)
+select case when is_called then last_value else last_value - 1 end
+from events_sequence_seq;
+
+drop sequence events_sequence_seq;
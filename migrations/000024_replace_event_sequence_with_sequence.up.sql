@@ -0,0 +1,6 @@
+create sequence events_sequence_seq;
+
+select setval('events_sequence_seq', value + 1, false)
+from event_sequence;
+
+drop table event_sequence;
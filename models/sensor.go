@@ -7,7 +7,6 @@ package models
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
@@ -55,9 +54,9 @@ type Sensor struct {
 	// Pattern: ^\d{10}$
 	SerialNumber *string `json:"serial_number"`
 
-	// Тип
+	// Тип, код из реестра типов датчиков
 	// Required: true
-	// Enum: ["cc","adc"]
+	// Pattern: ^[a-z][a-z0-9_]{0,31}$
 	Type *string `json:"type"`
 }
 
@@ -182,43 +181,13 @@ func (m *Sensor) validateSerialNumber(formats strfmt.Registry) error {
 	return nil
 }
 
-var sensorTypeTypePropEnum []interface{}
-
-func init() {
-	var res []string
-	if err := json.Unmarshal([]byte(`["cc","adc"]`), &res); err != nil {
-		panic(err)
-	}
-	for _, v := range res {
-		sensorTypeTypePropEnum = append(sensorTypeTypePropEnum, v)
-	}
-}
-
-const (
-
-	// SensorTypeCc captures enum value "cc"
-	SensorTypeCc string = "cc"
-
-	// SensorTypeAdc captures enum value "adc"
-	SensorTypeAdc string = "adc"
-)
-
-// prop value enum
-func (m *Sensor) validateTypeEnum(path, location string, value string) error {
-	if err := validate.EnumCase(path, location, value, sensorTypeTypePropEnum, true); err != nil {
-		return err
-	}
-	return nil
-}
-
 func (m *Sensor) validateType(formats strfmt.Registry) error {
 
 	if err := validate.Required("type", "body", m.Type); err != nil {
 		return err
 	}
 
-	// value enum
-	if err := m.validateTypeEnum("type", "body", *m.Type); err != nil {
+	if err := validate.Pattern("type", "body", *m.Type, `^[a-z][a-z0-9_]{0,31}$`); err != nil {
 		return err
 	}
 
@@ -22,20 +22,32 @@ import (
 // swagger:model SensorEvent
 type SensorEvent struct {
 
-	// Информация от датчика
+	// Идентификатор события, заданный устройством. Повтор события с тем же идентификатором не сохраняется
+	// Max Length: 128
+	EventID string `json:"event_id,omitempty"`
+
+	// Информация от датчика: целое или дробное число, логическое значение, строка или объект со значениями каналов
 	// Required: true
-	Payload *int64 `json:"payload"`
+	Payload interface{} `json:"payload"`
 
 	// Серийный номер датчика
 	// Required: true
 	// Pattern: ^\d{10}$
 	SensorSerialNumber *string `json:"sensor_serial_number"`
+
+	// Время события по часам устройства, если не указано - время получения события сервером
+	// Format: date-time
+	Timestamp strfmt.DateTime `json:"timestamp,omitempty"`
 }
 
 // Validate validates this sensor event
 func (m *SensorEvent) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateEventID(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validatePayload(formats); err != nil {
 		res = append(res, err)
 	}
@@ -44,21 +56,37 @@ func (m *SensorEvent) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateTimestamp(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
 	return nil
 }
 
-func (m *SensorEvent) validatePayload(formats strfmt.Registry) error {
+func (m *SensorEvent) validateEventID(formats strfmt.Registry) error {
+	if swag.IsZero(m.EventID) { // not required
+		return nil
+	}
 
-	if err := validate.Required("payload", "body", m.Payload); err != nil {
+	if err := validate.MaxLength("event_id", "body", m.EventID, 128); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+func (m *SensorEvent) validatePayload(formats strfmt.Registry) error {
+
+	if m.Payload == nil {
+		return errors.Required("payload", "body", nil)
+	}
+
+	return nil
+}
+
 func (m *SensorEvent) validateSensorSerialNumber(formats strfmt.Registry) error {
 
 	if err := validate.Required("sensor_serial_number", "body", m.SensorSerialNumber); err != nil {
@@ -72,6 +100,18 @@ func (m *SensorEvent) validateSensorSerialNumber(formats strfmt.Registry) error
 	return nil
 }
 
+func (m *SensorEvent) validateTimestamp(formats strfmt.Registry) error {
+	if swag.IsZero(m.Timestamp) { // not required
+		return nil
+	}
+
+	if err := validate.FormatOf("timestamp", "body", "date-time", m.Timestamp.String(), formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // ContextValidate validates this sensor event based on context it is used
 func (m *SensorEvent) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
 	return nil
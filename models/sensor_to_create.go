@@ -7,7 +7,6 @@ package models
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
@@ -27,6 +26,10 @@ type SensorToCreate struct {
 	// Required: true
 	Description *string `json:"description"`
 
+	// Идентификатор дома, в котором регистрируется датчик
+	// Minimum: 1
+	HomeID int64 `json:"home_id,omitempty"`
+
 	// Флаг активности датчика
 	// Required: true
 	IsActive *bool `json:"is_active"`
@@ -36,9 +39,9 @@ type SensorToCreate struct {
 	// Pattern: ^\d{10}$
 	SerialNumber *string `json:"serial_number"`
 
-	// Тип
+	// Тип, код из реестра типов датчиков
 	// Required: true
-	// Enum: ["cc","adc"]
+	// Pattern: ^[a-z][a-z0-9_]{0,31}$
 	Type *string `json:"type"`
 }
 
@@ -50,6 +53,10 @@ func (m *SensorToCreate) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateHomeID(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateIsActive(formats); err != nil {
 		res = append(res, err)
 	}
@@ -77,54 +84,37 @@ func (m *SensorToCreate) validateDescription(formats strfmt.Registry) error {
 	return nil
 }
 
-func (m *SensorToCreate) validateIsActive(formats strfmt.Registry) error {
+func (m *SensorToCreate) validateHomeID(formats strfmt.Registry) error {
+	if swag.IsZero(m.HomeID) { // not required
+		return nil
+	}
 
-	if err := validate.Required("is_active", "body", m.IsActive); err != nil {
+	if err := validate.MinimumInt("home_id", "body", m.HomeID, 1, false); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (m *SensorToCreate) validateSerialNumber(formats strfmt.Registry) error {
-
-	if err := validate.Required("serial_number", "body", m.SerialNumber); err != nil {
-		return err
-	}
+func (m *SensorToCreate) validateIsActive(formats strfmt.Registry) error {
 
-	if err := validate.Pattern("serial_number", "body", *m.SerialNumber, `^\d{10}$`); err != nil {
+	if err := validate.Required("is_active", "body", m.IsActive); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-var sensorToCreateTypeTypePropEnum []interface{}
+func (m *SensorToCreate) validateSerialNumber(formats strfmt.Registry) error {
 
-func init() {
-	var res []string
-	if err := json.Unmarshal([]byte(`["cc","adc"]`), &res); err != nil {
-		panic(err)
-	}
-	for _, v := range res {
-		sensorToCreateTypeTypePropEnum = append(sensorToCreateTypeTypePropEnum, v)
+	if err := validate.Required("serial_number", "body", m.SerialNumber); err != nil {
+		return err
 	}
-}
 
-const (
-
-	// SensorToCreateTypeCc captures enum value "cc"
-	SensorToCreateTypeCc string = "cc"
-
-	// SensorToCreateTypeAdc captures enum value "adc"
-	SensorToCreateTypeAdc string = "adc"
-)
-
-// prop value enum
-func (m *SensorToCreate) validateTypeEnum(path, location string, value string) error {
-	if err := validate.EnumCase(path, location, value, sensorToCreateTypeTypePropEnum, true); err != nil {
+	if err := validate.Pattern("serial_number", "body", *m.SerialNumber, `^\d{10}$`); err != nil {
 		return err
 	}
+
 	return nil
 }
 
@@ -134,8 +124,7 @@ func (m *SensorToCreate) validateType(formats strfmt.Registry) error {
 		return err
 	}
 
-	// value enum
-	if err := m.validateTypeEnum("type", "body", *m.Type); err != nil {
+	if err := validate.Pattern("type", "body", *m.Type, `^[a-z][a-z0-9_]{0,31}$`); err != nil {
 		return err
 	}
 
@@ -7,6 +7,7 @@ package models
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
@@ -17,11 +18,15 @@ import (
 // SensorToUserBinding SensorToUserBinding
 //
 // Связка датчика с пользователем
-// Example: {"sensor_id":1}
+// Example: {"role":"viewer","sensor_id":1}
 //
 // swagger:model SensorToUserBinding
 type SensorToUserBinding struct {
 
+	// Роль пользователя, по умолчанию viewer
+	// Enum: ["owner","member","viewer"]
+	Role string `json:"role,omitempty"`
+
 	// Идентификатор датчика
 	// Required: true
 	// Minimum: 1
@@ -32,6 +37,10 @@ type SensorToUserBinding struct {
 func (m *SensorToUserBinding) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateRole(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateSensorID(formats); err != nil {
 		res = append(res, err)
 	}
@@ -42,6 +51,51 @@ func (m *SensorToUserBinding) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
+var sensorToUserBindingTypeRolePropEnum []interface{}
+
+func init() {
+	var res []string
+	if err := json.Unmarshal([]byte(`["owner","member","viewer"]`), &res); err != nil {
+		panic(err)
+	}
+	for _, v := range res {
+		sensorToUserBindingTypeRolePropEnum = append(sensorToUserBindingTypeRolePropEnum, v)
+	}
+}
+
+const (
+
+	// SensorToUserBindingRoleOwner captures enum value "owner"
+	SensorToUserBindingRoleOwner string = "owner"
+
+	// SensorToUserBindingRoleMember captures enum value "member"
+	SensorToUserBindingRoleMember string = "member"
+
+	// SensorToUserBindingRoleViewer captures enum value "viewer"
+	SensorToUserBindingRoleViewer string = "viewer"
+)
+
+// prop value enum
+func (m *SensorToUserBinding) validateRoleEnum(path, location string, value string) error {
+	if err := validate.EnumCase(path, location, value, sensorToUserBindingTypeRolePropEnum, true); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *SensorToUserBinding) validateRole(formats strfmt.Registry) error {
+	if swag.IsZero(m.Role) { // not required
+		return nil
+	}
+
+	// value enum
+	if err := m.validateRoleEnum("role", "body", m.Role); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m *SensorToUserBinding) validateSensorID(formats strfmt.Registry) error {
 
 	if err := validate.Required("sensor_id", "body", m.SensorID); err != nil {
@@ -17,7 +17,7 @@ import (
 // UserToCreate UserToCreate
 //
 // Пользователь умного дома, которого надо создать
-// Example: {"name":"Иван Иваныч Иванов"}
+// Example: {"name":"Иван Иваныч Иванов","password":"correct horse"}
 //
 // swagger:model UserToCreate
 type UserToCreate struct {
@@ -26,6 +26,11 @@ type UserToCreate struct {
 	// Required: true
 	// Min Length: 1
 	Name *string `json:"name"`
+
+	// Пароль
+	// Required: true
+	// Min Length: 8
+	Password *string `json:"password"`
 }
 
 // Validate validates this user to create
@@ -36,6 +41,10 @@ func (m *UserToCreate) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validatePassword(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
@@ -55,6 +64,19 @@ func (m *UserToCreate) validateName(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *UserToCreate) validatePassword(formats strfmt.Registry) error {
+
+	if err := validate.Required("password", "body", m.Password); err != nil {
+		return err
+	}
+
+	if err := validate.MinLength("password", "body", *m.Password, 8); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // ContextValidate validates this user to create based on context it is used
 func (m *UserToCreate) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
 	return nil